@@ -9,12 +9,14 @@ import (
 	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
 	"github.com/spiffe/spire/cmd/spire-agent/cli/common"
 	"github.com/spiffe/spire/pkg/common/cli"
+	lsvidv1 "github.com/spiffe/spire/proto/spire/api/workload/lsvid/v1"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 )
 
 type workloadClient struct {
 	workload.SpiffeWorkloadAPIClient
+	lsvidv1.LSVIDWorkloadAPIClient
 	timeout time.Duration
 }
 
@@ -32,6 +34,7 @@ func newWorkloadClient(ctx context.Context, socketPath string, timeout time.Dura
 	}
 	return &workloadClient{
 		SpiffeWorkloadAPIClient: workload.NewSpiffeWorkloadAPIClient(conn),
+		LSVIDWorkloadAPIClient:  lsvidv1.NewLSVIDWorkloadAPIClient(conn),
 		timeout:                 timeout,
 	}, nil
 }
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	lsvidv1 "github.com/spiffe/spire/proto/spire/api/workload/lsvid/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type testEnv struct {
+	env    *common_cli.Env
+	stdin  *bytes.Buffer
+	stdout *bytes.Buffer
+	stderr *bytes.Buffer
+}
+
+func newTestEnv() *testEnv {
+	stdin := new(bytes.Buffer)
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	return &testEnv{
+		env: &common_cli.Env{
+			Stdin:  stdin,
+			Stdout: stdout,
+			Stderr: stderr,
+		},
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: stderr,
+	}
+}
+
+// fakeLSVIDClient records the requests made through the LSVID Workload API
+// and returns the configured responses.
+type fakeLSVIDClient struct {
+	lsvidv1.LSVIDWorkloadAPIClient
+
+	err error
+
+	fetchReq     *lsvidv1.LSVIDRequest
+	fetchResp    *lsvidv1.LSVIDResponse
+	validateReq  *lsvidv1.ValidateLSVIDRequest
+	validateResp *lsvidv1.ValidateLSVIDResponse
+	extendReq    *lsvidv1.ExtendLSVIDRequest
+	extendResp   *lsvidv1.ExtendLSVIDResponse
+}
+
+func (c *fakeLSVIDClient) FetchLSVID(ctx context.Context, req *lsvidv1.LSVIDRequest, opts ...grpc.CallOption) (*lsvidv1.LSVIDResponse, error) {
+	if err := checkWorkloadHeader(ctx); err != nil {
+		return nil, err
+	}
+	c.fetchReq = req
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.fetchResp, nil
+}
+
+func (c *fakeLSVIDClient) ValidateLSVID(ctx context.Context, req *lsvidv1.ValidateLSVIDRequest, opts ...grpc.CallOption) (*lsvidv1.ValidateLSVIDResponse, error) {
+	if err := checkWorkloadHeader(ctx); err != nil {
+		return nil, err
+	}
+	c.validateReq = req
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.validateResp, nil
+}
+
+func (c *fakeLSVIDClient) ExtendLSVID(ctx context.Context, req *lsvidv1.ExtendLSVIDRequest, opts ...grpc.CallOption) (*lsvidv1.ExtendLSVIDResponse, error) {
+	if err := checkWorkloadHeader(ctx); err != nil {
+		return nil, err
+	}
+	c.extendReq = req
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.extendResp, nil
+}
+
+func (c *fakeLSVIDClient) maker() workloadClientMaker {
+	return func(ctx context.Context, socketPath string, timeout time.Duration) (*workloadClient, error) {
+		return &workloadClient{
+			LSVIDWorkloadAPIClient: c,
+			timeout:                timeout,
+		}, nil
+	}
+}
+
+func checkWorkloadHeader(ctx context.Context) error {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if len(md.Get("workload.spiffe.io")) != 1 {
+		return errors.New("missing workload.spiffe.io header")
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/mitchellh/cli"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	lsvidv1 "github.com/spiffe/spire/proto/spire/api/workload/lsvid/v1"
+)
+
+func NewExtendLSVIDCommand() cli.Command {
+	return newExtendLSVIDCommand(common_cli.DefaultEnv, newWorkloadClient)
+}
+
+func newExtendLSVIDCommand(env *common_cli.Env, clientMaker workloadClientMaker) cli.Command {
+	return adaptCommand(env, clientMaker, new(extendLSVIDCommand))
+}
+
+type extendLSVIDCommand struct {
+	audience string
+	lsvid    string
+	alg      string
+}
+
+func (*extendLSVIDCommand) name() string {
+	return "extend lsvid"
+}
+
+func (*extendLSVIDCommand) synopsis() string {
+	return "Extends an LSVID to a new audience through the Workload API"
+}
+
+func (c *extendLSVIDCommand) appendFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.audience, "audience", "", "audience of the new layer")
+	fs.StringVar(&c.lsvid, "lsvid", "", "LSVID token or document addressed to the caller")
+	fs.StringVar(&c.alg, "alg", "", "signature algorithm of the new layer (defaults to the algorithm of the SVID key)")
+}
+
+func (c *extendLSVIDCommand) run(ctx context.Context, env *common_cli.Env, client *workloadClient) error {
+	if c.audience == "" {
+		return errors.New("audience must be specified")
+	}
+	if len(c.lsvid) == 0 {
+		return errors.New("lsvid must be specified")
+	}
+
+	resp, err := c.extendLSVID(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	if err := env.Printf("lsvid(%s):\n\t%s\n", resp.SpiffeId, resp.Lsvid); err != nil {
+		return err
+	}
+	return env.Printf("\texpires at %s\n", time.Unix(resp.ExpiresAt, 0).UTC().Format(time.RFC3339))
+}
+
+func (c *extendLSVIDCommand) extendLSVID(ctx context.Context, client *workloadClient) (*lsvidv1.ExtendLSVIDResponse, error) {
+	ctx, cancel := client.prepareContext(ctx)
+	defer cancel()
+	resp, err := client.ExtendLSVID(ctx, &lsvidv1.ExtendLSVIDRequest{
+		Lsvid:    c.lsvid,
+		Audience: c.audience,
+		Alg:      c.alg,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to extend LSVID: %w", err)
+	}
+	return resp, nil
+}
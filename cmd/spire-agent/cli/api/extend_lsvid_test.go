@@ -0,0 +1,107 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	lsvidv1 "github.com/spiffe/spire/proto/spire/api/workload/lsvid/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestExtendLSVIDSynopsis(t *testing.T) {
+	cmd := newExtendLSVIDCommand(newTestEnv().env, new(fakeLSVIDClient).maker())
+	assert.Equal(t, "Extends an LSVID to a new audience through the Workload API", cmd.Synopsis())
+}
+
+func TestExtendLSVIDRun(t *testing.T) {
+	expiresAt := time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		name string
+
+		args []string
+		resp *lsvidv1.ExtendLSVIDResponse
+		err  error
+
+		code    int
+		stdout  string
+		stderr  string
+		request *lsvidv1.ExtendLSVIDRequest
+	}{
+		{
+			name: "success",
+			args: []string{"-audience", "spiffe://example.org/service", "-lsvid", "token"},
+			resp: &lsvidv1.ExtendLSVIDResponse{
+				SpiffeId:  "spiffe://example.org/workload",
+				Lsvid:     "extended",
+				ExpiresAt: expiresAt.Unix(),
+			},
+			stdout: "lsvid(spiffe://example.org/workload):\n\textended\n\texpires at 2021-09-01T12:00:00Z\n",
+			request: &lsvidv1.ExtendLSVIDRequest{
+				Audience: "spiffe://example.org/service",
+				Lsvid:    "token",
+			},
+		},
+		{
+			name: "with algorithm",
+			args: []string{"-audience", "spiffe://example.org/service", "-lsvid", "token", "-alg", "SAEdDSA"},
+			resp: &lsvidv1.ExtendLSVIDResponse{
+				SpiffeId:  "spiffe://example.org/workload",
+				Lsvid:     "extended",
+				ExpiresAt: expiresAt.Unix(),
+			},
+			stdout: "lsvid(spiffe://example.org/workload):\n\textended\n\texpires at 2021-09-01T12:00:00Z\n",
+			request: &lsvidv1.ExtendLSVIDRequest{
+				Audience: "spiffe://example.org/service",
+				Lsvid:    "token",
+				Alg:      "SAEdDSA",
+			},
+		},
+		{
+			name:   "missing audience",
+			args:   []string{"-lsvid", "token"},
+			code:   1,
+			stderr: "audience must be specified\n",
+		},
+		{
+			name:   "missing lsvid",
+			args:   []string{"-audience", "spiffe://example.org/service"},
+			code:   1,
+			stderr: "lsvid must be specified\n",
+		},
+		{
+			name:   "extension fails",
+			args:   []string{"-audience", "spiffe://example.org/service", "-lsvid", "token"},
+			err:    status.Error(codes.PermissionDenied, "LSVID is not addressed to the caller"),
+			code:   1,
+			stderr: "unable to extend LSVID: rpc error: code = PermissionDenied desc = LSVID is not addressed to the caller\n",
+			request: &lsvidv1.ExtendLSVIDRequest{
+				Audience: "spiffe://example.org/service",
+				Lsvid:    "token",
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := newTestEnv()
+			client := &fakeLSVIDClient{extendResp: tt.resp, err: tt.err}
+			cmd := newExtendLSVIDCommand(test.env, client.maker())
+
+			code := cmd.Run(tt.args)
+			assert.Equal(t, tt.code, code)
+			assert.Equal(t, tt.stdout, test.stdout.String())
+			assert.Equal(t, tt.stderr, test.stderr.String())
+			if tt.request == nil {
+				assert.Nil(t, client.extendReq)
+				return
+			}
+			require.NotNil(t, client.extendReq)
+			assert.Equal(t, tt.request.Audience, client.extendReq.Audience)
+			assert.Equal(t, tt.request.Lsvid, client.extendReq.Lsvid)
+			assert.Equal(t, tt.request.Alg, client.extendReq.Alg)
+		})
+	}
+}
@@ -2,14 +2,13 @@ package api
 
 import (
 	"context"
-	// "errors"
+	"errors"
 	"flag"
 	"fmt"
 
 	"github.com/mitchellh/cli"
 	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
-	
 )
 
 func NewFetchJWTCommand() cli.Command {
@@ -26,15 +25,22 @@ type fetchJWTCommand struct {
 }
 
 func (c *fetchJWTCommand) name() string {
-	return "fetch svidng"
+	return "fetch jwt"
 }
 
 func (c *fetchJWTCommand) synopsis() string {
-	return "Fetches a SVID-NG from the Workload API"
+	return "Fetches a JWT SVID from the Workload API"
 }
 
 func (c *fetchJWTCommand) run(ctx context.Context, env *common_cli.Env, client *workloadClient) error {
+	if len(c.audience) == 0 {
+		return errors.New("audience must be specified")
+	}
 
+	bundlesResp, err := c.fetchJWTBundles(ctx, client)
+	if err != nil {
+		return err
+	}
 	svidResp, err := c.fetchJWTSVID(ctx, client)
 	if err != nil {
 		return err
@@ -44,11 +50,15 @@ func (c *fetchJWTCommand) run(ctx context.Context, env *common_cli.Env, client *
 		fmt.Printf("token(%s):\n\t%s\n", svid.SpiffeId, svid.Svid)
 	}
 
+	for trustDomainID, jwksJSON := range bundlesResp.Bundles {
+		fmt.Printf("bundle(%s):\n\t%s\n", trustDomainID, string(jwksJSON))
+	}
+
 	return nil
 }
 
 func (c *fetchJWTCommand) appendFlags(fs *flag.FlagSet) {
-	fs.Var(&c.audience, "audience", "Append the ID to a given one.")
+	fs.Var(&c.audience, "audience", "comma separated list of audience values")
 	fs.StringVar(&c.spiffeID, "spiffeID", "", "SPIFFE ID subject (optional)")
 }
 
@@ -66,7 +76,7 @@ func (c *fetchJWTCommand) fetchJWTBundles(ctx context.Context, client *workloadC
 	defer cancel()
 	stream, err := client.FetchJWTBundles(ctx, &workload.JWTBundlesRequest{})
 	if err != nil {
-		return nil, fmt.Errorf("failed to receive SVID-NG bundles: %w", err)
+		return nil, fmt.Errorf("failed to receive JWT bundles: %w", err)
 	}
 	return stream.Recv()
 }
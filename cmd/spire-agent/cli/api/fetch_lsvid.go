@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/mitchellh/cli"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	lsvidv1 "github.com/spiffe/spire/proto/spire/api/workload/lsvid/v1"
+)
+
+func NewFetchLSVIDCommand() cli.Command {
+	return newFetchLSVIDCommand(common_cli.DefaultEnv, newWorkloadClient)
+}
+
+func newFetchLSVIDCommand(env *common_cli.Env, clientMaker workloadClientMaker) cli.Command {
+	return adaptCommand(env, clientMaker, new(fetchLSVIDCommand))
+}
+
+type fetchLSVIDCommand struct {
+	spiffeID      string
+	includeBundle bool
+}
+
+func (c *fetchLSVIDCommand) name() string {
+	return "fetch lsvid"
+}
+
+func (c *fetchLSVIDCommand) synopsis() string {
+	return "Fetches LSVIDs from the Workload API"
+}
+
+func (c *fetchLSVIDCommand) run(ctx context.Context, env *common_cli.Env, client *workloadClient) error {
+	resp, err := c.fetchLSVID(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	for _, svid := range resp.Lsvids {
+		if err := env.Printf("lsvid(%s):\n\t%s\n", svid.SpiffeId, svid.Lsvid); err != nil {
+			return err
+		}
+		if err := env.Printf("\texpires at %s\n", time.Unix(svid.ExpiresAt, 0).UTC().Format(time.RFC3339)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *fetchLSVIDCommand) appendFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.spiffeID, "spiffeID", "", "SPIFFE ID subject (optional)")
+	fs.BoolVar(&c.includeBundle, "includeBundle", false, "Embed the LSVID trust bundle document in the returned LSVID documents")
+}
+
+func (c *fetchLSVIDCommand) fetchLSVID(ctx context.Context, client *workloadClient) (*lsvidv1.LSVIDResponse, error) {
+	ctx, cancel := client.prepareContext(ctx)
+	defer cancel()
+	resp, err := client.FetchLSVID(ctx, &lsvidv1.LSVIDRequest{
+		SpiffeId:      c.spiffeID,
+		IncludeBundle: c.includeBundle,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch LSVID: %w", err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	lsvidv1 "github.com/spiffe/spire/proto/spire/api/workload/lsvid/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFetchLSVIDSynopsis(t *testing.T) {
+	cmd := newFetchLSVIDCommand(newTestEnv().env, new(fakeLSVIDClient).maker())
+	assert.Equal(t, "Fetches LSVIDs from the Workload API", cmd.Synopsis())
+}
+
+func TestFetchLSVIDRun(t *testing.T) {
+	expiresAt := time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		name string
+
+		args []string
+		resp *lsvidv1.LSVIDResponse
+		err  error
+
+		code    int
+		stdout  string
+		stderr  string
+		request *lsvidv1.LSVIDRequest
+	}{
+		{
+			name: "success",
+			resp: &lsvidv1.LSVIDResponse{
+				Lsvids: []*lsvidv1.LSVID{
+					{SpiffeId: "spiffe://example.org/workload", Lsvid: "token1", ExpiresAt: expiresAt.Unix()},
+					{SpiffeId: "spiffe://example.org/other", Lsvid: "token2", ExpiresAt: expiresAt.Add(time.Hour).Unix()},
+				},
+			},
+			stdout: "lsvid(spiffe://example.org/workload):\n\ttoken1\n\texpires at 2021-09-01T12:00:00Z\n" +
+				"lsvid(spiffe://example.org/other):\n\ttoken2\n\texpires at 2021-09-01T13:00:00Z\n",
+			request: &lsvidv1.LSVIDRequest{},
+		},
+		{
+			name: "subject and bundle",
+			args: []string{"-spiffeID", "spiffe://example.org/workload", "-includeBundle"},
+			resp: &lsvidv1.LSVIDResponse{
+				Lsvids: []*lsvidv1.LSVID{
+					{SpiffeId: "spiffe://example.org/workload", Lsvid: "document", ExpiresAt: expiresAt.Unix()},
+				},
+			},
+			stdout: "lsvid(spiffe://example.org/workload):\n\tdocument\n\texpires at 2021-09-01T12:00:00Z\n",
+			request: &lsvidv1.LSVIDRequest{
+				SpiffeId:      "spiffe://example.org/workload",
+				IncludeBundle: true,
+			},
+		},
+		{
+			name:    "fetch fails",
+			err:     status.Error(codes.PermissionDenied, "no identity issued"),
+			code:    1,
+			stderr:  "unable to fetch LSVID: rpc error: code = PermissionDenied desc = no identity issued\n",
+			request: &lsvidv1.LSVIDRequest{},
+		},
+		{
+			name:   "invalid flag",
+			args:   []string{"-bad", "flag"},
+			code:   1,
+			stderr: "flag provided but not defined: -bad\n",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := newTestEnv()
+			client := &fakeLSVIDClient{fetchResp: tt.resp, err: tt.err}
+			cmd := newFetchLSVIDCommand(test.env, client.maker())
+
+			code := cmd.Run(tt.args)
+			assert.Equal(t, tt.code, code)
+			assert.Equal(t, tt.stdout, test.stdout.String())
+			if tt.code == 0 {
+				assert.Empty(t, test.stderr.String())
+			} else {
+				require.Contains(t, test.stderr.String(), tt.stderr)
+			}
+			if tt.request == nil {
+				assert.Nil(t, client.fetchReq)
+				return
+			}
+			require.NotNil(t, client.fetchReq)
+			assert.Equal(t, tt.request.SpiffeId, client.fetchReq.SpiffeId)
+			assert.Equal(t, tt.request.IncludeBundle, client.fetchReq.IncludeBundle)
+		})
+	}
+}
@@ -0,0 +1,357 @@
+package api
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/cli"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/lsvid"
+)
+
+const (
+	outputTree = "tree"
+	outputJSON = "json"
+)
+
+func NewInspectLSVIDCommand() cli.Command {
+	return newInspectLSVIDCommand(common_cli.DefaultEnv)
+}
+
+func newInspectLSVIDCommand(env *common_cli.Env) cli.Command {
+	c := &inspectLSVIDCommand{env: env}
+
+	fs := flag.NewFlagSet("inspect lsvid", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	fs.StringVar(&c.lsvid, "lsvid", "", "LSVID token or document to inspect. Read from stdin if not set")
+	fs.StringVar(&c.output, "output", outputTree, "Output format: tree or json")
+	c.flags = fs
+
+	return c
+}
+
+// inspectLSVIDCommand decodes an LSVID and prints its layers without
+// contacting the agent. Signatures are verified with the keys carried by the
+// token itself, so a valid signature does not mean the LSVID is trusted.
+type inspectLSVIDCommand struct {
+	env   *common_cli.Env
+	flags *flag.FlagSet
+
+	lsvid  string
+	output string
+}
+
+func (c *inspectLSVIDCommand) Synopsis() string {
+	return "Decodes an LSVID and prints its layers"
+}
+
+func (c *inspectLSVIDCommand) Help() string {
+	_ = c.flags.Parse([]string{"-h"})
+	return ""
+}
+
+func (c *inspectLSVIDCommand) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		_ = c.env.ErrPrintln(err)
+		return 1
+	}
+	if err := c.run(); err != nil {
+		_ = c.env.ErrPrintln("Error:", err)
+		return 1
+	}
+	return 0
+}
+
+func (c *inspectLSVIDCommand) run() error {
+	if c.output != outputTree && c.output != outputJSON {
+		return fmt.Errorf("unsupported output format %q", c.output)
+	}
+
+	encoded := c.lsvid
+	if encoded == "" {
+		data, err := io.ReadAll(c.env.Stdin)
+		if err != nil {
+			return fmt.Errorf("unable to read LSVID from stdin: %w", err)
+		}
+		encoded = string(data)
+	}
+	encoded = strings.TrimSpace(encoded)
+	if encoded == "" {
+		return errors.New("lsvid must be specified")
+	}
+
+	inspected, err := inspectLSVID(encoded)
+	if err != nil {
+		return err
+	}
+
+	if c.output == outputJSON {
+		out, err := json.MarshalIndent(inspected, "", "  ")
+		if err != nil {
+			return fmt.Errorf("unable to marshal LSVID: %w", err)
+		}
+		return c.env.Println(string(out))
+	}
+
+	root := &treeNode{label: "LSVID"}
+	root.add("token", inspected.Token.tree(1))
+	if inspected.Bundle != nil {
+		root.add("bundle", inspected.Bundle.tree(1))
+	}
+	return c.env.Printf("%s", root.String())
+}
+
+type inspectedLSVID struct {
+	Token  *inspectedLayer `json:"token"`
+	Bundle *inspectedLayer `json:"bundle,omitempty"`
+}
+
+type inspectedLayer struct {
+	Issuer    *inspectedID           `json:"iss,omitempty"`
+	Subject   *inspectedID           `json:"sub,omitempty"`
+	Audience  *inspectedID           `json:"aud,omitempty"`
+	Alg       string                 `json:"alg"`
+	IssuedAt  string                 `json:"iat,omitempty"`
+	ExpiresAt string                 `json:"exp,omitempty"`
+	Claims    map[string]interface{} `json:"claims,omitempty"`
+	Crit      []string               `json:"crit,omitempty"`
+	Keys      []*inspectedAuthority  `json:"keys,omitempty"`
+	Signature string                 `json:"signature"`
+	Nested    *inspectedLayer        `json:"nested,omitempty"`
+}
+
+type inspectedID struct {
+	CN  string          `json:"cn"`
+	PK  string          `json:"pk,omitempty"`
+	Kid string          `json:"kid,omitempty"`
+	X5T string          `json:"x5t,omitempty"`
+	ID  *inspectedLayer `json:"id,omitempty"`
+}
+
+type inspectedAuthority struct {
+	Kid       string `json:"kid"`
+	PK        string `json:"pk"`
+	ExpiresAt string `json:"exp,omitempty"`
+}
+
+// inspectLSVID decodes either an LSVID document or a bare LSVID token.
+func inspectLSVID(encoded string) (*inspectedLSVID, error) {
+	if doc, err := lsvid.DecodeLSVID(encoded); err == nil {
+		inspected := &inspectedLSVID{
+			Token: inspectLayer(doc.Token, doc.Token),
+		}
+		if doc.Bundle != nil {
+			inspected.Bundle = inspectLayer(doc.Bundle, doc.Bundle)
+		}
+		return inspected, nil
+	}
+
+	token, err := lsvid.Decode(encoded)
+	if err != nil {
+		return nil, err
+	}
+	return &inspectedLSVID{
+		Token: inspectLayer(token, token),
+	}, nil
+}
+
+func inspectLayer(layer, token *lsvid.Token) *inspectedLayer {
+	payload := layer.Payload
+	inspected := &inspectedLayer{
+		Issuer:    inspectID(payload.Iss),
+		Subject:   inspectID(payload.Sub),
+		Audience:  inspectID(payload.Aud),
+		Alg:       payload.Alg,
+		IssuedAt:  formatTime(payload.Iat),
+		ExpiresAt: formatTime(payload.Exp),
+		Claims:    payload.Claims,
+		Crit:      payload.Crit,
+		Signature: signatureStatus(layer, token),
+	}
+	for _, authority := range payload.Keys {
+		inspected.Keys = append(inspected.Keys, &inspectedAuthority{
+			Kid:       authority.Kid,
+			PK:        keyFingerprint(authority.PK),
+			ExpiresAt: formatTime(authority.Exp),
+		})
+	}
+	if layer.Nested != nil {
+		inspected.Nested = inspectLayer(layer.Nested, token)
+	}
+	return inspected
+}
+
+func inspectID(claim *lsvid.IDClaim) *inspectedID {
+	if claim == nil {
+		return nil
+	}
+	inspected := &inspectedID{
+		CN:  claim.CN,
+		Kid: claim.Kid,
+	}
+	if len(claim.PK) > 0 {
+		inspected.PK = keyFingerprint(claim.PK)
+	}
+	if len(claim.X5T) > 0 {
+		inspected.X5T = hex.EncodeToString(claim.X5T)
+	}
+	if claim.ID != nil {
+		inspected.ID = inspectLayer(claim.ID, claim.ID)
+	}
+	return inspected
+}
+
+// signatureStatus verifies the signature of the layer with the issuer key
+// found in the token: the embedded key of the root issuer, the subject key of
+// the issuer LSVID, or the root subject key when the subject extends its own
+// LSVID.
+func signatureStatus(layer, token *lsvid.Token) string {
+	iss := layer.Payload.Iss
+	if iss == nil {
+		return "unverified: missing issuer"
+	}
+
+	var rawKey []byte
+	note := ""
+	switch {
+	case layer.Nested == nil:
+		rawKey = iss.PK
+		note = " (embedded issuer key)"
+	case iss.ID != nil && iss.ID.Subject() != nil:
+		rawKey = iss.ID.Subject().PK
+	case token.Subject() != nil && token.Subject().CN == iss.CN:
+		rawKey = token.Subject().PK
+	}
+	switch {
+	case len(rawKey) == 0 && layer.Nested == nil && iss.Kid != "":
+		return fmt.Sprintf("unverified: issuer key %q is not embedded", iss.Kid)
+	case len(rawKey) == 0:
+		return "unverified: unable to resolve issuer key"
+	}
+
+	if alg := layer.Payload.Alg; alg == lsvid.AlgSAEdDSA && (layer.Aggregate == nil || (layer.Nested != nil && layer.Nested.Payload.Alg == alg)) {
+		return fmt.Sprintf("unverified: covered by the aggregate signature of its %s run", alg)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(rawKey)
+	if err != nil {
+		return fmt.Sprintf("unverified: %v", err)
+	}
+	if err := lsvid.VerifySignature(layer, key); err != nil {
+		return fmt.Sprintf("invalid: %v", err)
+	}
+	return "valid" + note
+}
+
+func keyFingerprint(rawKey []byte) string {
+	sum := sha256.Sum256(rawKey)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func formatTime(unix int64) string {
+	if unix == 0 {
+		return ""
+	}
+	return time.Unix(unix, 0).UTC().Format(time.RFC3339)
+}
+
+func (l *inspectedLayer) tree(depth int) *treeNode {
+	node := &treeNode{label: fmt.Sprintf("layer %d", depth)}
+	node.addID("iss", l.Issuer)
+	node.addID("sub", l.Subject)
+	node.addID("aud", l.Audience)
+	node.add("alg: " + l.Alg)
+	if l.IssuedAt != "" {
+		node.add("iat: " + l.IssuedAt)
+	}
+	if l.ExpiresAt != "" {
+		node.add("exp: " + l.ExpiresAt)
+	}
+	if len(l.Claims) > 0 {
+		claims := node.add("claims")
+		names := make([]string, 0, len(l.Claims))
+		for name := range l.Claims {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			value, _ := json.Marshal(l.Claims[name])
+			claims.add(fmt.Sprintf("%s: %s", name, value))
+		}
+	}
+	if len(l.Crit) > 0 {
+		node.add("crit: " + strings.Join(l.Crit, ", "))
+	}
+	if len(l.Keys) > 0 {
+		keys := node.add("keys")
+		for _, authority := range l.Keys {
+			key := keys.add("kid: " + authority.Kid)
+			key.add("pk: " + authority.PK)
+			if authority.ExpiresAt != "" {
+				key.add("exp: " + authority.ExpiresAt)
+			}
+		}
+	}
+	node.add("signature: " + l.Signature)
+	if l.Nested != nil {
+		node.add("nested", l.Nested.tree(depth+1))
+	}
+	return node
+}
+
+type treeNode struct {
+	label    string
+	children []*treeNode
+}
+
+func (n *treeNode) add(label string, children ...*treeNode) *treeNode {
+	child := &treeNode{label: label, children: children}
+	n.children = append(n.children, child)
+	return child
+}
+
+func (n *treeNode) addID(name string, id *inspectedID) {
+	if id == nil {
+		return
+	}
+	node := n.add(name + ": " + id.CN)
+	if id.PK != "" {
+		node.add("pk: " + id.PK)
+	}
+	if id.Kid != "" {
+		node.add("kid: " + id.Kid)
+	}
+	if id.X5T != "" {
+		node.add("x5t: " + id.X5T)
+	}
+	if id.ID != nil {
+		node.add("id", id.ID.tree(1))
+	}
+}
+
+func (n *treeNode) String() string {
+	b := new(strings.Builder)
+	b.WriteString(n.label + "\n")
+	n.writeChildren(b, "")
+	return b.String()
+}
+
+func (n *treeNode) writeChildren(b *strings.Builder, prefix string) {
+	for i, child := range n.children {
+		branch, indent := "├── ", "│   "
+		if i == len(n.children)-1 {
+			branch, indent = "└── ", "    "
+		}
+		b.WriteString(prefix + branch + child.label + "\n")
+		child.writeChildren(b, prefix+indent)
+	}
+}
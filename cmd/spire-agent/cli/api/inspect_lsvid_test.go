@@ -0,0 +1,370 @@
+package api
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spiffe/spire/pkg/common/lsvid"
+	"github.com/spiffe/spire/test/testkey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	inspectTrustDomainID = "spiffe://example.org"
+	inspectWorkloadID    = "spiffe://example.org/workload"
+	inspectServiceID     = "spiffe://example.org/service"
+)
+
+var (
+	inspectIssuedAt  = time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+	inspectExpiresAt = inspectIssuedAt.Add(time.Hour)
+)
+
+func TestInspectLSVIDSynopsis(t *testing.T) {
+	cmd := newInspectLSVIDCommand(newTestEnv().env)
+	assert.Equal(t, "Decodes an LSVID and prints its layers", cmd.Synopsis())
+}
+
+func TestInspectLSVIDRun(t *testing.T) {
+	rootKey := testkey.MustEC256()
+	workloadKey := testkey.MustEC384()
+
+	token := inspectRoot(t, rootKey, workloadKey, map[string]interface{}{
+		"https://example.org/order": "42",
+	})
+	encoded, err := lsvid.Encode(token)
+	require.NoError(t, err)
+
+	document, err := lsvid.EncodeLSVID(&lsvid.LSVID{
+		Token:  token,
+		Bundle: inspectBundle(t, rootKey),
+	})
+	require.NoError(t, err)
+
+	tree := fmt.Sprintf(`LSVID
+└── token
+    └── layer 1
+        ├── iss: spiffe://example.org
+        │   └── pk: %s
+        ├── sub: spiffe://example.org/workload
+        │   └── pk: %s
+        ├── aud: spiffe://example.org/service
+        ├── alg: ES256
+        ├── iat: 2021-09-01T12:00:00Z
+        ├── exp: 2021-09-01T13:00:00Z
+        ├── claims
+        │   └── https://example.org/order: "42"
+        └── signature: valid (embedded issuer key)
+`, inspectFingerprint(t, rootKey), inspectFingerprint(t, workloadKey))
+
+	bundleTree := fmt.Sprintf(`└── bundle
+    └── layer 1
+        ├── iss: spiffe://example.org
+        │   └── pk: %s
+        ├── sub: spiffe://example.org
+        ├── alg: ES256
+        ├── iat: 2021-09-01T12:00:00Z
+        ├── exp: 2021-09-01T13:00:00Z
+        ├── keys
+        │   └── kid: authority
+        │       ├── pk: %s
+        │       └── exp: 2021-09-01T13:00:00Z
+        └── signature: valid (embedded issuer key)
+`, inspectFingerprint(t, rootKey), inspectFingerprint(t, rootKey))
+
+	for _, tt := range []struct {
+		name  string
+		args  []string
+		stdin string
+
+		code   int
+		stdout string
+		stderr string
+	}{
+		{
+			name:   "bare token from flag",
+			args:   []string{"-lsvid", encoded},
+			stdout: tree,
+		},
+		{
+			name:   "bare token from stdin",
+			stdin:  encoded + "\n",
+			stdout: tree,
+		},
+		{
+			name:   "document",
+			args:   []string{"-lsvid", document},
+			stdout: "LSVID\n├── token\n" + indentTree(tree) + bundleTree,
+		},
+		{
+			name:   "missing lsvid",
+			stdin:  " \n",
+			code:   1,
+			stderr: "Error: lsvid must be specified\n",
+		},
+		{
+			name:   "unsupported output",
+			args:   []string{"-lsvid", encoded, "-output", "yaml"},
+			code:   1,
+			stderr: "Error: unsupported output format \"yaml\"\n",
+		},
+		{
+			name:   "malformed lsvid",
+			args:   []string{"-lsvid", "not an LSVID"},
+			code:   1,
+			stderr: "Error: ",
+		},
+		{
+			name:   "invalid flag",
+			args:   []string{"-bad", "flag"},
+			code:   1,
+			stderr: "flag provided but not defined: -bad\n",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := newTestEnv()
+			test.stdin.WriteString(tt.stdin)
+			cmd := newInspectLSVIDCommand(test.env)
+
+			code := cmd.Run(tt.args)
+			assert.Equal(t, tt.code, code)
+			assert.Equal(t, tt.stdout, test.stdout.String())
+			if tt.code == 0 {
+				assert.Empty(t, test.stderr.String())
+			} else {
+				assert.Contains(t, test.stderr.String(), tt.stderr)
+			}
+		})
+	}
+}
+
+func TestInspectLSVIDJSONOutput(t *testing.T) {
+	rootKey := testkey.MustEC256()
+	workloadKey := testkey.MustEC384()
+
+	token := inspectRoot(t, rootKey, workloadKey, nil)
+	extended, err := lsvid.Extend(token, &lsvid.Payload{
+		Ver: lsvid.Version,
+		Iss: &lsvid.IDClaim{CN: inspectWorkloadID},
+		Aud: &lsvid.IDClaim{CN: "spiffe://example.org/other"},
+	}, workloadKey)
+	require.NoError(t, err)
+	encoded, err := lsvid.Encode(extended)
+	require.NoError(t, err)
+
+	test := newTestEnv()
+	cmd := newInspectLSVIDCommand(test.env)
+	require.Equal(t, 0, cmd.Run([]string{"-lsvid", encoded, "-output", "json"}))
+	require.Empty(t, test.stderr.String())
+
+	var inspected inspectedLSVID
+	require.NoError(t, json.Unmarshal(test.stdout.Bytes(), &inspected))
+	require.Nil(t, inspected.Bundle)
+	require.NotNil(t, inspected.Token)
+
+	outer := inspected.Token
+	assert.Equal(t, inspectWorkloadID, outer.Issuer.CN)
+	assert.Equal(t, "spiffe://example.org/other", outer.Audience.CN)
+	assert.Equal(t, lsvid.AlgES384, outer.Alg)
+	assert.Equal(t, "2021-09-01T13:00:00Z", outer.ExpiresAt)
+	assert.Equal(t, "valid", outer.Signature)
+
+	require.NotNil(t, outer.Nested)
+	inner := outer.Nested
+	assert.Equal(t, inspectTrustDomainID, inner.Issuer.CN)
+	assert.Equal(t, inspectFingerprint(t, rootKey), inner.Issuer.PK)
+	assert.Equal(t, inspectWorkloadID, inner.Subject.CN)
+	assert.Equal(t, inspectFingerprint(t, workloadKey), inner.Subject.PK)
+	assert.Equal(t, lsvid.AlgES256, inner.Alg)
+	assert.Equal(t, "valid (embedded issuer key)", inner.Signature)
+	assert.Nil(t, inner.Nested)
+}
+
+func TestSignatureStatus(t *testing.T) {
+	rootKey := testkey.MustEC256()
+	workloadKey := testkey.MustEC384()
+	otherKey := testkey.MustEC256()
+
+	token := inspectRoot(t, rootKey, workloadKey, nil)
+
+	_, edRootKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, edWorkloadKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	single, err := lsvid.Sign(&lsvid.Payload{
+		Ver: lsvid.Version,
+		Alg: lsvid.AlgSAEdDSA,
+		Iat: inspectIssuedAt.Unix(),
+		Exp: inspectExpiresAt.Unix(),
+		Iss: &lsvid.IDClaim{CN: inspectTrustDomainID, PK: inspectMarshalKey(t, edRootKey)},
+		Sub: &lsvid.IDClaim{CN: inspectWorkloadID, PK: inspectMarshalKey(t, edWorkloadKey)},
+		Aud: &lsvid.IDClaim{CN: inspectServiceID},
+	}, edRootKey)
+	require.NoError(t, err)
+	aggregated, err := lsvid.Extend(single, &lsvid.Payload{
+		Ver: lsvid.Version,
+		Alg: lsvid.AlgSAEdDSA,
+		Iss: &lsvid.IDClaim{CN: inspectWorkloadID},
+		Aud: &lsvid.IDClaim{CN: "spiffe://example.org/other"},
+	}, edWorkloadKey)
+	require.NoError(t, err)
+
+	kidOnly, err := lsvid.Sign(&lsvid.Payload{
+		Ver: lsvid.Version,
+		Exp: inspectExpiresAt.Unix(),
+		Iss: &lsvid.IDClaim{CN: inspectTrustDomainID, Kid: "authority"},
+		Sub: &lsvid.IDClaim{CN: inspectWorkloadID, PK: inspectMarshalKey(t, workloadKey)},
+		Aud: &lsvid.IDClaim{CN: inspectServiceID},
+	}, rootKey)
+	require.NoError(t, err)
+
+	unresolved, err := lsvid.Extend(token, &lsvid.Payload{
+		Ver: lsvid.Version,
+		Iss: &lsvid.IDClaim{CN: "spiffe://example.org/unknown"},
+		Aud: &lsvid.IDClaim{CN: inspectWorkloadID},
+	}, otherKey)
+	require.NoError(t, err)
+
+	tampered := inspectRoot(t, rootKey, workloadKey, nil)
+	tampered.Payload.Iss.PK = inspectMarshalKey(t, otherKey)
+
+	for _, tt := range []struct {
+		name   string
+		layer  *lsvid.Token
+		token  *lsvid.Token
+		status string
+	}{
+		{
+			name:   "embedded issuer key",
+			layer:  token,
+			token:  token,
+			status: "valid (embedded issuer key)",
+		},
+		{
+			name:   "missing issuer",
+			layer:  &lsvid.Token{Payload: &lsvid.Payload{Alg: lsvid.AlgES256}},
+			token:  token,
+			status: "unverified: missing issuer",
+		},
+		{
+			name:   "issuer key not embedded",
+			layer:  kidOnly,
+			token:  kidOnly,
+			status: `unverified: issuer key "authority" is not embedded`,
+		},
+		{
+			name:   "issuer key not resolved",
+			layer:  unresolved,
+			token:  unresolved,
+			status: "unverified: unable to resolve issuer key",
+		},
+		{
+			name:   "invalid signature",
+			layer:  tampered,
+			token:  tampered,
+			status: "invalid: ",
+		},
+		{
+			name:   "aggregate run of a single layer",
+			layer:  single,
+			token:  single,
+			status: "valid (embedded issuer key)",
+		},
+		{
+			name:   "outermost layer of an aggregate run",
+			layer:  aggregated,
+			token:  aggregated,
+			status: "unverified: covered by the aggregate signature of its SAEdDSA run",
+		},
+		{
+			name:   "inner layer of an aggregate run",
+			layer:  aggregated.Nested,
+			token:  aggregated,
+			status: "unverified: covered by the aggregate signature of its SAEdDSA run",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Contains(t, signatureStatus(tt.layer, tt.token), tt.status)
+		})
+	}
+}
+
+func TestTreeNode(t *testing.T) {
+	root := &treeNode{label: "root"}
+	a := root.add("a")
+	a.add("a1")
+	a.add("a2").add("a2x")
+	root.add("b", &treeNode{label: "b1"})
+
+	require.Equal(t, `root
+├── a
+│   ├── a1
+│   └── a2
+│       └── a2x
+└── b
+    └── b1
+`, root.String())
+}
+
+func inspectRoot(t *testing.T, rootKey, subjectKey crypto.Signer, claims map[string]interface{}) *lsvid.Token {
+	token, err := lsvid.Sign(&lsvid.Payload{
+		Ver:    lsvid.Version,
+		Alg:    lsvid.AlgES256,
+		Iat:    inspectIssuedAt.Unix(),
+		Exp:    inspectExpiresAt.Unix(),
+		Iss:    &lsvid.IDClaim{CN: inspectTrustDomainID, PK: inspectMarshalKey(t, rootKey)},
+		Sub:    &lsvid.IDClaim{CN: inspectWorkloadID, PK: inspectMarshalKey(t, subjectKey)},
+		Aud:    &lsvid.IDClaim{CN: inspectServiceID},
+		Claims: claims,
+	}, rootKey)
+	require.NoError(t, err)
+	return token
+}
+
+func inspectBundle(t *testing.T, key crypto.Signer) *lsvid.Token {
+	bundle, err := lsvid.Sign(&lsvid.Payload{
+		Ver: lsvid.Version,
+		Alg: lsvid.AlgES256,
+		Iat: inspectIssuedAt.Unix(),
+		Exp: inspectExpiresAt.Unix(),
+		Iss: &lsvid.IDClaim{CN: inspectTrustDomainID, PK: inspectMarshalKey(t, key)},
+		Sub: &lsvid.IDClaim{CN: inspectTrustDomainID},
+		Keys: []*lsvid.Authority{
+			{Kid: "authority", PK: inspectMarshalKey(t, key), Exp: inspectExpiresAt.Unix()},
+		},
+	}, key)
+	require.NoError(t, err)
+	return bundle
+}
+
+func inspectMarshalKey(t *testing.T, key crypto.Signer) []byte {
+	pk, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+	return pk
+}
+
+func inspectFingerprint(t *testing.T, key crypto.Signer) string {
+	return keyFingerprint(inspectMarshalKey(t, key))
+}
+
+// indentTree returns the token subtree of a tree rendered by inspect, as
+// rendered when a bundle follows it.
+func indentTree(tree string) string {
+	lines := strings.SplitAfter(strings.TrimPrefix(tree, "LSVID\n└── token\n"), "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "│" + strings.TrimPrefix(line, " ")
+		}
+	}
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/mitchellh/cli"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	lsvidv1 "github.com/spiffe/spire/proto/spire/api/workload/lsvid/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func NewValidateLSVIDCommand() cli.Command {
+	return newValidateLSVIDCommand(common_cli.DefaultEnv, newWorkloadClient)
+}
+
+func newValidateLSVIDCommand(env *common_cli.Env, clientMaker workloadClientMaker) cli.Command {
+	return adaptCommand(env, clientMaker, new(validateLSVIDCommand))
+}
+
+type validateLSVIDCommand struct {
+	audience string
+	lsvid    string
+}
+
+func (*validateLSVIDCommand) name() string {
+	return "validate lsvid"
+}
+
+func (*validateLSVIDCommand) synopsis() string {
+	return "Validates an LSVID"
+}
+
+func (c *validateLSVIDCommand) appendFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.audience, "audience", "", "expected audience value")
+	fs.StringVar(&c.lsvid, "lsvid", "", "LSVID token or document")
+}
+
+func (c *validateLSVIDCommand) run(ctx context.Context, env *common_cli.Env, client *workloadClient) error {
+	if c.audience == "" {
+		return errors.New("audience must be specified")
+	}
+	if len(c.lsvid) == 0 {
+		return errors.New("lsvid must be specified")
+	}
+
+	resp, err := c.validateLSVID(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	if err := env.Println("LSVID is valid."); err != nil {
+		return err
+	}
+	if err := env.Println("SPIFFE ID :", resp.SpiffeId); err != nil {
+		return err
+	}
+	claims, err := protojson.Marshal(resp.Claims)
+	if err != nil {
+		return fmt.Errorf("unable to unmarshal claims: %w", err)
+	}
+	return env.Println("Claims    :", string(claims))
+}
+
+func (c *validateLSVIDCommand) validateLSVID(ctx context.Context, client *workloadClient) (*lsvidv1.ValidateLSVIDResponse, error) {
+	ctx, cancel := client.prepareContext(ctx)
+	defer cancel()
+	resp, err := client.ValidateLSVID(ctx, &lsvidv1.ValidateLSVIDRequest{
+		Audience: c.audience,
+		Lsvid:    c.lsvid,
+	})
+	if err != nil {
+		if s := status.Convert(err); s.Code() == codes.InvalidArgument {
+			for _, detail := range s.Details() {
+				if info, ok := detail.(*errdetails.ErrorInfo); ok {
+					return nil, fmt.Errorf("LSVID is not valid (%s): %v", info.Reason, s.Message())
+				}
+			}
+			return nil, fmt.Errorf("LSVID is not valid: %v", s.Message())
+		}
+		return nil, fmt.Errorf("unable to validate LSVID: %w", err)
+	}
+	return resp, nil
+}
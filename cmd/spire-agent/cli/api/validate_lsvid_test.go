@@ -0,0 +1,124 @@
+package api
+
+import (
+	"testing"
+
+	lsvidv1 "github.com/spiffe/spire/proto/spire/api/workload/lsvid/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestValidateLSVIDSynopsis(t *testing.T) {
+	cmd := newValidateLSVIDCommand(newTestEnv().env, new(fakeLSVIDClient).maker())
+	assert.Equal(t, "Validates an LSVID", cmd.Synopsis())
+}
+
+func TestValidateLSVIDRun(t *testing.T) {
+	claims, err := structpb.NewStruct(map[string]interface{}{
+		"sub": "spiffe://example.org/workload",
+	})
+	require.NoError(t, err)
+
+	invalid, err := status.New(codes.InvalidArgument, "LSVID has expired").WithDetails(&errdetails.ErrorInfo{
+		Reason: "EXPIRED",
+		Domain: "lsvid.spiffe.io",
+	})
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name string
+
+		args []string
+		resp *lsvidv1.ValidateLSVIDResponse
+		err  error
+
+		code    int
+		stdout  string
+		stderr  string
+		request *lsvidv1.ValidateLSVIDRequest
+	}{
+		{
+			name: "success",
+			args: []string{"-audience", "spiffe://example.org/service", "-lsvid", "token"},
+			resp: &lsvidv1.ValidateLSVIDResponse{
+				SpiffeId: "spiffe://example.org/workload",
+				Claims:   claims,
+			},
+			stdout: "LSVID is valid.\n" +
+				"SPIFFE ID : spiffe://example.org/workload\n" +
+				"Claims    : {\"sub\":\"spiffe://example.org/workload\"}\n",
+			request: &lsvidv1.ValidateLSVIDRequest{
+				Audience: "spiffe://example.org/service",
+				Lsvid:    "token",
+			},
+		},
+		{
+			name:   "missing audience",
+			args:   []string{"-lsvid", "token"},
+			code:   1,
+			stderr: "audience must be specified\n",
+		},
+		{
+			name:   "missing lsvid",
+			args:   []string{"-audience", "spiffe://example.org/service"},
+			code:   1,
+			stderr: "lsvid must be specified\n",
+		},
+		{
+			name:   "invalid LSVID with reason",
+			args:   []string{"-audience", "spiffe://example.org/service", "-lsvid", "token"},
+			err:    invalid.Err(),
+			code:   1,
+			stderr: "LSVID is not valid (EXPIRED): LSVID has expired\n",
+			request: &lsvidv1.ValidateLSVIDRequest{
+				Audience: "spiffe://example.org/service",
+				Lsvid:    "token",
+			},
+		},
+		{
+			name:   "invalid LSVID",
+			args:   []string{"-audience", "spiffe://example.org/service", "-lsvid", "token"},
+			err:    status.Error(codes.InvalidArgument, "malformed LSVID"),
+			code:   1,
+			stderr: "LSVID is not valid: malformed LSVID\n",
+			request: &lsvidv1.ValidateLSVIDRequest{
+				Audience: "spiffe://example.org/service",
+				Lsvid:    "token",
+			},
+		},
+		{
+			name:   "validation fails",
+			args:   []string{"-audience", "spiffe://example.org/service", "-lsvid", "token"},
+			err:    status.Error(codes.Unavailable, "agent unavailable"),
+			code:   1,
+			stderr: "unable to validate LSVID: rpc error: code = Unavailable desc = agent unavailable\n",
+			request: &lsvidv1.ValidateLSVIDRequest{
+				Audience: "spiffe://example.org/service",
+				Lsvid:    "token",
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := newTestEnv()
+			client := &fakeLSVIDClient{validateResp: tt.resp, err: tt.err}
+			cmd := newValidateLSVIDCommand(test.env, client.maker())
+
+			code := cmd.Run(tt.args)
+			assert.Equal(t, tt.code, code)
+			assert.Equal(t, tt.stdout, test.stdout.String())
+			assert.Equal(t, tt.stderr, test.stderr.String())
+			if tt.request == nil {
+				assert.Nil(t, client.validateReq)
+				return
+			}
+			require.NotNil(t, client.validateReq)
+			assert.Equal(t, tt.request.Audience, client.validateReq.Audience)
+			assert.Equal(t, tt.request.Lsvid, client.validateReq.Lsvid)
+		})
+	}
+}
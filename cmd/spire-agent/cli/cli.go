@@ -30,9 +30,21 @@ func (cc *CLI) Run(args []string) int {
 		"api fetch jwt": func() (cli.Command, error) {
 			return api.NewFetchJWTCommand(), nil
 		},
+		"api fetch lsvid": func() (cli.Command, error) {
+			return api.NewFetchLSVIDCommand(), nil
+		},
 		"api validate jwt": func() (cli.Command, error) {
 			return api.NewValidateJWTCommand(), nil
 		},
+		"api validate lsvid": func() (cli.Command, error) {
+			return api.NewValidateLSVIDCommand(), nil
+		},
+		"api extend lsvid": func() (cli.Command, error) {
+			return api.NewExtendLSVIDCommand(), nil
+		},
+		"api inspect lsvid": func() (cli.Command, error) {
+			return api.NewInspectLSVIDCommand(), nil
+		},
 		"api watch": func() (cli.Command, error) {
 			return &api.WatchCLI{}, nil
 		},
@@ -20,6 +20,7 @@ import (
 	"github.com/imdario/mergo"
 	"github.com/mitchellh/cli"
 	"github.com/sirupsen/logrus"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/cmd/spire-agent/cli/common"
 	"github.com/spiffe/spire/pkg/agent"
 	"github.com/spiffe/spire/pkg/common/catalog"
@@ -27,6 +28,7 @@ import (
 	"github.com/spiffe/spire/pkg/common/health"
 	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/common/log"
+	"github.com/spiffe/spire/pkg/common/lsvid"
 	"github.com/spiffe/spire/pkg/common/pemutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/util"
@@ -71,6 +73,11 @@ type agentConfig struct {
 	TrustDomain                   string    `hcl:"trust_domain"`
 	AllowUnauthenticatedVerifiers bool      `hcl:"allow_unauthenticated_verifiers"`
 	AllowedForeignJWTClaims       []string  `hcl:"allowed_foreign_jwt_claims"`
+	KnownLSVIDClaims              []string  `hcl:"known_lsvid_claims"`
+
+	LSVIDFederationPolicy map[string]lsvidFederationPolicyConfig `hcl:"lsvid_federation_policy"`
+	LSVIDChainPolicy      *lsvidChainPolicyConfig                `hcl:"lsvid_chain_policy"`
+	LSVIDReplayCaches     map[string]string                      `hcl:"lsvid_replay_caches"`
 
 	AuthorizedDelegates []string `hcl:"authorized_delegates"`
 
@@ -93,6 +100,32 @@ type sdsConfig struct {
 	DefaultAllBundlesName string `hcl:"default_all_bundles_name"`
 }
 
+type lsvidFederationPolicyConfig struct {
+	ForeignIssuers []lsvidForeignIssuerConfig `hcl:"foreign_issuers"`
+	UnusedKeys     []string                   `hcl:",unusedKeys"`
+}
+
+type lsvidForeignIssuerConfig struct {
+	ID         string   `hcl:"id"`
+	MinDepth   int      `hcl:"min_depth"`
+	MaxDepth   int      `hcl:"max_depth"`
+	UnusedKeys []string `hcl:",unusedKeys"`
+}
+
+type lsvidChainPolicyConfig struct {
+	MaxDepth    int                   `hcl:"max_depth"`
+	AllowCycles bool                  `hcl:"allow_cycles"`
+	Hops        []lsvidChainHopConfig `hcl:"hops"`
+	UnusedKeys  []string              `hcl:",unusedKeys"`
+}
+
+type lsvidChainHopConfig struct {
+	Depth      int      `hcl:"depth"`
+	Issuers    []string `hcl:"issuers"`
+	Audiences  []string `hcl:"audiences"`
+	UnusedKeys []string `hcl:",unusedKeys"`
+}
+
 type experimentalConfig struct {
 	SyncInterval string `hcl:"sync_interval"`
 
@@ -421,6 +454,28 @@ func NewAgentConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool)
 	ac.ProfilingNames = c.Agent.ProfilingNames
 
 	ac.AllowedForeignJWTClaims = c.Agent.AllowedForeignJWTClaims
+	ac.KnownLSVIDClaims = c.Agent.KnownLSVIDClaims
+
+	if c.Agent.LSVIDFederationPolicy != nil {
+		ac.LSVIDFederationPolicy, err = parseLSVIDFederationPolicy(c.Agent.LSVIDFederationPolicy, ac.TrustDomain)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.Agent.LSVIDChainPolicy != nil {
+		ac.LSVIDChainPolicy, err = parseLSVIDChainPolicy(c.Agent.LSVIDChainPolicy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for audience, kind := range c.Agent.LSVIDReplayCaches {
+		if kind != agent.LSVIDReplayCacheMemory && kind != agent.LSVIDReplayCacheDisk {
+			return nil, fmt.Errorf("lsvid_replay_caches[%q]: unknown replay cache %q; must be %q or %q", audience, kind, agent.LSVIDReplayCacheMemory, agent.LSVIDReplayCacheDisk)
+		}
+	}
+	ac.LSVIDReplayCaches = c.Agent.LSVIDReplayCaches
 
 	ac.PluginConfigs = *c.Plugins
 	ac.Telemetry = c.Telemetry
@@ -445,6 +500,70 @@ func NewAgentConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool)
 	return ac, nil
 }
 
+func parseLSVIDFederationPolicy(c map[string]lsvidFederationPolicyConfig, localTD spiffeid.TrustDomain) (lsvid.FederationPolicy, error) {
+	policy := make(lsvid.FederationPolicy, len(c))
+	for trustDomain, tdConfig := range c {
+		td, err := idutil.TrustDomainFromString(trustDomain)
+		if err != nil {
+			return nil, fmt.Errorf("lsvid_federation_policy[%q]: %w", trustDomain, err)
+		}
+		if td == localTD {
+			return nil, fmt.Errorf("lsvid_federation_policy[%q]: the trust domain of the agent is not a foreign trust domain", trustDomain)
+		}
+
+		var issuers []lsvid.ForeignIssuer
+		for _, issuer := range tdConfig.ForeignIssuers {
+			if issuer.ID != "" {
+				id, err := spiffeid.FromString(issuer.ID)
+				if err != nil {
+					return nil, fmt.Errorf("lsvid_federation_policy[%q]: invalid foreign issuer: %w", trustDomain, err)
+				}
+				if !id.MemberOf(td) {
+					return nil, fmt.Errorf("lsvid_federation_policy[%q]: foreign issuer %q does not belong to the trust domain", trustDomain, issuer.ID)
+				}
+			}
+			if issuer.MinDepth < 0 || issuer.MaxDepth < 0 {
+				return nil, fmt.Errorf("lsvid_federation_policy[%q]: foreign issuer depths cannot be negative", trustDomain)
+			}
+			if issuer.MaxDepth != 0 && issuer.MinDepth > issuer.MaxDepth {
+				return nil, fmt.Errorf("lsvid_federation_policy[%q]: foreign issuer min_depth cannot be greater than max_depth", trustDomain)
+			}
+			issuers = append(issuers, lsvid.ForeignIssuer{
+				ID:       issuer.ID,
+				MinDepth: issuer.MinDepth,
+				MaxDepth: issuer.MaxDepth,
+			})
+		}
+		policy[td] = issuers
+	}
+	return policy, nil
+}
+
+func parseLSVIDChainPolicy(c *lsvidChainPolicyConfig) (*lsvid.ChainPolicy, error) {
+	if c.MaxDepth < 0 {
+		return nil, errors.New("lsvid_chain_policy: max_depth cannot be negative")
+	}
+
+	policy := &lsvid.ChainPolicy{
+		MaxDepth:    c.MaxDepth,
+		AllowCycles: c.AllowCycles,
+	}
+	for _, hop := range c.Hops {
+		if hop.Depth < 0 {
+			return nil, errors.New("lsvid_chain_policy: hop depth cannot be negative")
+		}
+		policy.Hops = append(policy.Hops, lsvid.ChainHop{
+			Depth:     hop.Depth,
+			Issuers:   hop.Issuers,
+			Audiences: hop.Audiences,
+		})
+	}
+	if err := policy.ValidatePatterns(); err != nil {
+		return nil, fmt.Errorf("lsvid_chain_policy: %w", err)
+	}
+	return policy, nil
+}
+
 func validateConfig(c *Config) error {
 	if c.Agent == nil {
 		return errors.New("agent section must be configured")
@@ -508,6 +627,30 @@ func checkForUnknownConfig(c *Config, l logrus.FieldLogger) (err error) {
 		detectedUnknown("agent", a.UnusedKeys)
 	}
 
+	if a := c.Agent; a != nil && a.LSVIDChainPolicy != nil {
+		if len(a.LSVIDChainPolicy.UnusedKeys) != 0 {
+			detectedUnknown("lsvid_chain_policy", a.LSVIDChainPolicy.UnusedKeys)
+		}
+		for _, hop := range a.LSVIDChainPolicy.Hops {
+			if len(hop.UnusedKeys) != 0 {
+				detectedUnknown("lsvid_chain_policy hops", hop.UnusedKeys)
+			}
+		}
+	}
+
+	if a := c.Agent; a != nil {
+		for td, policy := range a.LSVIDFederationPolicy {
+			if len(policy.UnusedKeys) != 0 {
+				detectedUnknown(fmt.Sprintf("lsvid_federation_policy %q", td), policy.UnusedKeys)
+			}
+			for _, issuer := range policy.ForeignIssuers {
+				if len(issuer.UnusedKeys) != 0 {
+					detectedUnknown(fmt.Sprintf("lsvid_federation_policy %q foreign_issuers", td), issuer.UnusedKeys)
+				}
+			}
+		}
+	}
+
 	// TODO: Re-enable unused key detection for telemetry. See
 	// https://github.com/spiffe/spire/issues/1101 for more information
 	//
@@ -14,9 +14,11 @@ import (
 	"github.com/hashicorp/hcl/hcl/printer"
 	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/agent"
 	"github.com/spiffe/spire/pkg/common/catalog"
 	"github.com/spiffe/spire/pkg/common/log"
+	"github.com/spiffe/spire/pkg/common/lsvid"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/spiffe/spire/test/util"
 	"github.com/stretchr/testify/assert"
@@ -98,6 +100,26 @@ func TestParseConfigGood(t *testing.T) {
 	assert.Equal(t, c.Agent.TrustDomain, "example.org")
 	assert.Equal(t, c.Agent.AllowUnauthenticatedVerifiers, true)
 	assert.Equal(t, []string{"c1", "c2", "c3"}, c.Agent.AllowedForeignJWTClaims)
+	assert.Equal(t, map[string]lsvidFederationPolicyConfig{
+		"domain1.test": {},
+		"domain2.test": {
+			ForeignIssuers: []lsvidForeignIssuerConfig{
+				{ID: "spiffe://domain2.test", MaxDepth: 1},
+				{ID: "spiffe://domain2.test/workload", MinDepth: 2, MaxDepth: 2},
+			},
+		},
+	}, c.Agent.LSVIDFederationPolicy)
+	assert.Equal(t, &lsvidChainPolicyConfig{
+		MaxDepth: 4,
+		Hops: []lsvidChainHopConfig{
+			{Depth: 1, Issuers: []string{"spiffe://example.org"}},
+			{Audiences: []string{"spiffe://example.org/*"}},
+		},
+	}, c.Agent.LSVIDChainPolicy)
+	assert.Equal(t, map[string]string{
+		"spiffe://example.org/payments": "disk",
+		"spiffe://example.org/orders":   "memory",
+	}, c.Agent.LSVIDReplayCaches)
 
 	// Check for plugins configurations
 	pluginConfigs := *c.Plugins
@@ -859,6 +881,178 @@ func TestNewAgentConfig(t *testing.T) {
 				require.Equal(t, []string{"c1", "c2"}, c.AllowedForeignJWTClaims)
 			},
 		},
+		{
+			msg: "known_lsvid_claims provided",
+			input: func(c *Config) {
+				c.Agent.KnownLSVIDClaims = []string{"urn:example:scope"}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, []string{"urn:example:scope"}, c.KnownLSVIDClaims)
+			},
+		},
+		{
+			msg: "lsvid_federation_policy provided",
+			input: func(c *Config) {
+				c.Agent.LSVIDFederationPolicy = map[string]lsvidFederationPolicyConfig{
+					"domain1.test": {},
+					"domain2.test": {
+						ForeignIssuers: []lsvidForeignIssuerConfig{
+							{ID: "spiffe://domain2.test", MaxDepth: 1},
+							{ID: "spiffe://domain2.test/workload", MinDepth: 2},
+						},
+					},
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, lsvid.FederationPolicy{
+					spiffeid.RequireTrustDomainFromString("domain1.test"): nil,
+					spiffeid.RequireTrustDomainFromString("domain2.test"): {
+						{ID: "spiffe://domain2.test", MaxDepth: 1},
+						{ID: "spiffe://domain2.test/workload", MinDepth: 2},
+					},
+				}, c.LSVIDFederationPolicy)
+			},
+		},
+		{
+			msg: "lsvid_federation_policy not provided",
+			input: func(c *Config) {
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c.LSVIDFederationPolicy)
+			},
+		},
+		{
+			msg: "lsvid_chain_policy provided",
+			input: func(c *Config) {
+				c.Agent.LSVIDChainPolicy = &lsvidChainPolicyConfig{
+					MaxDepth:    3,
+					AllowCycles: true,
+					Hops: []lsvidChainHopConfig{
+						{Depth: 1, Issuers: []string{"spiffe://example.org"}},
+						{Audiences: []string{"spiffe://example.org/*"}},
+					},
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, &lsvid.ChainPolicy{
+					MaxDepth:    3,
+					AllowCycles: true,
+					Hops: []lsvid.ChainHop{
+						{Depth: 1, Issuers: []string{"spiffe://example.org"}},
+						{Audiences: []string{"spiffe://example.org/*"}},
+					},
+				}, c.LSVIDChainPolicy)
+			},
+		},
+		{
+			msg: "lsvid_chain_policy not provided",
+			input: func(c *Config) {
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c.LSVIDChainPolicy)
+			},
+		},
+		{
+			msg: "lsvid_replay_caches provided",
+			input: func(c *Config) {
+				c.Agent.LSVIDReplayCaches = map[string]string{
+					"spiffe://example.org/payments": "disk",
+					"spiffe://example.org/orders":   "memory",
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, map[string]string{
+					"spiffe://example.org/payments": "disk",
+					"spiffe://example.org/orders":   "memory",
+				}, c.LSVIDReplayCaches)
+			},
+		},
+		{
+			msg:         "lsvid_replay_caches with unknown kind",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.LSVIDReplayCaches = map[string]string{
+					"spiffe://example.org/payments": "redis",
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "lsvid_chain_policy with negative max_depth",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.LSVIDChainPolicy = &lsvidChainPolicyConfig{MaxDepth: -1}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "lsvid_chain_policy with invalid pattern",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.LSVIDChainPolicy = &lsvidChainPolicyConfig{
+					Hops: []lsvidChainHopConfig{{Issuers: []string{"spiffe://example.org/["}}},
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "lsvid_federation_policy with invalid trust domain",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.LSVIDFederationPolicy = map[string]lsvidFederationPolicyConfig{
+					"Invalid Domain": {},
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "lsvid_federation_policy for the trust domain of the agent",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.LSVIDFederationPolicy = map[string]lsvidFederationPolicyConfig{
+					c.Agent.TrustDomain: {},
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "lsvid_federation_policy with issuer of another trust domain",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.LSVIDFederationPolicy = map[string]lsvidFederationPolicyConfig{
+					"domain1.test": {
+						ForeignIssuers: []lsvidForeignIssuerConfig{{ID: "spiffe://domain2.test/workload"}},
+					},
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "lsvid_federation_policy with inverted depths",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.LSVIDFederationPolicy = map[string]lsvidFederationPolicyConfig{
+					"domain1.test": {
+						ForeignIssuers: []lsvidForeignIssuerConfig{{MinDepth: 3, MaxDepth: 2}},
+					},
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
 		{
 			msg: "allowed_foreign_jwt_claims no provided",
 			input: func(c *Config) {
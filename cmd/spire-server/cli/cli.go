@@ -10,6 +10,7 @@ import (
 	"github.com/spiffe/spire/cmd/spire-server/cli/federation"
 	"github.com/spiffe/spire/cmd/spire-server/cli/healthcheck"
 	"github.com/spiffe/spire/cmd/spire-server/cli/jwt"
+	"github.com/spiffe/spire/cmd/spire-server/cli/lsvid"
 	"github.com/spiffe/spire/cmd/spire-server/cli/run"
 	"github.com/spiffe/spire/cmd/spire-server/cli/token"
 	"github.com/spiffe/spire/cmd/spire-server/cli/validate"
@@ -108,6 +109,18 @@ func (cc *CLI) Run(args []string) int {
 		"jwt mint": func() (cli.Command, error) {
 			return jwt.NewMintCommand(), nil
 		},
+		"lsvid mint": func() (cli.Command, error) {
+			return lsvid.NewMintCommand(), nil
+		},
+		"lsvid revoke": func() (cli.Command, error) {
+			return lsvid.NewRevokeCommand(), nil
+		},
+		"lsvid revocation list": func() (cli.Command, error) {
+			return lsvid.NewListRevocationsCommand(), nil
+		},
+		"lsvid revocation delete": func() (cli.Command, error) {
+			return lsvid.NewDeleteRevocationCommand(), nil
+		},
 		"validate": func() (cli.Command, error) {
 			return validate.NewValidateCommand(), nil
 		},
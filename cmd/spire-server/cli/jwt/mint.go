@@ -9,7 +9,7 @@ import (
 	"time"
 
 	"github.com/mitchellh/cli"
-	// "github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	svidv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/svid/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/cmd/spire-server/util"
@@ -47,29 +47,33 @@ func (c *mintCommand) AppendFlags(fs *flag.FlagSet) {
 }
 
 func (c *mintCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
-	// if c.spiffeID == "" {
-	// 	return errors.New("spiffeID must be specified")
-	// }
-	// if len(c.audience) == 0 {
-	// 	return errors.New("at least one audience must be specified")
-	// }
-	// spiffeID, err := spiffeid.FromString(c.spiffeID)
-	// if err != nil {
-	// 	return err
-	// }
+	if c.spiffeID == "" {
+		return errors.New("spiffeID must be specified")
+	}
+	if len(c.audience) == 0 {
+		return errors.New("at least one audience must be specified")
+	}
+	spiffeID, err := spiffeid.FromString(c.spiffeID)
+	if err != nil {
+		return err
+	}
 
 	client := serverClient.NewSVIDClient()
-	resp, err := client.MintJWTSVID(ctx, &svidv1.MintJWTSVIDRequest{Id: &types.SPIFFEID{
-		// TrustDomain: spiffeID.TrustDomain().String(),
-		// Path:        spiffeID.Path(),
-	},
-		// Ttl:      ttlToSeconds(c.ttl),
+	resp, err := client.MintJWTSVID(ctx, &svidv1.MintJWTSVIDRequest{
+		Id: &types.SPIFFEID{
+			TrustDomain: spiffeID.TrustDomain().String(),
+			Path:        spiffeID.Path(),
+		},
+		Ttl:      ttlToSeconds(c.ttl),
 		Audience: c.audience,
 	})
 	if err != nil {
 		return fmt.Errorf("unable to mint SVID: %w", err)
 	}
-	token := resp.Svid.Token
+	token := resp.Svid.GetToken()
+	if err := c.validateToken(token, env); err != nil {
+		return err
+	}
 
 	// Print in stdout
 	if c.write == "" {
@@ -101,6 +105,7 @@ func (c *mintCommand) validateToken(token string, env *common_cli.Env) error {
 
 	return nil
 }
+
 func getJWTSVIDEndOfLife(token string) (time.Time, error) {
 	t, err := jwt.ParseSigned(token)
 	if err != nil {
@@ -0,0 +1,119 @@
+package lsvid
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	lsvidv1 "github.com/spiffe/spire/proto/spire/api/server/lsvid/v1"
+)
+
+func NewMintCommand() cli.Command {
+	return newMintCommand(common_cli.DefaultEnv)
+}
+
+func newMintCommand(env *common_cli.Env) cli.Command {
+	return util.AdaptCommand(env, new(mintCommand))
+}
+
+type mintCommand struct {
+	spiffeID  string
+	publicKey string
+	audience  string
+	ttl       time.Duration
+	claims    string
+	crit      common_cli.StringsFlag
+	write     string
+}
+
+func (c *mintCommand) Name() string {
+	return "lsvid mint"
+}
+
+func (c *mintCommand) Synopsis() string {
+	return "Mints an LSVID"
+}
+
+func (c *mintCommand) AppendFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.spiffeID, "spiffeID", "", "SPIFFE ID of the LSVID subject")
+	fs.StringVar(&c.publicKey, "publicKey", "", "Path to the PEM encoded public key of the LSVID subject")
+	fs.StringVar(&c.audience, "audience", "", "SPIFFE ID of the LSVID audience")
+	fs.DurationVar(&c.ttl, "ttl", 0, "TTL of the LSVID")
+	fs.StringVar(&c.claims, "claims", "", "JSON object holding custom claims, keyed by absolute URIs")
+	fs.Var(&c.crit, "critical", "Custom claim that validators must understand. Can be used more than once.")
+	fs.StringVar(&c.write, "write", "", "File to write token to instead of stdout")
+}
+
+func (c *mintCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
+	if c.spiffeID == "" {
+		return errors.New("spiffeID must be specified")
+	}
+	if c.publicKey == "" {
+		return errors.New("publicKey must be specified")
+	}
+	if c.audience == "" {
+		return errors.New("audience must be specified")
+	}
+	spiffeID, err := spiffeid.FromString(c.spiffeID)
+	if err != nil {
+		return err
+	}
+
+	publicKey, err := pemutil.LoadPublicKey(env.JoinPath(c.publicKey))
+	if err != nil {
+		return fmt.Errorf("unable to load public key: %w", err)
+	}
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return fmt.Errorf("unable to marshal public key: %w", err)
+	}
+
+	client := serverClient.NewLSVIDClient()
+	resp, err := client.MintLSVID(ctx, &lsvidv1.MintLSVIDRequest{
+		SpiffeId:       spiffeID.String(),
+		PublicKey:      publicKeyBytes,
+		Audience:       c.audience,
+		Ttl:            ttlToSeconds(c.ttl),
+		Claims:         c.claims,
+		CriticalClaims: c.crit,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to mint LSVID: %w", err)
+	}
+	token := resp.Lsvid.GetToken()
+	if token == "" {
+		return errors.New("server response missing token")
+	}
+
+	eol := time.Unix(resp.Lsvid.ExpiresAt, 0)
+	if time.Until(eol) < c.ttl {
+		env.ErrPrintf("LSVID lifetime was capped shorter than specified ttl; expires %q\n", eol.UTC().Format(time.RFC3339))
+	}
+
+	// Print in stdout
+	if c.write == "" {
+		return env.Println(token)
+	}
+
+	// Save in file
+	tokenPath := env.JoinPath(c.write)
+	if err := os.WriteFile(tokenPath, []byte(token), 0600); err != nil {
+		return fmt.Errorf("unable to write token: %w", err)
+	}
+	return env.Printf("LSVID written to %s\n", tokenPath)
+}
+
+// ttlToSeconds returns the number of seconds in a duration, rounded up to
+// the nearest second
+func ttlToSeconds(ttl time.Duration) int32 {
+	return int32((ttl + time.Second - 1) / time.Second)
+}
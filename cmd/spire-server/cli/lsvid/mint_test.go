@@ -0,0 +1,334 @@
+package lsvid
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	lsvidv1 "github.com/spiffe/spire/proto/spire/api/server/lsvid/v1"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testkey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+const (
+	expectedUsage = `Usage of lsvid mint:
+  -audience string
+    	SPIFFE ID of the LSVID audience
+  -claims string
+    	JSON object holding custom claims, keyed by absolute URIs
+  -critical value
+    	Custom claim that validators must understand. Can be used more than once.
+  -publicKey string
+    	Path to the PEM encoded public key of the LSVID subject
+  -socketPath string
+    	Path to the SPIRE Server API socket (default "/tmp/spire-server/private/api.sock")
+  -spiffeID string
+    	SPIFFE ID of the LSVID subject
+  -ttl duration
+    	TTL of the LSVID
+  -write string
+    	File to write token to instead of stdout
+`
+)
+
+func TestMintSynopsis(t *testing.T) {
+	cmd := NewMintCommand()
+	assert.Equal(t, "Mints an LSVID", cmd.Synopsis())
+}
+
+func TestMintHelp(t *testing.T) {
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd := newMintCommand(&common_cli.Env{
+		Stdin:  new(bytes.Buffer),
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+	assert.Equal(t, "flag: help requested", cmd.Help())
+	assert.Empty(t, stdout.String())
+	assert.Equal(t, expectedUsage, stderr.String())
+}
+
+func TestMintRun(t *testing.T) {
+	dir := spiretest.TempDir(t)
+
+	tokenPath := filepath.Join(dir, "token")
+
+	publicKey, err := x509.MarshalPKIXPublicKey(testkey.MustEC256().Public())
+	require.NoError(t, err)
+	publicKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "key.pem"), publicKeyPEM, 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.pem"), []byte("not a key"), 0600))
+
+	server := new(fakeLSVIDServer)
+
+	socketPath := filepath.Join(dir, "socket")
+
+	spiretest.StartGRPCSocketServer(t, socketPath, func(s *grpc.Server) {
+		lsvidv1.RegisterLSVIDServer(s, server)
+	})
+
+	expiresAt := time.Now().Add(30 * time.Second).Unix()
+	expiredAt := time.Now().Add(-30 * time.Second)
+
+	testCases := []struct {
+		name string
+
+		// flags
+		spiffeID  string
+		publicKey string
+		audience  string
+		ttl       time.Duration
+		claims    string
+		crit      []string
+		write     string
+		extraArgs []string
+
+		// results
+		code   int
+		stderr string
+
+		noRequestExpected bool
+		resp              *lsvidv1.MintLSVIDResponse
+	}{
+		{
+			name:              "missing spiffeID flag",
+			publicKey:         "key.pem",
+			audience:          "spiffe://domain.test/audience",
+			code:              1,
+			stderr:            "Error: spiffeID must be specified\n",
+			noRequestExpected: true,
+		},
+		{
+			name:              "missing publicKey flag",
+			spiffeID:          "spiffe://domain.test/workload",
+			audience:          "spiffe://domain.test/audience",
+			code:              1,
+			stderr:            "Error: publicKey must be specified\n",
+			noRequestExpected: true,
+		},
+		{
+			name:              "missing audience flag",
+			spiffeID:          "spiffe://domain.test/workload",
+			publicKey:         "key.pem",
+			code:              1,
+			stderr:            "Error: audience must be specified\n",
+			noRequestExpected: true,
+		},
+		{
+			name:              "invalid flag",
+			code:              1,
+			stderr:            fmt.Sprintf("flag provided but not defined: -bad\n%s", expectedUsage),
+			extraArgs:         []string{"-bad", "flag"},
+			noRequestExpected: true,
+		},
+		{
+			name:              "malformed spiffeID",
+			spiffeID:          "domain.test/workload",
+			publicKey:         "key.pem",
+			audience:          "spiffe://domain.test/audience",
+			code:              1,
+			stderr:            "Error: spiffeid: invalid scheme\n",
+			noRequestExpected: true,
+		},
+		{
+			name:              "malformed public key",
+			spiffeID:          "spiffe://domain.test/workload",
+			publicKey:         "bad.pem",
+			audience:          "spiffe://domain.test/audience",
+			code:              1,
+			stderr:            "Error: unable to load public key: no PEM blocks\n",
+			noRequestExpected: true,
+		},
+		{
+			name:      "RPC fails",
+			spiffeID:  "spiffe://domain.test/workload",
+			publicKey: "key.pem",
+			audience:  "spiffe://domain.test/audience",
+			code:      1,
+			stderr:    "Error: unable to mint LSVID: rpc error: code = Unknown desc = response not configured in test\n",
+		},
+		{
+			name:      "response missing token",
+			spiffeID:  "spiffe://domain.test/workload",
+			publicKey: "key.pem",
+			audience:  "spiffe://domain.test/audience",
+			code:      1,
+			stderr:    "Error: server response missing token\n",
+			resp:      &lsvidv1.MintLSVIDResponse{Lsvid: &lsvidv1.Token{}},
+		},
+		{
+			name:      "success with defaults",
+			spiffeID:  "spiffe://domain.test/workload",
+			publicKey: "key.pem",
+			audience:  "spiffe://domain.test/audience",
+			resp: &lsvidv1.MintLSVIDResponse{
+				Lsvid: &lsvidv1.Token{Token: "TOKEN", ExpiresAt: expiresAt},
+			},
+		},
+		{
+			name:      "write on invalid path",
+			spiffeID:  "spiffe://domain.test/workload",
+			publicKey: "key.pem",
+			audience:  "spiffe://domain.test/audience",
+			code:      1,
+			resp: &lsvidv1.MintLSVIDResponse{
+				Lsvid: &lsvidv1.Token{Token: "TOKEN", ExpiresAt: expiresAt},
+			},
+			write:  "/",
+			stderr: fmt.Sprintf("Error: unable to write token: open %s: is a directory\n", dir),
+		},
+		{
+			name:      "expired token",
+			spiffeID:  "spiffe://domain.test/workload",
+			publicKey: "key.pem",
+			audience:  "spiffe://domain.test/audience",
+			resp: &lsvidv1.MintLSVIDResponse{
+				Lsvid: &lsvidv1.Token{Token: "TOKEN", ExpiresAt: expiredAt.Unix()},
+			},
+			stderr: fmt.Sprintf("LSVID lifetime was capped shorter than specified ttl; expires %q\n", expiredAt.UTC().Format(time.RFC3339)),
+		},
+		{
+			name:      "success with ttl and custom claims, output to file",
+			spiffeID:  "spiffe://domain.test/workload",
+			publicKey: "key.pem",
+			audience:  "spiffe://domain.test/audience",
+			ttl:       time.Minute,
+			claims:    `{"https://example.org/job":"batch"}`,
+			crit:      []string{"https://example.org/job"},
+			write:     "token",
+			resp: &lsvidv1.MintLSVIDResponse{
+				Lsvid: &lsvidv1.Token{Token: "TOKEN", ExpiresAt: expiresAt},
+			},
+			stderr: fmt.Sprintf("LSVID lifetime was capped shorter than specified ttl; expires %q\n", time.Unix(expiresAt, 0).UTC().Format(time.RFC3339)),
+		},
+	}
+
+	for _, testCase := range testCases {
+		tt := testCase
+		t.Run(tt.name, func(t *testing.T) {
+			server.setMintLSVIDResponse(tt.resp)
+			server.resetMintLSVIDRequest()
+
+			stdout := new(bytes.Buffer)
+			stderr := new(bytes.Buffer)
+			cmd := newMintCommand(&common_cli.Env{
+				Stdin:   new(bytes.Buffer),
+				Stdout:  stdout,
+				Stderr:  stderr,
+				BaseDir: dir,
+			})
+
+			args := []string{"-socketPath", socketPath}
+			if tt.spiffeID != "" {
+				args = append(args, "-spiffeID", tt.spiffeID)
+			}
+			if tt.publicKey != "" {
+				args = append(args, "-publicKey", tt.publicKey)
+			}
+			if tt.audience != "" {
+				args = append(args, "-audience", tt.audience)
+			}
+			if tt.ttl != 0 {
+				args = append(args, "-ttl", fmt.Sprint(tt.ttl))
+			}
+			if tt.claims != "" {
+				args = append(args, "-claims", tt.claims)
+			}
+			for _, crit := range tt.crit {
+				args = append(args, "-critical", crit)
+			}
+			if tt.write != "" {
+				args = append(args, "-write", tt.write)
+			}
+			args = append(args, tt.extraArgs...)
+
+			code := cmd.Run(args)
+
+			assert.Equal(t, tt.code, code, "exit code does not match")
+			assert.Equal(t, tt.stderr, stderr.String(), "stderr does not match")
+
+			req := server.lastMintLSVIDRequest()
+			if tt.noRequestExpected {
+				assert.Nil(t, req)
+				return
+			}
+
+			if assert.NotNil(t, req) {
+				assert.Equal(t, tt.spiffeID, req.SpiffeId)
+				assert.Equal(t, publicKey, req.PublicKey)
+				assert.Equal(t, tt.audience, req.Audience)
+				assert.Equal(t, int32(tt.ttl/time.Second), req.Ttl)
+				assert.Equal(t, tt.claims, req.Claims)
+				assert.Equal(t, tt.crit, req.CriticalClaims)
+			}
+
+			// assert output file contents
+			if code == 0 {
+				if tt.write != "" {
+					assert.Equal(t, fmt.Sprintf("LSVID written to %s\n", tokenPath),
+						stdout.String(), "stdout does not write output path")
+					assertFileData(t, filepath.Join(dir, tt.write), tt.resp.Lsvid.Token)
+				} else {
+					assert.Equal(t, tt.resp.Lsvid.Token+"\n", stdout.String())
+				}
+			}
+		})
+	}
+}
+
+type fakeLSVIDServer struct {
+	lsvidv1.LSVIDServer
+
+	mu   sync.Mutex
+	req  *lsvidv1.MintLSVIDRequest
+	resp *lsvidv1.MintLSVIDResponse
+}
+
+func (f *fakeLSVIDServer) resetMintLSVIDRequest() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.req = nil
+}
+
+func (f *fakeLSVIDServer) lastMintLSVIDRequest() *lsvidv1.MintLSVIDRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.req
+}
+
+func (f *fakeLSVIDServer) setMintLSVIDResponse(resp *lsvidv1.MintLSVIDResponse) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.resp = resp
+}
+
+func (f *fakeLSVIDServer) MintLSVID(ctx context.Context, req *lsvidv1.MintLSVIDRequest) (*lsvidv1.MintLSVIDResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.req = req
+	if f.resp == nil {
+		return nil, errors.New("response not configured in test")
+	}
+	return f.resp, nil
+}
+
+func assertFileData(t *testing.T, path string, expectedData string) {
+	b, err := os.ReadFile(path)
+	if assert.NoError(t, err) {
+		assert.Equal(t, expectedData, string(b))
+	}
+}
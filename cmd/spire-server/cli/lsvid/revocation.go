@@ -0,0 +1,169 @@
+package lsvid
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	lsvidv1 "github.com/spiffe/spire/proto/spire/api/server/lsvid/v1"
+)
+
+const kindUsage = "Kind of revocation (either SPIFFE_ID, KEY_HASH or TOKEN_ID)"
+
+func NewRevokeCommand() cli.Command {
+	return newRevokeCommand(common_cli.DefaultEnv)
+}
+
+func newRevokeCommand(env *common_cli.Env) cli.Command {
+	return util.AdaptCommand(env, new(revokeCommand))
+}
+
+type revokeCommand struct {
+	kind  string
+	value string
+}
+
+func (c *revokeCommand) Name() string {
+	return "lsvid revoke"
+}
+
+func (c *revokeCommand) Synopsis() string {
+	return "Revokes LSVIDs by SPIFFE ID, key hash or token ID"
+}
+
+func (c *revokeCommand) AppendFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.kind, "kind", "", kindUsage)
+	fs.StringVar(&c.value, "value", "", "SPIFFE ID, key hash or token ID to revoke")
+}
+
+func (c *revokeCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
+	if err := validateRevocationFlags(c.kind, c.value); err != nil {
+		return err
+	}
+
+	client := serverClient.NewLSVIDClient()
+	resp, err := client.RevokeLSVID(ctx, &lsvidv1.RevokeLSVIDRequest{
+		Kind:  c.kind,
+		Value: c.value,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to revoke LSVID: %w", err)
+	}
+
+	env.Println("Revocation created:")
+	printRevocation(resp.Revocation, env.Printf)
+	return nil
+}
+
+func NewListRevocationsCommand() cli.Command {
+	return newListRevocationsCommand(common_cli.DefaultEnv)
+}
+
+func newListRevocationsCommand(env *common_cli.Env) cli.Command {
+	return util.AdaptCommand(env, new(listRevocationsCommand))
+}
+
+type listRevocationsCommand struct{}
+
+func (c *listRevocationsCommand) Name() string {
+	return "lsvid revocation list"
+}
+
+func (c *listRevocationsCommand) Synopsis() string {
+	return "Lists LSVID revocations"
+}
+
+func (c *listRevocationsCommand) AppendFlags(fs *flag.FlagSet) {}
+
+func (c *listRevocationsCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
+	client := serverClient.NewLSVIDClient()
+	pageToken := ""
+	var revocations []*lsvidv1.Revocation
+	for {
+		resp, err := client.ListLSVIDRevocations(ctx, &lsvidv1.ListLSVIDRevocationsRequest{
+			PageSize:  1000,
+			PageToken: pageToken,
+		})
+		if err != nil {
+			return fmt.Errorf("unable to list LSVID revocations: %w", err)
+		}
+		revocations = append(revocations, resp.Revocations...)
+		if pageToken = resp.NextPageToken; pageToken == "" {
+			break
+		}
+	}
+
+	msg := fmt.Sprintf("Found %v ", len(revocations))
+	msg = util.Pluralizer(msg, "revocation", "revocations", len(revocations))
+
+	env.Println(msg)
+	for _, revocation := range revocations {
+		env.Println()
+		printRevocation(revocation, env.Printf)
+	}
+	return nil
+}
+
+func NewDeleteRevocationCommand() cli.Command {
+	return newDeleteRevocationCommand(common_cli.DefaultEnv)
+}
+
+func newDeleteRevocationCommand(env *common_cli.Env) cli.Command {
+	return util.AdaptCommand(env, new(deleteRevocationCommand))
+}
+
+type deleteRevocationCommand struct {
+	kind  string
+	value string
+}
+
+func (c *deleteRevocationCommand) Name() string {
+	return "lsvid revocation delete"
+}
+
+func (c *deleteRevocationCommand) Synopsis() string {
+	return "Deletes an LSVID revocation"
+}
+
+func (c *deleteRevocationCommand) AppendFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.kind, "kind", "", kindUsage)
+	fs.StringVar(&c.value, "value", "", "Revoked SPIFFE ID, key hash or token ID")
+}
+
+func (c *deleteRevocationCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
+	if err := validateRevocationFlags(c.kind, c.value); err != nil {
+		return err
+	}
+
+	client := serverClient.NewLSVIDClient()
+	if _, err := client.DeleteLSVIDRevocation(ctx, &lsvidv1.DeleteLSVIDRevocationRequest{
+		Kind:  c.kind,
+		Value: c.value,
+	}); err != nil {
+		return fmt.Errorf("unable to delete LSVID revocation: %w", err)
+	}
+
+	env.Println("Revocation deleted.")
+	return nil
+}
+
+func validateRevocationFlags(kind, value string) error {
+	if kind == "" {
+		return errors.New("kind must be specified")
+	}
+	if value == "" {
+		return errors.New("value must be specified")
+	}
+	return nil
+}
+
+func printRevocation(revocation *lsvidv1.Revocation, printf func(format string, args ...interface{}) error) {
+	_ = printf("Kind       : %s\n", revocation.Kind)
+	_ = printf("Value      : %s\n", revocation.Value)
+	_ = printf("Created at : %s\n", time.Unix(revocation.CreatedAt, 0).UTC().Format(time.RFC3339))
+}
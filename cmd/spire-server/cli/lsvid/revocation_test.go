@@ -0,0 +1,231 @@
+package lsvid
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	lsvidv1 "github.com/spiffe/spire/proto/spire/api/server/lsvid/v1"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRevocationSynopsis(t *testing.T) {
+	assert.Equal(t, "Revokes LSVIDs by SPIFFE ID, key hash or token ID", NewRevokeCommand().Synopsis())
+	assert.Equal(t, "Lists LSVID revocations", NewListRevocationsCommand().Synopsis())
+	assert.Equal(t, "Deletes an LSVID revocation", NewDeleteRevocationCommand().Synopsis())
+}
+
+func TestRevokeRun(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		args          []string
+		err           error
+		expectCode    int
+		expectStdout  string
+		expectStderr  string
+		expectRequest *lsvidv1.RevokeLSVIDRequest
+	}{
+		{
+			name:         "missing kind",
+			args:         []string{"-value", "spiffe://domain.test/workload"},
+			expectCode:   1,
+			expectStderr: "Error: kind must be specified\n",
+		},
+		{
+			name:         "missing value",
+			args:         []string{"-kind", "SPIFFE_ID"},
+			expectCode:   1,
+			expectStderr: "Error: value must be specified\n",
+		},
+		{
+			name:          "RPC fails",
+			args:          []string{"-kind", "SERIAL", "-value", "1"},
+			err:           status.Error(codes.InvalidArgument, "invalid revocation"),
+			expectCode:    1,
+			expectStderr:  "Error: unable to revoke LSVID: rpc error: code = InvalidArgument desc = invalid revocation\n",
+			expectRequest: &lsvidv1.RevokeLSVIDRequest{Kind: "SERIAL", Value: "1"},
+		},
+		{
+			name: "success",
+			args: []string{"-kind", "SPIFFE_ID", "-value", "spiffe://domain.test/workload"},
+			expectStdout: `Revocation created:
+Kind       : SPIFFE_ID
+Value      : spiffe://domain.test/workload
+Created at : 2021-01-01T00:00:00Z
+`,
+			expectRequest: &lsvidv1.RevokeLSVIDRequest{Kind: "SPIFFE_ID", Value: "spiffe://domain.test/workload"},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			server := &fakeRevocationServer{err: tt.err}
+			code, stdout, stderr := runRevocationCommand(t, server, newRevokeCommand, tt.args...)
+			assert.Equal(t, tt.expectCode, code)
+			assert.Equal(t, tt.expectStdout, stdout)
+			assert.Equal(t, tt.expectStderr, stderr)
+			spiretest.AssertProtoEqual(t, tt.expectRequest, server.revokeReq)
+		})
+	}
+}
+
+func TestListRevocationsRun(t *testing.T) {
+	server := &fakeRevocationServer{
+		revocations: []*lsvidv1.Revocation{
+			{Kind: "SPIFFE_ID", Value: "spiffe://domain.test/workload", CreatedAt: createdAt},
+			{Kind: "TOKEN_ID", Value: "ID", CreatedAt: createdAt},
+		},
+	}
+	code, stdout, stderr := runRevocationCommand(t, server, newListRevocationsCommand)
+	assert.Equal(t, 0, code)
+	assert.Empty(t, stderr)
+	assert.Equal(t, `Found 2 revocations
+
+Kind       : SPIFFE_ID
+Value      : spiffe://domain.test/workload
+Created at : 2021-01-01T00:00:00Z
+
+Kind       : TOKEN_ID
+Value      : ID
+Created at : 2021-01-01T00:00:00Z
+`, stdout)
+
+	// Revocations are listed in pages
+	server = new(fakeRevocationServer)
+	for i := 0; i < 1001; i++ {
+		server.revocations = append(server.revocations, &lsvidv1.Revocation{Kind: "TOKEN_ID", Value: strconv.Itoa(i), CreatedAt: createdAt})
+	}
+	code, stdout, stderr = runRevocationCommand(t, server, newListRevocationsCommand)
+	assert.Equal(t, 0, code)
+	assert.Empty(t, stderr)
+	assert.Contains(t, stdout, "Found 1001 revocations\n")
+	assert.Contains(t, stdout, "Value      : 1000\n")
+
+	server = &fakeRevocationServer{err: status.Error(codes.Internal, "oh no")}
+	code, stdout, stderr = runRevocationCommand(t, server, newListRevocationsCommand)
+	assert.Equal(t, 1, code)
+	assert.Empty(t, stdout)
+	assert.Equal(t, "Error: unable to list LSVID revocations: rpc error: code = Internal desc = oh no\n", stderr)
+}
+
+func TestDeleteRevocationRun(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		args          []string
+		err           error
+		expectCode    int
+		expectStdout  string
+		expectStderr  string
+		expectRequest *lsvidv1.DeleteLSVIDRevocationRequest
+	}{
+		{
+			name:         "missing kind",
+			args:         []string{"-value", "spiffe://domain.test/workload"},
+			expectCode:   1,
+			expectStderr: "Error: kind must be specified\n",
+		},
+		{
+			name:          "RPC fails",
+			args:          []string{"-kind", "SPIFFE_ID", "-value", "spiffe://domain.test/workload"},
+			err:           status.Error(codes.NotFound, "revocation not found"),
+			expectCode:    1,
+			expectStderr:  "Error: unable to delete LSVID revocation: rpc error: code = NotFound desc = revocation not found\n",
+			expectRequest: &lsvidv1.DeleteLSVIDRevocationRequest{Kind: "SPIFFE_ID", Value: "spiffe://domain.test/workload"},
+		},
+		{
+			name:          "success",
+			args:          []string{"-kind", "SPIFFE_ID", "-value", "spiffe://domain.test/workload"},
+			expectStdout:  "Revocation deleted.\n",
+			expectRequest: &lsvidv1.DeleteLSVIDRevocationRequest{Kind: "SPIFFE_ID", Value: "spiffe://domain.test/workload"},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			server := &fakeRevocationServer{err: tt.err}
+			code, stdout, stderr := runRevocationCommand(t, server, newDeleteRevocationCommand, tt.args...)
+			assert.Equal(t, tt.expectCode, code)
+			assert.Equal(t, tt.expectStdout, stdout)
+			assert.Equal(t, tt.expectStderr, stderr)
+			spiretest.AssertProtoEqual(t, tt.expectRequest, server.deleteReq)
+		})
+	}
+}
+
+// 2021-01-01T00:00:00Z
+const createdAt = 1609459200
+
+func runRevocationCommand(t *testing.T, server *fakeRevocationServer, newCommand func(*common_cli.Env) cli.Command, args ...string) (int, string, string) {
+	socketPath := filepath.Join(spiretest.TempDir(t), "socket")
+	spiretest.StartGRPCSocketServer(t, socketPath, func(s *grpc.Server) {
+		lsvidv1.RegisterLSVIDServer(s, server)
+	})
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd := newCommand(&common_cli.Env{
+		Stdin:  new(bytes.Buffer),
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+	code := cmd.Run(append([]string{"-socketPath", socketPath}, args...))
+	return code, stdout.String(), stderr.String()
+}
+
+type fakeRevocationServer struct {
+	lsvidv1.UnimplementedLSVIDServer
+
+	err         error
+	revocations []*lsvidv1.Revocation
+	revokeReq   *lsvidv1.RevokeLSVIDRequest
+	deleteReq   *lsvidv1.DeleteLSVIDRevocationRequest
+}
+
+func (f *fakeRevocationServer) RevokeLSVID(ctx context.Context, req *lsvidv1.RevokeLSVIDRequest) (*lsvidv1.RevokeLSVIDResponse, error) {
+	f.revokeReq = req
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &lsvidv1.RevokeLSVIDResponse{
+		Revocation: &lsvidv1.Revocation{Kind: req.Kind, Value: req.Value, CreatedAt: createdAt},
+	}, nil
+}
+
+func (f *fakeRevocationServer) ListLSVIDRevocations(ctx context.Context, req *lsvidv1.ListLSVIDRevocationsRequest) (*lsvidv1.ListLSVIDRevocationsResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if req.PageSize == 0 {
+		return &lsvidv1.ListLSVIDRevocationsResponse{Revocations: f.revocations}, nil
+	}
+	start := 0
+	if req.PageToken != "" {
+		var err error
+		if start, err = strconv.Atoi(req.PageToken); err != nil {
+			return nil, err
+		}
+	}
+	end := start + int(req.PageSize)
+	if end > len(f.revocations) {
+		end = len(f.revocations)
+	}
+	resp := &lsvidv1.ListLSVIDRevocationsResponse{Revocations: f.revocations[start:end]}
+	if end < len(f.revocations) {
+		resp.NextPageToken = strconv.Itoa(end)
+	}
+	return resp, nil
+}
+
+func (f *fakeRevocationServer) DeleteLSVIDRevocation(ctx context.Context, req *lsvidv1.DeleteLSVIDRevocationRequest) (*lsvidv1.DeleteLSVIDRevocationResponse, error) {
+	f.deleteReq = req
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &lsvidv1.DeleteLSVIDRevocationResponse{}, nil
+}
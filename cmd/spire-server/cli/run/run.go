@@ -71,6 +71,7 @@ type serverConfig struct {
 	CASubject       *caSubjectConfig   `hcl:"ca_subject"`
 	CATTL           string             `hcl:"ca_ttl"`
 	DataDir         string             `hcl:"data_dir"`
+	DefaultLSVIDTTL string             `hcl:"default_lsvid_ttl"`
 	DefaultSVIDTTL  string             `hcl:"default_svid_ttl"`
 	Experimental    experimentalConfig `hcl:"experimental"`
 	Federation      *federationConfig  `hcl:"federation"`
@@ -448,6 +449,14 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 		sc.SVIDTTL = ttl
 	}
 
+	if c.Server.DefaultLSVIDTTL != "" {
+		ttl, err := time.ParseDuration(c.Server.DefaultLSVIDTTL)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse default LSVID ttl %q: %w", c.Server.DefaultLSVIDTTL, err)
+		}
+		sc.LSVIDTTL = ttl
+	}
+
 	if c.Server.CATTL != "" {
 		ttl, err := time.ParseDuration(c.Server.CATTL)
 		if err != nil {
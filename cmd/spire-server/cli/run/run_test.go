@@ -393,6 +393,16 @@ func TestMergeInput(t *testing.T) {
 				require.Equal(t, "1h", c.Server.DefaultSVIDTTL)
 			},
 		},
+		{
+			msg: "default_lsvid_ttl should be configurable by file",
+			fileInput: func(c *Config) {
+				c.Server.DefaultLSVIDTTL = "10m"
+			},
+			cliFlags: []string{},
+			test: func(t *testing.T, c *Config) {
+				require.Equal(t, "10m", c.Server.DefaultLSVIDTTL)
+			},
+		},
 		{
 			msg:       "trust_domain should not have a default value",
 			fileInput: func(c *Config) {},
@@ -643,6 +653,25 @@ func TestNewServerConfig(t *testing.T) {
 				require.Nil(t, c)
 			},
 		},
+		{
+			msg: "default_lsvid_ttl is correctly parsed",
+			input: func(c *Config) {
+				c.Server.DefaultLSVIDTTL = "10m"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, 10*time.Minute, c.LSVIDTTL)
+			},
+		},
+		{
+			msg:         "invalid default_lsvid_ttl returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.DefaultLSVIDTTL = "b"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
 		{
 			msg: "ca_key_type and jwt_key_type are set as default",
 			input: func(c *Config) {
@@ -19,6 +19,7 @@ import (
 	api_types "github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/pemutil"
+	lsvidv1 "github.com/spiffe/spire/proto/spire/api/server/lsvid/v1"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health/grpc_health_v1"
 )
@@ -51,6 +52,7 @@ type ServerClient interface {
 	NewBundleClient() bundlev1.BundleClient
 	NewEntryClient() entryv1.EntryClient
 	NewSVIDClient() svidv1.SVIDClient
+	NewLSVIDClient() lsvidv1.LSVIDClient
 	NewTrustDomainClient() trustdomainv1.TrustDomainClient
 	NewHealthClient() grpc_health_v1.HealthClient
 }
@@ -87,6 +89,10 @@ func (c *serverClient) NewSVIDClient() svidv1.SVIDClient {
 	return svidv1.NewSVIDClient(c.conn)
 }
 
+func (c *serverClient) NewLSVIDClient() lsvidv1.LSVIDClient {
+	return lsvidv1.NewLSVIDClient(c.conn)
+}
+
 func (c *serverClient) NewTrustDomainClient() trustdomainv1.TrustDomainClient {
 	return trustdomainv1.NewTrustDomainClient(c.conn)
 }
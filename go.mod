@@ -6,6 +6,7 @@ require (
 	cloud.google.com/go/secretmanager v1.0.0
 	cloud.google.com/go/security v0.1.0
 	cloud.google.com/go/storage v1.16.1
+	filippo.io/edwards25519 v1.0.0
 	github.com/Azure/azure-sdk-for-go v57.3.0+incompatible
 	github.com/Azure/go-autorest/autorest v0.11.21
 	github.com/Azure/go-autorest/autorest/azure/auth v0.5.8
@@ -24,6 +25,7 @@ require (
 	github.com/aws/aws-sdk-go-v2/service/sts v1.7.0
 	github.com/blang/semver v3.5.1+incompatible
 	github.com/cenkalti/backoff/v3 v3.2.2
+	github.com/cyberphone/json-canonicalization v0.0.0-20220623050100-57a0ce2678a7
 	github.com/docker/docker v20.10.8+incompatible
 	github.com/envoyproxy/go-control-plane v0.9.9
 	github.com/go-logr/logr v0.4.0
@@ -1,282 +1,286 @@
-package lsvid
-
-// Package developed by SPIFFE Assertions and Tokens WG, to provide, extend and validate Lightweight SVID.
-// Ref document:
+// Package lsvid provides workloads with helpers to fetch, extend and validate
+// Lightweight SVIDs (LSVIDs), as developed by the SPIFFE Assertions and Tokens
+// WG. Ref document:
 // https://docs.google.com/document/d/15rfAkzNTQa1ycs-fn9hyIYV5HbznPBsxB-f0vxhNJ24/edit?usp=drive_link
+//
+// The token format, signing and validation rules live in the canonical
+// github.com/spiffe/spire/pkg/common/lsvid package, shared with the SPIRE
+// server and agent. This package only re-exports them and adds the Workload
+// API plumbing workloads need.
+package lsvid
 
 import (
-
 	"context"
-	"fmt"
-	"encoding/base64"
-	"encoding/json"
 	"crypto"
-	"crypto/rand"
-	hash256 "crypto/sha256"
 	"crypto/x509"
-	"crypto/ecdsa"
-	"log"
-	"github.com/spiffe/go-spiffe/v2/workloadapi"
-	"github.com/spiffe/go-spiffe/v2/svid/jwtsvid"
-	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"fmt"
 	"time"
 
+	"github.com/andres-erbsen/clock"
+	"github.com/spiffe/go-spiffe/v2/bundle/jwtbundle"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+	core "github.com/spiffe/spire/pkg/common/lsvid"
+	lsvidv1 "github.com/spiffe/spire/proto/spire/api/workload/lsvid/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 )
 
-type LSVID struct {
-	Token		*Token		`json:"token"`		// The LSVID document
-	Bundle		*Token		`json:"bundle"`		// The Trust bundle document
-}
+type (
+	LSVID   = core.LSVID
+	Token   = core.Token
+	Payload = core.Payload
+	IDClaim = core.IDClaim
+
+	ValidateOption   = core.ValidateOption
+	FederationPolicy = core.FederationPolicy
+	ForeignIssuer    = core.ForeignIssuer
+	ChainPolicy      = core.ChainPolicy
+	ChainHop         = core.ChainHop
+	ValidationError  = core.ValidationError
+	Reason           = core.Reason
+	Revocation       = core.Revocation
+	RevocationKind   = core.RevocationKind
+	RevocationList   = core.RevocationList
+	ReplayCache      = core.ReplayCache
+	Proof            = core.Proof
+	ProofRequest     = core.ProofRequest
+
+	MemoryReplayCache = core.MemoryReplayCache
+)
+
+// TypCOSE is the typ of LSVID layers encoded as COSE_Sign1 structures.
+const TypCOSE = core.TypCOSE
+
+// TypJWS is the typ of LSVID layers encoded as JWS compact serializations.
+const TypJWS = core.TypJWS
+
+// AlgSAEdDSA is the alg of LSVID layers signed with Ed25519 signatures,
+// aggregated across consecutive SAEdDSA layers. It is requested explicitly
+// when extending an LSVID.
+const AlgSAEdDSA = core.AlgSAEdDSA
 
-type Token struct {	
-	Nested		*Token		`json:"nested,omitempty"`
-	Payload		*Payload	`json:"payload"`
-	Signature	[]byte		`json:"signature"`
+const (
+	RevokeSPIFFEID = core.RevokeSPIFFEID
+	RevokeKeyHash  = core.RevokeKeyHash
+	RevokeTokenID  = core.RevokeTokenID
+)
+
+// Encode encodes the token as a base64url JSON document or, if its layers are
+// typed TypCOSE, as base64url COSE, or, if they are typed TypJWS, as a JWS
+// compact serialization.
+func Encode(token *Token) (string, error) {
+	return core.Encode(token)
 }
 
-type Payload struct {
-	Ver 		int8		`json:"ver,omitempty"`
-	Alg 		string		`json:"alg,omitempty"`
-	Iat			int64		`json:"iat,omitempty"`
-	Iss			*IDClaim	`json:"iss,omitempty"`
-	Sub			*IDClaim	`json:"sub,omitempty"`
-	Aud			*IDClaim	`json:"aud,omitempty"`
+// Decode decodes a token encoded by Encode, detecting its encoding.
+func Decode(encoded string) (*Token, error) {
+	return core.Decode(encoded)
 }
 
-type IDClaim struct {
-	CN			string		`json:"cn,omitempty"` // e.g.: spiffe://example.org/workload
-	PK			[]byte		`json:"pk,omitempty"` // e.g.: VGhpcyBpcyBteSBQdWJsaWMgS2V5
-	ID			*Token		`json:"id,omitempty"` // e.g.: a complete LSVID
+// DecodeLSVID decodes the document returned by FetchLSVID.
+func DecodeLSVID(encoded string) (*LSVID, error) {
+	return core.DecodeLSVID(encoded)
 }
 
-// lsvid -> string
-func Encode(lsvid *Token) (string, error) {
-	// Marshal the LSVID struct into JSON
-	lsvidJSON, err := json.Marshal(lsvid)
+// Extend adds a new layer with the given payload on top of the token, signs
+// it with the provided key and returns the encoded result.
+func Extend(token *Token, payload *Payload, key crypto.Signer) (string, error) {
+	extended, err := core.Extend(token, payload, key)
 	if err != nil {
-		return "", fmt.Errorf("error marshaling LSVID to JSON: %v", err)
+		return "", err
 	}
+	return core.Encode(extended)
+}
 
-	// Encode the JSON byte slice to Base64.RawURLEncoded string
-	encLSVID := base64.RawURLEncoding.EncodeToString(lsvidJSON)
+// Validate validates the signature of every layer of the token and the
+// aud -> iss link between them.
+func Validate(token *Token, opts ...ValidateOption) (bool, error) {
+	if err := core.Validate(token, opts...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
 
-	return encLSVID, nil
+// WithKnownClaims declares the custom claims understood by the caller, so that
+// Validate accepts layers listing them as critical.
+func WithKnownClaims(names ...string) ValidateOption {
+	return core.WithKnownClaims(names...)
 }
 
-// string -> lsvid
-func Decode(encLSVID string) (*Token, error) {
-
-	// fmt.Printf("LSVID to be decoded: %s\n", encLSVID)
-    // Decode the base64.RawURLEncoded LSVID
-    decoded, err := base64.RawURLEncoding.DecodeString(encLSVID)
-    if err != nil {
-        return nil, fmt.Errorf("error decoding LSVID: %v\n", err)
-    }
-	// fmt.Printf("Decoded LSVID to be unmarshaled: %s\n", decoded)
-
-    // Unmarshal the decoded byte slice into your struct
-    var decLSVID LSVID
-    err = json.Unmarshal(decoded, &decLSVID)
-    if err != nil {
-        return nil, fmt.Errorf("error unmarshalling LSVID: %v\n", err)
-    }
-
-    return &decLSVID, nil
+// WithJWTBundles anchors the root layers of the token in the JWT authorities
+// of the issuer trust domain, as returned by the source. A workloadapi.JWTSource
+// provides the bundles of the trust domain of the workload and of the trust
+// domains it federates with.
+func WithJWTBundles(source jwtbundle.Source) ValidateOption {
+	return core.WithAuthorities(func(trustDomainID string) (map[string]crypto.PublicKey, bool) {
+		td, err := spiffeid.TrustDomainFromString(trustDomainID)
+		if err != nil {
+			return nil, false
+		}
+		bundle, err := source.GetJWTBundleForTrustDomain(td)
+		if err != nil {
+			return nil, false
+		}
+		return bundle.JWTAuthorities(), true
+	})
 }
 
-// Add the new Token to extend an existing one, and sign using provided key
-func Extend(lsvid *Token, newPayload *agentPayload, key crypto.Signer) (string, error) {
-	// TODO: Modify the payload struct to support custom claims (maybe using map[string]{interface})
-	// Create the extended LSVID structure
-	extLSVID := &Token{
-		Nested:		lsvid,
-		Payload:	newPayload,
-	}
+// WithFederationPolicy restricts the issuers of trust domains other than td
+// to the ones allowed by the policy, at the depths it allows them.
+func WithFederationPolicy(td spiffeid.TrustDomain, policy FederationPolicy) ValidateOption {
+	return core.WithFederationPolicy(td, policy)
+}
 
-	// Marshal to JSON
-	tmpToSign, err := json.Marshal(extLSVID)
-	if err != nil {
-		return "", fmt.Errorf("Error generating json: %v\n", err)
-	} 
+// WithChainPolicy checks the chain of layers of the token against the policy.
+func WithChainPolicy(policy ChainPolicy) ValidateOption {
+	return core.WithChainPolicy(policy)
+}
 
-	// Sign extlSVID
-	hash 	:= hash256.Sum256(tmpToSign)
-	s, err := key.Sign(rand.Reader, hash[:], crypto.SHA256)
-	if err != nil {
-		return "", fmt.Errorf("Error generating signed assertion: %v\n", err)
-	} 
+// WithRevocations rejects tokens containing a layer revoked by the list.
+func WithRevocations(list RevocationList) ValidateOption {
+	return core.WithRevocations(list)
+}
 
-	// Set extLSVID signature
-	extLSVID.Signature = s
+// NewRevocationList returns the list of the given revocations.
+func NewRevocationList(revocations ...Revocation) RevocationList {
+	return core.NewRevocationList(revocations...)
+}
 
-	// Encode signed LSVID
-	outLSVID, err := Encode(extLSVID)
-	if err != nil {
-		return "", fmt.Errorf("Error encoding LSVID: %v\n", err)
-	} 
+// WithReplayCache rejects tokens already accepted with the same cache, so
+// that each token is accepted only once.
+func WithReplayCache(cache ReplayCache) ValidateOption {
+	return core.WithReplayCache(cache)
+}
 
-	return outLSVID, nil
+// NewMemoryReplayCache returns an empty replay cache held in memory.
+func NewMemoryReplayCache() *MemoryReplayCache {
+	return core.NewMemoryReplayCache(clock.New())
+}
 
+// MarshalCOSE serializes a token whose layers are typed TypCOSE into its
+// binary COSE form.
+func MarshalCOSE(token *Token) ([]byte, error) {
+	return core.MarshalCOSE(token)
 }
 
-// Validate the given LSVID. 
-// TODO: include the root (trust bundle) LSVID as parameter, to validate the inner signature
-func Validate(lsvid *Token) (bool, error) {
+// UnmarshalCOSE parses a token produced by MarshalCOSE.
+func UnmarshalCOSE(data []byte) (*Token, error) {
+	return core.UnmarshalCOSE(data)
+}
 
-	for (lsvid.Nested != nil) {
+// MarshalJWS serializes a token whose layers are typed TypJWS into the JWS
+// compact serialization of its outermost layer.
+func MarshalJWS(token *Token) (string, error) {
+	return core.MarshalJWS(token)
+}
 
-		// Check Aud -> Iss link
-		if lsvid.Payload.Iss.CN != lsvid.Nested.Payload.Aud.CN {
-			return false, fmt.Errorf("Aud -> Iss link validation failed\n")
-		}
-		fmt.Printf("Aud -> Iss link validation successful!\n")
+// UnmarshalJWS parses a token produced by MarshalJWS.
+func UnmarshalJWS(compact string) (*Token, error) {
+	return core.UnmarshalJWS(compact)
+}
 
-		// Marshal the LSVID struct into JSON
-		tmpLSVID := &Token{
-			Nested:		lsvid.Nested,
-			Payload:	lsvid.Payload,
-		}
-		lsvidJSON, err := json.Marshal(tmpLSVID)
-		if err != nil {
-		return false, fmt.Errorf("error marshaling LSVID to JSON: %v\n", err)
-		}
-		hash 	:= hash256.Sum256(lsvidJSON)
-
-		// Parse the public key
-		// TODO: Currently the pk is extracted from the iss lsvid that MUST be present
-		// and we are still not validating the trust bundle.
-		var issLSVID *Token
-		issLSVID = lsvid.Payload.Iss.LS
-		for (issLSVID.Nested != nil) {
-			// fmt.Printf("Issuer nested LSVID found! %v\n", issLSVID.Nested)
-			issLSVID = issLSVID.Nested
-		}
-		issLSSubPk, err := x509.ParsePKIXPublicKey(issLSVID.Payload.Sub.PK)
-		if err != nil {
-			return false, fmt.Errorf("Failed to parse public key: %v\n", err)
-		}
+// CreateProof signs a proof of possession of the token for the request with
+// the key of its holder.
+func CreateProof(token *Token, req ProofRequest, key crypto.Signer) (*Proof, error) {
+	return core.CreateProof(token, req, key)
+}
 
-		// validate the signature
-		log.Printf("Verifying signature created by %s\n", lsvid.Payload.Iss.CN)
-		verify := ecdsa.VerifyASN1(issLSSubPk.(*ecdsa.PublicKey), hash[:], lsvid.Signature)
-		if verify == false {
-			fmt.Printf("\nSignature validation failed!\n\n")
-			return false, nil
-		}
-		log.Printf("Signature validation successful!\n")
+// VerifyProof verifies that the proof was signed by the holder of the token
+// for the request. The token itself is not validated.
+func VerifyProof(token *Token, proof *Proof, req ProofRequest, opts ...ValidateOption) error {
+	return core.VerifyProof(token, proof, req, opts...)
+}
 
-		// jump to nested token
-		lsvid = lsvid.Nested
-	}
+// WithProof requires the token to be presented with a proof of possession
+// signed by its holder for the request.
+func WithProof(proof *Proof, req ProofRequest) ValidateOption {
+	return core.WithProof(proof, req)
+}
 
-	// reached the inner most LSVID. 
-	// Marshal the LSVID struct into JSON
-	lsvidJSON, err := json.Marshal(lsvid.Payload)
-	if err != nil {
-	return false, fmt.Errorf("error marshaling LSVID to JSON: %v\n", err)
-	}
-	// log.Printf("Payload to be hashed: %s", lsvidJSON)
-	hash 	:= hash256.Sum256(lsvidJSON)
+// HolderKey returns the SPIFFE ID and the public key of the holder of the
+// token.
+func HolderKey(token *Token) (string, []byte, error) {
+	return core.HolderKey(token)
+}
 
-	// Parse the public key
-	issPk, err := x509.ParsePKIXPublicKey(lsvid.Payload.Iss.PK)
-	if err != nil {
-		return false, fmt.Errorf("Failed to parse public key: %v\n", err)
-	}
-	log.Printf("Public key to be used: %s", issPk)
+// EncodeProof encodes the proof as a base64url JSON document.
+func EncodeProof(proof *Proof) (string, error) {
+	return core.EncodeProof(proof)
+}
 
-	log.Printf("Verifying signature created by %s", lsvid.Payload.Iss.CN)
-	verify := ecdsa.VerifyASN1(issPk.(*ecdsa.PublicKey), hash[:], lsvid.Signature)
-	if verify == false {
-		fmt.Printf("\nSignature validation failed!\n\n")
-		return false, nil
-	}
+// DecodeProof decodes a base64url JSON encoded proof.
+func DecodeProof(encoded string) (*Proof, error) {
+	return core.DecodeProof(encoded)
+}
 
-	return true, nil
+// ReasonOf returns the reason the validation failed with.
+func ReasonOf(err error) Reason {
+	return core.ReasonOf(err)
 }
 
-// Fetch workload LSVID using modified FetchJWTSVID endpoint
+// FetchLSVID fetches the caller's LSVID document from the LSVID Workload API
+// exposed by the SPIRE agent at socketPath.
 func FetchLSVID(ctx context.Context, socketPath string) (string, error) {
-	
-	// Fetch claims data
-	clientSVID, err := fetchSVID(ctx, socketPath)
+	conn, err := grpc.DialContext(ctx, socketPath, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
-		return "", fmt.Errorf("Unable to fetch X509 SVID: %v\n", err)
+		return "", fmt.Errorf("unable to dial Workload API: %w", err)
 	}
-	clientID 		:= clientSVID.ID.String()
+	defer conn.Close()
 
-	source, err := workloadapi.NewJWTSource(ctx, workloadapi.WithClientOptions(workloadapi.WithAddr(socketPath)))
+	ctx = metadata.AppendToOutgoingContext(ctx, "workload.spiffe.io", "true")
+	resp, err := lsvidv1.NewLSVIDWorkloadAPIClient(conn).FetchLSVID(ctx, &lsvidv1.LSVIDRequest{})
 	if err != nil {
-		return "", fmt.Errorf("Unable to create JWTSource %v\n", err)
+		return "", fmt.Errorf("unable to fetch LSVID: %w", err)
 	}
-	defer source.Close()
-	
-	fetchLSVID, err := source.FetchJWTSVID(ctx, jwtsvid.Params{
-		Audience: clientID,
-	})
-	if err != nil {
-		return "", fmt.Errorf("Unable to Fetch LSVID %v\n", err)
+	if len(resp.Lsvids) == 0 {
+		return "", fmt.Errorf("no LSVID returned")
 	}
 
-	return fmt.Sprintf("%s", fetchLSVID.LSVID.Svid), nil
+	return resp.Lsvids[0].Lsvid, nil
 }
 
-// Create an LSVID payload given a x509 certificate.
-// PS: Payload claims are based in LSVID spec doc
+// Cert2LSR creates an LSVID payload for the given certificate, issued by the
+// workload at socketPath.
 func Cert2LSR(ctx context.Context, socketPath string, cert *x509.Certificate, audience string) (*Payload, error) {
-
 	clientSVID, err := fetchSVID(ctx, socketPath)
 	if err != nil {
-		return &Payload{}, fmt.Errorf("Unable to fetch X509 SVID: %v\n", err)
+		return nil, fmt.Errorf("unable to fetch X509 SVID: %w", err)
 	}
-	clientID 		:= clientSVID.ID.String()
 
-	// generate encoded public key
-	tmppk, err := x509.MarshalPKIXPublicKey(cert.PublicKey)
-	if err != nil {
-		return &Payload{}, err
+	if len(cert.URIs) == 0 {
+		return nil, fmt.Errorf("certificate has no URI SAN")
 	}
-	// pubkey :=  base64.RawURLEncoding.EncodeToString(tmppk)
 
-	// Versioning needs TBD. For poc, considering vr = 1
-	if cert.URIs[0] == nil {
-		return &Payload{}, fmt.Errorf("No certificate URI: %v", err)
+	pk, err := x509.MarshalPKIXPublicKey(cert.PublicKey)
+	if err != nil {
+		return nil, err
 	}
-	sub := cert.URIs[0].String()
-	// Create LSVID payload
-	lsvidPayload := &Payload{
-		Ver:	1,
-		Alg:	"ES256",
-		Iat:	time.Now().Round(0).Unix(),
-		Iss:	&IDClaim{
-			CN:	clientID,
+
+	return &Payload{
+		Ver: core.Version,
+		Iat: time.Now().Unix(),
+		Iss: &IDClaim{
+			CN: clientSVID.ID.String(),
 		},
-		Sub:	&IDClaim{
-			CN:	sub,
-			PK:	tmppk,
+		Sub: &IDClaim{
+			CN: cert.URIs[0].String(),
+			PK: pk,
 		},
-		Aud:	&IDClaim{
-			CN:	audience,
+		Aud: &IDClaim{
+			CN: audience,
 		},
-	}
-
-	return lsvidPayload, nil
+	}, nil
 }
 
-// Fetch workload X509 SVID
 func fetchSVID(ctx context.Context, socketPath string) (*x509svid.SVID, error) {
-
-	// Create a `workloadapi.X509Source`, it will connect to Workload API using provided socket.
 	source, err := workloadapi.NewX509Source(ctx, workloadapi.WithClientOptions(workloadapi.WithAddr(socketPath)))
 	if err != nil {
-		return nil, fmt.Errorf("Unable to create X509Source: %v", err)
+		return nil, fmt.Errorf("unable to create X509Source: %w", err)
 	}
 	defer source.Close()
 
-	svid, err := source.GetX509SVID()
-	if err != nil {
-		return nil, fmt.Errorf("Unable to fetch SVID: %v", err)
-	}
-
-	return svid, nil
-}
\ No newline at end of file
+	return source.GetX509SVID()
+}
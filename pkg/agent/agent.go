@@ -2,6 +2,8 @@ package agent
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/http"
@@ -12,16 +14,19 @@ import (
 	"runtime"
 	"sync"
 
+	"github.com/andres-erbsen/clock"
 	"github.com/spiffe/go-spiffe/v2/workloadapi"
 	admin_api "github.com/spiffe/spire/pkg/agent/api"
 	node_attestor "github.com/spiffe/spire/pkg/agent/attestor/node"
 	workload_attestor "github.com/spiffe/spire/pkg/agent/attestor/workload"
 	"github.com/spiffe/spire/pkg/agent/catalog"
 	"github.com/spiffe/spire/pkg/agent/endpoints"
+	endpointslsvid "github.com/spiffe/spire/pkg/agent/endpoints/lsvid"
 	"github.com/spiffe/spire/pkg/agent/manager"
 	"github.com/spiffe/spire/pkg/agent/manager/storecache"
 	"github.com/spiffe/spire/pkg/agent/svid/store"
 	"github.com/spiffe/spire/pkg/common/health"
+	"github.com/spiffe/spire/pkg/common/lsvid"
 	"github.com/spiffe/spire/pkg/common/profiling"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/uptime"
@@ -96,7 +101,12 @@ func (a *Agent) Run(ctx context.Context) error {
 		Metrics: metrics,
 	})
 
-	endpoints := a.newEndpoints(metrics, manager, workloadAttestor, as)
+	lsvidReplayCaches, err := a.newLSVIDReplayCaches()
+	if err != nil {
+		return err
+	}
+
+	endpoints := a.newEndpoints(metrics, manager, workloadAttestor, lsvidReplayCaches)
 
 	if err := healthChecker.AddCheck("agent", a); err != nil {
 		return fmt.Errorf("failed adding healthcheck: %w", err)
@@ -239,7 +249,7 @@ func (a *Agent) newSVIDStoreService(cache *storecache.Cache, cat catalog.Catalog
 	return store.New(config)
 }
 
-func (a *Agent) newEndpoints(metrics telemetry.Metrics, mgr manager.Manager, attestor workload_attestor.Attestor, as *node_attestor.AttestationResult) endpoints.Server {
+func (a *Agent) newEndpoints(metrics telemetry.Metrics, mgr manager.Manager, attestor workload_attestor.Attestor, lsvidReplayCaches map[string]lsvid.ReplayCache) endpoints.Server {
 	return endpoints.New(endpoints.Config{
 		BindAddr:                      a.c.BindAddress,
 		Attestor:                      attestor,
@@ -251,9 +261,11 @@ func (a *Agent) newEndpoints(metrics telemetry.Metrics, mgr manager.Manager, att
 		DefaultAllBundlesName:         a.c.DefaultAllBundlesName,
 		AllowUnauthenticatedVerifiers: a.c.AllowUnauthenticatedVerifiers,
 		AllowedForeignJWTClaims:       a.c.AllowedForeignJWTClaims,
+		KnownLSVIDClaims:              a.c.KnownLSVIDClaims,
+		LSVIDFederationPolicy:         a.c.LSVIDFederationPolicy,
+		LSVIDChainPolicy:              a.c.LSVIDChainPolicy,
+		LSVIDReplayCaches:             lsvidReplayCaches,
 		TrustDomain:                   a.c.TrustDomain,
-		AgentPrivKey:				   as.Key,
-		AgentSVID:					   as.SVID,
 	})
 }
 
@@ -270,6 +282,31 @@ func (a *Agent) newAdminEndpoints(mgr manager.Manager, attestor workload_attesto
 
 	return admin_api.New(config)
 }
+
+// newLSVIDReplayCaches returns the LSVID replay caches configured for each
+// audience. Disk caches are stored in the data directory, in a file named
+// after the SHA-256 digest of the audience.
+func (a *Agent) newLSVIDReplayCaches() (map[string]lsvid.ReplayCache, error) {
+	caches := make(map[string]lsvid.ReplayCache, len(a.c.LSVIDReplayCaches))
+	for audience, kind := range a.c.LSVIDReplayCaches {
+		switch kind {
+		case LSVIDReplayCacheMemory:
+			caches[audience] = lsvid.NewMemoryReplayCache(clock.New())
+		case LSVIDReplayCacheDisk:
+			sum := sha256.Sum256([]byte(audience))
+			cachePath := path.Join(a.c.DataDir, "lsvid_replay", hex.EncodeToString(sum[:])+".log")
+			cache, err := endpointslsvid.NewDiskReplayCache(a.c.Log.WithField(telemetry.Audience, audience), cachePath, clock.New())
+			if err != nil {
+				return nil, fmt.Errorf("failed to load LSVID replay cache for %q: %w", audience, err)
+			}
+			caches[audience] = cache
+		default:
+			return nil, fmt.Errorf("unknown LSVID replay cache %q for %q", kind, audience)
+		}
+	}
+	return caches, nil
+}
+
 func (a *Agent) bundleCachePath() string {
 	return path.Join(a.c.DataDir, "bundle.der")
 }
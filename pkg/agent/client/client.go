@@ -18,7 +18,9 @@ import (
 	svidv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/svid/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/pkg/common/bundleutil"
+	"github.com/spiffe/spire/pkg/common/lsvid"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	lsvidv1 "github.com/spiffe/spire/proto/spire/api/server/lsvid/v1"
 	"github.com/spiffe/spire/proto/spire/common"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -31,6 +33,10 @@ var (
 
 const rpcTimeout = 30 * time.Second
 
+// lsvidRevocationsPageSize is the number of LSVID revocations fetched per
+// request.
+const lsvidRevocationsPageSize = 1000
+
 type X509SVID struct {
 	CertChain []byte
 	ExpiresAt int64
@@ -42,11 +48,23 @@ type JWTSVID struct {
 	ExpiresAt time.Time
 }
 
+// LSVIDRequest holds the subject and audience of an LSVID to be signed by the
+// server, along with the X509-SVID chain binding the subject key.
+type LSVIDRequest struct {
+	EntryID   string
+	SPIFFEID  string
+	PublicKey []byte
+	Audience  string
+	X509SVID  [][]byte
+}
+
 type Client interface {
 	FetchUpdates(ctx context.Context) (*Update, error)
 	RenewSVID(ctx context.Context, csr []byte) (*X509SVID, error)
 	NewX509SVIDs(ctx context.Context, csrs map[string][]byte) (map[string]*X509SVID, error)
 	NewJWTSVID(ctx context.Context, entryID string, audience []string) (*JWTSVID, error)
+	NewLSVID(ctx context.Context, req *LSVIDRequest) (*JWTSVID, error)
+	FetchLSVIDBundle(ctx context.Context, trustDomain string) (*JWTSVID, error)
 
 	// Release releases any resources that were held by this Client, if any.
 	Release()
@@ -75,6 +93,7 @@ type client struct {
 	createNewBundleClient func(grpc.ClientConnInterface) bundlev1.BundleClient
 	createNewSVIDClient   func(grpc.ClientConnInterface) svidv1.SVIDClient
 	createNewAgentClient  func(grpc.ClientConnInterface) agentv1.AgentClient
+	createNewLSVIDClient  func(grpc.ClientConnInterface) lsvidv1.LSVIDClient
 
 	// Constructor used for testing purposes.
 	dialContext func(ctx context.Context, target string, opts ...grpc.DialOption) (*grpc.ClientConn, error)
@@ -92,6 +111,7 @@ func newClient(c *Config) *client {
 		createNewBundleClient: bundlev1.NewBundleClient,
 		createNewSVIDClient:   svidv1.NewSVIDClient,
 		createNewAgentClient:  agentv1.NewAgentClient,
+		createNewLSVIDClient:  lsvidv1.NewLSVIDClient,
 	}
 }
 
@@ -148,9 +168,17 @@ func (c *client) FetchUpdates(ctx context.Context) (*Update, error) {
 		bundles[bundle.TrustDomainId] = bundle
 	}
 
+	// Failing to fetch the revocations must not hold back entries and
+	// bundles. The manager keeps the last known revocations instead.
+	revocations, err := c.fetchLSVIDRevocations(ctx)
+	if err != nil {
+		c.c.Log.WithError(err).Warn("Failed to fetch LSVID revocations; keeping the last known revocations")
+	}
+
 	return &Update{
-		Entries: regEntries,
-		Bundles: bundles,
+		Entries:          regEntries,
+		Bundles:          bundles,
+		LSVIDRevocations: revocations,
 	}, nil
 }
 
@@ -268,6 +296,95 @@ func (c *client) NewJWTSVID(ctx context.Context, entryID string, audience []stri
 	}, nil
 }
 
+func (c *client) NewLSVID(ctx context.Context, req *LSVIDRequest) (*JWTSVID, error) {
+	ctx, cancel := context.WithTimeout(ctx, rpcTimeout)
+	defer cancel()
+
+	c.c.RotMtx.RLock()
+	defer c.c.RotMtx.RUnlock()
+
+	lsvidClient, connection, err := c.newLSVIDClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer connection.Release()
+
+	resp, err := lsvidClient.NewLSVID(ctx, &lsvidv1.NewLSVIDRequest{
+		EntryId:   req.EntryID,
+		SpiffeId:  req.SPIFFEID,
+		PublicKey: req.PublicKey,
+		Audience:  req.Audience,
+		X509Svid:  req.X509SVID,
+	})
+	if err != nil {
+		c.release(connection)
+		c.c.Log.WithError(err).Error("Failed to fetch LSVID")
+		return nil, fmt.Errorf("failed to fetch LSVID: %w", err)
+	}
+
+	svid := resp.Lsvid
+	switch {
+	case svid == nil:
+		return nil, errors.New("LSVID response missing LSVID")
+	case svid.IssuedAt == 0:
+		return nil, errors.New("LSVID missing issued at")
+	case svid.ExpiresAt == 0:
+		return nil, errors.New("LSVID missing expires at")
+	case svid.IssuedAt > svid.ExpiresAt:
+		return nil, errors.New("LSVID issued after it has expired")
+	}
+
+	return &JWTSVID{
+		Token:     svid.Token,
+		IssuedAt:  time.Unix(svid.IssuedAt, 0).UTC(),
+		ExpiresAt: time.Unix(svid.ExpiresAt, 0).UTC(),
+	}, nil
+}
+
+// FetchLSVIDBundle fetches the LSVID trust bundle document of the given
+// trust domain, signed by the server. The trust domain of the agent is
+// requested if empty. The document is returned as a JWTSVID so that it can be
+// cached and renewed like LSVIDs.
+func (c *client) FetchLSVIDBundle(ctx context.Context, trustDomain string) (*JWTSVID, error) {
+	ctx, cancel := context.WithTimeout(ctx, rpcTimeout)
+	defer cancel()
+
+	c.c.RotMtx.RLock()
+	defer c.c.RotMtx.RUnlock()
+
+	lsvidClient, connection, err := c.newLSVIDClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer connection.Release()
+
+	resp, err := lsvidClient.GetLSVIDBundle(ctx, &lsvidv1.GetLSVIDBundleRequest{
+		TrustDomain: trustDomain,
+	})
+	if err != nil {
+		c.release(connection)
+		c.c.Log.WithError(err).Error("Failed to fetch LSVID trust bundle document")
+		return nil, fmt.Errorf("failed to fetch LSVID trust bundle document: %w", err)
+	}
+
+	switch {
+	case resp.Bundle == "":
+		return nil, errors.New("LSVID trust bundle document response missing document")
+	case resp.IssuedAt == 0:
+		return nil, errors.New("LSVID trust bundle document missing issued at")
+	case resp.ExpiresAt == 0:
+		return nil, errors.New("LSVID trust bundle document missing expires at")
+	case resp.IssuedAt > resp.ExpiresAt:
+		return nil, errors.New("LSVID trust bundle document issued after it has expired")
+	}
+
+	return &JWTSVID{
+		Token:     resp.Bundle,
+		IssuedAt:  time.Unix(resp.IssuedAt, 0).UTC(),
+		ExpiresAt: time.Unix(resp.ExpiresAt, 0).UTC(),
+	}, nil
+}
+
 // Release the underlying connection.
 func (c *client) Release() {
 	c.release(nil)
@@ -361,6 +478,46 @@ func (c *client) fetchBundles(ctx context.Context, federatedBundles []string) ([
 	return bundles, nil
 }
 
+// fetchLSVIDRevocations fetches the LSVID revocations of the server, one
+// page at a time. Revocations of unknown kinds are ignored. Servers that do
+// not implement LSVID revocations have none.
+func (c *client) fetchLSVIDRevocations(ctx context.Context) (lsvid.RevocationList, error) {
+	lsvidClient, connection, err := c.newLSVIDClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer connection.Release()
+
+	var revocations []lsvid.Revocation
+	pageToken := ""
+	for {
+		resp, err := lsvidClient.ListLSVIDRevocations(ctx, &lsvidv1.ListLSVIDRevocationsRequest{
+			PageSize:  lsvidRevocationsPageSize,
+			PageToken: pageToken,
+		})
+		switch status.Code(err) {
+		case codes.OK:
+		case codes.Unimplemented:
+			return lsvid.NewRevocationList(), nil
+		default:
+			c.release(connection)
+			return nil, fmt.Errorf("failed to fetch LSVID revocations: %w", err)
+		}
+
+		for _, r := range resp.Revocations {
+			kind := lsvid.RevocationKind(r.Kind)
+			if err := kind.Validate(); err != nil {
+				c.c.Log.WithError(err).Warn("Received malformed LSVID revocation from SPIRE server")
+				continue
+			}
+			revocations = append(revocations, lsvid.Revocation{Kind: kind, Value: r.Value})
+		}
+		if pageToken = resp.NextPageToken; pageToken == "" {
+			return lsvid.NewRevocationList(revocations...), nil
+		}
+	}
+}
+
 func (c *client) fetchSVIDs(ctx context.Context, params []*svidv1.NewX509SVIDParams) ([]*types.X509SVID, error) {
 	svidClient, connection, err := c.newSVIDClient(ctx)
 	if err != nil {
@@ -454,3 +611,18 @@ func (c *client) newAgentClient(ctx context.Context) (agentv1.AgentClient, *node
 	c.connections.AddRef()
 	return c.createNewAgentClient(c.connections.conn), c.connections, nil
 }
+
+func (c *client) newLSVIDClient(ctx context.Context) (lsvidv1.LSVIDClient, *nodeConn, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if c.connections == nil {
+		conn, err := c.dial(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		c.connections = newNodeConn(conn)
+	}
+	c.connections.AddRef()
+	return c.createNewLSVIDClient(c.connections.conn), c.connections, nil
+}
@@ -5,6 +5,8 @@ import (
 	"crypto"
 	"crypto/x509"
 	"errors"
+	"fmt"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
@@ -16,11 +18,14 @@ import (
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	svidv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/svid/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/lsvid"
+	lsvidv1 "github.com/spiffe/spire/proto/spire/api/server/lsvid/v1"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var (
@@ -156,6 +161,12 @@ func TestFetchUpdates(t *testing.T) {
 		},
 	}
 
+	tc.lsvidClient.revocations = []*lsvidv1.Revocation{
+		{Kind: "SPIFFE_ID", Value: "spiffe://example.org/id1"},
+		// This revocation should be ignored since its kind is unknown
+		{Kind: "SERIAL", Value: "1"},
+	}
+
 	// Simulate an ongoing SVID rotation (request should not be made in the middle of a rotation)
 	client.c.RotMtx.Lock()
 
@@ -180,6 +191,10 @@ func TestFetchUpdates(t *testing.T) {
 	// Assert results
 	require.Nil(t, err)
 	assert.Equal(t, testBundles, update.Bundles)
+	assert.Equal(t, lsvid.NewRevocationList(lsvid.Revocation{
+		Kind:  lsvid.RevokeSPIFFEID,
+		Value: "spiffe://example.org/id1",
+	}), update.LSVIDRevocations)
 	// Only the first registration entry should be returned since the rest are
 	// invalid for one reason or another
 	if assert.Len(t, update.Entries, 1) {
@@ -551,6 +566,60 @@ func TestFetchUpdatesReleaseConnectionIfItFailsToFetch(t *testing.T) {
 	}
 }
 
+func TestFetchUpdatesLSVIDRevocations(t *testing.T) {
+	var revocations []*lsvidv1.Revocation
+	var expected []lsvid.Revocation
+	for i := 0; i < 2*lsvidRevocationsPageSize+1; i++ {
+		value := fmt.Sprintf("token-%d", i)
+		revocations = append(revocations, &lsvidv1.Revocation{Kind: "TOKEN_ID", Value: value})
+		expected = append(expected, lsvid.Revocation{Kind: lsvid.RevokeTokenID, Value: value})
+	}
+
+	for _, tt := range []struct {
+		name           string
+		revocationsErr error
+		expected       lsvid.RevocationList
+		expectRequests int
+	}{
+		{
+			name:           "paginated",
+			expected:       lsvid.NewRevocationList(expected...),
+			expectRequests: 3,
+		},
+		{
+			name:           "server does not implement revocations",
+			revocationsErr: status.Error(codes.Unimplemented, "unknown method"),
+			expected:       lsvid.NewRevocationList(),
+			expectRequests: 1,
+		},
+		{
+			name:           "fetch fails",
+			revocationsErr: status.Error(codes.Unavailable, "an error"),
+			expectRequests: 1,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			client, tc := createClient()
+			tc.entryClient.entries = []*types.Entry{}
+			tc.bundleClient.agentBundle = &types.Bundle{
+				TrustDomain:     "example.org",
+				X509Authorities: []*types.X509Certificate{{Asn1: []byte{10, 20, 30, 40}}},
+			}
+			tc.lsvidClient.revocations = revocations
+			tc.lsvidClient.revocationsErr = tt.revocationsErr
+
+			// Entries and bundles are updated even if the revocations can't
+			// be fetched.
+			update, err := client.FetchUpdates(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, testBundles["spiffe://example.org"], update.Bundles["spiffe://example.org"])
+			require.Equal(t, tt.expected, update.LSVIDRevocations)
+			require.Equal(t, tt.expectRequests, tc.lsvidClient.revocationsRequests)
+		})
+	}
+}
+
 func TestFetchUpdatesReleaseConnectionIfItFails(t *testing.T) {
 	client, tc := createClient()
 
@@ -707,6 +776,192 @@ func TestFetchJWTSVID(t *testing.T) {
 	}
 }
 
+func TestNewLSVIDClientFailsDial(t *testing.T) {
+	client := newClient(&Config{
+		KeysAndBundle: keysAndBundle,
+		TrustDomain:   trustDomain,
+	})
+	lsvidClient, conn, err := client.newLSVIDClient(context.Background())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "dial tcp: missing address")
+	require.Nil(t, lsvidClient)
+	require.Nil(t, conn)
+}
+
+func TestFetchLSVID(t *testing.T) {
+	client, tc := createClient()
+	ctx := context.Background()
+
+	issuedAt := time.Now().Unix()
+	expiresAt := time.Now().Add(time.Minute).Unix()
+	req := &LSVIDRequest{
+		EntryID:   "entry-id",
+		SPIFFEID:  "spiffe://example.org/workload",
+		PublicKey: []byte{1, 2, 3},
+		Audience:  "spiffe://example.org/agent",
+		X509SVID:  [][]byte{{4, 5, 6}},
+	}
+	for _, tt := range []struct {
+		name       string
+		lsvid      *lsvidv1.Token
+		fetchErr   error
+		err        string
+		expectSVID *JWTSVID
+	}{
+		{
+			name: "success",
+			lsvid: &lsvidv1.Token{
+				Token:     "token",
+				ExpiresAt: expiresAt,
+				IssuedAt:  issuedAt,
+			},
+			expectSVID: &JWTSVID{
+				Token:     "token",
+				ExpiresAt: time.Unix(expiresAt, 0).UTC(),
+				IssuedAt:  time.Unix(issuedAt, 0).UTC(),
+			},
+		},
+		{
+			name:     "client fails",
+			fetchErr: errors.New("client fails"),
+			err:      "failed to fetch LSVID: client fails",
+		},
+		{
+			name: "empty response",
+			err:  "LSVID response missing LSVID",
+		},
+		{
+			name: "missing issuedAt",
+			lsvid: &lsvidv1.Token{
+				Token:     "token",
+				ExpiresAt: expiresAt,
+			},
+			err: "LSVID missing issued at",
+		},
+		{
+			name: "missing expiredAt",
+			lsvid: &lsvidv1.Token{
+				Token:    "token",
+				IssuedAt: issuedAt,
+			},
+			err: "LSVID missing expires at",
+		},
+		{
+			name: "issued after expired",
+			lsvid: &lsvidv1.Token{
+				Token:     "token",
+				ExpiresAt: issuedAt,
+				IssuedAt:  expiresAt,
+			},
+			err: "LSVID issued after it has expired",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tc.lsvidClient.lsvid = tt.lsvid
+			tc.lsvidClient.err = tt.fetchErr
+			resp, err := client.NewLSVID(ctx, req)
+			if tt.err != "" {
+				require.Nil(t, resp)
+				require.EqualError(t, err, tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expectSVID, resp)
+			require.Equal(t, &lsvidv1.NewLSVIDRequest{
+				EntryId:   req.EntryID,
+				SpiffeId:  req.SPIFFEID,
+				PublicKey: req.PublicKey,
+				Audience:  req.Audience,
+				X509Svid:  req.X509SVID,
+			}, tc.lsvidClient.lastRequest)
+		})
+	}
+}
+
+func TestFetchLSVIDBundle(t *testing.T) {
+	client, tc := createClient()
+	ctx := context.Background()
+
+	issuedAt := time.Now().Unix()
+	expiresAt := time.Now().Add(time.Minute).Unix()
+	for _, tt := range []struct {
+		name         string
+		bundle       *lsvidv1.GetLSVIDBundleResponse
+		fetchErr     error
+		err          string
+		expectBundle *JWTSVID
+	}{
+		{
+			name: "success",
+			bundle: &lsvidv1.GetLSVIDBundleResponse{
+				Bundle:    "bundle",
+				ExpiresAt: expiresAt,
+				IssuedAt:  issuedAt,
+			},
+			expectBundle: &JWTSVID{
+				Token:     "bundle",
+				ExpiresAt: time.Unix(expiresAt, 0).UTC(),
+				IssuedAt:  time.Unix(issuedAt, 0).UTC(),
+			},
+		},
+		{
+			name:     "client fails",
+			fetchErr: errors.New("client fails"),
+			err:      "failed to fetch LSVID trust bundle document: client fails",
+		},
+		{
+			name:   "empty response",
+			bundle: &lsvidv1.GetLSVIDBundleResponse{},
+			err:    "LSVID trust bundle document response missing document",
+		},
+		{
+			name: "missing issuedAt",
+			bundle: &lsvidv1.GetLSVIDBundleResponse{
+				Bundle:    "bundle",
+				ExpiresAt: expiresAt,
+			},
+			err: "LSVID trust bundle document missing issued at",
+		},
+		{
+			name: "missing expiredAt",
+			bundle: &lsvidv1.GetLSVIDBundleResponse{
+				Bundle:   "bundle",
+				IssuedAt: issuedAt,
+			},
+			err: "LSVID trust bundle document missing expires at",
+		},
+		{
+			name: "issued after expired",
+			bundle: &lsvidv1.GetLSVIDBundleResponse{
+				Bundle:    "bundle",
+				ExpiresAt: issuedAt,
+				IssuedAt:  expiresAt,
+			},
+			err: "LSVID trust bundle document issued after it has expired",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tc.lsvidClient.bundle = tt.bundle
+			tc.lsvidClient.err = tt.fetchErr
+			resp, err := client.FetchLSVIDBundle(ctx, "domain.test")
+			if tt.err != "" {
+				require.Nil(t, resp)
+				require.EqualError(t, err, tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expectBundle, resp)
+			require.Equal(t, &lsvidv1.GetLSVIDBundleRequest{
+				TrustDomain: "domain.test",
+			}, tc.lsvidClient.lastBundleRequest)
+		})
+	}
+}
+
 // createClient creates a sample client with mocked components for testing purposes
 func createClient() (*client, *testClient) {
 	tc := &testClient{
@@ -714,6 +969,7 @@ func createClient() (*client, *testClient) {
 		bundleClient: &fakeBundleClient{},
 		entryClient:  &fakeEntryClient{},
 		svidClient:   &fakeSVIDClient{},
+		lsvidClient:  &fakeLSVIDClient{},
 	}
 
 	client := newClient(&Config{
@@ -734,6 +990,9 @@ func createClient() (*client, *testClient) {
 	client.createNewSVIDClient = func(conn grpc.ClientConnInterface) svidv1.SVIDClient {
 		return tc.svidClient
 	}
+	client.createNewLSVIDClient = func(conn grpc.ClientConnInterface) lsvidv1.LSVIDClient {
+		return tc.lsvidClient
+	}
 
 	client.dialContext = func(ctx context.Context, addr string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
 		// make a normal grpc dial but without any of the provided options that may cause it to fail
@@ -862,6 +1121,69 @@ func (c *fakeSVIDClient) NewJWTSVID(ctx context.Context, in *svidv1.NewJWTSVIDRe
 	}, nil
 }
 
+type fakeLSVIDClient struct {
+	lsvidv1.LSVIDClient
+	err         error
+	lsvid       *lsvidv1.Token
+	bundle      *lsvidv1.GetLSVIDBundleResponse
+	lastRequest *lsvidv1.NewLSVIDRequest
+
+	lastBundleRequest *lsvidv1.GetLSVIDBundleRequest
+
+	revocations         []*lsvidv1.Revocation
+	revocationsErr      error
+	revocationsRequests int
+}
+
+func (c *fakeLSVIDClient) NewLSVID(ctx context.Context, in *lsvidv1.NewLSVIDRequest, opts ...grpc.CallOption) (*lsvidv1.NewLSVIDResponse, error) {
+	c.lastRequest = in
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &lsvidv1.NewLSVIDResponse{
+		Lsvid: c.lsvid,
+	}, nil
+}
+
+func (c *fakeLSVIDClient) GetLSVIDBundle(ctx context.Context, in *lsvidv1.GetLSVIDBundleRequest, opts ...grpc.CallOption) (*lsvidv1.GetLSVIDBundleResponse, error) {
+	c.lastBundleRequest = in
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.bundle, nil
+}
+
+func (c *fakeLSVIDClient) ListLSVIDRevocations(ctx context.Context, in *lsvidv1.ListLSVIDRevocationsRequest, opts ...grpc.CallOption) (*lsvidv1.ListLSVIDRevocationsResponse, error) {
+	c.revocationsRequests++
+	if c.revocationsErr != nil {
+		return nil, c.revocationsErr
+	}
+	if in.PageSize == 0 {
+		return &lsvidv1.ListLSVIDRevocationsResponse{
+			Revocations: c.revocations,
+		}, nil
+	}
+
+	start := 0
+	if in.PageToken != "" {
+		var err error
+		if start, err = strconv.Atoi(in.PageToken); err != nil {
+			return nil, err
+		}
+	}
+	end := start + int(in.PageSize)
+	if end > len(c.revocations) {
+		end = len(c.revocations)
+	}
+	resp := &lsvidv1.ListLSVIDRevocationsResponse{
+		Revocations: c.revocations[start:end],
+	}
+	if end < len(c.revocations) {
+		resp.NextPageToken = strconv.Itoa(end)
+	}
+	return resp, nil
+}
+
 type fakeAgentClient struct {
 	agentv1.AgentClient
 	err  error
@@ -887,4 +1209,5 @@ type testClient struct {
 	bundleClient *fakeBundleClient
 	entryClient  *fakeEntryClient
 	svidClient   *fakeSVIDClient
+	lsvidClient  *fakeLSVIDClient
 }
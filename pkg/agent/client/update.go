@@ -1,8 +1,16 @@
 package client
 
-import "github.com/spiffe/spire/proto/spire/common"
+import (
+	"github.com/spiffe/spire/pkg/common/lsvid"
+	"github.com/spiffe/spire/proto/spire/common"
+)
 
 type Update struct {
 	Entries map[string]*common.RegistrationEntry
 	Bundles map[string]*common.Bundle
+
+	// LSVIDRevocations holds the LSVID revocations of the server. It is nil
+	// when the revocations could not be fetched, in which case the last known
+	// revocations remain in effect.
+	LSVIDRevocations lsvid.RevocationList
 }
@@ -9,9 +9,21 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/common/catalog"
 	"github.com/spiffe/spire/pkg/common/health"
+	"github.com/spiffe/spire/pkg/common/lsvid"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 )
 
+const (
+	// LSVIDReplayCacheMemory keeps the token IDs of the LSVIDs validated for an
+	// audience in memory.
+	LSVIDReplayCacheMemory = "memory"
+
+	// LSVIDReplayCacheDisk keeps the token IDs of the LSVIDs validated for an
+	// audience in memory and persists them in the data directory, so that
+	// they survive agent restarts.
+	LSVIDReplayCacheDisk = "disk"
+)
+
 type Config struct {
 	// Address to bind the workload api to
 	BindAddress *net.UnixAddr
@@ -75,6 +87,24 @@ type Config struct {
 	// List of allowed claims response when calling ValidateJWTSVID using a foreign identity
 	AllowedForeignJWTClaims []string
 
+	// List of critical LSVID claims understood by the workloads validating
+	// LSVIDs through the Workload API
+	KnownLSVIDClaims []string
+
+	// Foreign issuers allowed in the LSVIDs validated through the Workload
+	// API, per federated trust domain. If nil, foreign issuers are only
+	// required to be anchored in the bundle of their trust domain.
+	LSVIDFederationPolicy lsvid.FederationPolicy
+
+	// Constraints on the chain of layers of the LSVIDs validated through the
+	// Workload API, if any
+	LSVIDChainPolicy *lsvid.ChainPolicy
+
+	// Replay caches rejecting the LSVIDs already validated through the
+	// Workload API, keyed by audience. Either LSVIDReplayCacheMemory or
+	// LSVIDReplayCacheDisk.
+	LSVIDReplayCaches map[string]string
+
 	AuthorizedDelegates []string
 }
 
@@ -10,16 +10,15 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	healthv1 "github.com/spiffe/spire/pkg/agent/api/health/v1"
 	attestor "github.com/spiffe/spire/pkg/agent/attestor/workload"
+	"github.com/spiffe/spire/pkg/agent/endpoints/lsvid"
 	"github.com/spiffe/spire/pkg/agent/endpoints/sdsv2"
 	"github.com/spiffe/spire/pkg/agent/endpoints/sdsv3"
 	"github.com/spiffe/spire/pkg/agent/endpoints/workload"
 	"github.com/spiffe/spire/pkg/agent/manager"
+	core "github.com/spiffe/spire/pkg/common/lsvid"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	lsvidv1 "github.com/spiffe/spire/proto/spire/api/workload/lsvid/v1"
 	"google.golang.org/grpc/health/grpc_health_v1"
-
-	// to selectors assertion
-	"github.com/spiffe/spire/pkg/agent/plugin/keymanager"
-	"crypto/x509"
 )
 
 type Config struct {
@@ -46,15 +45,20 @@ type Config struct {
 
 	AllowedForeignJWTClaims []string
 
-	TrustDomain spiffeid.TrustDomain
+	KnownLSVIDClaims []string
+
+	LSVIDFederationPolicy core.FederationPolicy
 
-	AgentPrivKey keymanager.Key
+	LSVIDChainPolicy *core.ChainPolicy
 
-	AgentSVID []*x509.Certificate
+	LSVIDReplayCaches map[string]core.ReplayCache
+
+	TrustDomain spiffeid.TrustDomain
 
 	// Hooks used by the unit tests to assert that the configuration provided
 	// to each handler is correct and return fake handlers.
 	newWorkloadAPIServer func(workload.Config) workload_pb.SpiffeWorkloadAPIServer
+	newLSVIDServer       func(lsvid.Config) lsvidv1.LSVIDWorkloadAPIServer
 	newSDSv2Server       func(sdsv2.Config) discovery_v2.SecretDiscoveryServiceServer
 	newSDSv3Server       func(sdsv3.Config) secret_v3.SecretDiscoveryServiceServer
 	newHealthServer      func(healthv1.Config) grpc_health_v1.HealthServer
@@ -12,12 +12,14 @@ import (
 	"github.com/sirupsen/logrus"
 	workload_pb "github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
 	healthv1 "github.com/spiffe/spire/pkg/agent/api/health/v1"
+	"github.com/spiffe/spire/pkg/agent/endpoints/lsvid"
 	"github.com/spiffe/spire/pkg/agent/endpoints/sdsv2"
 	"github.com/spiffe/spire/pkg/agent/endpoints/sdsv3"
 	"github.com/spiffe/spire/pkg/agent/endpoints/workload"
 	"github.com/spiffe/spire/pkg/common/api/middleware"
 	"github.com/spiffe/spire/pkg/common/peertracker"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	lsvidv1 "github.com/spiffe/spire/proto/spire/api/workload/lsvid/v1"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health/grpc_health_v1"
 )
@@ -31,6 +33,7 @@ type Endpoints struct {
 	log               logrus.FieldLogger
 	metrics           telemetry.Metrics
 	workloadAPIServer workload_pb.SpiffeWorkloadAPIServer
+	lsvidServer       lsvidv1.LSVIDWorkloadAPIServer
 	sdsv2Server       discovery_v2.SecretDiscoveryServiceServer
 	sdsv3Server       secret_v3.SecretDiscoveryServiceServer
 	healthServer      grpc_health_v1.HealthServer
@@ -44,6 +47,11 @@ func New(c Config) *Endpoints {
 			return workload.New(c)
 		}
 	}
+	if c.newLSVIDServer == nil {
+		c.newLSVIDServer = func(c lsvid.Config) lsvidv1.LSVIDWorkloadAPIServer {
+			return lsvid.New(c)
+		}
+	}
 	if c.newSDSv2Server == nil {
 		c.newSDSv2Server = func(c sdsv2.Config) discovery_v2.SecretDiscoveryServiceServer {
 			return sdsv2.New(c)
@@ -71,8 +79,18 @@ func New(c Config) *Endpoints {
 		AllowUnauthenticatedVerifiers: c.AllowUnauthenticatedVerifiers,
 		AllowedForeignJWTClaims:       allowedClaims,
 		TrustDomain:                   c.TrustDomain,
-		AgentPrivKey:				   c.AgentPrivKey,
-		AgentSVID:					   c.AgentSVID,
+	})
+
+	lsvidServer := c.newLSVIDServer(lsvid.Config{
+		Manager:                       c.Manager,
+		Attestor:                      attestor,
+		AllowUnauthenticatedVerifiers: c.AllowUnauthenticatedVerifiers,
+		AllowedForeignJWTClaims:       allowedClaims,
+		KnownLSVIDClaims:              c.KnownLSVIDClaims,
+		FederationPolicy:              c.LSVIDFederationPolicy,
+		ChainPolicy:                   c.LSVIDChainPolicy,
+		ReplayCaches:                  c.LSVIDReplayCaches,
+		TrustDomain:                   c.TrustDomain,
 	})
 
 	sdsv2Server := c.newSDSv2Server(sdsv2.Config{
@@ -99,6 +117,7 @@ func New(c Config) *Endpoints {
 		log:               c.Log,
 		metrics:           c.Metrics,
 		workloadAPIServer: workloadAPIServer,
+		lsvidServer:       lsvidServer,
 		sdsv2Server:       sdsv2Server,
 		sdsv3Server:       sdsv3Server,
 		healthServer:      healthServer,
@@ -117,6 +136,7 @@ func (e *Endpoints) ListenAndServe(ctx context.Context) error {
 	)
 
 	workload_pb.RegisterSpiffeWorkloadAPIServer(server, e.workloadAPIServer)
+	lsvidv1.RegisterLSVIDWorkloadAPIServer(server, e.lsvidServer)
 	discovery_v2.RegisterSecretDiscoveryServiceServer(server, e.sdsv2Server)
 	secret_v3.RegisterSecretDiscoveryServiceServer(server, e.sdsv3Server)
 	grpc_health_v1.RegisterHealthServer(server, e.healthServer)
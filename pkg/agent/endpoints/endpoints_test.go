@@ -17,13 +17,18 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
 	workload_pb "github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	healthv1 "github.com/spiffe/spire/pkg/agent/api/health/v1"
 	"github.com/spiffe/spire/pkg/agent/api/rpccontext"
+	"github.com/spiffe/spire/pkg/agent/endpoints/lsvid"
 	"github.com/spiffe/spire/pkg/agent/endpoints/sdsv2"
 	"github.com/spiffe/spire/pkg/agent/endpoints/sdsv3"
 	"github.com/spiffe/spire/pkg/agent/endpoints/workload"
 	"github.com/spiffe/spire/pkg/agent/manager"
+	core "github.com/spiffe/spire/pkg/common/lsvid"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	lsvidv1 "github.com/spiffe/spire/proto/spire/api/workload/lsvid/v1"
+	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakemetrics"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/stretchr/testify/assert"
@@ -40,6 +45,8 @@ func TestEndpoints(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
+	replayCache := core.NewMemoryReplayCache(clock.NewMock(t))
+
 	for _, tt := range []struct {
 		name            string
 		do              func(t *testing.T, conn *grpc.ClientConn)
@@ -99,6 +106,56 @@ func TestEndpoints(t *testing.T) {
 				}},
 			},
 		},
+		{
+			name: "lsvid workload api fails without security header",
+			do: func(t *testing.T, conn *grpc.ClientConn) {
+				lsvidClient := lsvidv1.NewLSVIDWorkloadAPIClient(conn)
+				ctx := metadata.NewOutgoingContext(ctx, metadata.MD{})
+				_, err := lsvidClient.FetchLSVID(ctx, &lsvidv1.LSVIDRequest{})
+				spiretest.AssertGRPCStatus(t, err, codes.InvalidArgument, "security header missing from request")
+			},
+			expectedMetrics: []fakemetrics.MetricItem{
+				// Global connection counter and then the increment/decrement of the connection gauge
+				{Type: fakemetrics.IncrCounterType, Key: []string{"workload_api", "connection"}, Val: 1},
+				{Type: fakemetrics.SetGaugeType, Key: []string{"workload_api", "connections"}, Val: 1},
+				{Type: fakemetrics.SetGaugeType, Key: []string{"workload_api", "connections"}, Val: 0},
+				// Call counter
+				{Type: fakemetrics.IncrCounterWithLabelsType, Key: []string{"rpc", "lsvid_workload_api", "fetch_lsvid"}, Val: 1, Labels: []metrics.Label{
+					{Name: "status", Value: "InvalidArgument"},
+				}},
+				{Type: fakemetrics.MeasureSinceWithLabelsType, Key: []string{"rpc", "lsvid_workload_api", "fetch_lsvid", "elapsed_time"}, Val: 0, Labels: []metrics.Label{
+					{Name: "status", Value: "InvalidArgument"},
+				}},
+			},
+		},
+		{
+			name: "lsvid workload api has peertracker attestor plumbed",
+			do: func(t *testing.T, conn *grpc.ClientConn) {
+				lsvidClient := lsvidv1.NewLSVIDWorkloadAPIClient(conn)
+				ctx = metadata.NewOutgoingContext(ctx, metadata.Pairs("workload.spiffe.io", "true"))
+				_, err := lsvidClient.FetchLSVID(ctx, &lsvidv1.LSVIDRequest{})
+				require.NoError(t, err)
+			},
+			expectedLogs: []spiretest.LogEntry{
+				logEntryWithPID(logrus.InfoLevel, "Success",
+					"method", "FetchLSVID",
+					"service", "LSVIDWorkloadAPI",
+				),
+			},
+			expectedMetrics: []fakemetrics.MetricItem{
+				// Global connection counter and then the increment/decrement of the connection gauge
+				{Type: fakemetrics.IncrCounterType, Key: []string{"workload_api", "connection"}, Val: 1},
+				{Type: fakemetrics.SetGaugeType, Key: []string{"workload_api", "connections"}, Val: 1},
+				{Type: fakemetrics.SetGaugeType, Key: []string{"workload_api", "connections"}, Val: 0},
+				// Call counter
+				{Type: fakemetrics.IncrCounterWithLabelsType, Key: []string{"rpc", "lsvid_workload_api", "fetch_lsvid"}, Val: 1, Labels: []metrics.Label{
+					{Name: "status", Value: "OK"},
+				}},
+				{Type: fakemetrics.MeasureSinceWithLabelsType, Key: []string{"rpc", "lsvid_workload_api", "fetch_lsvid", "elapsed_time"}, Val: 0, Labels: []metrics.Label{
+					{Name: "status", Value: "OK"},
+				}},
+			},
+		},
 		{
 			name: "sds v2 api has peertracker attestor plumbed",
 			do: func(t *testing.T, conn *grpc.ClientConn) {
@@ -174,6 +231,10 @@ func TestEndpoints(t *testing.T) {
 				DefaultBundleName:       "DefaultBundleName",
 				DefaultAllBundlesName:   "DefaultAllBundlesName",
 				AllowedForeignJWTClaims: tt.allowedClaims,
+				KnownLSVIDClaims:        []string{"urn:example:scope"},
+				LSVIDFederationPolicy:   core.FederationPolicy{spiffeid.RequireTrustDomainFromString("domain.test"): nil},
+				LSVIDChainPolicy:        &core.ChainPolicy{MaxDepth: 3},
+				LSVIDReplayCaches:       map[string]core.ReplayCache{"spiffe://domain.test/service": replayCache},
 
 				// Assert the provided config and return a fake Workload API server
 				newWorkloadAPIServer: func(c workload.Config) workload_pb.SpiffeWorkloadAPIServer {
@@ -188,6 +249,23 @@ func TestEndpoints(t *testing.T) {
 					return FakeWorkloadAPIServer{Attestor: attestor}
 				},
 
+				// Assert the provided config and return a fake LSVID Workload API server
+				newLSVIDServer: func(c lsvid.Config) lsvidv1.LSVIDWorkloadAPIServer {
+					attestor, ok := c.Attestor.(PeerTrackerAttestor)
+					require.True(t, ok, "attestor was not a PeerTrackerAttestor wrapper")
+					assert.Equal(t, FakeManager{}, c.Manager)
+					if tt.expectClaims != nil {
+						assert.Equal(t, tt.expectClaims, c.AllowedForeignJWTClaims)
+					} else {
+						assert.Empty(t, c.AllowedForeignJWTClaims)
+					}
+					assert.Equal(t, []string{"urn:example:scope"}, c.KnownLSVIDClaims)
+					assert.Equal(t, core.FederationPolicy{spiffeid.RequireTrustDomainFromString("domain.test"): nil}, c.FederationPolicy)
+					assert.Equal(t, &core.ChainPolicy{MaxDepth: 3}, c.ChainPolicy)
+					assert.Equal(t, map[string]core.ReplayCache{"spiffe://domain.test/service": replayCache}, c.ReplayCaches)
+					return FakeLSVIDServer{Attestor: attestor}
+				},
+
 				// Assert the provided config and return a fake SDS server
 				newSDSv2Server: func(c sdsv2.Config) discovery_v2.SecretDiscoveryServiceServer {
 					attestor, ok := c.Attestor.(PeerTrackerAttestor)
@@ -268,6 +346,18 @@ func (s FakeWorkloadAPIServer) FetchJWTSVID(ctx context.Context, in *workload_pb
 	return &workload_pb.JWTSVIDResponse{}, nil
 }
 
+type FakeLSVIDServer struct {
+	Attestor PeerTrackerAttestor
+	*lsvidv1.UnimplementedLSVIDWorkloadAPIServer
+}
+
+func (s FakeLSVIDServer) FetchLSVID(ctx context.Context, in *lsvidv1.LSVIDRequest) (*lsvidv1.LSVIDResponse, error) {
+	if err := attest(ctx, s.Attestor); err != nil {
+		return nil, err
+	}
+	return &lsvidv1.LSVIDResponse{}, nil
+}
+
 type FakeSDSv2Server struct {
 	Attestor PeerTrackerAttestor
 	*discovery_v2.UnimplementedSecretDiscoveryServiceServer
@@ -0,0 +1,765 @@
+package lsvid
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/api/rpccontext"
+	"github.com/spiffe/spire/pkg/agent/client"
+	"github.com/spiffe/spire/pkg/agent/manager/cache"
+	"github.com/spiffe/spire/pkg/agent/svid"
+	"github.com/spiffe/spire/pkg/common/bundleutil"
+	core "github.com/spiffe/spire/pkg/common/lsvid"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	lsvidv1 "github.com/spiffe/spire/proto/spire/api/workload/lsvid/v1"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// validationErrorDomain is the domain of the ErrorInfo details of the
+// validation failures returned by ValidateLSVID and ExtendLSVID.
+const validationErrorDomain = "lsvid.spire.spiffe.io"
+
+// minRefreshInterval bounds how often FetchLSVIDStream sends LSVIDs that
+// could not be renewed at half of their lifetime.
+const minRefreshInterval = 5 * time.Second
+
+type Manager interface {
+	SubscribeToCacheChanges(cache.Selectors) cache.Subscriber
+	MatchingIdentities([]*common.Selector) []cache.Identity
+	FetchLSVID(ctx context.Context, spiffeID spiffeid.ID, svid []*x509.Certificate, audience string) (*client.JWTSVID, error)
+	FetchLSVIDBundle(ctx context.Context, td spiffeid.TrustDomain) (*client.JWTSVID, error)
+	FetchWorkloadUpdate([]*common.Selector) *cache.WorkloadUpdate
+	LSVIDRevocations() core.RevocationList
+	GetCurrentCredentials() svid.State
+}
+
+type Attestor interface {
+	Attest(ctx context.Context) ([]*common.Selector, error)
+}
+
+// Config is the configuration of the LSVID Workload API handler.
+type Config struct {
+	Manager                       Manager
+	Attestor                      Attestor
+	AllowUnauthenticatedVerifiers bool
+	AllowedForeignJWTClaims       map[string]struct{}
+	KnownLSVIDClaims              []string
+	FederationPolicy              core.FederationPolicy
+	ChainPolicy                   *core.ChainPolicy
+	ReplayCaches                  map[string]core.ReplayCache
+	TrustDomain                   spiffeid.TrustDomain
+}
+
+// Handler implements the LSVID Workload API
+type Handler struct {
+	lsvidv1.UnsafeLSVIDWorkloadAPIServer
+	c Config
+}
+
+func New(c Config) *Handler {
+	return &Handler{
+		c: c,
+	}
+}
+
+// FetchLSVID attests the caller and returns its LSVIDs. Each LSVID is signed
+// by the server for the agent and extended by the agent to the workload,
+// embedding the LSVID of the agent in the issuer claim. The LSVID trust bundle
+// document signed by the server is embedded when requested.
+func (h *Handler) FetchLSVID(ctx context.Context, req *lsvidv1.LSVIDRequest) (*lsvidv1.LSVIDResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	if err := checkRequestedSPIFFEID(req.SpiffeId, log); err != nil {
+		return nil, err
+	}
+
+	selectors, err := h.c.Attestor.Attest(ctx)
+	if err != nil {
+		log.WithError(err).Error("Workload attestation failed")
+		return nil, err
+	}
+
+	resp, _, err := h.composeLSVIDResponse(ctx, log, h.c.Manager.MatchingIdentities(selectors), req.SpiffeId, req.IncludeBundle)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// FetchLSVIDStream attests the caller and streams its LSVIDs, sending new ones
+// when the identities of the caller change and when the LSVIDs reach half of
+// their lifetime.
+func (h *Handler) FetchLSVIDStream(req *lsvidv1.LSVIDRequest, stream lsvidv1.LSVIDWorkloadAPI_FetchLSVIDStreamServer) error {
+	ctx := stream.Context()
+	log := rpccontext.Logger(ctx)
+
+	if err := checkRequestedSPIFFEID(req.SpiffeId, log); err != nil {
+		return err
+	}
+
+	selectors, err := h.c.Attestor.Attest(ctx)
+	if err != nil {
+		log.WithError(err).Error("Workload attestation failed")
+		return err
+	}
+
+	subscriber := h.c.Manager.SubscribeToCacheChanges(selectors)
+	defer subscriber.Finish()
+
+	var identities []cache.Identity
+	var refresh <-chan time.Time
+	for {
+		select {
+		case update := <-subscriber.Updates():
+			identities = update.Identities
+		case <-refresh:
+		case <-ctx.Done():
+			return nil
+		}
+
+		resp, refreshAt, err := h.composeLSVIDResponse(ctx, log, identities, req.SpiffeId, req.IncludeBundle)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(resp); err != nil {
+			log.WithError(err).Error("Failed to send LSVID response")
+			return err
+		}
+		refresh = time.After(refreshInterval(refreshAt))
+	}
+}
+
+// FetchLSVIDBundles streams the JWT authorities of the trust domain of the
+// agent and of the trust domains it federates with, which anchor root LSVID
+// layers, along with the LSVID trust bundle documents of those trust domains
+// signed by the server. A new response is sent when the bundles change, e.g.
+// when the server rotates or prunes its JWT keys, and when a document reaches
+// half of its lifetime.
+func (h *Handler) FetchLSVIDBundles(_ *lsvidv1.LSVIDBundlesRequest, stream lsvidv1.LSVIDWorkloadAPI_FetchLSVIDBundlesServer) error {
+	ctx := stream.Context()
+	log := rpccontext.Logger(ctx)
+
+	selectors, err := h.c.Attestor.Attest(ctx)
+	if err != nil {
+		log.WithError(err).Error("Workload attestation failed")
+		return err
+	}
+
+	subscriber := h.c.Manager.SubscribeToCacheChanges(selectors)
+	defer subscriber.Finish()
+
+	var update *cache.WorkloadUpdate
+	var refresh <-chan time.Time
+	for {
+		select {
+		case update = <-subscriber.Updates():
+		case <-refresh:
+		case <-ctx.Done():
+			return nil
+		}
+
+		refreshAt, err := h.sendLSVIDBundlesResponse(ctx, update, stream, log)
+		if err != nil {
+			return err
+		}
+		refresh = time.After(refreshInterval(refreshAt))
+	}
+}
+
+// ValidateLSVID validates the LSVID in the request. Every layer must be
+// properly signed by its issuer, linked to the layer it extends and not
+// expired, and the root layer must be signed by a JWT authority of the issuer
+// trust domain, as found in the bundles cached by the agent. The outermost
+// layer must be addressed to the requested audience, critical claims must be
+// known to the agent and the chain of layers must satisfy the chain policy of
+// the agent, if any. Custom claims of every layer are returned along with the
+// standard claims of the outermost layer. When the agent keeps a replay cache
+// for the requested audience, each token is only accepted once. Failures
+// carry their reason as ErrorInfo details.
+func (h *Handler) ValidateLSVID(ctx context.Context, req *lsvidv1.ValidateLSVIDRequest) (*lsvidv1.ValidateLSVIDResponse, error) {
+	log := rpccontext.Logger(ctx)
+	if req.Audience == "" {
+		log.Error("Missing required audience parameter")
+		return nil, status.Error(codes.InvalidArgument, "audience must be specified")
+	}
+	if req.Lsvid == "" {
+		log.Error("Missing required lsvid parameter")
+		return nil, status.Error(codes.InvalidArgument, "lsvid must be specified")
+	}
+
+	log = log.WithField(telemetry.Audience, req.Audience)
+
+	selectors, err := h.c.Attestor.Attest(ctx)
+	if err != nil {
+		log.WithError(err).Error("Workload attestation failed")
+		return nil, err
+	}
+
+	var opts []core.ValidateOption
+	if replayCache, ok := h.c.ReplayCaches[req.Audience]; ok {
+		opts = append(opts, core.WithReplayCache(replayCache))
+	}
+
+	token, err := decodeLSVIDToken(req.Lsvid)
+	if err == nil && req.ProofOfPossession != nil {
+		pop := req.ProofOfPossession
+		var proof *core.Proof
+		proof, err = core.DecodeProof(pop.Proof)
+		opts = append(opts, core.WithProof(proof, core.ProofRequest{
+			Nonce:  pop.Nonce,
+			Method: pop.Method,
+			URI:    pop.Uri,
+		}))
+	}
+	if err == nil {
+		err = h.validateLSVID(token, selectors, h.c.Manager.MatchingIdentities(selectors), req.Audience, opts...)
+	}
+	if err != nil {
+		log.WithError(err).Warn("Failed to validate LSVID")
+		return nil, validationStatus(err)
+	}
+
+	subject := token.Subject()
+	spiffeID, err := spiffeid.FromString(subject.CN)
+	if err != nil {
+		log.WithError(err).Warn("Failed to validate LSVID")
+		return nil, status.Errorf(codes.InvalidArgument, "invalid subject SPIFFE ID: %v", err)
+	}
+	log.WithField(telemetry.SPIFFEID, subject.CN).Debug("Successfully validated LSVID")
+
+	claims := token.Claims()
+	claims["sub"] = subject.CN
+	claims["aud"] = token.Payload.Aud.CN
+	claims["exp"] = token.Payload.Exp
+	claims["iss"] = token.Payload.Iss.CN
+	claims["iat"] = token.Payload.Iat
+
+	if spiffeID.TrustDomain() != h.c.TrustDomain {
+		for claim := range claims {
+			if !isClaimAllowed(claim, h.c.AllowedForeignJWTClaims) {
+				delete(claims, claim)
+			}
+		}
+	}
+
+	s, err := structpb.NewStruct(claims)
+	if err != nil {
+		log.WithError(err).Error("Error deserializing claims from LSVID")
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &lsvidv1.ValidateLSVIDResponse{
+		SpiffeId: subject.CN,
+		Claims:   s,
+	}, nil
+}
+
+// ExtendLSVID validates the LSVID in the request as ValidateLSVID does, and
+// extends it to the requested audience on behalf of the caller. The outermost
+// layer of the LSVID must be addressed to one of the identities of the caller.
+// The new layer is signed with the key of the X509-SVID of that identity and
+// embeds its LSVID in the issuer claim, so validators can resolve the key.
+// Extending an LSVID presents it to the caller, so when the agent keeps a
+// replay cache for that identity, each token is only extended once.
+func (h *Handler) ExtendLSVID(ctx context.Context, req *lsvidv1.ExtendLSVIDRequest) (*lsvidv1.ExtendLSVIDResponse, error) {
+	log := rpccontext.Logger(ctx)
+	if req.Audience == "" {
+		log.Error("Missing required audience parameter")
+		return nil, status.Error(codes.InvalidArgument, "audience must be specified")
+	}
+	if req.Lsvid == "" {
+		log.Error("Missing required lsvid parameter")
+		return nil, status.Error(codes.InvalidArgument, "lsvid must be specified")
+	}
+
+	log = log.WithField(telemetry.Audience, req.Audience)
+
+	selectors, err := h.c.Attestor.Attest(ctx)
+	if err != nil {
+		log.WithError(err).Error("Workload attestation failed")
+		return nil, err
+	}
+	identities := h.c.Manager.MatchingIdentities(selectors)
+
+	token, err := decodeLSVIDToken(req.Lsvid)
+	if err == nil {
+		// Tokens that are not addressed to the caller are rejected below,
+		// and must not be recorded on its behalf.
+		var opts []core.ValidateOption
+		if _, ok := identityForAudience(identities, token.Payload.Aud); ok {
+			if replayCache, ok := h.c.ReplayCaches[token.Payload.Aud.CN]; ok {
+				opts = append(opts, core.WithReplayCache(replayCache))
+			}
+		}
+		err = h.validateLSVID(token, selectors, identities, "", opts...)
+	}
+	if err != nil {
+		log.WithError(err).Warn("Failed to validate LSVID")
+		return nil, validationStatus(err)
+	}
+
+	identity, ok := identityForAudience(identities, token.Payload.Aud)
+	if !ok {
+		log.WithField(telemetry.Registered, false).Error("LSVID not addressed to the caller")
+		return nil, status.Error(codes.PermissionDenied, "LSVID is not addressed to an identity of the caller")
+	}
+	log = log.WithField(telemetry.SPIFFEID, identity.Entry.SpiffeId)
+
+	if req.Alg != "" {
+		if err := core.CheckAlgorithm(req.Alg, identity.PrivateKey.Public()); err != nil {
+			log.WithError(err).Error("Invalid signature algorithm")
+			return nil, status.Errorf(codes.InvalidArgument, "invalid alg: %v", err)
+		}
+	}
+
+	spiffeID, err := spiffeid.FromString(identity.Entry.SpiffeId)
+	if err != nil {
+		log.WithError(err).Error("Invalid SPIFFE ID")
+		return nil, status.Errorf(codes.Unavailable, "could not parse SPIFFE ID: %v", err)
+	}
+
+	agentID, agentLSVID, agentKey, err := h.agentLSVID(ctx, log)
+	if err != nil {
+		return nil, err
+	}
+	workloadLSVID, err := h.fetchLSVID(ctx, spiffeID, identity.SVID, agentID.String())
+	if err != nil {
+		log.WithError(err).Error("Could not fetch LSVID")
+		return nil, err
+	}
+	callerLSVID, err := h.extendToWorkload(workloadLSVID, agentID, agentLSVID, agentKey, spiffeID)
+	if err != nil {
+		log.WithError(err).Error("Could not extend LSVID")
+		return nil, status.Errorf(codes.Unavailable, "could not extend LSVID: %v", err)
+	}
+
+	extended, err := core.Extend(token, &core.Payload{
+		Ver: core.Version,
+		Alg: req.Alg,
+		Iat: time.Now().Unix(),
+		Iss: &core.IDClaim{
+			CN: spiffeID.String(),
+			ID: callerLSVID,
+		},
+		Aud: &core.IDClaim{
+			CN: req.Audience,
+		},
+	}, identity.PrivateKey)
+	if err != nil {
+		log.WithError(err).Error("Could not extend LSVID")
+		return nil, status.Errorf(codes.Unavailable, "could not extend LSVID: %v", err)
+	}
+
+	encoded, err := core.Encode(extended)
+	if err != nil {
+		log.WithError(err).Error("Could not encode LSVID")
+		return nil, status.Errorf(codes.Unavailable, "could not encode LSVID: %v", err)
+	}
+	log.WithField(telemetry.TTL, time.Until(extended.ExpiresAt()).Seconds()).Debug("Extended LSVID")
+
+	return &lsvidv1.ExtendLSVIDResponse{
+		SpiffeId:  spiffeID.String(),
+		Lsvid:     encoded,
+		ExpiresAt: extended.Payload.Exp,
+	}, nil
+}
+
+// composeLSVIDResponse returns the LSVIDs of the given identities, or of the
+// identity with the requested SPIFFE ID, along with the time the earliest of
+// them reaches half of its lifetime.
+func (h *Handler) composeLSVIDResponse(ctx context.Context, log logrus.FieldLogger, identities []cache.Identity, requestedID string, includeBundle bool) (*lsvidv1.LSVIDResponse, time.Time, error) {
+	var matching []cache.Identity
+	for _, identity := range identities {
+		if requestedID != "" && identity.Entry.SpiffeId != requestedID {
+			continue
+		}
+		matching = append(matching, identity)
+	}
+
+	if len(matching) == 0 {
+		log.WithField(telemetry.Registered, false).Error("No identity issued")
+		return nil, time.Time{}, status.Error(codes.PermissionDenied, "no identity issued")
+	}
+	log = log.WithField(telemetry.Registered, true)
+
+	agentID, agentLSVID, agentKey, err := h.agentLSVID(ctx, log)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	refreshAt := halfLife(agentLSVID)
+
+	var bundle *core.Token
+	if includeBundle {
+		bundle, err = h.fetchLSVIDBundle(ctx, h.c.TrustDomain)
+		if err != nil {
+			log.WithError(err).Error("Could not fetch LSVID trust bundle document")
+			return nil, time.Time{}, err
+		}
+		if t := halfLife(bundle); t.Before(refreshAt) {
+			refreshAt = t
+		}
+	}
+
+	resp := new(lsvidv1.LSVIDResponse)
+	for _, identity := range matching {
+		loopLog := log.WithField(telemetry.SPIFFEID, identity.Entry.SpiffeId)
+
+		spiffeID, err := spiffeid.FromString(identity.Entry.SpiffeId)
+		if err != nil {
+			loopLog.WithError(err).Error("Invalid SPIFFE ID")
+			return nil, time.Time{}, status.Errorf(codes.Unavailable, "could not parse SPIFFE ID: %v", err)
+		}
+
+		// The workload LSVID is issued to the agent, which extends it to
+		// the workload.
+		workloadLSVID, err := h.fetchLSVID(ctx, spiffeID, identity.SVID, agentID.String())
+		if err != nil {
+			loopLog.WithError(err).Error("Could not fetch LSVID")
+			return nil, time.Time{}, err
+		}
+		if t := halfLife(workloadLSVID); t.Before(refreshAt) {
+			refreshAt = t
+		}
+
+		extended, err := h.extendToWorkload(workloadLSVID, agentID, agentLSVID, agentKey, spiffeID)
+		if err != nil {
+			loopLog.WithError(err).Error("Could not extend LSVID")
+			return nil, time.Time{}, status.Errorf(codes.Unavailable, "could not extend LSVID: %v", err)
+		}
+
+		encoded, err := core.EncodeLSVID(&core.LSVID{
+			Token:  extended,
+			Bundle: bundle,
+		})
+		if err != nil {
+			loopLog.WithError(err).Error("Could not encode LSVID")
+			return nil, time.Time{}, status.Errorf(codes.Unavailable, "could not encode LSVID: %v", err)
+		}
+
+		resp.Lsvids = append(resp.Lsvids, &lsvidv1.LSVID{
+			SpiffeId:  spiffeID.String(),
+			Lsvid:     encoded,
+			ExpiresAt: extended.Payload.Exp,
+		})
+
+		ttl := time.Until(extended.ExpiresAt())
+		loopLog.WithField(telemetry.TTL, ttl.Seconds()).Debug("Fetched LSVID")
+	}
+
+	return resp, refreshAt, nil
+}
+
+// agentLSVID returns the SPIFFE ID of the agent, its LSVID and the key it
+// extends LSVIDs with. They are taken from the current agent SVID, which is
+// rotated by the manager, so that the LSVID of the agent is always bound to
+// an X509-SVID the server still accepts.
+func (h *Handler) agentLSVID(ctx context.Context, log logrus.FieldLogger) (spiffeid.ID, *core.Token, crypto.Signer, error) {
+	state := h.c.Manager.GetCurrentCredentials()
+	if len(state.SVID) == 0 || len(state.SVID[0].URIs) == 0 || state.Key == nil {
+		log.Error("Agent SVID not available")
+		return spiffeid.ID{}, nil, nil, status.Error(codes.Unavailable, "agent SVID not available")
+	}
+	agentID, err := spiffeid.FromURI(state.SVID[0].URIs[0])
+	if err != nil {
+		log.WithError(err).Error("Invalid agent SPIFFE ID")
+		return spiffeid.ID{}, nil, nil, status.Errorf(codes.Unavailable, "could not parse agent SPIFFE ID: %v", err)
+	}
+
+	agentLSVID, err := h.fetchLSVID(ctx, agentID, state.SVID, agentID.String())
+	if err != nil {
+		log.WithField(telemetry.SPIFFEID, agentID.String()).WithError(err).Error("Could not fetch agent LSVID")
+		return spiffeid.ID{}, nil, nil, err
+	}
+	return agentID, agentLSVID, state.Key, nil
+}
+
+// extendToWorkload extends an LSVID issued to the agent for a workload to
+// that workload, embedding the LSVID of the agent in the issuer claim.
+func (h *Handler) extendToWorkload(workloadLSVID *core.Token, agentID spiffeid.ID, agentLSVID *core.Token, agentKey crypto.Signer, spiffeID spiffeid.ID) (*core.Token, error) {
+	return core.Extend(workloadLSVID, &core.Payload{
+		Ver: core.Version,
+		Iat: time.Now().Unix(),
+		Iss: &core.IDClaim{
+			CN: agentID.String(),
+			ID: agentLSVID,
+		},
+		Aud: &core.IDClaim{
+			CN: spiffeID.String(),
+		},
+	}, agentKey)
+}
+
+// fetchLSVID returns an LSVID for the leaf certificate of the given chain.
+// LSVIDs are cached by the manager, which requests the server to sign them
+// when missing or expiring, sending the chain along to prove that the subject
+// key is bound to a valid X509-SVID.
+func (h *Handler) fetchLSVID(ctx context.Context, spiffeID spiffeid.ID, chain []*x509.Certificate, audience string) (*core.Token, error) {
+	if len(chain) == 0 {
+		return nil, status.Error(codes.Unavailable, "no certificate to request an LSVID for")
+	}
+
+	svid, err := h.c.Manager.FetchLSVID(ctx, spiffeID, chain, audience)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "could not fetch LSVID: %v", err)
+	}
+
+	token, err := core.Decode(svid.Token)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "could not decode LSVID: %v", err)
+	}
+	return token, nil
+}
+
+// fetchLSVIDBundle returns the LSVID trust bundle document of the given trust
+// domain signed by the server, as cached by the manager.
+func (h *Handler) fetchLSVIDBundle(ctx context.Context, td spiffeid.TrustDomain) (*core.Token, error) {
+	bundle, err := h.c.Manager.FetchLSVIDBundle(ctx, td)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "could not fetch LSVID trust bundle document: %v", err)
+	}
+
+	token, err := core.Decode(bundle.Token)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "could not decode LSVID trust bundle document: %v", err)
+	}
+	return token, nil
+}
+
+// validateLSVID validates the token, anchoring its root layers in the bundles
+// of the trust domains the caller federates with and enforcing the federation
+// policy, if any, on the issuers of foreign trust domains. The chain policy,
+// if any, is enforced too, and the outermost layer must be addressed to the
+// given audience, unless empty. Extra options are applied last.
+func (h *Handler) validateLSVID(token *core.Token, selectors []*common.Selector, identities []cache.Identity, audience string, extraOpts ...core.ValidateOption) error {
+	opts := []core.ValidateOption{
+		core.WithAuthorities(authoritiesFromBundles(h.getWorkloadBundles(selectors))),
+		core.WithX509SVIDs(x509SVIDsFromIdentities(identities)),
+		core.WithKnownClaims(h.c.KnownLSVIDClaims...),
+		core.WithRevocations(h.c.Manager.LSVIDRevocations()),
+	}
+	opts = append(opts, extraOpts...)
+	if h.c.FederationPolicy != nil {
+		opts = append(opts, core.WithFederationPolicy(h.c.TrustDomain, h.c.FederationPolicy))
+	}
+
+	if h.c.ChainPolicy != nil || audience != "" {
+		policy := core.ChainPolicy{AllowCycles: true}
+		if h.c.ChainPolicy != nil {
+			policy = *h.c.ChainPolicy
+		}
+		policy.Audience = audience
+		opts = append(opts, core.WithChainPolicy(policy))
+	}
+	return core.Validate(token, opts...)
+}
+
+// validationStatus returns the InvalidArgument status of a validation failure,
+// carrying its reason, and the depth and issuer of the failing layer if any,
+// as ErrorInfo details.
+func validationStatus(err error) error {
+	info := &errdetails.ErrorInfo{
+		Reason: string(core.ReasonOf(err)),
+		Domain: validationErrorDomain,
+	}
+	var verr *core.ValidationError
+	if errors.As(err, &verr) && verr.Depth != 0 {
+		info.Metadata = map[string]string{
+			"depth":  strconv.Itoa(verr.Depth),
+			"issuer": verr.Issuer,
+		}
+	}
+
+	st := status.New(codes.InvalidArgument, err.Error())
+	if withDetails, detailsErr := st.WithDetails(info); detailsErr == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+func (h *Handler) getWorkloadBundles(selectors []*common.Selector) (bundles []*bundleutil.Bundle) {
+	update := h.c.Manager.FetchWorkloadUpdate(selectors)
+
+	if update.Bundle != nil {
+		bundles = append(bundles, update.Bundle)
+	}
+	for _, federatedBundle := range update.FederatedBundles {
+		bundles = append(bundles, federatedBundle)
+	}
+	return bundles
+}
+
+// sendLSVIDBundlesResponse sends the bundles of the given update along with
+// their LSVID trust bundle documents, and returns the time the earliest of
+// the documents reaches half of its lifetime. The JWT authorities are sent
+// even if a document cannot be fetched, in which case it is fetched again on
+// the next refresh.
+func (h *Handler) sendLSVIDBundlesResponse(ctx context.Context, update *cache.WorkloadUpdate, stream lsvidv1.LSVIDWorkloadAPI_FetchLSVIDBundlesServer, log logrus.FieldLogger) (time.Time, error) {
+	if !h.c.AllowUnauthenticatedVerifiers && !update.HasIdentity() {
+		log.WithField(telemetry.Registered, false).Error("No identity issued")
+		return time.Time{}, status.Error(codes.PermissionDenied, "no identity issued")
+	}
+
+	resp, err := composeLSVIDBundlesResponse(update)
+	if err != nil {
+		log.WithError(err).Error("Could not serialize LSVID bundle response")
+		return time.Time{}, status.Errorf(codes.Unavailable, "could not serialize response: %v", err)
+	}
+
+	trustDomains := []spiffeid.TrustDomain{h.c.TrustDomain}
+	if update.HasIdentity() {
+		for td := range update.FederatedBundles {
+			trustDomains = append(trustDomains, td)
+		}
+	}
+
+	var refreshAt time.Time
+	resp.LsvidBundles = make(map[string]string)
+	for _, td := range trustDomains {
+		bundle, err := h.c.Manager.FetchLSVIDBundle(ctx, td)
+		if err != nil {
+			log.WithError(err).WithField(telemetry.TrustDomainID, td.IDString()).Warn("Could not fetch LSVID trust bundle document")
+			refreshAt = time.Now()
+			continue
+		}
+		resp.LsvidBundles[td.IDString()] = bundle.Token
+		if t := bundle.IssuedAt.Add(bundle.ExpiresAt.Sub(bundle.IssuedAt) / 2); refreshAt.IsZero() || t.Before(refreshAt) {
+			refreshAt = t
+		}
+	}
+
+	if err := stream.Send(resp); err != nil {
+		log.WithError(err).Error("Failed to send LSVID bundle response")
+		return time.Time{}, err
+	}
+
+	return refreshAt, nil
+}
+
+func composeLSVIDBundlesResponse(update *cache.WorkloadUpdate) (*lsvidv1.LSVIDBundlesResponse, error) {
+	if update.Bundle == nil {
+		// This should be purely defensive since the cache should always supply
+		// a bundle.
+		return nil, errors.New("bundle not available")
+	}
+
+	bundles := make(map[string][]byte)
+	jwksBytes, err := bundleutil.Marshal(update.Bundle, bundleutil.NoX509SVIDKeys(), bundleutil.StandardJWKS())
+	if err != nil {
+		return nil, err
+	}
+	bundles[update.Bundle.TrustDomainID()] = jwksBytes
+
+	if update.HasIdentity() {
+		for _, federatedBundle := range update.FederatedBundles {
+			jwksBytes, err := bundleutil.Marshal(federatedBundle, bundleutil.NoX509SVIDKeys(), bundleutil.StandardJWKS())
+			if err != nil {
+				return nil, err
+			}
+			bundles[federatedBundle.TrustDomainID()] = jwksBytes
+		}
+	}
+
+	return &lsvidv1.LSVIDBundlesResponse{
+		Bundles: bundles,
+	}, nil
+}
+
+func checkRequestedSPIFFEID(requestedID string, log logrus.FieldLogger) error {
+	if requestedID == "" {
+		return nil
+	}
+	if _, err := spiffeid.FromString(requestedID); err != nil {
+		log.WithField(telemetry.SPIFFEID, requestedID).WithError(err).Error("Invalid requested SPIFFE ID")
+		return status.Errorf(codes.InvalidArgument, "invalid requested SPIFFE ID: %v", err)
+	}
+	return nil
+}
+
+// identityForAudience returns the identity the given audience claim refers to.
+func identityForAudience(identities []cache.Identity, aud *core.IDClaim) (cache.Identity, bool) {
+	if aud == nil {
+		return cache.Identity{}, false
+	}
+	for _, identity := range identities {
+		if identity.Entry.SpiffeId == aud.CN && len(identity.SVID) > 0 && identity.PrivateKey != nil {
+			return identity, true
+		}
+	}
+	return cache.Identity{}, false
+}
+
+// decodeLSVIDToken decodes either an LSVID document, as returned by
+// FetchLSVID, or a bare token, as produced by a workload extending it. The
+// bundle of an LSVID document is not trusted and is ignored.
+func decodeLSVIDToken(encoded string) (*core.Token, error) {
+	if doc, err := core.DecodeLSVID(encoded); err == nil {
+		return doc.Token, nil
+	}
+	return core.Decode(encoded)
+}
+
+func authoritiesFromBundles(bundles []*bundleutil.Bundle) core.AuthorityLookup {
+	trustDomainKeys := make(map[string]map[string]crypto.PublicKey)
+	for _, bundle := range bundles {
+		trustDomainKeys[bundle.TrustDomainID()] = bundle.JWTSigningKeys()
+	}
+	return func(trustDomainID string) (map[string]crypto.PublicKey, bool) {
+		keys, ok := trustDomainKeys[trustDomainID]
+		return keys, ok
+	}
+}
+
+// x509SVIDsFromIdentities returns the X509-SVIDs of the caller identities as
+// an X509SVIDLookup. The X5T binding is thus only checked for LSVIDs whose
+// subject is one of the caller identities; the agent has no view of the
+// current X509-SVIDs of other workloads, whose LSVIDs are only bounded by
+// their expiration.
+func x509SVIDsFromIdentities(identities []cache.Identity) core.X509SVIDLookup {
+	svids := make(map[string][]*x509.Certificate)
+	for _, identity := range identities {
+		if len(identity.SVID) > 0 {
+			svids[identity.Entry.SpiffeId] = append(svids[identity.Entry.SpiffeId], identity.SVID[0])
+		}
+	}
+	return func(spiffeID string) ([]*x509.Certificate, bool) {
+		certs, ok := svids[spiffeID]
+		return certs, ok
+	}
+}
+
+// halfLife returns the time the token reaches half of its lifetime, measured
+// from the issue time of its root layer.
+func halfLife(token *core.Token) time.Time {
+	issuedAt := time.Unix(token.Root().Payload.Iat, 0)
+	expiresAt := token.ExpiresAt()
+	return issuedAt.Add(expiresAt.Sub(issuedAt) / 2)
+}
+
+// refreshInterval returns how long to wait before sending LSVIDs again.
+func refreshInterval(refreshAt time.Time) time.Duration {
+	interval := time.Until(refreshAt)
+	if interval < minRefreshInterval {
+		return minRefreshInterval
+	}
+	return interval
+}
+
+func isClaimAllowed(claim string, allowedClaims map[string]struct{}) bool {
+	switch claim {
+	case "sub", "exp", "aud":
+		return true
+	default:
+		_, ok := allowedClaims[claim]
+		return ok
+	}
+}
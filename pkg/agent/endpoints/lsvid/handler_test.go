@@ -0,0 +1,1951 @@
+package lsvid_test
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/go-spiffe/v2/bundle/spiffebundle"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"github.com/spiffe/spire/pkg/agent/api/rpccontext"
+	"github.com/spiffe/spire/pkg/agent/client"
+	endpointslsvid "github.com/spiffe/spire/pkg/agent/endpoints/lsvid"
+	"github.com/spiffe/spire/pkg/agent/manager/cache"
+	"github.com/spiffe/spire/pkg/agent/svid"
+	"github.com/spiffe/spire/pkg/common/api/middleware"
+	"github.com/spiffe/spire/pkg/common/bundleutil"
+	"github.com/spiffe/spire/pkg/common/lsvid"
+	lsvidv1 "github.com/spiffe/spire/proto/spire/api/workload/lsvid/v1"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/clock"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+var (
+	td  = spiffeid.RequireTrustDomainFromString("domain.test")
+	td2 = spiffeid.RequireTrustDomainFromString("domain2.test")
+)
+
+func TestFetchLSVID(t *testing.T) {
+	ca := testca.New(t, td)
+
+	agentSVID := ca.CreateX509SVID(td.NewID("/spire/agent/test"))
+	x509SVID1 := ca.CreateX509SVID(td.NewID("/one"))
+	x509SVID2 := ca.CreateX509SVID(td.NewID("/two"))
+
+	for _, tt := range []struct {
+		name           string
+		identities     []cache.Identity
+		spiffeID       string
+		includeBundle  bool
+		noAgentSVID    bool
+		attestErr      error
+		managerErr     error
+		lsvidBundleErr error
+		expectCode     codes.Code
+		expectMsg      string
+		expectIDs      []spiffeid.ID
+		expectLogs     []spiretest.LogEntry
+	}{
+		{
+			name:       "spiffe_id set, but not a valid SPIFFE ID",
+			spiffeID:   "foo",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "invalid requested SPIFFE ID: spiffeid: invalid scheme",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid requested SPIFFE ID",
+					Data: logrus.Fields{
+						"service":       "LSVIDWorkloadAPI",
+						"method":        "FetchLSVID",
+						"spiffe_id":     "foo",
+						logrus.ErrorKey: "spiffeid: invalid scheme",
+					},
+				},
+			},
+		},
+		{
+			name:       "attest error",
+			attestErr:  errors.New("ohno"),
+			expectCode: codes.Unknown,
+			expectMsg:  "ohno",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Workload attestation failed",
+					Data: logrus.Fields{
+						"service":       "LSVIDWorkloadAPI",
+						"method":        "FetchLSVID",
+						logrus.ErrorKey: "ohno",
+					},
+				},
+			},
+		},
+		{
+			name:       "no identity issued",
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "no identity issued",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "No identity issued",
+					Data: logrus.Fields{
+						"registered": "false",
+						"service":    "LSVIDWorkloadAPI",
+						"method":     "FetchLSVID",
+					},
+				},
+			},
+		},
+		{
+			name: "identity issued by unexpected SPIFFE ID",
+			identities: []cache.Identity{
+				identityFromX509SVID(x509SVID1),
+			},
+			spiffeID:   x509SVID2.ID.String(),
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "no identity issued",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "No identity issued",
+					Data: logrus.Fields{
+						"registered": "false",
+						"service":    "LSVIDWorkloadAPI",
+						"method":     "FetchLSVID",
+					},
+				},
+			},
+		},
+		{
+			name: "agent SVID not available",
+			identities: []cache.Identity{
+				identityFromX509SVID(x509SVID1),
+			},
+			noAgentSVID: true,
+			expectCode:  codes.Unavailable,
+			expectMsg:   "agent SVID not available",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Agent SVID not available",
+					Data: logrus.Fields{
+						"registered": "true",
+						"service":    "LSVIDWorkloadAPI",
+						"method":     "FetchLSVID",
+					},
+				},
+			},
+		},
+		{
+			name: "fail to fetch LSVID",
+			identities: []cache.Identity{
+				identityFromX509SVID(x509SVID1),
+			},
+			managerErr: errors.New("ohno"),
+			expectCode: codes.Unavailable,
+			expectMsg:  "could not fetch LSVID: ohno",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Could not fetch agent LSVID",
+					Data: logrus.Fields{
+						"registered":    "true",
+						"service":       "LSVIDWorkloadAPI",
+						"method":        "FetchLSVID",
+						"spiffe_id":     agentSVID.ID.String(),
+						logrus.ErrorKey: "rpc error: code = Unavailable desc = could not fetch LSVID: ohno",
+					},
+				},
+			},
+		},
+		{
+			name: "success all",
+			identities: []cache.Identity{
+				identityFromX509SVID(x509SVID1),
+				identityFromX509SVID(x509SVID2),
+			},
+			expectCode: codes.OK,
+			expectIDs:  []spiffeid.ID{x509SVID1.ID, x509SVID2.ID},
+		},
+		{
+			name: "success specific",
+			identities: []cache.Identity{
+				identityFromX509SVID(x509SVID1),
+				identityFromX509SVID(x509SVID2),
+			},
+			spiffeID:   x509SVID2.ID.String(),
+			expectCode: codes.OK,
+			expectIDs:  []spiffeid.ID{x509SVID2.ID},
+		},
+		{
+			name: "fail to fetch trust bundle document",
+			identities: []cache.Identity{
+				identityFromX509SVID(x509SVID1),
+			},
+			includeBundle:  true,
+			lsvidBundleErr: errors.New("ohno"),
+			expectCode:     codes.Unavailable,
+			expectMsg:      "could not fetch LSVID trust bundle document: ohno",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Could not fetch LSVID trust bundle document",
+					Data: logrus.Fields{
+						"registered":    "true",
+						"service":       "LSVIDWorkloadAPI",
+						"method":        "FetchLSVID",
+						logrus.ErrorKey: "rpc error: code = Unavailable desc = could not fetch LSVID trust bundle document: ohno",
+					},
+				},
+			},
+		},
+		{
+			name: "success with trust bundle document",
+			identities: []cache.Identity{
+				identityFromX509SVID(x509SVID1),
+				identityFromX509SVID(x509SVID2),
+			},
+			includeBundle: true,
+			expectCode:    codes.OK,
+			expectIDs:     []spiffeid.ID{x509SVID1.ID, x509SVID2.ID},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			params := testParams{
+				CA:             ca,
+				Identities:     tt.identities,
+				AttestErr:      tt.attestErr,
+				ManagerErr:     tt.managerErr,
+				LSVIDBundleErr: tt.lsvidBundleErr,
+				ExpectLogs:     tt.expectLogs,
+			}
+			if !tt.noAgentSVID {
+				params.AgentSVID = agentSVID
+			}
+			runTest(t, params,
+				func(ctx context.Context, client lsvidv1.LSVIDWorkloadAPIClient) {
+					resp, err := client.FetchLSVID(ctx, &lsvidv1.LSVIDRequest{
+						SpiffeId:      tt.spiffeID,
+						IncludeBundle: tt.includeBundle,
+					})
+					spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+
+					if tt.expectCode != codes.OK {
+						assert.Nil(t, resp)
+						return
+					}
+					assert.Equal(t, tt.expectIDs, requireLSVIDs(t, ca, agentSVID, resp, tt.includeBundle))
+				})
+		})
+	}
+}
+
+func TestFetchLSVIDStream(t *testing.T) {
+	ca := testca.New(t, td)
+
+	agentSVID := ca.CreateX509SVID(td.NewID("/spire/agent/test"))
+	x509SVID1 := ca.CreateX509SVID(td.NewID("/one"))
+	x509SVID2 := ca.CreateX509SVID(td.NewID("/two"))
+
+	for _, tt := range []struct {
+		name       string
+		updates    []*cache.WorkloadUpdate
+		attestErr  error
+		expectCode codes.Code
+		expectMsg  string
+		expectIDs  [][]spiffeid.ID
+		expectLogs []spiretest.LogEntry
+	}{
+		{
+			name:       "attest error",
+			attestErr:  errors.New("ohno"),
+			expectCode: codes.Unknown,
+			expectMsg:  "ohno",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Workload attestation failed",
+					Data: logrus.Fields{
+						"service":       "LSVIDWorkloadAPI",
+						"method":        "FetchLSVIDStream",
+						logrus.ErrorKey: "ohno",
+					},
+				},
+			},
+		},
+		{
+			name:       "no identity issued",
+			updates:    []*cache.WorkloadUpdate{{}},
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "no identity issued",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "No identity issued",
+					Data: logrus.Fields{
+						"registered": "false",
+						"service":    "LSVIDWorkloadAPI",
+						"method":     "FetchLSVIDStream",
+					},
+				},
+			},
+		},
+		{
+			name: "success with identity updates",
+			updates: []*cache.WorkloadUpdate{
+				{
+					Identities: []cache.Identity{
+						identityFromX509SVID(x509SVID1),
+					},
+				},
+				{
+					Identities: []cache.Identity{
+						identityFromX509SVID(x509SVID1),
+						identityFromX509SVID(x509SVID2),
+					},
+				},
+			},
+			expectCode: codes.OK,
+			expectIDs: [][]spiffeid.ID{
+				{x509SVID1.ID},
+				{x509SVID1.ID, x509SVID2.ID},
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			params := testParams{
+				CA:         ca,
+				Updates:    tt.updates,
+				AttestErr:  tt.attestErr,
+				ExpectLogs: tt.expectLogs,
+				AgentSVID:  agentSVID,
+			}
+			runTest(t, params,
+				func(ctx context.Context, client lsvidv1.LSVIDWorkloadAPIClient) {
+					stream, err := client.FetchLSVIDStream(ctx, &lsvidv1.LSVIDRequest{})
+					require.NoError(t, err)
+
+					if tt.expectCode != codes.OK {
+						resp, err := stream.Recv()
+						spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+						assert.Nil(t, resp)
+						return
+					}
+					for _, expectIDs := range tt.expectIDs {
+						resp, err := stream.Recv()
+						require.NoError(t, err)
+						assert.Equal(t, expectIDs, requireLSVIDs(t, ca, agentSVID, resp, false))
+					}
+				})
+		})
+	}
+}
+
+func TestFetchLSVIDBundles(t *testing.T) {
+	ca := testca.New(t, td)
+
+	x509SVID := ca.CreateX509SVID(td.NewID("/workload"))
+
+	indent := func(in []byte) []byte {
+		buf := new(bytes.Buffer)
+		require.NoError(t, json.Indent(buf, in, "", "    "))
+		return buf.Bytes()
+	}
+
+	bundle := ca.Bundle()
+	bundleJWKS, err := bundle.JWTBundle().Marshal()
+	require.NoError(t, err)
+	bundleJWKS = indent(bundleJWKS)
+
+	federatedCA := testca.New(t, td2)
+	federatedBundle := federatedCA.Bundle()
+	federatedBundleJWKS, err := federatedBundle.JWTBundle().Marshal()
+	require.NoError(t, err)
+	federatedBundleJWKS = indent(federatedBundleJWKS)
+
+	for _, tt := range []struct {
+		name                          string
+		updates                       []*cache.WorkloadUpdate
+		attestErr                     error
+		expectCode                    codes.Code
+		expectMsg                     string
+		lsvidBundleErr                error
+		federatedCAs                  map[spiffeid.TrustDomain]*testca.CA
+		expectResp                    *lsvidv1.LSVIDBundlesResponse
+		expectLSVIDBundles            map[string]*testca.CA
+		expectLogs                    []spiretest.LogEntry
+		allowUnauthenticatedVerifiers bool
+	}{
+		{
+			name:       "no identity issued",
+			updates:    []*cache.WorkloadUpdate{{}},
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "no identity issued",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "No identity issued",
+					Data: logrus.Fields{
+						"registered": "false",
+						"service":    "LSVIDWorkloadAPI",
+						"method":     "FetchLSVIDBundles",
+					},
+				},
+			},
+		},
+		{
+			name:       "attest error",
+			attestErr:  errors.New("ohno"),
+			expectCode: codes.Unknown,
+			expectMsg:  "ohno",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Workload attestation failed",
+					Data: logrus.Fields{
+						"service":       "LSVIDWorkloadAPI",
+						"method":        "FetchLSVIDBundles",
+						logrus.ErrorKey: "ohno",
+					},
+				},
+			},
+		},
+		{
+			name: "cache update unexpectedly missing bundle",
+			updates: []*cache.WorkloadUpdate{
+				{
+					Identities: []cache.Identity{
+						identityFromX509SVID(x509SVID),
+					},
+				},
+			},
+			expectCode: codes.Unavailable,
+			expectMsg:  "could not serialize response: bundle not available",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Could not serialize LSVID bundle response",
+					Data: logrus.Fields{
+						"service":       "LSVIDWorkloadAPI",
+						"method":        "FetchLSVIDBundles",
+						logrus.ErrorKey: "bundle not available",
+					},
+				},
+			},
+		},
+		{
+			name: "success",
+			updates: []*cache.WorkloadUpdate{
+				{
+					Identities: []cache.Identity{
+						identityFromX509SVID(x509SVID),
+					},
+					Bundle: utilBundleFromBundle(t, bundle),
+					FederatedBundles: map[spiffeid.TrustDomain]*bundleutil.Bundle{
+						federatedBundle.TrustDomain(): utilBundleFromBundle(t, federatedBundle),
+					},
+				},
+			},
+			federatedCAs: map[spiffeid.TrustDomain]*testca.CA{td2: federatedCA},
+			expectCode:   codes.OK,
+			expectResp: &lsvidv1.LSVIDBundlesResponse{
+				Bundles: map[string][]byte{
+					bundle.TrustDomain().IDString():          bundleJWKS,
+					federatedBundle.TrustDomain().IDString(): federatedBundleJWKS,
+				},
+			},
+			expectLSVIDBundles: map[string]*testca.CA{
+				td.IDString():  ca,
+				td2.IDString(): federatedCA,
+			},
+		},
+		{
+			name: "federated trust bundle document not available",
+			updates: []*cache.WorkloadUpdate{
+				{
+					Identities: []cache.Identity{
+						identityFromX509SVID(x509SVID),
+					},
+					Bundle: utilBundleFromBundle(t, bundle),
+					FederatedBundles: map[spiffeid.TrustDomain]*bundleutil.Bundle{
+						federatedBundle.TrustDomain(): utilBundleFromBundle(t, federatedBundle),
+					},
+				},
+			},
+			expectCode: codes.OK,
+			expectResp: &lsvidv1.LSVIDBundlesResponse{
+				Bundles: map[string][]byte{
+					bundle.TrustDomain().IDString():          bundleJWKS,
+					federatedBundle.TrustDomain().IDString(): federatedBundleJWKS,
+				},
+			},
+			expectLSVIDBundles: map[string]*testca.CA{
+				td.IDString(): ca,
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.WarnLevel,
+					Message: "Could not fetch LSVID trust bundle document",
+					Data: logrus.Fields{
+						"service":         "LSVIDWorkloadAPI",
+						"method":          "FetchLSVIDBundles",
+						"trust_domain_id": td2.IDString(),
+						logrus.ErrorKey:   `no trust bundle document for "spiffe://domain2.test"`,
+					},
+				},
+			},
+		},
+		{
+			name: "trust bundle document not available",
+			updates: []*cache.WorkloadUpdate{
+				{
+					Identities: []cache.Identity{
+						identityFromX509SVID(x509SVID),
+					},
+					Bundle: utilBundleFromBundle(t, bundle),
+				},
+			},
+			lsvidBundleErr: errors.New("ohno"),
+			expectCode:     codes.OK,
+			expectResp: &lsvidv1.LSVIDBundlesResponse{
+				Bundles: map[string][]byte{
+					bundle.TrustDomain().IDString(): bundleJWKS,
+				},
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.WarnLevel,
+					Message: "Could not fetch LSVID trust bundle document",
+					Data: logrus.Fields{
+						"service":         "LSVIDWorkloadAPI",
+						"method":          "FetchLSVIDBundles",
+						"trust_domain_id": td.IDString(),
+						logrus.ErrorKey:   "ohno",
+					},
+				},
+			},
+		},
+		{
+			name:                          "when allowed to fetch without identity",
+			allowUnauthenticatedVerifiers: true,
+			updates: []*cache.WorkloadUpdate{
+				{
+					Identities: []cache.Identity{},
+					Bundle:     utilBundleFromBundle(t, bundle),
+					FederatedBundles: map[spiffeid.TrustDomain]*bundleutil.Bundle{
+						federatedBundle.TrustDomain(): utilBundleFromBundle(t, federatedBundle),
+					},
+				},
+			},
+			expectCode: codes.OK,
+			expectResp: &lsvidv1.LSVIDBundlesResponse{
+				Bundles: map[string][]byte{
+					bundle.TrustDomain().IDString(): bundleJWKS,
+				},
+			},
+			expectLSVIDBundles: map[string]*testca.CA{
+				td.IDString(): ca,
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			params := testParams{
+				CA:                            ca,
+				Updates:                       tt.updates,
+				AttestErr:                     tt.attestErr,
+				LSVIDBundleErr:                tt.lsvidBundleErr,
+				FederatedCAs:                  tt.federatedCAs,
+				ExpectLogs:                    tt.expectLogs,
+				AllowUnauthenticatedVerifiers: tt.allowUnauthenticatedVerifiers,
+			}
+			runTest(t, params,
+				func(ctx context.Context, client lsvidv1.LSVIDWorkloadAPIClient) {
+					stream, err := client.FetchLSVIDBundles(ctx, &lsvidv1.LSVIDBundlesRequest{})
+					require.NoError(t, err)
+
+					resp, err := stream.Recv()
+					spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+
+					if resp != nil {
+						require.Len(t, resp.LsvidBundles, len(tt.expectLSVIDBundles))
+						for trustDomainID, subjectCA := range tt.expectLSVIDBundles {
+							bundleDoc, err := lsvid.Decode(resp.LsvidBundles[trustDomainID])
+							require.NoError(t, err, "trust bundle document is malformed")
+							// Documents are signed by the trust domain of the agent.
+							actualID, authorities, err := lsvid.ValidateBundle(bundleDoc, ca.X509CAKey())
+							require.NoError(t, err, "trust bundle document is invalid")
+							assert.Equal(t, trustDomainID, actualID)
+							assert.Equal(t, subjectCA.JWTAuthorities(), authorities)
+						}
+						resp.LsvidBundles = nil
+					}
+					spiretest.RequireProtoEqual(t, tt.expectResp, resp)
+				})
+		})
+	}
+}
+
+func TestFetchLSVIDBundlesPushesBundleUpdates(t *testing.T) {
+	ca := testca.New(t, td)
+	x509SVID := ca.CreateX509SVID(td.NewID("/workload"))
+	identities := []cache.Identity{identityFromX509SVID(x509SVID)}
+
+	// The second update carries the bundle after the server rotated its JWT
+	// key, which evicts the cached trust bundle document.
+	rotated := ca.Bundle()
+	require.NoError(t, rotated.AddJWTAuthority("rotated", x509SVID.PrivateKey.Public()))
+
+	params := testParams{
+		CA: ca,
+		Updates: []*cache.WorkloadUpdate{
+			{Identities: identities, Bundle: utilBundleFromBundle(t, ca.Bundle())},
+			{Identities: identities, Bundle: utilBundleFromBundle(t, rotated)},
+		},
+	}
+	runTest(t, params,
+		func(ctx context.Context, client lsvidv1.LSVIDWorkloadAPIClient) {
+			stream, err := client.FetchLSVIDBundles(ctx, &lsvidv1.LSVIDBundlesRequest{})
+			require.NoError(t, err)
+
+			for _, expectKeys := range []int{1, 2} {
+				resp, err := stream.Recv()
+				require.NoError(t, err)
+				require.Contains(t, resp.LsvidBundles, td.IDString())
+
+				var jwks struct {
+					Keys []json.RawMessage `json:"keys"`
+				}
+				require.NoError(t, json.Unmarshal(resp.Bundles[td.IDString()], &jwks))
+				require.Len(t, jwks.Keys, expectKeys)
+			}
+		})
+}
+
+func TestValidateLSVID(t *testing.T) {
+	ca := testca.New(t, td)
+	ca2 := testca.New(t, td2)
+	otherCA := testca.New(t, td)
+
+	bundle := ca.Bundle()
+	federatedBundle := ca2.Bundle()
+
+	workloadSVID := ca.CreateX509SVID(td.NewID("/workload"))
+	rotatedSVID := ca.CreateX509SVID(td.NewID("/workload"))
+	federatedSVID := ca2.CreateX509SVID(td2.NewID("/federated-workload"))
+
+	rootLSVID := ca.CreateLSVID(workloadSVID, "AUDIENCE")
+	coseLSVID := ca.CreateCOSELSVID(workloadSVID, "AUDIENCE")
+	jwsLSVID := ca.CreateJWSLSVID(workloadSVID, "AUDIENCE")
+	federatedLSVID := ca2.CreateLSVID(federatedSVID, "AUDIENCE")
+	untrustedLSVID := otherCA.CreateLSVID(workloadSVID, "AUDIENCE")
+	untrustedMsg := fmt.Sprintf("key %q not found for trust domain %q", untrustedLSVID.Payload.Iss.Kid, "spiffe://domain.test")
+
+	// The workload extends an LSVID addressed to itself to the audience.
+	extendedLSVID, err := lsvid.Extend(ca.CreateLSVID(workloadSVID, workloadSVID.ID.String()), &lsvid.Payload{
+		Ver: lsvid.Version,
+		Iat: time.Now().Unix(),
+		Iss: &lsvid.IDClaim{CN: workloadSVID.ID.String()},
+		Aud: &lsvid.IDClaim{CN: "AUDIENCE"},
+	}, workloadSVID.PrivateKey)
+	require.NoError(t, err)
+
+	// The workload attaches custom claims when extending its LSVID.
+	claimsLSVID, err := lsvid.Extend(ca.CreateLSVID(workloadSVID, workloadSVID.ID.String()), &lsvid.Payload{
+		Ver: lsvid.Version,
+		Iat: time.Now().Unix(),
+		Iss: &lsvid.IDClaim{CN: workloadSVID.ID.String()},
+		Aud: &lsvid.IDClaim{CN: "AUDIENCE"},
+		Claims: map[string]interface{}{
+			"https://example.org/order_id": "order-1",
+			"urn:example:scope":            "read",
+		},
+		Crit: []string{"urn:example:scope"},
+	}, workloadSVID.PrivateKey)
+	require.NoError(t, err)
+	unknownCritMsg := `layer issued by "spiffe://domain.test/workload" has unknown critical claim "urn:example:scope"`
+
+	encode := func(token *lsvid.Token) string {
+		encoded, err := lsvid.Encode(token)
+		require.NoError(t, err)
+		return encoded
+	}
+	encodeDocument := func(token *lsvid.Token) string {
+		encoded, err := lsvid.EncodeLSVID(&lsvid.LSVID{Token: token})
+		require.NoError(t, err)
+		return encoded
+	}
+	claims := func(token *lsvid.Token, names ...string) *structpb.Struct {
+		all := map[string]interface{}{
+			"sub": token.Subject().CN,
+			"aud": token.Payload.Aud.CN,
+			"exp": token.Payload.Exp,
+			"iss": token.Payload.Iss.CN,
+			"iat": token.Payload.Iat,
+		}
+		values := token.Claims()
+		for _, name := range names {
+			values[name] = all[name]
+		}
+		s, err := structpb.NewStruct(values)
+		require.NoError(t, err)
+		return s
+	}
+
+	_, malformedErr := lsvid.Decode("BAD")
+	require.Error(t, malformedErr)
+
+	replayedCache := lsvid.NewMemoryReplayCache(clock.NewMock(t))
+	replayedCache.Seen(rootLSVID.ID(), rootLSVID.ExpiresAt())
+	replayedMsg := fmt.Sprintf("token %q was already presented", rootLSVID.ID())
+
+	proofRequest := lsvid.ProofRequest{Nonce: "NONCE", Method: "POST", URI: "https://service.domain.test/orders"}
+	proof, err := lsvid.CreateProof(rootLSVID, proofRequest, workloadSVID.PrivateKey)
+	require.NoError(t, err)
+	encodedProof, err := lsvid.EncodeProof(proof)
+	require.NoError(t, err)
+	_, malformedProofErr := lsvid.DecodeProof("BAD")
+	require.Error(t, malformedProofErr)
+
+	updatesWithBundleOnly := []*cache.WorkloadUpdate{{
+		Bundle: utilBundleFromBundle(t, bundle),
+	}}
+
+	updatesWithFederatedBundle := []*cache.WorkloadUpdate{{
+		Bundle: utilBundleFromBundle(t, bundle),
+		FederatedBundles: map[spiffeid.TrustDomain]*bundleutil.Bundle{
+			federatedBundle.TrustDomain(): utilBundleFromBundle(t, federatedBundle),
+		},
+	}}
+
+	validationFailure := func(msg string) []spiretest.LogEntry {
+		return []spiretest.LogEntry{
+			{
+				Level:   logrus.WarnLevel,
+				Message: "Failed to validate LSVID",
+				Data: logrus.Fields{
+					"audience":      "AUDIENCE",
+					"service":       "LSVIDWorkloadAPI",
+					"method":        "ValidateLSVID",
+					logrus.ErrorKey: msg,
+				},
+			},
+		}
+	}
+
+	for _, tt := range []struct {
+		name                    string
+		lsvid                   string
+		audience                string
+		identities              []cache.Identity
+		updates                 []*cache.WorkloadUpdate
+		attestErr               error
+		expectCode              codes.Code
+		expectMsg               string
+		expectLogs              []spiretest.LogEntry
+		expectResponse          *lsvidv1.ValidateLSVIDResponse
+		allowedForeignJWTClaims map[string]struct{}
+		knownLSVIDClaims        []string
+		federationPolicy        lsvid.FederationPolicy
+		chainPolicy             *lsvid.ChainPolicy
+		revocations             lsvid.RevocationList
+		replayCaches            map[string]lsvid.ReplayCache
+		proofOfPossession       *lsvidv1.ProofOfPossession
+		expectErrorInfo         *errdetails.ErrorInfo
+	}{
+		{
+			name:       "missing required audience",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "audience must be specified",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Missing required audience parameter",
+					Data: logrus.Fields{
+						"service": "LSVIDWorkloadAPI",
+						"method":  "ValidateLSVID",
+					},
+				},
+			},
+		},
+		{
+			name:       "missing required svid",
+			audience:   "AUDIENCE",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "lsvid must be specified",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Missing required lsvid parameter",
+					Data: logrus.Fields{
+						"service": "LSVIDWorkloadAPI",
+						"method":  "ValidateLSVID",
+					},
+				},
+			},
+		},
+		{
+			name:       "malformed svid",
+			lsvid:      "BAD",
+			audience:   "AUDIENCE",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  malformedErr.Error(),
+			expectLogs: validationFailure(malformedErr.Error()),
+		},
+		{
+			name:       "attest error",
+			lsvid:      "BAD",
+			audience:   "AUDIENCE",
+			attestErr:  errors.New("ohno"),
+			expectCode: codes.Unknown,
+			expectMsg:  "ohno",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Workload attestation failed",
+					Data: logrus.Fields{
+						"audience":      "AUDIENCE",
+						"service":       "LSVIDWorkloadAPI",
+						"method":        "ValidateLSVID",
+						logrus.ErrorKey: "ohno",
+					},
+				},
+			},
+		},
+		{
+			name:       "success",
+			audience:   "AUDIENCE",
+			lsvid:      encode(rootLSVID),
+			updates:    updatesWithBundleOnly,
+			expectCode: codes.OK,
+			expectResponse: &lsvidv1.ValidateLSVIDResponse{
+				SpiffeId: "spiffe://domain.test/workload",
+				Claims:   claims(rootLSVID, "sub", "aud", "exp", "iss", "iat"),
+			},
+		},
+		{
+			name:       "success with LSVID document",
+			audience:   "AUDIENCE",
+			lsvid:      encodeDocument(rootLSVID),
+			updates:    updatesWithBundleOnly,
+			expectCode: codes.OK,
+			expectResponse: &lsvidv1.ValidateLSVIDResponse{
+				SpiffeId: "spiffe://domain.test/workload",
+				Claims:   claims(rootLSVID, "sub", "aud", "exp", "iss", "iat"),
+			},
+		},
+		{
+			name:       "success with extended LSVID",
+			audience:   "AUDIENCE",
+			lsvid:      encode(extendedLSVID),
+			identities: []cache.Identity{identityFromX509SVID(workloadSVID)},
+			updates:    updatesWithBundleOnly,
+			expectCode: codes.OK,
+			expectResponse: &lsvidv1.ValidateLSVIDResponse{
+				SpiffeId: "spiffe://domain.test/workload",
+				Claims:   claims(extendedLSVID, "sub", "aud", "exp", "iss", "iat"),
+			},
+		},
+		{
+			name:             "success with custom claims",
+			audience:         "AUDIENCE",
+			lsvid:            encode(claimsLSVID),
+			identities:       []cache.Identity{identityFromX509SVID(workloadSVID)},
+			updates:          updatesWithBundleOnly,
+			knownLSVIDClaims: []string{"urn:example:scope"},
+			expectCode:       codes.OK,
+			expectResponse: &lsvidv1.ValidateLSVIDResponse{
+				SpiffeId: "spiffe://domain.test/workload",
+				Claims:   claims(claimsLSVID, "sub", "aud", "exp", "iss", "iat"),
+			},
+		},
+		{
+			name:       "unknown critical claim",
+			audience:   "AUDIENCE",
+			lsvid:      encode(claimsLSVID),
+			identities: []cache.Identity{identityFromX509SVID(workloadSVID)},
+			updates:    updatesWithBundleOnly,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  unknownCritMsg,
+			expectLogs: validationFailure(unknownCritMsg),
+		},
+		{
+			name:       "success with federated LSVID",
+			audience:   "AUDIENCE",
+			lsvid:      encode(federatedLSVID),
+			updates:    updatesWithFederatedBundle,
+			expectCode: codes.OK,
+			expectResponse: &lsvidv1.ValidateLSVIDResponse{
+				SpiffeId: "spiffe://domain2.test/federated-workload",
+				Claims:   claims(federatedLSVID, "sub", "aud", "exp"),
+			},
+		},
+		{
+			name:                    "success with federated LSVID with allowed foreign claims",
+			audience:                "AUDIENCE",
+			lsvid:                   encode(federatedLSVID),
+			updates:                 updatesWithFederatedBundle,
+			expectCode:              codes.OK,
+			allowedForeignJWTClaims: map[string]struct{}{"iat": {}, "iss": {}},
+			expectResponse: &lsvidv1.ValidateLSVIDResponse{
+				SpiffeId: "spiffe://domain2.test/federated-workload",
+				Claims:   claims(federatedLSVID, "sub", "aud", "exp", "iss", "iat"),
+			},
+		},
+		{
+			name:       "success with federated LSVID allowed by the federation policy",
+			audience:   "AUDIENCE",
+			lsvid:      encode(federatedLSVID),
+			updates:    updatesWithFederatedBundle,
+			expectCode: codes.OK,
+			federationPolicy: lsvid.FederationPolicy{
+				td2: {{ID: "spiffe://domain2.test", MaxDepth: 1}},
+			},
+			expectResponse: &lsvidv1.ValidateLSVIDResponse{
+				SpiffeId: "spiffe://domain2.test/federated-workload",
+				Claims:   claims(federatedLSVID, "sub", "aud", "exp"),
+			},
+		},
+		{
+			name:       "federated LSVID rejected by the federation policy",
+			audience:   "AUDIENCE",
+			lsvid:      encode(federatedLSVID),
+			updates:    updatesWithFederatedBundle,
+			expectCode: codes.InvalidArgument,
+			federationPolicy: lsvid.FederationPolicy{
+				td2: {{ID: "spiffe://domain2.test", MinDepth: 2}},
+			},
+			expectMsg:  `foreign issuer "spiffe://domain2.test" is not allowed at depth 1`,
+			expectLogs: validationFailure(`foreign issuer "spiffe://domain2.test" is not allowed at depth 1`),
+		},
+		{
+			name:             "federated LSVID from a trust domain missing from the federation policy",
+			audience:         "AUDIENCE",
+			lsvid:            encode(federatedLSVID),
+			updates:          updatesWithFederatedBundle,
+			expectCode:       codes.InvalidArgument,
+			federationPolicy: lsvid.FederationPolicy{},
+			expectMsg:        `issuer "spiffe://domain2.test" belongs to trust domain "spiffe://domain2.test", which is not federated`,
+			expectLogs:       validationFailure(`issuer "spiffe://domain2.test" belongs to trust domain "spiffe://domain2.test", which is not federated`),
+		},
+		{
+			name:       "failure with federated LSVID",
+			audience:   "AUDIENCE",
+			lsvid:      encode(federatedLSVID),
+			updates:    updatesWithBundleOnly,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `no authorities found for trust domain "spiffe://domain2.test"`,
+			expectLogs: validationFailure(`no authorities found for trust domain "spiffe://domain2.test"`),
+		},
+		{
+			name:       "root not signed by a bundle authority",
+			audience:   "AUDIENCE",
+			lsvid:      encode(untrustedLSVID),
+			updates:    updatesWithBundleOnly,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  untrustedMsg,
+			expectLogs: validationFailure(untrustedMsg),
+		},
+		{
+			name:       "audience mismatch",
+			audience:   "OTHER",
+			lsvid:      encode(rootLSVID),
+			updates:    updatesWithBundleOnly,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `LSVID audience "AUDIENCE" does not match "OTHER"`,
+			expectErrorInfo: &errdetails.ErrorInfo{
+				Reason:   "AUDIENCE_MISMATCH",
+				Domain:   "lsvid.spire.spiffe.io",
+				Metadata: map[string]string{"depth": "1", "issuer": "spiffe://domain.test"},
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.WarnLevel,
+					Message: "Failed to validate LSVID",
+					Data: logrus.Fields{
+						"audience":      "OTHER",
+						"service":       "LSVIDWorkloadAPI",
+						"method":        "ValidateLSVID",
+						logrus.ErrorKey: `LSVID audience "AUDIENCE" does not match "OTHER"`,
+					},
+				},
+			},
+		},
+		{
+			name:        "success with extended LSVID allowed by the chain policy",
+			audience:    "AUDIENCE",
+			lsvid:       encode(extendedLSVID),
+			identities:  []cache.Identity{identityFromX509SVID(workloadSVID)},
+			updates:     updatesWithBundleOnly,
+			chainPolicy: &lsvid.ChainPolicy{MaxDepth: 2, Hops: []lsvid.ChainHop{{Depth: 2, Issuers: []string{"spiffe://domain.test/*"}}}},
+			expectCode:  codes.OK,
+			expectResponse: &lsvidv1.ValidateLSVIDResponse{
+				SpiffeId: "spiffe://domain.test/workload",
+				Claims:   claims(extendedLSVID, "sub", "aud", "exp", "iss", "iat"),
+			},
+		},
+		{
+			name:        "extended LSVID too deep for the chain policy",
+			audience:    "AUDIENCE",
+			lsvid:       encode(extendedLSVID),
+			identities:  []cache.Identity{identityFromX509SVID(workloadSVID)},
+			updates:     updatesWithBundleOnly,
+			chainPolicy: &lsvid.ChainPolicy{MaxDepth: 1},
+			expectCode:  codes.InvalidArgument,
+			expectMsg:   "LSVID has 2 layers, more than the 1 allowed",
+			expectErrorInfo: &errdetails.ErrorInfo{
+				Reason:   "CHAIN_TOO_DEEP",
+				Domain:   "lsvid.spire.spiffe.io",
+				Metadata: map[string]string{"depth": "2", "issuer": "spiffe://domain.test/workload"},
+			},
+			expectLogs: validationFailure("LSVID has 2 layers, more than the 1 allowed"),
+		},
+		{
+			name:        "extended LSVID issuer not allowed by the chain policy",
+			audience:    "AUDIENCE",
+			lsvid:       encode(extendedLSVID),
+			identities:  []cache.Identity{identityFromX509SVID(workloadSVID)},
+			updates:     updatesWithBundleOnly,
+			chainPolicy: &lsvid.ChainPolicy{Hops: []lsvid.ChainHop{{Depth: 2, Issuers: []string{"spiffe://domain.test/service"}}}},
+			expectCode:  codes.InvalidArgument,
+			expectMsg:   `issuer "spiffe://domain.test/workload" is not allowed at depth 2`,
+			expectErrorInfo: &errdetails.ErrorInfo{
+				Reason:   "ISSUER_NOT_ALLOWED",
+				Domain:   "lsvid.spire.spiffe.io",
+				Metadata: map[string]string{"depth": "2", "issuer": "spiffe://domain.test/workload"},
+			},
+			expectLogs: validationFailure(`issuer "spiffe://domain.test/workload" is not allowed at depth 2`),
+		},
+		{
+			name:       "subject X509-SVID rotated",
+			audience:   "AUDIENCE",
+			lsvid:      encode(rootLSVID),
+			identities: []cache.Identity{identityFromX509SVID(rotatedSVID)},
+			updates:    updatesWithBundleOnly,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `subject "spiffe://domain.test/workload" is no longer bound to a current X509-SVID`,
+			expectErrorInfo: &errdetails.ErrorInfo{
+				Reason: "SUBJECT_ROTATED",
+				Domain: "lsvid.spire.spiffe.io",
+			},
+			expectLogs: validationFailure(`subject "spiffe://domain.test/workload" is no longer bound to a current X509-SVID`),
+		},
+		{
+			name:        "revoked subject",
+			audience:    "AUDIENCE",
+			lsvid:       encode(rootLSVID),
+			updates:     updatesWithBundleOnly,
+			revocations: lsvid.NewRevocationList(lsvid.Revocation{Kind: lsvid.RevokeSPIFFEID, Value: "spiffe://domain.test/workload"}),
+			expectCode:  codes.InvalidArgument,
+			expectMsg:   `SPIFFE ID "spiffe://domain.test/workload" is revoked`,
+			expectErrorInfo: &errdetails.ErrorInfo{
+				Reason:   "REVOKED",
+				Domain:   "lsvid.spire.spiffe.io",
+				Metadata: map[string]string{"depth": "1", "issuer": "spiffe://domain.test"},
+			},
+			expectLogs: validationFailure(`SPIFFE ID "spiffe://domain.test/workload" is revoked`),
+		},
+		{
+			name:       "success with a COSE LSVID",
+			audience:   "AUDIENCE",
+			lsvid:      encode(coseLSVID),
+			updates:    updatesWithBundleOnly,
+			expectCode: codes.OK,
+			expectResponse: &lsvidv1.ValidateLSVIDResponse{
+				SpiffeId: "spiffe://domain.test/workload",
+				Claims:   claims(coseLSVID, "sub", "aud", "exp", "iss", "iat"),
+			},
+		},
+		{
+			name:       "success with a JWS LSVID",
+			audience:   "AUDIENCE",
+			lsvid:      encode(jwsLSVID),
+			updates:    updatesWithBundleOnly,
+			expectCode: codes.OK,
+			expectResponse: &lsvidv1.ValidateLSVIDResponse{
+				SpiffeId: "spiffe://domain.test/workload",
+				Claims:   claims(jwsLSVID, "sub", "aud", "exp", "iss", "iat"),
+			},
+		},
+		{
+			name:         "success with a replay cache for another audience",
+			audience:     "AUDIENCE",
+			lsvid:        encode(rootLSVID),
+			updates:      updatesWithBundleOnly,
+			replayCaches: map[string]lsvid.ReplayCache{"OTHER": replayedCache},
+			expectCode:   codes.OK,
+			expectResponse: &lsvidv1.ValidateLSVIDResponse{
+				SpiffeId: "spiffe://domain.test/workload",
+				Claims:   claims(rootLSVID, "sub", "aud", "exp", "iss", "iat"),
+			},
+		},
+		{
+			name:         "replayed LSVID",
+			audience:     "AUDIENCE",
+			lsvid:        encode(rootLSVID),
+			updates:      updatesWithBundleOnly,
+			replayCaches: map[string]lsvid.ReplayCache{"AUDIENCE": replayedCache},
+			expectCode:   codes.InvalidArgument,
+			expectMsg:    replayedMsg,
+			expectErrorInfo: &errdetails.ErrorInfo{
+				Reason:   "REPLAYED",
+				Domain:   "lsvid.spire.spiffe.io",
+				Metadata: map[string]string{"depth": "1", "issuer": "spiffe://domain.test"},
+			},
+			expectLogs: validationFailure(replayedMsg),
+		},
+		{
+			name:     "success with proof of possession",
+			audience: "AUDIENCE",
+			lsvid:    encode(rootLSVID),
+			updates:  updatesWithBundleOnly,
+			proofOfPossession: &lsvidv1.ProofOfPossession{
+				Proof:  encodedProof,
+				Nonce:  "NONCE",
+				Method: "POST",
+				Uri:    "https://service.domain.test/orders",
+			},
+			expectCode: codes.OK,
+			expectResponse: &lsvidv1.ValidateLSVIDResponse{
+				SpiffeId: "spiffe://domain.test/workload",
+				Claims:   claims(rootLSVID, "sub", "aud", "exp", "iss", "iat"),
+			},
+		},
+		{
+			name:     "proof of possession for another nonce",
+			audience: "AUDIENCE",
+			lsvid:    encode(rootLSVID),
+			updates:  updatesWithBundleOnly,
+			proofOfPossession: &lsvidv1.ProofOfPossession{
+				Proof:  encodedProof,
+				Nonce:  "OTHER",
+				Method: "POST",
+				Uri:    "https://service.domain.test/orders",
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "proof of possession nonce does not match",
+			expectErrorInfo: &errdetails.ErrorInfo{
+				Reason:   "INVALID_PROOF",
+				Domain:   "lsvid.spire.spiffe.io",
+				Metadata: map[string]string{"depth": "1", "issuer": "spiffe://domain.test"},
+			},
+			expectLogs: validationFailure("proof of possession nonce does not match"),
+		},
+		{
+			name:     "malformed proof of possession",
+			audience: "AUDIENCE",
+			lsvid:    encode(rootLSVID),
+			updates:  updatesWithBundleOnly,
+			proofOfPossession: &lsvidv1.ProofOfPossession{
+				Proof: "BAD",
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  malformedProofErr.Error(),
+			expectErrorInfo: &errdetails.ErrorInfo{
+				Reason: "INVALID_PROOF",
+				Domain: "lsvid.spire.spiffe.io",
+			},
+			expectLogs: validationFailure(malformedProofErr.Error()),
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			params := testParams{
+				Identities:              tt.identities,
+				Updates:                 tt.updates,
+				AttestErr:               tt.attestErr,
+				ExpectLogs:              tt.expectLogs,
+				AllowedForeignJWTClaims: tt.allowedForeignJWTClaims,
+				KnownLSVIDClaims:        tt.knownLSVIDClaims,
+				FederationPolicy:        tt.federationPolicy,
+				ChainPolicy:             tt.chainPolicy,
+				Revocations:             tt.revocations,
+				ReplayCaches:            tt.replayCaches,
+			}
+			runTest(t, params,
+				func(ctx context.Context, client lsvidv1.LSVIDWorkloadAPIClient) {
+					resp, err := client.ValidateLSVID(ctx, &lsvidv1.ValidateLSVIDRequest{
+						Lsvid:             tt.lsvid,
+						Audience:          tt.audience,
+						ProofOfPossession: tt.proofOfPossession,
+					})
+					spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+					if tt.expectErrorInfo != nil {
+						details := status.Convert(err).Details()
+						require.Len(t, details, 1)
+						spiretest.AssertProtoEqual(t, tt.expectErrorInfo, details[0].(*errdetails.ErrorInfo))
+					}
+					if tt.expectCode != codes.OK {
+						assert.Nil(t, resp)
+						return
+					}
+					spiretest.AssertProtoEqual(t, tt.expectResponse, resp)
+				})
+		})
+	}
+}
+
+func TestExtendLSVID(t *testing.T) {
+	ca := testca.New(t, td)
+
+	bundle := ca.Bundle()
+	agentSVID := ca.CreateX509SVID(td.NewID("/spire/agent/test"))
+	sourceSVID := ca.CreateX509SVID(td.NewID("/source"))
+	workloadSVID := ca.CreateX509SVID(td.NewID("/workload"))
+
+	// The source workload received an LSVID and extended it to the workload.
+	receivedLSVID := ca.CreateLSVID(sourceSVID, workloadSVID.ID.String())
+	otherLSVID := ca.CreateLSVID(sourceSVID, "OTHER")
+
+	encode := func(token *lsvid.Token) string {
+		encoded, err := lsvid.Encode(token)
+		require.NoError(t, err)
+		return encoded
+	}
+
+	_, malformedErr := lsvid.Decode("BAD")
+	require.Error(t, malformedErr)
+
+	updates := []*cache.WorkloadUpdate{{
+		Bundle: utilBundleFromBundle(t, bundle),
+	}}
+
+	replayedCache := lsvid.NewMemoryReplayCache(clock.NewMock(t))
+	replayedCache.Seen(receivedLSVID.ID(), receivedLSVID.ExpiresAt())
+	replayedMsg := fmt.Sprintf("token %q was already presented", receivedLSVID.ID())
+
+	for _, tt := range []struct {
+		name         string
+		lsvid        string
+		audience     string
+		alg          string
+		identities   []cache.Identity
+		replayCaches map[string]lsvid.ReplayCache
+		noAgentSVID  bool
+		attestErr    error
+		managerErr   error
+		expectCode   codes.Code
+		expectMsg    string
+		expectLogs   []spiretest.LogEntry
+	}{
+		{
+			name:       "missing required audience",
+			lsvid:      encode(receivedLSVID),
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "audience must be specified",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Missing required audience parameter",
+					Data: logrus.Fields{
+						"service": "LSVIDWorkloadAPI",
+						"method":  "ExtendLSVID",
+					},
+				},
+			},
+		},
+		{
+			name:       "missing required lsvid",
+			audience:   "AUDIENCE",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "lsvid must be specified",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Missing required lsvid parameter",
+					Data: logrus.Fields{
+						"service": "LSVIDWorkloadAPI",
+						"method":  "ExtendLSVID",
+					},
+				},
+			},
+		},
+		{
+			name:       "attest error",
+			lsvid:      encode(receivedLSVID),
+			audience:   "AUDIENCE",
+			attestErr:  errors.New("ohno"),
+			expectCode: codes.Unknown,
+			expectMsg:  "ohno",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Workload attestation failed",
+					Data: logrus.Fields{
+						"audience":      "AUDIENCE",
+						"service":       "LSVIDWorkloadAPI",
+						"method":        "ExtendLSVID",
+						logrus.ErrorKey: "ohno",
+					},
+				},
+			},
+		},
+		{
+			name:       "malformed lsvid",
+			lsvid:      "BAD",
+			audience:   "AUDIENCE",
+			identities: []cache.Identity{identityFromX509SVID(workloadSVID)},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  malformedErr.Error(),
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.WarnLevel,
+					Message: "Failed to validate LSVID",
+					Data: logrus.Fields{
+						"audience":      "AUDIENCE",
+						"service":       "LSVIDWorkloadAPI",
+						"method":        "ExtendLSVID",
+						logrus.ErrorKey: malformedErr.Error(),
+					},
+				},
+			},
+		},
+		{
+			name:       "lsvid not addressed to the caller",
+			lsvid:      encode(otherLSVID),
+			audience:   "AUDIENCE",
+			identities: []cache.Identity{identityFromX509SVID(workloadSVID)},
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "LSVID is not addressed to an identity of the caller",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "LSVID not addressed to the caller",
+					Data: logrus.Fields{
+						"audience":   "AUDIENCE",
+						"registered": "false",
+						"service":    "LSVIDWorkloadAPI",
+						"method":     "ExtendLSVID",
+					},
+				},
+			},
+		},
+		{
+			name:         "replayed LSVID",
+			lsvid:        encode(receivedLSVID),
+			audience:     "AUDIENCE",
+			identities:   []cache.Identity{identityFromX509SVID(workloadSVID)},
+			replayCaches: map[string]lsvid.ReplayCache{workloadSVID.ID.String(): replayedCache},
+			expectCode:   codes.InvalidArgument,
+			expectMsg:    replayedMsg,
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.WarnLevel,
+					Message: "Failed to validate LSVID",
+					Data: logrus.Fields{
+						"audience":      "AUDIENCE",
+						"service":       "LSVIDWorkloadAPI",
+						"method":        "ExtendLSVID",
+						logrus.ErrorKey: replayedMsg,
+					},
+				},
+			},
+		},
+		{
+			name:        "agent SVID not available",
+			lsvid:       encode(receivedLSVID),
+			audience:    "AUDIENCE",
+			identities:  []cache.Identity{identityFromX509SVID(workloadSVID)},
+			noAgentSVID: true,
+			expectCode:  codes.Unavailable,
+			expectMsg:   "agent SVID not available",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Agent SVID not available",
+					Data: logrus.Fields{
+						"audience":  "AUDIENCE",
+						"spiffe_id": workloadSVID.ID.String(),
+						"service":   "LSVIDWorkloadAPI",
+						"method":    "ExtendLSVID",
+					},
+				},
+			},
+		},
+		{
+			name:       "fail to fetch LSVID",
+			lsvid:      encode(receivedLSVID),
+			audience:   "AUDIENCE",
+			identities: []cache.Identity{identityFromX509SVID(workloadSVID)},
+			managerErr: errors.New("ohno"),
+			expectCode: codes.Unavailable,
+			expectMsg:  "could not fetch LSVID: ohno",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Could not fetch agent LSVID",
+					Data: logrus.Fields{
+						"audience":      "AUDIENCE",
+						"spiffe_id":     agentSVID.ID.String(),
+						"service":       "LSVIDWorkloadAPI",
+						"method":        "ExtendLSVID",
+						logrus.ErrorKey: "rpc error: code = Unavailable desc = could not fetch LSVID: ohno",
+					},
+				},
+			},
+		},
+		{
+			name:       "alg does not match the key of the caller",
+			lsvid:      encode(receivedLSVID),
+			audience:   "AUDIENCE",
+			alg:        lsvid.AlgRS256,
+			identities: []cache.Identity{identityFromX509SVID(workloadSVID)},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `invalid alg: signature algorithm "RS256" does not match ES256 key`,
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid signature algorithm",
+					Data: logrus.Fields{
+						"audience":      "AUDIENCE",
+						"spiffe_id":     workloadSVID.ID.String(),
+						"service":       "LSVIDWorkloadAPI",
+						"method":        "ExtendLSVID",
+						logrus.ErrorKey: `signature algorithm "RS256" does not match ES256 key`,
+					},
+				},
+			},
+		},
+		{
+			name:       "success",
+			lsvid:      encode(receivedLSVID),
+			audience:   "AUDIENCE",
+			identities: []cache.Identity{identityFromX509SVID(workloadSVID)},
+			expectCode: codes.OK,
+		},
+		{
+			name:         "success with a replay cache for another audience",
+			lsvid:        encode(receivedLSVID),
+			audience:     "AUDIENCE",
+			identities:   []cache.Identity{identityFromX509SVID(workloadSVID)},
+			replayCaches: map[string]lsvid.ReplayCache{"OTHER": replayedCache},
+			expectCode:   codes.OK,
+		},
+		{
+			name:       "success with an explicit alg",
+			lsvid:      encode(receivedLSVID),
+			audience:   "AUDIENCE",
+			alg:        lsvid.AlgES256,
+			identities: []cache.Identity{identityFromX509SVID(workloadSVID)},
+			expectCode: codes.OK,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			params := testParams{
+				CA:           ca,
+				Identities:   tt.identities,
+				Updates:      updates,
+				AttestErr:    tt.attestErr,
+				ManagerErr:   tt.managerErr,
+				ReplayCaches: tt.replayCaches,
+				ExpectLogs:   tt.expectLogs,
+			}
+			if !tt.noAgentSVID {
+				params.AgentSVID = agentSVID
+			}
+			runTest(t, params,
+				func(ctx context.Context, client lsvidv1.LSVIDWorkloadAPIClient) {
+					resp, err := client.ExtendLSVID(ctx, &lsvidv1.ExtendLSVIDRequest{
+						Lsvid:    tt.lsvid,
+						Audience: tt.audience,
+						Alg:      tt.alg,
+					})
+					spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+					if tt.expectCode != codes.OK {
+						assert.Nil(t, resp)
+						return
+					}
+
+					token, err := lsvid.Decode(resp.Lsvid)
+					require.NoError(t, err)
+					err = lsvid.Validate(token, lsvid.WithAuthorities(func(trustDomainID string) (map[string]crypto.PublicKey, bool) {
+						return ca.JWTAuthorities(), trustDomainID == td.IDString()
+					}))
+					require.NoError(t, err, "extended LSVID is invalid")
+
+					assert.Equal(t, workloadSVID.ID.String(), resp.SpiffeId)
+					assert.Equal(t, token.Payload.Exp, resp.ExpiresAt)
+					assert.Equal(t, 2, token.Depth())
+					assert.Equal(t, sourceSVID.ID.String(), token.Subject().CN)
+					assert.Equal(t, workloadSVID.ID.String(), token.Payload.Iss.CN)
+					assert.Equal(t, workloadSVID.ID.String(), token.Payload.Iss.ID.Subject().CN)
+					assert.Equal(t, "AUDIENCE", token.Payload.Aud.CN)
+					if tt.alg != "" {
+						assert.Equal(t, tt.alg, token.Payload.Alg)
+					}
+				})
+		})
+	}
+
+	t.Run("LSVID extended once", func(t *testing.T) {
+		replayCache := lsvid.NewMemoryReplayCache(clock.NewMock(t))
+		params := testParams{
+			CA:         ca,
+			AgentSVID:  agentSVID,
+			Identities: []cache.Identity{identityFromX509SVID(workloadSVID)},
+			Updates:    updates,
+			ReplayCaches: map[string]lsvid.ReplayCache{
+				workloadSVID.ID.String(): replayCache,
+				"OTHER":                  replayCache,
+			},
+			ExpectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "LSVID not addressed to the caller",
+					Data: logrus.Fields{
+						"audience":   "AUDIENCE",
+						"registered": "false",
+						"service":    "LSVIDWorkloadAPI",
+						"method":     "ExtendLSVID",
+					},
+				},
+				{
+					Level:   logrus.WarnLevel,
+					Message: "Failed to validate LSVID",
+					Data: logrus.Fields{
+						"audience":      "AUDIENCE",
+						"service":       "LSVIDWorkloadAPI",
+						"method":        "ExtendLSVID",
+						logrus.ErrorKey: replayedMsg,
+					},
+				},
+			},
+		}
+		runTest(t, params,
+			func(ctx context.Context, client lsvidv1.LSVIDWorkloadAPIClient) {
+				// LSVIDs not addressed to the caller are not recorded.
+				_, err := client.ExtendLSVID(ctx, &lsvidv1.ExtendLSVIDRequest{
+					Lsvid:    encode(otherLSVID),
+					Audience: "AUDIENCE",
+				})
+				spiretest.RequireGRPCStatus(t, err, codes.PermissionDenied, "LSVID is not addressed to an identity of the caller")
+				entries := replayCache.Entries()
+				assert.NotContains(t, entries, otherLSVID.ID())
+
+				req := &lsvidv1.ExtendLSVIDRequest{
+					Lsvid:    encode(receivedLSVID),
+					Audience: "AUDIENCE",
+				}
+				_, err = client.ExtendLSVID(ctx, req)
+				require.NoError(t, err)
+				_, err = client.ExtendLSVID(ctx, req)
+				spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, replayedMsg)
+			})
+	})
+
+	t.Run("agent SVID rotated", func(t *testing.T) {
+		credentials := newFakeCredentials(newExpiredX509SVID(t, agentSVID.ID))
+		params := testParams{
+			CA:               ca,
+			Identities:       []cache.Identity{identityFromX509SVID(workloadSVID)},
+			Updates:          updates,
+			AgentCredentials: credentials,
+			ExpectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Could not fetch agent LSVID",
+					Data: logrus.Fields{
+						"audience":      "AUDIENCE",
+						"spiffe_id":     agentSVID.ID.String(),
+						"service":       "LSVIDWorkloadAPI",
+						"method":        "ExtendLSVID",
+						logrus.ErrorKey: "rpc error: code = Unavailable desc = could not fetch LSVID: X509-SVID has expired",
+					},
+				},
+			},
+		}
+		runTest(t, params,
+			func(ctx context.Context, client lsvidv1.LSVIDWorkloadAPIClient) {
+				req := &lsvidv1.ExtendLSVIDRequest{
+					Lsvid:    encode(receivedLSVID),
+					Audience: "AUDIENCE",
+				}
+				_, err := client.ExtendLSVID(ctx, req)
+				spiretest.RequireGRPCStatus(t, err, codes.Unavailable, "could not fetch LSVID: X509-SVID has expired")
+
+				// The handler picks up the rotated SVID and key of the agent.
+				credentials.set(agentSVID)
+				resp, err := client.ExtendLSVID(ctx, req)
+				require.NoError(t, err)
+
+				token, err := lsvid.Decode(resp.Lsvid)
+				require.NoError(t, err)
+				err = lsvid.Validate(token, lsvid.WithAuthorities(func(trustDomainID string) (map[string]crypto.PublicKey, bool) {
+					return ca.JWTAuthorities(), trustDomainID == td.IDString()
+				}))
+				require.NoError(t, err, "extended LSVID is invalid")
+
+				callerLSVID := token.Payload.Iss.ID
+				assert.Equal(t, agentSVID.ID.String(), callerLSVID.Payload.Iss.CN)
+				agentLSVID := callerLSVID.Payload.Iss.ID
+				assert.Equal(t, lsvid.X509SVIDThumbprint(agentSVID.Certificates[0]), agentLSVID.Subject().X5T)
+				assert.Equal(t, pkixFromPublicKey(t, agentSVID.PrivateKey.Public()), agentLSVID.Subject().PK)
+			})
+	})
+}
+
+type testParams struct {
+	CA                            *testca.CA
+	Identities                    []cache.Identity
+	Updates                       []*cache.WorkloadUpdate
+	AttestErr                     error
+	ManagerErr                    error
+	LSVIDBundleErr                error
+	FederatedCAs                  map[spiffeid.TrustDomain]*testca.CA
+	ExpectLogs                    []spiretest.LogEntry
+	AgentSVID                     *x509svid.SVID
+	AgentCredentials              *fakeCredentials
+	AllowUnauthenticatedVerifiers bool
+	AllowedForeignJWTClaims       map[string]struct{}
+	KnownLSVIDClaims              []string
+	FederationPolicy              lsvid.FederationPolicy
+	ChainPolicy                   *lsvid.ChainPolicy
+	Revocations                   lsvid.RevocationList
+	ReplayCaches                  map[string]lsvid.ReplayCache
+}
+
+func runTest(t *testing.T, params testParams, fn func(ctx context.Context, client lsvidv1.LSVIDWorkloadAPIClient)) {
+	log, logHook := test.NewNullLogger()
+
+	manager := &FakeManager{
+		ca:         params.CA,
+		identities: params.Identities,
+		updates:    params.Updates,
+		err:        params.ManagerErr,
+		bundleErr:  params.LSVIDBundleErr,
+
+		federatedCAs: params.FederatedCAs,
+		revocations:  params.Revocations,
+		credentials:  params.AgentCredentials,
+	}
+	if manager.credentials == nil {
+		manager.credentials = newFakeCredentials(params.AgentSVID)
+	}
+
+	config := endpointslsvid.Config{
+		TrustDomain:                   td,
+		Manager:                       manager,
+		Attestor:                      &FakeAttestor{err: params.AttestErr},
+		AllowUnauthenticatedVerifiers: params.AllowUnauthenticatedVerifiers,
+		AllowedForeignJWTClaims:       params.AllowedForeignJWTClaims,
+		KnownLSVIDClaims:              params.KnownLSVIDClaims,
+		FederationPolicy:              params.FederationPolicy,
+		ChainPolicy:                   params.ChainPolicy,
+		ReplayCaches:                  params.ReplayCaches,
+	}
+	handler := endpointslsvid.New(config)
+
+	unaryInterceptor, streamInterceptor := middleware.Interceptors(middleware.Chain(
+		middleware.WithLogger(log),
+		middleware.Preprocess(func(ctx context.Context, fullMethod string, req interface{}) (context.Context, error) {
+			return rpccontext.WithCallerPID(ctx, 0), nil
+		}),
+	))
+
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(unaryInterceptor),
+		grpc.StreamInterceptor(streamInterceptor),
+	)
+	lsvidv1.RegisterLSVIDWorkloadAPIServer(server, handler)
+	socketPath := spiretest.ServeGRPCServerOnTempSocket(t, server)
+	t.Cleanup(func() { server.Stop() })
+
+	// Provide a cancelable context to ensure the stream is always
+	// closed when the test case is done, and also to ensure that
+	// any unexpected blocking call is timed out.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, "unix://"+socketPath, grpc.WithInsecure())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	fn(ctx, lsvidv1.NewLSVIDWorkloadAPIClient(conn))
+
+	cancel()
+	server.GracefulStop()
+
+	assert.Equal(t, 0, manager.Subscribers(), "there should be no more subscribers")
+
+	spiretest.AssertLogs(t, logHook.AllEntries(), params.ExpectLogs)
+}
+
+// requireLSVIDs validates the LSVIDs in the response, which must be issued by
+// the agent to the workloads, and returns their SPIFFE IDs.
+func requireLSVIDs(t *testing.T, ca *testca.CA, agentSVID *x509svid.SVID, resp *lsvidv1.LSVIDResponse, expectBundle bool) []spiffeid.ID {
+	var ids []spiffeid.ID
+	for _, svid := range resp.Lsvids {
+		doc, err := lsvid.DecodeLSVID(svid.Lsvid)
+		require.NoError(t, err, "LSVID is malformed")
+
+		err = lsvid.Validate(doc.Token, lsvid.WithAuthorities(func(trustDomainID string) (map[string]crypto.PublicKey, bool) {
+			return ca.JWTAuthorities(), trustDomainID == td.IDString()
+		}))
+		require.NoError(t, err, "LSVID is invalid")
+
+		if expectBundle {
+			require.NotNil(t, doc.Bundle, "LSVID document missing trust bundle document")
+			require.NoError(t, lsvid.ValidateWithBundle(doc.Token, doc.Bundle, ca.X509CAKey()), "LSVID is invalid offline")
+		} else {
+			assert.Nil(t, doc.Bundle)
+		}
+
+		assert.Equal(t, agentSVID.ID.String(), doc.Token.Payload.Iss.CN)
+		assert.Equal(t, svid.SpiffeId, doc.Token.Payload.Aud.CN)
+		assert.Equal(t, svid.SpiffeId, doc.Token.Subject().CN)
+		assert.Equal(t, doc.Token.Payload.Exp, svid.ExpiresAt)
+
+		ids = append(ids, spiffeid.RequireFromString(svid.SpiffeId))
+	}
+	return ids
+}
+
+type FakeManager struct {
+	ca          *testca.CA
+	identities  []cache.Identity
+	updates     []*cache.WorkloadUpdate
+	subscribers int32
+	err         error
+	bundleErr   error
+
+	federatedCAs map[spiffeid.TrustDomain]*testca.CA
+	revocations  lsvid.RevocationList
+	credentials  *fakeCredentials
+}
+
+func (m *FakeManager) GetCurrentCredentials() svid.State {
+	return m.credentials.get()
+}
+
+func (m *FakeManager) MatchingIdentities(selectors []*common.Selector) []cache.Identity {
+	return m.identities
+}
+
+func (m *FakeManager) FetchLSVID(ctx context.Context, spiffeID spiffeid.ID, svid []*x509.Certificate, audience string) (*client.JWTSVID, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	// As the server does, refuse to bind LSVIDs to expired X509-SVIDs.
+	if time.Now().After(svid[0].NotAfter) {
+		return nil, errors.New("X509-SVID has expired")
+	}
+	token := m.ca.CreateLSVID(&x509svid.SVID{ID: spiffeID, Certificates: svid}, audience)
+	encoded, err := lsvid.Encode(token)
+	if err != nil {
+		return nil, err
+	}
+	return &client.JWTSVID{
+		Token:     encoded,
+		IssuedAt:  time.Unix(token.Payload.Iat, 0),
+		ExpiresAt: time.Unix(token.Payload.Exp, 0),
+	}, nil
+}
+
+func (m *FakeManager) FetchLSVIDBundle(ctx context.Context, trustDomain spiffeid.TrustDomain) (*client.JWTSVID, error) {
+	if m.bundleErr != nil {
+		return nil, m.bundleErr
+	}
+	subject := m.ca
+	if trustDomain != td {
+		var ok bool
+		subject, ok = m.federatedCAs[trustDomain]
+		if !ok {
+			return nil, fmt.Errorf("no trust bundle document for %q", trustDomain.IDString())
+		}
+	}
+	token := m.ca.CreateLSVIDBundleFor(subject)
+	encoded, err := lsvid.Encode(token)
+	if err != nil {
+		return nil, err
+	}
+	return &client.JWTSVID{
+		Token:     encoded,
+		IssuedAt:  time.Unix(token.Payload.Iat, 0),
+		ExpiresAt: time.Unix(token.Payload.Exp, 0),
+	}, nil
+}
+
+func (m *FakeManager) LSVIDRevocations() lsvid.RevocationList {
+	return m.revocations
+}
+
+func (m *FakeManager) SubscribeToCacheChanges(selectors cache.Selectors) cache.Subscriber {
+	atomic.AddInt32(&m.subscribers, 1)
+	return newFakeSubscriber(m, m.updates)
+}
+
+func (m *FakeManager) FetchWorkloadUpdate(selectors []*common.Selector) *cache.WorkloadUpdate {
+	if len(m.updates) == 0 {
+		return &cache.WorkloadUpdate{}
+	}
+	return m.updates[0]
+}
+
+func (m *FakeManager) Subscribers() int {
+	return int(atomic.LoadInt32(&m.subscribers))
+}
+
+func (m *FakeManager) subscriberDone() {
+	atomic.AddInt32(&m.subscribers, -1)
+}
+
+type fakeSubscriber struct {
+	m      *FakeManager
+	ch     chan *cache.WorkloadUpdate
+	cancel context.CancelFunc
+}
+
+func newFakeSubscriber(m *FakeManager, updates []*cache.WorkloadUpdate) *fakeSubscriber {
+	ch := make(chan *cache.WorkloadUpdate)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		for _, update := range updates {
+			select {
+			case ch <- update:
+			case <-ctx.Done():
+				return
+			}
+		}
+		<-ctx.Done()
+	}()
+	return &fakeSubscriber{
+		m:      m,
+		ch:     ch,
+		cancel: cancel,
+	}
+}
+
+func (s *fakeSubscriber) Updates() <-chan *cache.WorkloadUpdate {
+	return s.ch
+}
+
+func (s *fakeSubscriber) Finish() {
+	s.cancel()
+	s.m.subscriberDone()
+}
+
+type FakeAttestor struct {
+	selectors []*common.Selector
+	err       error
+}
+
+func (a *FakeAttestor) Attest(ctx context.Context) ([]*common.Selector, error) {
+	return a.selectors, a.err
+}
+
+// fakeCredentials holds the agent SVID, which tests can rotate while the
+// handler is serving.
+type fakeCredentials struct {
+	mtx   sync.Mutex
+	state svid.State
+}
+
+func newFakeCredentials(agentSVID *x509svid.SVID) *fakeCredentials {
+	c := new(fakeCredentials)
+	if agentSVID != nil {
+		c.set(agentSVID)
+	}
+	return c
+}
+
+func (c *fakeCredentials) get() svid.State {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.state
+}
+
+func (c *fakeCredentials) set(agentSVID *x509svid.SVID) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.state = svid.State{
+		SVID: agentSVID.Certificates,
+		Key:  agentSVID.PrivateKey,
+	}
+}
+
+// newExpiredX509SVID returns a self-signed X509-SVID that expired an hour
+// ago. Its key is not taken from the pregenerated test keys, which the tests
+// of this package exhaust.
+func newExpiredX509SVID(t *testing.T, id spiffeid.ID) *x509svid.SVID {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    now.Add(-2 * time.Hour),
+		NotAfter:     now.Add(-time.Hour),
+		URIs:         []*url.URL{id.URL()},
+	}
+	return &x509svid.SVID{
+		ID:           id,
+		Certificates: []*x509.Certificate{testca.CreateCertificate(t, tmpl, tmpl, key.Public(), key)},
+		PrivateKey:   key,
+	}
+}
+
+func identityFromX509SVID(svid *x509svid.SVID) cache.Identity {
+	return cache.Identity{
+		Entry:      &common.RegistrationEntry{SpiffeId: svid.ID.String()},
+		PrivateKey: svid.PrivateKey,
+		SVID:       svid.Certificates,
+	}
+}
+
+func utilBundleFromBundle(t *testing.T, bundle *spiffebundle.Bundle) *bundleutil.Bundle {
+	b, err := bundleutil.BundleFromProto(commonBundleFromBundle(t, bundle))
+	require.NoError(t, err)
+	return b
+}
+
+func commonBundleFromBundle(t *testing.T, bundle *spiffebundle.Bundle) *common.Bundle {
+	bundleProto := &common.Bundle{
+		TrustDomainId: bundle.TrustDomain().IDString(),
+	}
+	for _, x509Authority := range bundle.X509Authorities() {
+		bundleProto.RootCas = append(bundleProto.RootCas, &common.Certificate{
+			DerBytes: x509Authority.Raw,
+		})
+	}
+	for keyID, jwtAuthority := range bundle.JWTAuthorities() {
+		bundleProto.JwtSigningKeys = append(bundleProto.JwtSigningKeys, &common.PublicKey{
+			Kid:       keyID,
+			PkixBytes: pkixFromPublicKey(t, jwtAuthority),
+		})
+	}
+	return bundleProto
+}
+
+func pkixFromPublicKey(t *testing.T, publicKey crypto.PublicKey) []byte {
+	keyBytes, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+	return keyBytes
+}
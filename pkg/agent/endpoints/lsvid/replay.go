@@ -0,0 +1,188 @@
+package lsvid
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/andres-erbsen/clock"
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/diskutil"
+	core "github.com/spiffe/spire/pkg/common/lsvid"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+)
+
+// replayCompactMinRecords is the number of records the log of a
+// DiskReplayCache holds before it is first compacted.
+const replayCompactMinRecords = 1024
+
+// DiskReplayCache is a replay cache held in memory and persisted to a file,
+// so that tokens accepted before the agent restarts are still rejected
+// afterwards.
+//
+// The file is an append-only log with a JSON record per line: recording a
+// token ID appends its record, without rewriting the file or syncing it to
+// disk. The log is compacted down to the token IDs that have not expired when
+// the cache is loaded, and once it holds twice as many records as it did
+// after the last compaction.
+type DiskReplayCache struct {
+	log  logrus.FieldLogger
+	path string
+
+	mu           sync.Mutex
+	cache        *core.MemoryReplayCache
+	file         *os.File
+	records      int
+	compactAfter int
+}
+
+type replayRecord struct {
+	ID  string `json:"id"`
+	Exp int64  `json:"exp"`
+}
+
+// NewDiskReplayCache returns a replay cache persisted to the given file,
+// loaded with the token IDs the file holds, if it exists.
+func NewDiskReplayCache(log logrus.FieldLogger, path string, clk clock.Clock) (*DiskReplayCache, error) {
+	c := &DiskReplayCache{
+		log:          log.WithField(telemetry.Path, path),
+		path:         path,
+		cache:        core.NewMemoryReplayCache(clk),
+		compactAfter: replayCompactMinRecords,
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return c, nil
+	case err != nil:
+		return nil, err
+	}
+
+	entries, err := parseReplayLog(data)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.Restore(entries)
+	if err := c.compact(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Seen records the token ID until the given expiration and appends it to the
+// log. It returns true if the token ID was already recorded and has not
+// expired since. Failures to persist the token ID are logged: it is still
+// recorded in memory.
+func (c *DiskReplayCache) Seen(id string, expiresAt time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cache.Seen(id, expiresAt) {
+		return true
+	}
+	if err := c.persist(id, expiresAt); err != nil {
+		c.log.WithError(err).Warn("Failed to persist LSVID replay cache")
+	}
+	return false
+}
+
+func (c *DiskReplayCache) persist(id string, expiresAt time.Time) error {
+	if c.records >= c.compactAfter {
+		return c.compact()
+	}
+
+	line, err := json.Marshal(replayRecord{ID: id, Exp: expiresAt.Unix()})
+	if err != nil {
+		return err
+	}
+	if c.file == nil {
+		if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+			return err
+		}
+		if c.file, err = os.OpenFile(c.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600); err != nil {
+			return err
+		}
+	}
+	if _, err := c.file.Write(append(line, '\n')); err != nil {
+		// Reopen the log on the next write rather than appending after a
+		// partial record.
+		_ = c.closeFile()
+		return err
+	}
+	c.records++
+	return nil
+}
+
+// compact rewrites the log with the token IDs that have not expired.
+func (c *DiskReplayCache) compact() error {
+	entries := c.cache.Entries()
+	var buf bytes.Buffer
+	for id, exp := range entries {
+		line, err := json.Marshal(replayRecord{ID: id, Exp: exp.Unix()})
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	// The log is replaced by the compacted one, so appends must go to the
+	// new file.
+	if err := c.closeFile(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	if err := diskutil.AtomicWriteFile(c.path, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	c.records = len(entries)
+	c.compactAfter = 2 * len(entries)
+	if c.compactAfter < replayCompactMinRecords {
+		c.compactAfter = replayCompactMinRecords
+	}
+	return nil
+}
+
+func (c *DiskReplayCache) closeFile() error {
+	if c.file == nil {
+		return nil
+	}
+	err := c.file.Close()
+	c.file = nil
+	return err
+}
+
+// parseReplayLog returns the token IDs recorded in the log, along with their
+// latest expiration. A trailing record without a newline is the leftover of
+// an interrupted write and is ignored.
+func parseReplayLog(data []byte) (map[string]time.Time, error) {
+	if i := bytes.LastIndexByte(data, '\n'); i+1 < len(data) {
+		data = data[:i+1]
+	}
+
+	entries := make(map[string]time.Time)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		var record replayRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("malformed record on line %d: %w", n, err)
+		}
+		exp := time.Unix(record.Exp, 0)
+		if current, ok := entries[record.ID]; !ok || exp.After(current) {
+			entries[record.ID] = exp
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
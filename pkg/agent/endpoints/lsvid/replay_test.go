@@ -0,0 +1,130 @@
+package lsvid_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	endpointslsvid "github.com/spiffe/spire/pkg/agent/endpoints/lsvid"
+	"github.com/spiffe/spire/test/clock"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskReplayCache(t *testing.T) {
+	clk := clock.NewMock(t)
+	log, logHook := test.NewNullLogger()
+	dir := filepath.Join(spiretest.TempDir(t), "lsvid_replay")
+	path := filepath.Join(dir, "cache.log")
+
+	cache, err := endpointslsvid.NewDiskReplayCache(log, path, clk)
+	require.NoError(t, err)
+
+	exp := clk.Now().Add(time.Hour)
+	require.False(t, cache.Seen("A", exp))
+	require.True(t, cache.Seen("A", exp))
+	require.False(t, cache.Seen("B", clk.Now().Add(time.Minute)))
+	require.Equal(t, 2, countLines(t, path))
+
+	// Token IDs survive a restart until they expire, and the log is
+	// compacted on load.
+	clk.Add(2 * time.Minute)
+	restarted, err := endpointslsvid.NewDiskReplayCache(log, path, clk)
+	require.NoError(t, err)
+	require.Equal(t, 1, countLines(t, path))
+	require.True(t, restarted.Seen("A", exp))
+	require.False(t, restarted.Seen("B", exp))
+	require.Equal(t, 2, countLines(t, path))
+	require.Empty(t, logHook.AllEntries())
+}
+
+func TestDiskReplayCachePersistFailure(t *testing.T) {
+	clk := clock.NewMock(t)
+	log, logHook := test.NewNullLogger()
+	dir := filepath.Join(spiretest.TempDir(t), "lsvid_replay")
+	path := filepath.Join(dir, "cache.log")
+
+	cache, err := endpointslsvid.NewDiskReplayCache(log, path, clk)
+	require.NoError(t, err)
+
+	// The token ID is still recorded in memory when persisting fails.
+	require.NoError(t, os.WriteFile(dir, nil, 0600))
+	exp := clk.Now().Add(time.Hour)
+	require.False(t, cache.Seen("A", exp))
+	require.True(t, cache.Seen("A", exp))
+	require.Len(t, logHook.AllEntries(), 1)
+	require.Equal(t, logrus.WarnLevel, logHook.LastEntry().Level)
+	require.Equal(t, "Failed to persist LSVID replay cache", logHook.LastEntry().Message)
+
+	// Token IDs are persisted again once the log can be written.
+	require.NoError(t, os.Remove(dir))
+	require.False(t, cache.Seen("B", exp))
+	require.Len(t, logHook.AllEntries(), 1)
+	require.Equal(t, 1, countLines(t, path))
+}
+
+func TestDiskReplayCacheCompaction(t *testing.T) {
+	clk := clock.NewMock(t)
+	log, logHook := test.NewNullLogger()
+	path := filepath.Join(spiretest.TempDir(t), "cache.log")
+
+	cache, err := endpointslsvid.NewDiskReplayCache(log, path, clk)
+	require.NoError(t, err)
+
+	// The log is compacted once it holds 1024 records.
+	for i := 0; i < 1024; i++ {
+		require.False(t, cache.Seen(fmt.Sprint(i), clk.Now().Add(time.Minute)))
+	}
+	require.Equal(t, 1024, countLines(t, path))
+
+	clk.Add(2 * time.Minute)
+	exp := clk.Now().Add(time.Hour)
+	require.False(t, cache.Seen("A", exp))
+	require.Equal(t, 1, countLines(t, path))
+	require.False(t, cache.Seen("B", exp))
+	require.Equal(t, 2, countLines(t, path))
+	require.Empty(t, logHook.AllEntries())
+
+	restarted, err := endpointslsvid.NewDiskReplayCache(log, path, clk)
+	require.NoError(t, err)
+	require.True(t, restarted.Seen("A", exp))
+	require.True(t, restarted.Seen("B", exp))
+	require.False(t, restarted.Seen("0", exp))
+}
+
+func TestDiskReplayCacheTruncatedRecord(t *testing.T) {
+	clk := clock.NewMock(t)
+	path := filepath.Join(spiretest.TempDir(t), "cache.log")
+	exp := clk.Now().Add(time.Hour).Unix()
+	require.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf("{\"id\":\"A\",\"exp\":%d}\n{\"id\":\"B\",", exp)), 0600))
+
+	// The record left over by an interrupted write is dropped.
+	log, _ := test.NewNullLogger()
+	cache, err := endpointslsvid.NewDiskReplayCache(log, path, clk)
+	require.NoError(t, err)
+	require.Equal(t, 1, countLines(t, path))
+	require.True(t, cache.Seen("A", time.Unix(exp, 0)))
+	require.False(t, cache.Seen("B", time.Unix(exp, 0)))
+	require.Equal(t, 2, countLines(t, path))
+}
+
+func TestDiskReplayCacheMalformedFile(t *testing.T) {
+	path := filepath.Join(spiretest.TempDir(t), "cache.log")
+	require.NoError(t, os.WriteFile(path, []byte("not JSON\n"), 0600))
+
+	log, _ := test.NewNullLogger()
+	_, err := endpointslsvid.NewDiskReplayCache(log, path, clock.NewMock(t))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "malformed record on line 1")
+}
+
+func countLines(t *testing.T, path string) int {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return bytes.Count(data, []byte("\n"))
+}
@@ -15,7 +15,8 @@ import (
 )
 
 const (
-	workloadAPIMethodPrefix = "/SpiffeWorkloadAPI/"
+	workloadAPIMethodPrefix      = "/SpiffeWorkloadAPI/"
+	lsvidWorkloadAPIMethodPrefix = "/spire.api.workload.lsvid.v1.LSVIDWorkloadAPI/"
 )
 
 func Middleware(log logrus.FieldLogger, metrics telemetry.Metrics) middleware.Middleware {
@@ -46,7 +47,8 @@ func verifySecurityHeader(ctx context.Context, fullMethod string, req interface{
 }
 
 func isWorkloadAPIMethod(fullMethod string) bool {
-	return strings.HasPrefix(fullMethod, workloadAPIMethodPrefix)
+	return strings.HasPrefix(fullMethod, workloadAPIMethodPrefix) ||
+		strings.HasPrefix(fullMethod, lsvidWorkloadAPIMethodPrefix)
 }
 
 func hasSecurityHeader(ctx context.Context) bool {
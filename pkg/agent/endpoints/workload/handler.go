@@ -26,55 +26,8 @@ import (
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/types/known/structpb"
-
-	// to selectors assertion
-	"github.com/spiffe/spire/pkg/agent/plugin/keymanager"
-	"crypto/ecdsa"
-	"encoding/base64"
-	"strings"
-	"crypto/rand"
-	hash256 "crypto/sha256"
-	"encoding/pem"
-
-	// "github.com/spiffe/go-spiffe/v2/svid/x509svid"
-	mint "github.com/golang-jwt/jwt"
 )
 
-type IDClaim struct {
-	CN	string		`json:"cn,omitempty"`
-	PK	[]byte		`json:"pk,omitempty"`
-	ID	*Token		`json:"id,omitempty"`
-}
-
-type Payload struct {
-	Ver int8		`json:"ver,omitempty"`
-	Alg string		`json:"alg,omitempty"`
-	Iat	int64		`json:"iat,omitempty"`
-	Iss	*IDClaim	`json:"iss,omitempty"`
-	Sub	*IDClaim	`json:"sub,omitempty"`
-	Aud	*IDClaim	`json:"aud,omitempty"`
-}
-
-type Token struct {	
-	Nested		*Token		`json:"nested,omitempty"`
-	Payload		*Payload	`json:"payload"`
-	Signature	[]byte		`json:"signature"`
-}
-
-type LSVID struct {
-	Token		*Token		`json:"token"`
-	Bundle		*Token		`json:"bundle"`
-}
-
-// type Layer struct {	
-// 	Payload		*Payload
-// 	Signature	[]byte
-// }
-
-// type LSVID struct {	
-// 	Layers	[]Layer
-// }
-
 type Manager interface {
 	SubscribeToCacheChanges(cache.Selectors) cache.Subscriber
 	MatchingIdentities([]*common.Selector) []cache.Identity
@@ -93,8 +46,6 @@ type Config struct {
 	AllowUnauthenticatedVerifiers bool
 	AllowedForeignJWTClaims       map[string]struct{}
 	TrustDomain                   spiffeid.TrustDomain
-	AgentPrivKey				  keymanager.Key
-	AgentSVID					  []*x509.Certificate
 }
 
 type Handler struct {
@@ -108,139 +59,69 @@ func New(c Config) *Handler {
 	}
 }
 
-// attest caller and return its LSVID signed by the server
+// FetchJWTSVID processes request for a JWT-SVID
 func (h *Handler) FetchJWTSVID(ctx context.Context, req *workload.JWTSVIDRequest) (resp *workload.JWTSVIDResponse, err error) {
-
 	log := rpccontext.Logger(ctx)
-
-	// Retrieve workload identity
-	selectors, err := h.c.Attestor.Attest(ctx)
-	if err != nil {
-		log.WithError(err).Errorf("Workload attestation failed\n")
-		return nil, err
-	}
-	identities := h.c.Manager.MatchingIdentities(selectors)
-
-	// Generate LSVID payload using workload identity
-	wlPayload, err := h.cert2LSR(identities[0].SVID[0], h.c.AgentSVID[0].URIs[0].String())
-	if err != nil {
-		return nil, status.Errorf(codes.Unavailable, "Error converting cert to LSR: %v\n", err)
-	}
-
-	lsvidPayload, err := json.Marshal(wlPayload)
-	if err != nil {
-		return nil, status.Errorf(codes.Unavailable, "Error marshalling payload: %v\n", err)
+	if len(req.Audience) == 0 {
+		log.Error("Missing required audience parameter")
+		return nil, status.Error(codes.InvalidArgument, "audience must be specified")
 	}
-	// encode payload
-	encodedPayload := base64.RawURLEncoding.EncodeToString(lsvidPayload)
 
-	// Retrieve the workload SPIFFE-ID
-	wlSpiffeId, err := spiffeid.FromString(identities[0].Entry.SpiffeId)
-	if err != nil {
-		return nil, status.Errorf(codes.Unavailable, "could not fetch SPIFFE-ID: %v\n", err)
+	if req.SpiffeId != "" {
+		if _, err := spiffeid.FromString(req.SpiffeId); err != nil {
+			log.WithField(telemetry.SPIFFEID, req.SpiffeId).WithError(err).Error("Invalid requested SPIFFE ID")
+			return nil, status.Errorf(codes.InvalidArgument, "invalid requested SPIFFE ID: %v", err)
+		}
 	}
 
-	// Sign workload LSR using modified FetchJWTSVID endpoint
-	svid, err := h.c.Manager.FetchJWTSVID(ctx, wlSpiffeId, []string{encodedPayload})
+	selectors, err := h.c.Attestor.Attest(ctx)
 	if err != nil {
-		return nil, status.Errorf(codes.Unavailable, "could not fetch JWT-SVID: %v\n", err)
+		log.WithError(err).Error("Workload attestation failed")
+		return nil, err
 	}
-	log.Info("Workload LSVID signed by server	: %s\n", fmt.Sprintf("%s", svid))
 
-	// // Generate Agent LSVID to test embedding it in issuer claim
-
-	// Generate LSR from Agent certificate
-	// TODO Create a func to create LSR without using a x509 cert
-	agentPayload, err := h.cert2LSR(h.c.AgentSVID[0], h.c.AgentSVID[0].URIs[0].String())
-	if err != nil {
-		return nil, status.Errorf(codes.Unavailable, "Error converting cert to LSR: %v\n", err)
-	}
+	var spiffeIDs []spiffeid.ID
+	identities := h.c.Manager.MatchingIdentities(selectors)
+	for _, identity := range identities {
+		if req.SpiffeId != "" && identity.Entry.SpiffeId != req.SpiffeId {
+			continue
+		}
 
-	//  Marshal payload
-	agentLSVIDPayload, err := json.Marshal(agentPayload)
-	if err != nil {
-		return nil, status.Errorf(codes.Unavailable, "Error marshalling payload: %v\n", err)
-	}
-	// encode payload
-	agentEncodedPayload := base64.RawURLEncoding.EncodeToString(agentLSVIDPayload)
+		spiffeID, err := spiffeid.FromString(identity.Entry.SpiffeId)
+		if err != nil {
+			log.WithField(telemetry.SPIFFEID, identity.Entry.SpiffeId).WithError(err).Error("Invalid requested SPIFFE ID")
+			return nil, status.Errorf(codes.InvalidArgument, "invalid requested SPIFFE ID: %v", err)
+		}
 
-	// Retrieve the workload SPIFFE-ID
-	agentSpiffeId, err := spiffeid.FromString(h.c.AgentSVID[0].URIs[0].String())
-	// spiffeid.New("example.org", h.c.AgentSVID[0].URIs[0].String())
-	if err != nil {
-		return nil, status.Errorf(codes.Unavailable, "could not fetch SPIFFE-ID: %v\n", err)
+		spiffeIDs = append(spiffeIDs, spiffeID)
 	}
 
-	// Sign workload LSR using modified FetchJWTSVID endpoint
-	agentLSVID, err := h.c.Manager.FetchJWTSVID(ctx, agentSpiffeId, []string{agentEncodedPayload})
-	if err != nil {
-		return nil, status.Errorf(codes.Unavailable, "could not fetch JWT-SVID: %v\n", err)
+	if len(spiffeIDs) == 0 {
+		log.WithField(telemetry.Registered, false).Error("No identity issued")
+		return nil, status.Error(codes.PermissionDenied, "no identity issued")
 	}
 
-	// decode agent LSVID to LSVID struct
-	decAgentLSVID, err := h.DecodeLSVID(agentLSVID.Token)
-	if err != nil {
-		return nil, status.Errorf(codes.Unavailable, "Error decoding LSVID: %v\n", err)
-	} 
-
-	// Now, extend LSVID using agent key.
-	extendedPayload := &Payload{
-		Ver:	1,
-		Alg:	"ES256",
-		Iat:	time.Now().Round(0).Unix(),
-		Iss:	&IDClaim{
-			CN:	h.c.AgentSVID[0].URIs[0].String(),
-			ID:	decAgentLSVID,
-		},
-		Aud:	&IDClaim{
-			CN:	wlSpiffeId.String(),
-		},
-	}
-
-	// decode svid.token to LSVID struct
-	decLSVID, err := h.DecodeLSVID(svid.Token)
-	if err != nil {
-		return nil, status.Errorf(codes.Unavailable, "Error decoding LSVID: %v\n", err)
-	} 
-
-	extLSVID, err := h.ExtendLSVID(decLSVID, extendedPayload, h.c.AgentPrivKey)
-	if err != nil {
-		return nil, status.Errorf(codes.Unavailable, "Error extending LSVID: %v\n", err)
-	} 
-
-	// decode svid.token to LSVID struct
-	decExtLSVID, err := h.DecodeLSVID(extLSVID)
-	if err != nil {
-		return nil, status.Errorf(codes.Unavailable, "Error decoding LSVID: %v\n", err)
-	} 
+	log = log.WithField(telemetry.Registered, true)
 
-	bundle, err := h.GetTrustbundle(ctx, h.c.AgentSVID[1])
-	if err != nil {
-		return nil, status.Errorf(codes.Unavailable, "Error retrieving LSVID trust bundle: %v\n", err)
-	} 
+	resp = new(workload.JWTSVIDResponse)
+	for _, id := range spiffeIDs {
+		loopLog := log.WithField(telemetry.SPIFFEID, id.String())
 
-	// create the LSVID in new format with bundle
-	finalLSVID := LSVID{
-		Token:		decExtLSVID,
-		Bundle:		bundle,
-	}
+		var svid *client.JWTSVID
+		svid, err = h.c.Manager.FetchJWTSVID(ctx, id, req.Audience)
+		if err != nil {
+			loopLog.WithError(err).Error("Could not fetch JWT-SVID")
+			return nil, status.Errorf(codes.Unavailable, "could not fetch JWT-SVID: %v", err)
+		}
+		resp.Svids = append(resp.Svids, &workload.JWTSVID{
+			SpiffeId: id.String(),
+			Svid:     svid.Token,
+		})
 
-	// Marshal the LSVID struct into JSON
-	lsvidJSON, err := json.Marshal(finalLSVID)
-	if err != nil {
-		return nil, status.Errorf(codes.Unavailable, "error marshaling LSVID to JSON: %v", err)
+		ttl := time.Until(svid.ExpiresAt)
+		loopLog.WithField(telemetry.TTL, ttl.Seconds()).Debug("Fetched JWT SVID")
 	}
 
-	// Encode the JSON byte slice to Base64.RawURLEncoded string
-	encLSVID := base64.RawURLEncoding.EncodeToString(lsvidJSON)
-
-	// Format response
-	resp = new(workload.JWTSVIDResponse)
-	resp.Svids = append(resp.Svids, &workload.JWTSVID{
-		SpiffeId: identities[0].Entry.SpiffeId,
-		Svid:     encLSVID,
-	})
-
 	return resp, nil
 }
 
@@ -249,26 +130,9 @@ func (h *Handler) FetchJWTBundles(req *workload.JWTBundlesRequest, stream worklo
 	ctx := stream.Context()
 	log := rpccontext.Logger(ctx)
 
-	// Create the experimental lightweight-SVID for Agent and all bundle
-	for i:=0; i< len(h.c.AgentSVID); i++ { 
-		// lsvid, err := cert2LSVID(h.c.AgentSVID[0].URIs[0].String(), h.c.AgentSVID[i], h.c.AgentPrivKey, "")
-		tmpPayload, err := h.cert2LSR(h.c.AgentSVID[i], h.c.AgentSVID[0].URIs[0].String())
-		if err != nil {
-			return err
-		}
-
-		lsvidPayload, err := json.Marshal(tmpPayload)
-		if err != nil {
-			return err
-		}
-
-		log.Info("SPIFFE-ID		: %s\n", fmt.Sprintf("%s", h.c.AgentSVID[i].URIs[0].String()))
-		log.Info("LSVID	Payoad	: %s\n", fmt.Sprintf("%s", &lsvidPayload))
-	}
-	
 	selectors, err := h.c.Attestor.Attest(ctx)
 	if err != nil {
-		log.WithError(err).Errorf("Workload attestation failed\n")
+		log.WithError(err).Error("Workload attestation failed")
 		return err
 	}
 
@@ -287,111 +151,58 @@ func (h *Handler) FetchJWTBundles(req *workload.JWTBundlesRequest, stream worklo
 	}
 }
 
-// Originally, ValidateJWTSVID processes request for JWT-SVID validation. Modified to prototyping the LSVID validation
-// Verify LSVID expiration and signature (TODO)
-// input: LSVID 
-// for each audience do
-// 			verify exp time (TODO)
-// 			Set public key 0 as root key
-// 			verify all signatures using pk0
-// 
-// TODO: add support to other algorithms rather than ECDSA
-// Maybe should be better an audience that is not an array
+// ValidateJWTSVID processes request for JWT-SVID validation
 func (h *Handler) ValidateJWTSVID(ctx context.Context, req *workload.ValidateJWTSVIDRequest) (*workload.ValidateJWTSVIDResponse, error) {
-
 	log := rpccontext.Logger(ctx)
 	if req.Audience == "" {
 		log.Error("Missing required audience parameter")
 		return nil, status.Error(codes.InvalidArgument, "audience must be specified")
 	}
-
-	tmplsvid := strings.Split(req.Audience, ";")
-
-	// set the first public key as LSVID issuer public key
-	rootValues := strings.Split(tmplsvid[0], ",")
-	rootkey := rootValues[3]
-
-	// validate all LSVIDs using pk0
-	for i:=0;i<len(tmplsvid);i++ {
-		// log.Info("Validating LSVID	: ", fmt.Sprintf("%v", tmplsvid[i]))
-		// log.Info("rootkey	: ", fmt.Sprintf("%v", rootkey))
-		_ = ValidateLSVID(ctx, tmplsvid[i], rootkey)
-	}
-	return &workload.ValidateJWTSVIDResponse{
-		// SpiffeId: spiffeID,
-		// Claims:   s,
-	}, nil
-}
-
-func ValidateLSVID(ctx context.Context, lsvid string, key string) bool {
-
-	log := rpccontext.Logger(ctx)
-	if lsvid == "" {
-		log.Error("Missing required lsvid parameter")
-		return false
+	if req.Svid == "" {
+		log.Error("Missing required svid parameter")
+		return nil, status.Error(codes.InvalidArgument, "svid must be specified")
 	}
 
-	tmpAud := strings.Split(lsvid, ";")
-	// partPay := strings.Split(lsvid, ",")
-	for i:=0;i<len(tmpAud);i++ {
-		log.Debug("LSVID: ", tmpAud[i])
-		parts := strings.Split(tmpAud[i], ",")
-		// fmt.Printf("parts: %v\n\n", parts)
-
-		sig := parts[len(parts)-1]
-		log.Debug("Sig: ", sig)
-
-		payload := strings.Join(parts[:len(parts)-1], ",")
-		log.Debug("payload: ", payload)
+	log = log.WithField(telemetry.Audience, req.Audience)
 
-		sigVer := ecdsaVerify2(key, payload, sig)
-		log.Debug("sigVerification: ", sigVer)
-		if sigVer == false {
-			log.Error("Signature validation failed!")
-			return false
-		}
-		log.Info("Signature successfully validated!")
-		return true
-	}
-	return true
-}
-
-// ecdsaVerify2 use `ecdsa.VerifyASN1()` to verify signature
-func ecdsaVerify2(base64PublicKey string, message string, base64Signature string) bool {
-	ecPublicKey, err := loadECPublicKey2(base64PublicKey)
+	selectors, err := h.c.Attestor.Attest(ctx)
 	if err != nil {
-		panic(err)
+		log.WithError(err).Error("Workload attestation failed")
+		return nil, err
 	}
 
-	hash := hash256.Sum256([]byte(message))
+	keyStore := keyStoreFromBundles(h.getWorkloadBundles(selectors))
 
-	sigBytes, err := base64.RawURLEncoding.DecodeString(base64Signature)
+	spiffeID, claims, err := jwtsvid.ValidateToken(ctx, req.Svid, keyStore, []string{req.Audience})
 	if err != nil {
-		panic(err)
+		log.WithError(err).Warn("Failed to validate JWT")
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	log.WithField(telemetry.SPIFFEID, spiffeID).Debug("Successfully validated JWT")
 
-	return ecdsa.VerifyASN1(ecPublicKey, hash[:], sigBytes)
-}
-
-func loadECPublicKey2(base64PublicKey string) (*ecdsa.PublicKey, error) {
-
-	fmt.Printf("pubkey: %v\n\n", base64PublicKey)
-	publicKeyBytes, err := base64.RawURLEncoding.DecodeString(base64PublicKey)
+	id, err := spiffeid.FromString(spiffeID)
 	if err != nil {
-		panic(err)
+		log.WithError(err).Warn("Failed to validate JWT")
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if id.TrustDomain() != h.c.TrustDomain {
+		for claim := range claims {
+			if !isClaimAllowed(claim, h.c.AllowedForeignJWTClaims) {
+				delete(claims, claim)
+			}
+		}
 	}
 
-	pub, err := x509.ParsePKIXPublicKey(publicKeyBytes)
+	s, err := structFromValues(claims)
 	if err != nil {
-		return nil, errors.New("Failed to parse ECDSA public key")
+		log.WithError(err).Error("Error deserializing claims from JWT-SVID")
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	publicKey, ok := pub.(*ecdsa.PublicKey)
-	if !ok {
-		return nil, errors.New("Not a ECDSA public key")
-	}
-	
-	return publicKey, nil
+	return &workload.ValidateJWTSVIDResponse{
+		SpiffeId: spiffeID,
+		Claims:   s,
+	}, nil
 }
 
 // FetchX509SVID processes request for an x509 SVID
@@ -664,287 +475,3 @@ func isClaimAllowed(claim string, allowedClaims map[string]struct{}) bool {
 		return ok
 	}
 }
-
-
-// Helper functions to returnselectors
-
-// generate or extend a new ecdsa signed encoded token
-//  receive payload already encoded
-func NewECDSAencode(newPayload string, oldToken string, key crypto.Signer) (string, error) {
-
-	// //  Marshal received claimset into JSON
-	// cs, _ := json.Marshal(claimset)
-	// payload := base64.RawURLEncoding.EncodeToString(cs)
-
-	// If no oldToken, generates a simple assertion
-	if oldToken == "" {
-		hash 	:= hash256.Sum256([]byte(newPayload))
-		s, err 	:= key.Sign(rand.Reader, hash[:], crypto.SHA256)
-		if err 	!= nil {
-			fmt.Printf("Error signing: %s\n", err)
-			return "", err
-		}
-		sig := base64.RawURLEncoding.EncodeToString(s)
-		encoded := strings.Join([]string{newPayload, sig}, ".")
-
-		// fmt.Printf("\nUser token size: %d\n", len(payload) + len(sig))
-
-		return encoded, nil
-	}
-	
-	//  Otherwise, append assertion to previous content (oldmain) and sign it
-	hash	:= hash256.Sum256([]byte(newPayload + "." + oldToken))
-	s, err 	:= ecdsa.SignASN1(rand.Reader, key.(*ecdsa.PrivateKey), hash[:])
-	if err != nil {
-		fmt.Printf("Error signing: %s\n", err)
-		return "", err
-	}
-	signature := base64.RawURLEncoding.EncodeToString(s)
-	encoded := strings.Join([]string{newPayload, oldToken, signature}, ".")
-	
-	// fmt.Printf("\nAssertion size: %d\n", len(payload) + len(oldmain)+ len(signature))
-
-	return encoded, nil
-}
-
-// CertToPEM is a utility function returns a PEM encoded x509 Certificate
-func CertToPEM(cert *x509.Certificate) []byte {
-	pemCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
-
-	return pemCert
-}
-
-func EncodeECDSAPublicKey(key *ecdsa.PublicKey) ([]byte, error) {
-
-	derKey, err := x509.MarshalPKIXPublicKey(key)
-		if err != nil {
-			return nil, err
-		}
-
-	keyBlock := &pem.Block{
-		Type:  "EC PUBLIC KEY",
-		Bytes: derKey,
-	}
-
-	return pem.EncodeToMemory(keyBlock), nil
-}
-
-func ParseTokenClaims(strAT string) map[string]interface{} {
-	// defer timeTrack(time.Now(), "Parse token claims")
-
-		// Parse access token without validating signature
-		token, _, err := new(mint.Parser).ParseUnverified(strAT, mint.MapClaims{})
-		if err != nil {
-			fmt.Printf("Error parsing JWT claims: %v", err)
-		}
-		claims, _ := token.Claims.(mint.MapClaims)
-		
-		// fmt.Println(claims)
-		return claims
-}
-
-func ValidateTokenExp(claims map[string]interface{}) (expresult bool, remainingtime string) {
-	// defer timeTrack(time.Now(), "Validate token exp")
-
-	tm := time.Unix(int64(claims["exp"].(float64)), 0)
-	remaining := tm.Sub(time.Now())
-
-	if remaining > 0 {
-		expresult = true 
-	} else {
-		expresult = false
-	}
-
-	return expresult, remaining.String()
-
-}
-
-// Create an LSVID given a x509 certificate.
-// TODO: Update considering the new cert2LSR and LSVID struct
-// Format: version.issuer.subject.subjpublickey.expiration.signature
-func cert2LSVID(iss string, cert *x509.Certificate, key keymanager.Key, oldmain string) (string, error) {
-
-	// generate encoded public key
-	tmppk, err := x509.MarshalPKIXPublicKey(cert.PublicKey)
-	if err != nil {
-		return "", err
-	}
-	pubkey :=  base64.RawURLEncoding.EncodeToString(tmppk)
-
-	// Versioning needs TBD. For poc, considering vr = 1 to ECDSA.
-	vr := "1"
-	sub := cert.URIs[0].String()
-	// Create LSVID payload
-	payload :=  "{"+vr+"."+iss[9:]+"."+sub[9:]+"."+fmt.Sprintf("%s", pubkey)+"."+fmt.Sprintf("%v", cert.NotAfter.Unix())+"}"
-
-	// If no oldmain, generates a simple id
-	if oldmain == "" {
-	
-		// hash and sign payload
-		hash 	:= hash256.Sum256([]byte(payload))
-		s, err 	:= key.Sign(rand.Reader, hash[:], crypto.SHA256)
-		if err 	!= nil {
-			fmt.Printf("Error signing: %s\n", err)
-			return "", err
-		}
-		// Encode signature
-		sig := base64.RawURLEncoding.EncodeToString(s)
-		// Concatenate payload and signature
-		encoded := strings.Join([]string{payload, sig}, ".")
-
-		return encoded, nil
-	}
-	
-	//  Otherwise, append id to previous content and sign it
-	hash	:= hash256.Sum256([]byte(payload + "." + oldmain))
-	s, err 	:= key.Sign(rand.Reader, hash[:], crypto.SHA256)
-	if err != nil {
-		fmt.Printf("Error signing: %s\n", err)
-		return "", err
-	}
-	signature := base64.RawURLEncoding.EncodeToString(s)
-	encoded := strings.Join([]string{payload, oldmain, signature}, ".")
-	
-	fmt.Printf("\nID size: %d\n", len(payload) + len(oldmain)+ len(signature))
-
-	return encoded, nil
-}
-
-// Create an LSVID sign request given a x509 certificate.
-// Format: version.issuer.subject.subjpublickey.expiration.signature
-func (h *Handler) cert2LSR(cert *x509.Certificate, audience string) (*Payload, error) {
-
-	// generate encoded public key
-	tmppk, err := x509.MarshalPKIXPublicKey(cert.PublicKey)
-	if err != nil {
-		return &Payload{}, err
-	}
-	// pubkey :=  base64.RawURLEncoding.EncodeToString(tmppk)
-
-	// Versioning needs TBD. For poc, considering vr = 1 to ECDSA.
-	sub := cert.URIs[0].String()
-	// Create LSVID payload
-	lsvidPayload := &Payload{
-		Ver:	1,
-		Alg:	"ES256",
-		Iat:	time.Now().Round(0).Unix(),
-		Iss:	&IDClaim{
-			CN:	h.c.TrustDomain.String(),
-		},
-		Sub:	&IDClaim{
-			CN:	sub,
-			PK:	tmppk,
-		},
-		Aud:	&IDClaim{
-			CN:	audience,
-		},
-	}
-
-	return lsvidPayload, nil
-}
-
-func (h *Handler) EncodeLSVID(lsvid *Token) (string, error) {
-	// Marshal the LSVID struct into JSON
-	lsvidJSON, err := json.Marshal(lsvid)
-	if err != nil {
-		return "", errs.New("error marshaling LSVID to JSON: %v", err)
-	}
-
-	// Encode the JSON byte slice to Base64.RawURLEncoded string
-	encLSVID := base64.RawURLEncoding.EncodeToString(lsvidJSON)
-
-	return encLSVID, nil
-}
-
-func (h *Handler) DecodeLSVID(encLSVID string) (*Token, error) {
-
-	fmt.Printf("LSVID to be decoded: %s", encLSVID)
-    // Decode the base64.RawURLEncoded LSVID
-    decoded, err := base64.RawURLEncoding.DecodeString(encLSVID)
-    if err != nil {
-        return nil, errs.New("error decoding LSVID: %v", err)
-    }
-
-	fmt.Printf("Decoded LSVID to be unmarshaled: %s", decoded)
-
-    // Unmarshal the decoded byte slice into your struct
-    var decLSVID Token
-    err = json.Unmarshal(decoded, &decLSVID)
-    if err != nil {
-        return nil, errs.New("error unmarshalling LSVID: %v", err)
-    }
-
-    return &decLSVID, nil
-}
-
-func (h *Handler) ExtendLSVID(lsvid *Token, newPayload *Payload, key crypto.Signer) (string, error) {
-
-	// Create the extended LSVID structure
-	extLSVID := &Token{
-		Nested:		lsvid,
-		Payload:	newPayload,
-	}
-
-	// Marshal to JSON
-	// TODO: Check if its necessary to marshal before signing. I mean, we need an byte array, 
-	// and using JSON marshaler we got it. But maybe there is a better way?
-	tmpToSign, err := json.Marshal(extLSVID)
-	if err != nil {
-		return "", errs.New("Error generating json: %v", err)
-	} 
-
-	// Sign extlSVID
-	hash 	:= hash256.Sum256(tmpToSign)
-	s, err := key.Sign(rand.Reader, hash[:], crypto.SHA256)
-	if err != nil {
-		return "", errs.New("Error generating signed assertion: %v", err)
-	} 
-
-	// Set extLSVID signature
-	extLSVID.Signature = s
-
-	// Encode signed LSVID
-	outLSVID, err := h.EncodeLSVID(extLSVID)
-	if err != nil {
-		return "", errs.New("Error encoding LSVID: %v", err)
-	} 
-
-	return outLSVID, nil
-
-}
-
-func (h *Handler) GetTrustbundle(ctx context.Context, svid *x509.Certificate) (*Token, error) {
-	trustBundlePayload, err := h.cert2LSR(svid, svid.URIs[0].String())
-	if err != nil {
-		return nil, status.Errorf(codes.Unavailable, "Error converting cert to LSR: %v\n", err)
-	}
-
-	//  Marshal payload
-	bundleLSVIDPayload, err := json.Marshal(trustBundlePayload)
-	if err != nil {
-		return nil, status.Errorf(codes.Unavailable, "Error marshalling payload: %v\n", err)
-	}
-	// encode payload
-	bundleEncodedPayload := base64.RawURLEncoding.EncodeToString(bundleLSVIDPayload)
-
-	// Retrieve the workload SPIFFE-ID
-	bundleSpiffeId, err := spiffeid.FromString(svid.URIs[0].String())
-	// spiffeid.New("example.org", h.c.AgentSVID[0].URIs[0].String())
-	if err != nil {
-		return nil, status.Errorf(codes.Unavailable, "could not fetch SPIFFE-ID: %v\n", err)
-	}
-
-	// Sign workload LSR using modified FetchJWTSVID endpoint
-	trustBundleLSVID, err := h.c.Manager.FetchJWTSVID(ctx, bundleSpiffeId, []string{bundleEncodedPayload})
-	if err != nil {
-		return nil, status.Errorf(codes.Unavailable, "could not fetch JWT-SVID: %v\n", err)
-	}
-
-	// decode agent LSVID to LSVID struct
-	decBundleLSVID, err := h.DecodeLSVID(trustBundleLSVID.Token)
-	if err != nil {
-		return nil, status.Errorf(codes.Unavailable, "Error decoding LSVID: %v\n", err)
-	} 
-
-	return decBundleLSVID, nil
-}
\ No newline at end of file
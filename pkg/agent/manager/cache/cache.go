@@ -99,6 +99,7 @@ type X509SVID struct {
 type Cache struct {
 	*BundleCache
 	*JWTSVIDCache
+	*LSVIDCache
 
 	log         logrus.FieldLogger
 	trustDomain spiffeid.TrustDomain
@@ -132,6 +133,7 @@ func New(log logrus.FieldLogger, trustDomain spiffeid.TrustDomain, bundle *Bundl
 	return &Cache{
 		BundleCache:  NewBundleCache(trustDomain, bundle),
 		JWTSVIDCache: NewJWTSVIDCache(),
+		LSVIDCache:   NewLSVIDCache(),
 
 		log:          log,
 		metrics:      metrics,
@@ -225,9 +227,11 @@ func (c *Cache) UpdateEntries(update *UpdateEntries, checkSVID func(*common.Regi
 	// the case if there is a bug on the server) since it is necessary to
 	// authenticate the server.
 	bundleRemoved := false
+	removedBundles := make(map[spiffeid.TrustDomain]bool)
 	for id := range c.bundles {
 		if _, ok := update.Bundles[id]; !ok && id != c.trustDomain {
 			bundleRemoved = true
+			removedBundles[id] = true
 			// bundle no longer exists.
 			c.log.WithField(telemetry.TrustDomainID, id).Debug("Bundle removed")
 			delete(c.bundles, id)
@@ -266,9 +270,14 @@ func (c *Cache) UpdateEntries(update *UpdateEntries, checkSVID func(*common.Regi
 	fedRem, fedRemDone := allocStringSet()
 	defer fedRemDone()
 
+	// SPIFFE IDs of removed registration entries, whose LSVIDs are evicted
+	// unless another entry still has them.
+	removedIDs := make(map[string]bool)
+
 	// Remove records for registration entries that no longer exist
 	for id, record := range c.records {
 		if _, ok := update.RegistrationEntries[id]; !ok {
+			removedIDs[record.entry.SpiffeId] = true
 			c.log.WithFields(logrus.Fields{
 				telemetry.Entry:    id,
 				telemetry.SPIFFEID: record.entry.SpiffeId,
@@ -295,6 +304,9 @@ func (c *Cache) UpdateEntries(update *UpdateEntries, checkSVID func(*common.Regi
 		clearStringSet(fedRem)
 
 		record, existingEntry := c.updateOrCreateRecord(newEntry)
+		if existingEntry != nil && existingEntry.SpiffeId != newEntry.SpiffeId {
+			removedIDs[existingEntry.SpiffeId] = true
+		}
 
 		// Calculate the difference in selectors, add/remove the record
 		// from impacted selector indices, and add the selector diff to the
@@ -375,6 +387,13 @@ func (c *Cache) UpdateEntries(update *UpdateEntries, checkSVID func(*common.Regi
 		}
 	}
 
+	for _, record := range c.records {
+		delete(removedIDs, record.entry.SpiffeId)
+	}
+	c.LSVIDCache.deleteLSVIDs(removedIDs)
+	c.LSVIDCache.deleteLSVIDBundles(removedBundles)
+	c.LSVIDCache.deleteLSVIDBundles(bundleChanged)
+
 	if bundleRemoved || len(bundleChanged) > 0 {
 		c.BundleCache.Update(c.bundles)
 	}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/client"
 	"github.com/spiffe/spire/pkg/common/bundleutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/proto/spire/common"
@@ -428,6 +429,80 @@ func TestSubcriberNotifiedWhenEntryDropped(t *testing.T) {
 	assertNoWorkloadUpdate(t, subB)
 }
 
+func TestLSVIDsEvictedWhenEntryDropped(t *testing.T) {
+	cache := newTestCache()
+
+	foo := makeRegistrationEntry("FOO", "A")
+	bar := makeRegistrationEntry("BAR", "B")
+	updateEntries := &UpdateEntries{
+		Bundles:             makeBundles(bundleV1),
+		RegistrationEntries: makeRegistrationEntries(foo, bar),
+	}
+	cache.UpdateEntries(updateEntries, nil)
+
+	fooKey := LSVIDKey{SPIFFEID: spiffeid.RequireFromString(foo.SpiffeId), Audience: "audience"}
+	barKey := LSVIDKey{SPIFFEID: spiffeid.RequireFromString(bar.SpiffeId), Audience: "audience"}
+	cache.SetLSVID(fooKey, &client.JWTSVID{Token: "FOO"})
+	cache.SetLSVID(barKey, &client.JWTSVID{Token: "BAR"})
+
+	// dropping FOO evicts its LSVIDs only
+	updateEntries.RegistrationEntries = makeRegistrationEntries(bar)
+	cache.UpdateEntries(updateEntries, nil)
+	_, ok := cache.GetLSVID(fooKey)
+	assert.False(t, ok)
+	_, ok = cache.GetLSVID(barKey)
+	assert.True(t, ok)
+
+	// LSVIDs are kept while another entry has the same SPIFFE ID
+	baz := makeRegistrationEntry("BAZ", "C")
+	baz.SpiffeId = bar.SpiffeId
+	updateEntries.RegistrationEntries = makeRegistrationEntries(bar, baz)
+	cache.UpdateEntries(updateEntries, nil)
+	updateEntries.RegistrationEntries = makeRegistrationEntries(baz)
+	cache.UpdateEntries(updateEntries, nil)
+	_, ok = cache.GetLSVID(barKey)
+	assert.True(t, ok)
+}
+
+func TestLSVIDBundlesEvictedWhenBundleChanges(t *testing.T) {
+	cache := newTestCache()
+
+	updateEntries := &UpdateEntries{
+		Bundles: makeBundles(bundleV1, otherBundleV1),
+	}
+	cache.UpdateEntries(updateEntries, nil)
+
+	setBundles := func() {
+		cache.SetLSVIDBundle(trustDomain1, &client.JWTSVID{Token: "DOMAIN1"})
+		cache.SetLSVIDBundle(trustDomain2, &client.JWTSVID{Token: "DOMAIN2"})
+	}
+	setBundles()
+
+	// unchanged bundles keep their documents
+	cache.UpdateEntries(updateEntries, nil)
+	_, ok := cache.GetLSVIDBundle(trustDomain1)
+	assert.True(t, ok)
+	_, ok = cache.GetLSVIDBundle(trustDomain2)
+	assert.True(t, ok)
+
+	// updating a bundle evicts its document only
+	updateEntries.Bundles = makeBundles(bundleV2, otherBundleV1)
+	cache.UpdateEntries(updateEntries, nil)
+	_, ok = cache.GetLSVIDBundle(trustDomain1)
+	assert.False(t, ok)
+	_, ok = cache.GetLSVIDBundle(trustDomain2)
+	assert.True(t, ok)
+
+	// removing a federated bundle evicts its document
+	setBundles()
+	updateEntries.Bundles = makeBundles(bundleV2)
+	cache.UpdateEntries(updateEntries, nil)
+	_, ok = cache.GetLSVIDBundle(trustDomain1)
+	assert.True(t, ok)
+	_, ok = cache.GetLSVIDBundle(trustDomain2)
+	assert.False(t, ok)
+}
+
 func TestSubcriberOnlyGetsEntriesWithSVID(t *testing.T) {
 	cache := newTestCache()
 
@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"sync"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/client"
+	"github.com/spiffe/spire/pkg/common/lsvid"
+)
+
+// LSVIDKey identifies a cached LSVID.
+type LSVIDKey struct {
+	// SPIFFEID is the SPIFFE ID of the LSVID subject.
+	SPIFFEID spiffeid.ID
+
+	// SubjectKey is the PKIX, ASN.1 DER encoded public key of the subject.
+	SubjectKey string
+
+	// X5T is the thumbprint of the X509-SVID the LSVID is bound to. See
+	// lsvid.X509SVIDThumbprint.
+	X5T string
+
+	// Audience is the audience the LSVID is addressed to.
+	Audience string
+}
+
+// CachedLSVID is an LSVID held by the cache.
+type CachedLSVID struct {
+	Key  LSVIDKey
+	SVID *client.JWTSVID
+
+	// Used is true if the LSVID was returned by GetLSVID since it was cached.
+	Used bool
+}
+
+type LSVIDCache struct {
+	mu     sync.Mutex
+	lsvids map[LSVIDKey]*CachedLSVID
+
+	// bundles holds the LSVID trust bundle documents signed by the server,
+	// keyed by the trust domain they list the authorities of.
+	bundles map[spiffeid.TrustDomain]*client.JWTSVID
+
+	// revocations holds the LSVID revocations received from the server.
+	revocations lsvid.RevocationList
+}
+
+func NewLSVIDCache() *LSVIDCache {
+	return &LSVIDCache{
+		lsvids:  make(map[LSVIDKey]*CachedLSVID),
+		bundles: make(map[spiffeid.TrustDomain]*client.JWTSVID),
+	}
+}
+
+// GetLSVID returns the cached LSVID for the given key and flags it as used.
+func (c *LSVIDCache) GetLSVID(key LSVIDKey) (*client.JWTSVID, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.lsvids[key]
+	if !ok {
+		return nil, false
+	}
+	cached.Used = true
+	return cached.SVID, true
+}
+
+// SetLSVID caches the LSVID for the given key.
+func (c *LSVIDCache) SetLSVID(key LSVIDKey, svid *client.JWTSVID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lsvids[key] = &CachedLSVID{
+		Key:  key,
+		SVID: svid,
+	}
+}
+
+// DeleteLSVID removes the LSVID for the given key from the cache.
+func (c *LSVIDCache) DeleteLSVID(key LSVIDKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.lsvids, key)
+}
+
+// LSVIDs returns a snapshot of the cached LSVIDs.
+func (c *LSVIDCache) LSVIDs() []CachedLSVID {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]CachedLSVID, 0, len(c.lsvids))
+	for _, cached := range c.lsvids {
+		out = append(out, *cached)
+	}
+	return out
+}
+
+// GetLSVIDBundle returns the cached LSVID trust bundle document of the given
+// trust domain.
+func (c *LSVIDCache) GetLSVIDBundle(td spiffeid.TrustDomain) (*client.JWTSVID, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	bundle, ok := c.bundles[td]
+	return bundle, ok
+}
+
+// SetLSVIDBundle caches the LSVID trust bundle document of the given trust
+// domain.
+func (c *LSVIDCache) SetLSVIDBundle(td spiffeid.TrustDomain, bundle *client.JWTSVID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bundles[td] = bundle
+}
+
+// LSVIDRevocations returns the LSVID revocations received from the server.
+func (c *LSVIDCache) LSVIDRevocations() lsvid.RevocationList {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.revocations
+}
+
+// SetLSVIDRevocations replaces the LSVID revocations received from the
+// server.
+func (c *LSVIDCache) SetLSVIDRevocations(revocations lsvid.RevocationList) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.revocations = revocations
+}
+
+// deleteLSVIDBundles removes the LSVID trust bundle documents of the given
+// trust domains from the cache, so that documents listing rotated or pruned
+// authorities are fetched again.
+func (c *LSVIDCache) deleteLSVIDBundles(tds map[spiffeid.TrustDomain]bool) {
+	if len(tds) == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for td := range tds {
+		delete(c.bundles, td)
+	}
+}
+
+// deleteLSVIDs removes the LSVIDs of the given subjects from the cache.
+func (c *LSVIDCache) deleteLSVIDs(spiffeIDs map[string]bool) {
+	if len(spiffeIDs) == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.lsvids {
+		if spiffeIDs[key.SPIFFEID.String()] {
+			delete(c.lsvids, key)
+		}
+	}
+}
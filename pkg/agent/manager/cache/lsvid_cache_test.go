@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLSVIDCache(t *testing.T) {
+	now := time.Now()
+	expected := &client.JWTSVID{Token: "X", IssuedAt: now, ExpiresAt: now.Add(time.Second)}
+
+	cache := NewLSVIDCache()
+
+	key := LSVIDKey{
+		SPIFFEID:   spiffeid.RequireFromString("spiffe://example.org/blog"),
+		SubjectKey: "key",
+		X5T:        "x5t",
+		Audience:   "spiffe://example.org/agent",
+	}
+
+	// LSVID is not cached
+	actual, ok := cache.GetLSVID(key)
+	assert.False(t, ok)
+	assert.Nil(t, actual)
+
+	// LSVID is cached but not used yet
+	cache.SetLSVID(key, expected)
+	assert.Equal(t, []CachedLSVID{{Key: key, SVID: expected}}, cache.LSVIDs())
+
+	// LSVID is returned and flagged as used
+	actual, ok = cache.GetLSVID(key)
+	assert.True(t, ok)
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, []CachedLSVID{{Key: key, SVID: expected, Used: true}}, cache.LSVIDs())
+
+	// LSVID is not returned for another subject key, X509-SVID or audience
+	otherKey := key
+	otherKey.SubjectKey = "other"
+	_, ok = cache.GetLSVID(otherKey)
+	assert.False(t, ok)
+	otherX509SVID := key
+	otherX509SVID.X5T = "other"
+	_, ok = cache.GetLSVID(otherX509SVID)
+	assert.False(t, ok)
+	otherAudience := key
+	otherAudience.Audience = "spiffe://example.org/other"
+	_, ok = cache.GetLSVID(otherAudience)
+	assert.False(t, ok)
+
+	// renewing the LSVID resets the used flag
+	cache.SetLSVID(key, expected)
+	assert.Equal(t, []CachedLSVID{{Key: key, SVID: expected}}, cache.LSVIDs())
+
+	// LSVID is deleted
+	cache.DeleteLSVID(key)
+	actual, ok = cache.GetLSVID(key)
+	assert.False(t, ok)
+	assert.Nil(t, actual)
+	assert.Empty(t, cache.LSVIDs())
+}
+
+func TestLSVIDBundleCache(t *testing.T) {
+	now := time.Now()
+	expected := &client.JWTSVID{Token: "X", IssuedAt: now, ExpiresAt: now.Add(time.Second)}
+	td := spiffeid.RequireTrustDomainFromString("example.org")
+
+	cache := NewLSVIDCache()
+
+	// trust bundle document is not cached
+	actual, ok := cache.GetLSVIDBundle(td)
+	assert.False(t, ok)
+	assert.Nil(t, actual)
+
+	// trust bundle document is cached
+	cache.SetLSVIDBundle(td, expected)
+	actual, ok = cache.GetLSVIDBundle(td)
+	assert.True(t, ok)
+	assert.Equal(t, expected, actual)
+
+	// trust bundle documents are cached per trust domain
+	_, ok = cache.GetLSVIDBundle(spiffeid.RequireTrustDomainFromString("domain.test"))
+	assert.False(t, ok)
+
+	// trust bundle document is deleted
+	cache.deleteLSVIDBundles(map[spiffeid.TrustDomain]bool{td: true})
+	_, ok = cache.GetLSVIDBundle(td)
+	assert.False(t, ok)
+}
@@ -0,0 +1,186 @@
+package manager
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/client"
+	"github.com/spiffe/spire/pkg/agent/manager/cache"
+	"github.com/spiffe/spire/pkg/common/lsvid"
+	"github.com/spiffe/spire/pkg/common/rotationutil"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+)
+
+func (m *manager) FetchLSVID(ctx context.Context, spiffeID spiffeid.ID, svid []*x509.Certificate, audience string) (*client.JWTSVID, error) {
+	key, request, err := m.newLSVIDRequest(spiffeID, svid, audience)
+	if err != nil {
+		return nil, err
+	}
+
+	now := m.clk.Now()
+
+	cachedSVID, ok := m.cache.GetLSVID(key)
+	if ok && !rotationutil.JWTSVIDExpiresSoon(cachedSVID, now) {
+		return cachedSVID, nil
+	}
+
+	newSVID, err := m.client.NewLSVID(ctx, request)
+	switch {
+	case err == nil:
+	case cachedSVID == nil:
+		return nil, err
+	case rotationutil.JWTSVIDExpired(cachedSVID, now):
+		return nil, fmt.Errorf("unable to renew LSVID for %q (err=%w)", spiffeID, err)
+	default:
+		m.c.Log.WithError(err).WithField(telemetry.SPIFFEID, spiffeID).Warn("Unable to renew LSVID; returning cached copy")
+		return cachedSVID, nil
+	}
+
+	m.cache.SetLSVID(key, newSVID)
+	return newSVID, nil
+}
+
+func (m *manager) FetchLSVIDBundle(ctx context.Context, td spiffeid.TrustDomain) (*client.JWTSVID, error) {
+	now := m.clk.Now()
+
+	cachedBundle, ok := m.cache.GetLSVIDBundle(td)
+	if ok && !rotationutil.JWTSVIDExpiresSoon(cachedBundle, now) {
+		return cachedBundle, nil
+	}
+
+	newBundle, err := m.client.FetchLSVIDBundle(ctx, td.String())
+	switch {
+	case err == nil:
+	case cachedBundle == nil:
+		return nil, err
+	case rotationutil.JWTSVIDExpired(cachedBundle, now):
+		return nil, fmt.Errorf("unable to renew LSVID trust bundle document for %q (err=%w)", td, err)
+	default:
+		m.c.Log.WithError(err).WithField(telemetry.TrustDomainID, td.IDString()).Warn("Unable to renew LSVID trust bundle document; returning cached copy")
+		return cachedBundle, nil
+	}
+
+	m.cache.SetLSVIDBundle(td, newBundle)
+	return newBundle, nil
+}
+
+func (m *manager) LSVIDRevocations() lsvid.RevocationList {
+	return m.cache.LSVIDRevocations()
+}
+
+// newLSVIDRequest builds the request sent to the server to sign an LSVID for
+// the leaf of the given X509-SVID chain, which proves that the subject key is
+// bound to the X509-SVID. The issuer claims are set by the server.
+func (m *manager) newLSVIDRequest(spiffeID spiffeid.ID, svid []*x509.Certificate, audience string) (cache.LSVIDKey, *client.LSVIDRequest, error) {
+	if len(svid) == 0 {
+		return cache.LSVIDKey{}, nil, errors.New("no X509-SVID to request an LSVID for")
+	}
+
+	subjectKey, err := x509.MarshalPKIXPublicKey(svid[0].PublicKey)
+	if err != nil {
+		return cache.LSVIDKey{}, nil, fmt.Errorf("unable to marshal X509-SVID public key: %w", err)
+	}
+
+	var chain [][]byte
+	for _, cert := range svid {
+		chain = append(chain, cert.Raw)
+	}
+
+	key := cache.LSVIDKey{
+		SPIFFEID:   spiffeID,
+		SubjectKey: string(subjectKey),
+		X5T:        string(lsvid.X509SVIDThumbprint(svid[0])),
+		Audience:   audience,
+	}
+	return key, &client.LSVIDRequest{
+		EntryID:   m.getEntryID(spiffeID.String()),
+		SPIFFEID:  spiffeID.String(),
+		PublicKey: subjectKey,
+		Audience:  audience,
+		X509SVID:  chain,
+	}, nil
+}
+
+func (m *manager) runLSVIDRefresher(ctx context.Context) error {
+	for {
+		select {
+		case <-m.clk.After(m.c.RotationInterval):
+			m.refreshLSVIDs(ctx)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// refreshLSVIDs renews the cached LSVIDs that reached half of their lifetime
+// and were fetched since they were cached. LSVIDs that were not fetched are
+// evicted instead, as are LSVIDs that could not be renewed before expiring.
+//
+// Renewal requests are built from the current X509-SVID of the subject, since
+// the server only binds LSVIDs to valid X509-SVIDs. LSVIDs whose subject no
+// longer holds an X509-SVID for the subject key are evicted.
+func (m *manager) refreshLSVIDs(ctx context.Context) {
+	now := m.clk.Now()
+	for _, cached := range m.cache.LSVIDs() {
+		if !rotationutil.JWTSVIDExpiresSoon(cached.SVID, now) {
+			continue
+		}
+		if !cached.Used {
+			m.cache.DeleteLSVID(cached.Key)
+			continue
+		}
+
+		log := m.c.Log.WithField(telemetry.SPIFFEID, cached.Key.SPIFFEID.String())
+		svid, ok := m.currentX509SVID(cached.Key.SPIFFEID, cached.Key.SubjectKey)
+		if !ok {
+			log.Debug("Evicting LSVID of a subject key without a current X509-SVID")
+			m.cache.DeleteLSVID(cached.Key)
+			continue
+		}
+		key, request, err := m.newLSVIDRequest(cached.Key.SPIFFEID, svid, cached.Key.Audience)
+		if err != nil {
+			log.WithError(err).Warn("Unable to renew LSVID")
+			m.cache.DeleteLSVID(cached.Key)
+			continue
+		}
+
+		newSVID, err := m.client.NewLSVID(ctx, request)
+		if err != nil {
+			log.WithError(err).Warn("Unable to renew LSVID")
+			if rotationutil.JWTSVIDExpired(cached.SVID, now) {
+				m.cache.DeleteLSVID(cached.Key)
+			}
+			continue
+		}
+
+		// The key changes when the X509-SVID of the subject was rotated.
+		if key != cached.Key {
+			m.cache.DeleteLSVID(cached.Key)
+		}
+		m.cache.SetLSVID(key, newSVID)
+		log.Debug("Renewed LSVID")
+	}
+}
+
+// currentX509SVID returns the current X509-SVID chain of the given subject
+// whose public key is the given PKIX, ASN.1 DER encoded key. The subject is
+// either the agent or one of the identities in the cache.
+func (m *manager) currentX509SVID(spiffeID spiffeid.ID, subjectKey string) ([]*x509.Certificate, bool) {
+	chains := [][]*x509.Certificate{m.svid.State().SVID}
+	for _, identity := range m.cache.Identities() {
+		chains = append(chains, identity.SVID)
+	}
+	for _, chain := range chains {
+		if len(chain) == 0 || len(chain[0].URIs) != 1 || chain[0].URIs[0].String() != spiffeID.String() {
+			continue
+		}
+		key, err := x509.MarshalPKIXPublicKey(chain[0].PublicKey)
+		if err == nil && string(key) == subjectKey {
+			return chain, true
+		}
+	}
+	return nil, false
+}
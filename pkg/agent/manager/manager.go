@@ -17,9 +17,10 @@ import (
 	"github.com/spiffe/spire/pkg/agent/manager/storecache"
 	"github.com/spiffe/spire/pkg/agent/svid"
 	"github.com/spiffe/spire/pkg/common/bundleutil"
+	"github.com/spiffe/spire/pkg/common/lsvid"
 	"github.com/spiffe/spire/pkg/common/nodeutil"
-	// "github.com/spiffe/spire/pkg/common/rotationutil"
-	// "github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/common/rotationutil"
+	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/util"
 	"github.com/spiffe/spire/proto/spire/common"
 )
@@ -70,6 +71,24 @@ type Manager interface {
 	// is no JWT cached, the manager will get one signed upstream.
 	FetchJWTSVID(ctx context.Context, spiffeID spiffeid.ID, audience []string) (*client.JWTSVID, error)
 
+	// FetchLSVID returns the root LSVID layer binding the key of the given
+	// X509-SVID to its SPIFFE ID, addressed to the given audience. If there is
+	// no LSVID cached or the cached one reached half of its lifetime, the
+	// manager will get one signed upstream. Cached LSVIDs that are in use are
+	// also renewed in the background.
+	FetchLSVID(ctx context.Context, spiffeID spiffeid.ID, svid []*x509.Certificate, audience string) (*client.JWTSVID, error)
+
+	// FetchLSVIDBundle returns the LSVID trust bundle document of the given
+	// trust domain, signed by the server. If there is no document cached or
+	// the cached one reached half of its lifetime, the manager will get a new
+	// one from the server. Cached documents are dropped when the bundle of
+	// their trust domain changes.
+	FetchLSVIDBundle(ctx context.Context, td spiffeid.TrustDomain) (*client.JWTSVID, error)
+
+	// LSVIDRevocations returns the LSVID revocations received from the
+	// server on the last synchronization.
+	LSVIDRevocations() lsvid.RevocationList
+
 	// CountSVIDs returns the amount of X509 SVIDs on memory
 	CountSVIDs() int
 
@@ -128,6 +147,7 @@ func (m *manager) Run(ctx context.Context) error {
 		m.runSynchronizer,
 		m.runSVIDObserver,
 		m.runBundleObserver,
+		m.runLSVIDRefresher,
 		m.svid.Run)
 
 	switch {
@@ -182,12 +202,26 @@ func (m *manager) FetchWorkloadUpdate(selectors []*common.Selector) *cache.Workl
 }
 
 func (m *manager) FetchJWTSVID(ctx context.Context, spiffeID spiffeid.ID, audience []string) (*client.JWTSVID, error) {
+	now := m.clk.Now()
 
-	newSVID, err := m.client.NewJWTSVID(ctx, "", audience)
-	if err != nil || newSVID == nil {
-		return nil, fmt.Errorf("error with NewJWTSVID function: %w", err)
+	cachedSVID, ok := m.cache.GetJWTSVID(spiffeID, audience)
+	if ok && !rotationutil.JWTSVIDExpiresSoon(cachedSVID, now) {
+		return cachedSVID, nil
+	}
+
+	newSVID, err := m.client.NewJWTSVID(ctx, m.getEntryID(spiffeID.String()), audience)
+	switch {
+	case err == nil:
+	case cachedSVID == nil:
+		return nil, err
+	case rotationutil.JWTSVIDExpired(cachedSVID, now):
+		return nil, fmt.Errorf("unable to renew JWT for %q (err=%w)", spiffeID, err)
+	default:
+		m.c.Log.WithError(err).WithField(telemetry.SPIFFEID, spiffeID).Warn("Unable to renew JWT; returning cached copy")
+		return cachedSVID, nil
 	}
 
+	m.cache.SetJWTSVID(spiffeID, audience, newSVID)
 	return newSVID, nil
 }
 
@@ -28,9 +28,11 @@ import (
 	"github.com/spiffe/spire/pkg/agent/plugin/keymanager"
 	"github.com/spiffe/spire/pkg/common/bundleutil"
 	"github.com/spiffe/spire/pkg/common/idutil"
+	"github.com/spiffe/spire/pkg/common/lsvid"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/pkg/server/api"
+	lsvidv1 "github.com/spiffe/spire/proto/spire/api/server/lsvid/v1"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakeagentcatalog"
@@ -45,6 +47,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 var (
@@ -899,6 +902,339 @@ func TestFetchJWTSVID(t *testing.T) {
 	require.Nil(t, svid)
 }
 
+func TestFetchLSVID(t *testing.T) {
+	dir := spiretest.TempDir(t)
+	km := fakeagentkeymanager.New(t, dir)
+
+	fetchResp := &lsvidv1.NewLSVIDResponse{}
+	var requests []*lsvidv1.NewLSVIDRequest
+
+	clk := clock.NewMock(t)
+	api := newMockAPI(t, &mockAPIConfig{
+		km: km,
+		getAuthorizedEntries: func(*mockAPI, int32, *entryv1.GetAuthorizedEntriesRequest) (*entryv1.GetAuthorizedEntriesResponse, error) {
+			return makeGetAuthorizedEntriesResponse(t, "resp1", "resp2"), nil
+		},
+		batchNewX509SVIDEntries: func(*mockAPI, int32) []*common.RegistrationEntry {
+			return makeBatchNewX509SVIDEntries("resp1", "resp2")
+		},
+		newLSVID: func(_ *mockAPI, req *lsvidv1.NewLSVIDRequest) (*lsvidv1.NewLSVIDResponse, error) {
+			requests = append(requests, req)
+			return fetchResp, nil
+		},
+		clk:     clk,
+		svidTTL: 200,
+	})
+
+	cat := fakeagentcatalog.New()
+	cat.SetKeyManager(km)
+
+	baseSVID, baseSVIDKey := api.newSVID(joinTokenID, 1*time.Hour)
+
+	c := &Config{
+		ServerAddr:       api.addr,
+		SVID:             baseSVID,
+		SVIDKey:          baseSVIDKey,
+		Log:              testLogger,
+		TrustDomain:      trustDomain,
+		SVIDCachePath:    path.Join(dir, "svid.der"),
+		BundleCachePath:  path.Join(dir, "bundle.der"),
+		Bundle:           api.bundle,
+		Metrics:          &telemetry.Blackhole{},
+		Catalog:          cat,
+		Clk:              clk,
+		RotationInterval: time.Second,
+		SVIDStoreCache:   storecache.New(&storecache.Config{TrustDomain: trustDomain, Log: testLogger}),
+	}
+
+	m := newManager(c)
+	require.NoError(t, m.Initialize(context.Background()))
+
+	identities := m.cache.Identities()
+	require.NotEmpty(t, identities)
+	identity := identities[0]
+	spiffeID := spiffeid.RequireFromString(identity.Entry.SpiffeId)
+	workloadSVID := identity.SVID
+	audience := "spiffe://example.org/agent"
+	setResp := func(token string) {
+		now := clk.Now()
+		fetchResp.Lsvid = &lsvidv1.Token{
+			Token:     token,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(time.Minute).Unix(),
+		}
+	}
+
+	// fetch fails without a certificate
+	svid, err := m.FetchLSVID(context.Background(), spiffeID, nil, audience)
+	require.EqualError(t, err, "no X509-SVID to request an LSVID for")
+	require.Nil(t, svid)
+
+	// fetch succeeds, sending the subject key and the X509-SVID chain
+	setResp("A")
+	svid, err = m.FetchLSVID(context.Background(), spiffeID, workloadSVID, audience)
+	require.NoError(t, err)
+	require.Equal(t, "A", svid.Token)
+	require.Len(t, requests, 1)
+	subjectKey, err := x509.MarshalPKIXPublicKey(workloadSVID[0].PublicKey)
+	require.NoError(t, err)
+	require.Equal(t, spiffeID.String(), requests[0].SpiffeId)
+	require.Equal(t, subjectKey, requests[0].PublicKey)
+	require.Equal(t, audience, requests[0].Audience)
+	require.Equal(t, [][]byte{workloadSVID[0].Raw}, requests[0].X509Svid)
+
+	// cached LSVID is returned w/o trying to fetch
+	setResp("B")
+	svid, err = m.FetchLSVID(context.Background(), spiffeID, workloadSVID, audience)
+	require.NoError(t, err)
+	require.Equal(t, "A", svid.Token)
+	require.Len(t, requests, 1)
+
+	// another audience is cached separately
+	svid, err = m.FetchLSVID(context.Background(), spiffeID, workloadSVID, "spiffe://example.org/other")
+	require.NoError(t, err)
+	require.Equal(t, "B", svid.Token)
+	require.Len(t, requests, 2)
+
+	// at half-life, the used LSVID is renewed with the same request while
+	// the unused one is evicted
+	clk.Add(30 * time.Second)
+	setResp("C")
+	m.refreshLSVIDs(context.Background())
+	require.Len(t, requests, 3)
+	spiretest.RequireProtoEqual(t, requests[0], requests[2])
+	cached := m.cache.LSVIDs()
+	require.Len(t, cached, 1)
+	require.Equal(t, "C", cached[0].SVID.Token)
+	require.False(t, cached[0].Used)
+
+	svid, err = m.FetchLSVID(context.Background(), spiffeID, workloadSVID, audience)
+	require.NoError(t, err)
+	require.Equal(t, "C", svid.Token)
+	require.Len(t, requests, 3)
+
+	// once the X509-SVID of the subject is rotated, the LSVID is renewed
+	// with the current X509-SVID rather than with the one it was bound to
+	rotatedSVID := createSVIDWithKey(t, clk, api.ca, api.caKey, spiffeID, time.Hour, identity.PrivateKey)
+	m.cache.UpdateSVIDs(&cache.UpdateSVIDs{X509SVIDs: map[string]*cache.X509SVID{
+		identity.Entry.EntryId: {Chain: rotatedSVID, PrivateKey: identity.PrivateKey},
+	}})
+	clk.Add(30 * time.Second)
+	setResp("D")
+	m.refreshLSVIDs(context.Background())
+	require.Len(t, requests, 4)
+	require.Equal(t, subjectKey, requests[3].PublicKey)
+	require.Equal(t, [][]byte{rotatedSVID[0].Raw}, requests[3].X509Svid)
+	require.Len(t, m.cache.LSVIDs(), 1)
+
+	svid, err = m.FetchLSVID(context.Background(), spiffeID, rotatedSVID, audience)
+	require.NoError(t, err)
+	require.Equal(t, "D", svid.Token)
+	require.Len(t, requests, 4)
+
+	// renewal fails, the cached LSVID is kept until it expires
+	clk.Add(30 * time.Second)
+	fetchResp.Lsvid = nil
+	m.refreshLSVIDs(context.Background())
+	require.Len(t, m.cache.LSVIDs(), 1)
+	_, err = m.FetchLSVID(context.Background(), spiffeID, rotatedSVID, audience)
+	require.NoError(t, err)
+
+	clk.Add(30 * time.Second)
+	m.refreshLSVIDs(context.Background())
+	require.Empty(t, m.cache.LSVIDs())
+
+	// once the subject key is rotated, the LSVID is evicted without being
+	// renewed
+	setResp("E")
+	_, err = m.FetchLSVID(context.Background(), spiffeID, rotatedSVID, audience)
+	require.NoError(t, err)
+	numRequests := len(requests)
+	_, err = m.FetchLSVID(context.Background(), spiffeID, rotatedSVID, audience)
+	require.NoError(t, err)
+	require.Len(t, requests, numRequests)
+
+	newKeySVID, newKey := createSVID(t, km, clk, api.ca, api.caKey, spiffeID, time.Hour)
+	m.cache.UpdateSVIDs(&cache.UpdateSVIDs{X509SVIDs: map[string]*cache.X509SVID{
+		identity.Entry.EntryId: {Chain: newKeySVID, PrivateKey: newKey},
+	}})
+	clk.Add(30 * time.Second)
+	m.refreshLSVIDs(context.Background())
+	require.Len(t, requests, numRequests)
+	require.Empty(t, m.cache.LSVIDs())
+}
+
+func TestFetchLSVIDBundle(t *testing.T) {
+	dir := spiretest.TempDir(t)
+	km := fakeagentkeymanager.New(t, dir)
+
+	var fetchResp *lsvidv1.GetLSVIDBundleResponse
+	var requests []*lsvidv1.GetLSVIDBundleRequest
+
+	clk := clock.NewMock(t)
+	api := newMockAPI(t, &mockAPIConfig{
+		km: km,
+		getAuthorizedEntries: func(*mockAPI, int32, *entryv1.GetAuthorizedEntriesRequest) (*entryv1.GetAuthorizedEntriesResponse, error) {
+			return makeGetAuthorizedEntriesResponse(t, "resp1", "resp2"), nil
+		},
+		batchNewX509SVIDEntries: func(*mockAPI, int32) []*common.RegistrationEntry {
+			return makeBatchNewX509SVIDEntries("resp1", "resp2")
+		},
+		getLSVIDBundle: func(_ *mockAPI, req *lsvidv1.GetLSVIDBundleRequest) (*lsvidv1.GetLSVIDBundleResponse, error) {
+			requests = append(requests, req)
+			if fetchResp == nil {
+				return nil, errors.New("no trust bundle document")
+			}
+			return fetchResp, nil
+		},
+		clk:     clk,
+		svidTTL: 200,
+	})
+
+	cat := fakeagentcatalog.New()
+	cat.SetKeyManager(km)
+
+	baseSVID, baseSVIDKey := api.newSVID(joinTokenID, 1*time.Hour)
+
+	c := &Config{
+		ServerAddr:       api.addr,
+		SVID:             baseSVID,
+		SVIDKey:          baseSVIDKey,
+		Log:              testLogger,
+		TrustDomain:      trustDomain,
+		SVIDCachePath:    path.Join(dir, "svid.der"),
+		BundleCachePath:  path.Join(dir, "bundle.der"),
+		Bundle:           api.bundle,
+		Metrics:          &telemetry.Blackhole{},
+		Catalog:          cat,
+		Clk:              clk,
+		RotationInterval: time.Second,
+		SVIDStoreCache:   storecache.New(&storecache.Config{TrustDomain: trustDomain, Log: testLogger}),
+	}
+
+	m := newManager(c)
+	require.NoError(t, m.Initialize(context.Background()))
+
+	setResp := func(bundle string) {
+		now := clk.Now()
+		fetchResp = &lsvidv1.GetLSVIDBundleResponse{
+			Bundle:    bundle,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(time.Minute).Unix(),
+		}
+	}
+
+	// fetch fails with nothing cached
+	bundle, err := m.FetchLSVIDBundle(context.Background(), trustDomain)
+	require.Error(t, err)
+	require.Nil(t, bundle)
+
+	// fetch succeeds
+	setResp("A")
+	bundle, err = m.FetchLSVIDBundle(context.Background(), trustDomain)
+	require.NoError(t, err)
+	require.Equal(t, "A", bundle.Token)
+	require.Len(t, requests, 2)
+	require.Equal(t, trustDomain.String(), requests[1].TrustDomain)
+
+	// cached document is returned w/o trying to fetch
+	bundle, err = m.FetchLSVIDBundle(context.Background(), trustDomain)
+	require.NoError(t, err)
+	require.Equal(t, "A", bundle.Token)
+	require.Len(t, requests, 2)
+
+	// another trust domain is cached separately
+	federatedTD := spiffeid.RequireTrustDomainFromString("domain.test")
+	setResp("B")
+	bundle, err = m.FetchLSVIDBundle(context.Background(), federatedTD)
+	require.NoError(t, err)
+	require.Equal(t, "B", bundle.Token)
+	require.Len(t, requests, 3)
+	require.Equal(t, "domain.test", requests[2].TrustDomain)
+
+	// at half-life, the document is renewed
+	clk.Add(30 * time.Second)
+	setResp("B")
+	bundle, err = m.FetchLSVIDBundle(context.Background(), trustDomain)
+	require.NoError(t, err)
+	require.Equal(t, "B", bundle.Token)
+	require.Len(t, requests, 4)
+
+	// renewal fails, the cached document is returned until it expires
+	clk.Add(30 * time.Second)
+	fetchResp = nil
+	bundle, err = m.FetchLSVIDBundle(context.Background(), trustDomain)
+	require.NoError(t, err)
+	require.Equal(t, "B", bundle.Token)
+
+	clk.Add(31 * time.Second)
+	bundle, err = m.FetchLSVIDBundle(context.Background(), trustDomain)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unable to renew LSVID trust bundle document")
+	require.Nil(t, bundle)
+}
+
+func TestSyncLSVIDRevocations(t *testing.T) {
+	dir := spiretest.TempDir(t)
+	km := fakeagentkeymanager.New(t, dir)
+
+	clk := clock.NewMock(t)
+	api := newMockAPI(t, &mockAPIConfig{
+		km: km,
+		getAuthorizedEntries: func(*mockAPI, int32, *entryv1.GetAuthorizedEntriesRequest) (*entryv1.GetAuthorizedEntriesResponse, error) {
+			return makeGetAuthorizedEntriesResponse(t, "resp1", "resp2"), nil
+		},
+		batchNewX509SVIDEntries: func(*mockAPI, int32) []*common.RegistrationEntry {
+			return makeBatchNewX509SVIDEntries("resp1", "resp2")
+		},
+		lsvidRevocations: []*lsvidv1.Revocation{
+			{Kind: "SPIFFE_ID", Value: "spiffe://example.org/revoked"},
+		},
+		clk:     clk,
+		svidTTL: 200,
+	})
+
+	cat := fakeagentcatalog.New()
+	cat.SetKeyManager(km)
+
+	baseSVID, baseSVIDKey := api.newSVID(joinTokenID, 1*time.Hour)
+
+	c := &Config{
+		ServerAddr:       api.addr,
+		SVID:             baseSVID,
+		SVIDKey:          baseSVIDKey,
+		Log:              testLogger,
+		TrustDomain:      trustDomain,
+		SVIDCachePath:    path.Join(dir, "svid.der"),
+		BundleCachePath:  path.Join(dir, "bundle.der"),
+		Bundle:           api.bundle,
+		Metrics:          &telemetry.Blackhole{},
+		Catalog:          cat,
+		Clk:              clk,
+		RotationInterval: time.Second,
+		SVIDStoreCache:   storecache.New(&storecache.Config{TrustDomain: trustDomain, Log: testLogger}),
+	}
+
+	m := newManager(c)
+	require.NoError(t, m.Initialize(context.Background()))
+	revocations := lsvid.NewRevocationList(lsvid.Revocation{
+		Kind:  lsvid.RevokeSPIFFEID,
+		Value: "spiffe://example.org/revoked",
+	})
+	require.Equal(t, revocations, m.LSVIDRevocations())
+
+	// the last known revocations are kept when they can't be fetched
+	api.c.lsvidRevocationsErr = status.Error(codes.Unavailable, "unavailable")
+	require.NoError(t, m.synchronize(context.Background()))
+	require.Equal(t, revocations, m.LSVIDRevocations())
+	api.c.lsvidRevocationsErr = nil
+
+	// revocations deleted on the server are dropped on the next sync
+	api.c.lsvidRevocations = nil
+	require.NoError(t, m.synchronize(context.Background()))
+	require.Empty(t, m.LSVIDRevocations())
+}
+
 func TestStorableSVIDsSync(t *testing.T) {
 	dir := spiretest.TempDir(t)
 	km := fakeagentkeymanager.New(t, dir)
@@ -1064,6 +1400,10 @@ type mockAPIConfig struct {
 	getAuthorizedEntries    func(api *mockAPI, count int32, req *entryv1.GetAuthorizedEntriesRequest) (*entryv1.GetAuthorizedEntriesResponse, error)
 	batchNewX509SVIDEntries func(api *mockAPI, count int32) []*common.RegistrationEntry
 	newJWTSVID              func(api *mockAPI, req *svidv1.NewJWTSVIDRequest) (*svidv1.NewJWTSVIDResponse, error)
+	newLSVID                func(api *mockAPI, req *lsvidv1.NewLSVIDRequest) (*lsvidv1.NewLSVIDResponse, error)
+	getLSVIDBundle          func(api *mockAPI, req *lsvidv1.GetLSVIDBundleRequest) (*lsvidv1.GetLSVIDBundleResponse, error)
+	lsvidRevocations        []*lsvidv1.Revocation
+	lsvidRevocationsErr     error
 
 	svidTTL int
 	clk     clock.Clock
@@ -1094,6 +1434,7 @@ type mockAPI struct {
 	bundlev1.UnimplementedBundleServer
 	entryv1.UnimplementedEntryServer
 	svidv1.UnimplementedSVIDServer
+	lsvidv1.UnimplementedLSVIDServer
 }
 
 func newMockAPI(t *testing.T, config *mockAPIConfig) *mockAPI {
@@ -1120,6 +1461,7 @@ func newMockAPI(t *testing.T, config *mockAPIConfig) *mockAPI {
 	bundlev1.RegisterBundleServer(server, h)
 	entryv1.RegisterEntryServer(server, h)
 	svidv1.RegisterSVIDServer(server, h)
+	lsvidv1.RegisterLSVIDServer(server, h)
 
 	listener, err := net.Listen("tcp", "localhost:")
 	require.NoError(t, err)
@@ -1200,6 +1542,27 @@ func (h *mockAPI) NewJWTSVID(ctx context.Context, req *svidv1.NewJWTSVIDRequest)
 	return nil, errors.New("no FetchJWTSVID implementation for test")
 }
 
+func (h *mockAPI) NewLSVID(ctx context.Context, req *lsvidv1.NewLSVIDRequest) (*lsvidv1.NewLSVIDResponse, error) {
+	if h.c.newLSVID != nil {
+		return h.c.newLSVID(h, req)
+	}
+	return nil, errors.New("no NewLSVID implementation for test")
+}
+
+func (h *mockAPI) GetLSVIDBundle(ctx context.Context, req *lsvidv1.GetLSVIDBundleRequest) (*lsvidv1.GetLSVIDBundleResponse, error) {
+	if h.c.getLSVIDBundle != nil {
+		return h.c.getLSVIDBundle(h, req)
+	}
+	return nil, errors.New("no GetLSVIDBundle implementation for test")
+}
+
+func (h *mockAPI) ListLSVIDRevocations(ctx context.Context, req *lsvidv1.ListLSVIDRevocationsRequest) (*lsvidv1.ListLSVIDRevocationsResponse, error) {
+	if h.c.lsvidRevocationsErr != nil {
+		return nil, h.c.lsvidRevocationsErr
+	}
+	return &lsvidv1.ListLSVIDRevocationsResponse{Revocations: h.c.lsvidRevocations}, nil
+}
+
 func (h *mockAPI) GetBundle(ctx context.Context, req *bundlev1.GetBundleRequest) (*types.Bundle, error) {
 	return api.BundleToProto(h.bundle.Proto())
 }
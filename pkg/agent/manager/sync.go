@@ -200,6 +200,9 @@ func (m *manager) fetchEntries(ctx context.Context) (_ *cache.UpdateEntries, _ *
 	if err != nil {
 		return nil, nil, err
 	}
+	if update.LSVIDRevocations != nil {
+		m.cache.SetLSVIDRevocations(update.LSVIDRevocations)
+	}
 
 	bundles, err := parseBundles(update.Bundles)
 	if err != nil {
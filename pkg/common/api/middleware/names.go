@@ -13,20 +13,23 @@ import (
 const (
 	serverAPIPrefix = "spire.api.server."
 
-	WorkloadAPIServiceName      = "SpiffeWorkloadAPI"
-	WorkloadAPIServiceShortName = "WorkloadAPI"
-	EnvoySDSv2ServiceName       = "envoy.service.discovery.v2.SecretDiscoveryService"
-	EnvoySDSv2ServiceShortName  = "SDS.v2"
-	EnvoySDSv3ServiceName       = "envoy.service.secret.v3.SecretDiscoveryService"
-	EnvoySDSv3ServiceShortName  = "SDS.v3"
-	HealthServiceName           = "grpc.health.v1.Health"
-	HealthServiceShortName      = "Health"
+	WorkloadAPIServiceName           = "SpiffeWorkloadAPI"
+	WorkloadAPIServiceShortName      = "WorkloadAPI"
+	LSVIDWorkloadAPIServiceName      = "spire.api.workload.lsvid.v1.LSVIDWorkloadAPI"
+	LSVIDWorkloadAPIServiceShortName = "LSVIDWorkloadAPI"
+	EnvoySDSv2ServiceName            = "envoy.service.discovery.v2.SecretDiscoveryService"
+	EnvoySDSv2ServiceShortName       = "SDS.v2"
+	EnvoySDSv3ServiceName            = "envoy.service.secret.v3.SecretDiscoveryService"
+	EnvoySDSv3ServiceShortName       = "SDS.v3"
+	HealthServiceName                = "grpc.health.v1.Health"
+	HealthServiceShortName           = "Health"
 )
 
 var (
 	serviceReplacer = strings.NewReplacer(
 		serverAPIPrefix, "",
 		WorkloadAPIServiceName, WorkloadAPIServiceShortName,
+		LSVIDWorkloadAPIServiceName, LSVIDWorkloadAPIServiceShortName,
 		EnvoySDSv2ServiceName, EnvoySDSv2ServiceShortName,
 		EnvoySDSv3ServiceName, EnvoySDSv3ServiceShortName,
 		HealthServiceName, HealthServiceShortName,
@@ -0,0 +1,146 @@
+package lsvid
+
+import (
+	"crypto"
+	"crypto/sha256"
+
+	"github.com/zeebo/errs"
+)
+
+// This file implements the half-aggregation of Schnorr signatures across
+// consecutive layers, in the manner of the half-aggregation of BIP 340
+// signatures. See saeddsa.go for the group and challenge of SAEdDSA, the only
+// aggregating algorithm.
+//
+// A run is a sequence of consecutive layers signed with the same aggregating
+// algorithm, from the innermost one, which extends a layer signed with another
+// algorithm or is the root layer, to the outermost one. Layer j of a run,
+// signed with the key x_j, public key A_j = x_j*G, over the signing input
+// whose SHA-256 digest is d_j, carries the nonce commitment R_j = k_j*G of
+// its Schnorr signature (R_j, s_j), where
+//
+//	s_j = k_j + c_j*x_j
+//
+// and c_j is the challenge of the algorithm. Instead of s_j, the outermost
+// layer of the run carries the aggregate S = sum(z_j*s_j) along with the chain
+// hash h_t, where
+//
+//	h_0 = 0^32
+//	h_j = H("LSVID/<alg>/aggregate", h_(j-1) || R_j || A_j || d_j)
+//	z_1 = 1, z_j = h_j for j > 1
+//
+// so that the run verifies with the single equation
+//
+//	S*G = sum(z_j*R_j + z_j*c_j*A_j)
+//
+// which is checked with one multi-scalar multiplication of 2t+1 terms for a
+// run of t layers, rather than with a signature verification per layer.
+//
+// The chain hash lets the signer of a new layer fold its signature into the
+// aggregate of the layer it extends without resolving the keys of the layers
+// of the run. Verifiers recompute it from the layers.
+
+const (
+	aggregateScalarSize = 32
+	aggregateSize       = 2 * aggregateScalarSize
+)
+
+// aggregateScheme is an aggregating signature algorithm.
+type aggregateScheme interface {
+	// sign signs the layer and folds the signature into prev, the aggregate
+	// of the run the layer extends, or nil if the layer starts a new run. It
+	// sets the nonce commitment and the new aggregate on the layer.
+	sign(token *Token, prev []byte, key crypto.Signer) error
+
+	// verify verifies the aggregate signature of the run.
+	verify(run *aggregateRun) error
+}
+
+var aggregateSchemes = map[string]aggregateScheme{
+	AlgSAEdDSA: saEdDSA{},
+}
+
+// isAggregateAlg returns true if the algorithm aggregates the signatures of
+// consecutive layers.
+func isAggregateAlg(alg string) bool {
+	_, ok := aggregateSchemes[alg]
+	return ok
+}
+
+// extendsRun returns true if the layer extends a layer signed with the same
+// aggregating algorithm, i.e. is not the innermost layer of its run.
+func extendsRun(layer *Token) bool {
+	return layer.Payload != nil && isAggregateAlg(layer.Payload.Alg) &&
+		layer.Nested != nil && layer.Nested.Payload != nil && layer.Nested.Payload.Alg == layer.Payload.Alg
+}
+
+// signAggregate signs the layer with its aggregating algorithm. If the layer
+// extends a layer of the same algorithm, the aggregate signature moves from
+// that layer to the new one.
+func signAggregate(token *Token, key crypto.Signer) (*Token, error) {
+	alg := token.Payload.Alg
+	var prev []byte
+	if extendsRun(token) {
+		nested := token.Nested
+		if nested.Aggregate == nil {
+			return nil, errs.New("extended %s layer missing aggregate signature", alg)
+		}
+		prev = nested.Aggregate
+		stripped := *nested
+		stripped.Aggregate = nil
+		token.Nested = &stripped
+	}
+	if err := aggregateSchemes[alg].sign(token, prev, key); err != nil {
+		return nil, errs.New("unable to sign LSVID: %v", err)
+	}
+	return token, nil
+}
+
+// aggregateRun collects the layers of a run, from the outermost one, along
+// with the keys of their issuers.
+type aggregateRun struct {
+	alg    string
+	layers []*Token
+	keys   []crypto.PublicKey
+}
+
+func newAggregateRun(alg string) *aggregateRun {
+	return &aggregateRun{alg: alg}
+}
+
+func (r *aggregateRun) add(layer *Token, key crypto.PublicKey) {
+	r.layers = append(r.layers, layer)
+	r.keys = append(r.keys, key)
+}
+
+// verify verifies the aggregate signature of the run.
+func (r *aggregateRun) verify() error {
+	return aggregateSchemes[r.alg].verify(r)
+}
+
+// splitAggregate splits the aggregate into its scalar and its chain hash.
+func splitAggregate(aggregate []byte) ([]byte, []byte, error) {
+	if len(aggregate) != aggregateSize {
+		return nil, nil, errs.New("aggregate signature must be %d bytes long", aggregateSize)
+	}
+	return aggregate[:aggregateScalarSize], append([]byte(nil), aggregate[aggregateScalarSize:]...), nil
+}
+
+// aggregateHash returns the chain hash h_j from h_(j-1), and R_j, A_j and d_j
+// as encoded by the algorithm.
+func aggregateHash(alg string, prev, r, a, d []byte) []byte {
+	return taggedHash("LSVID/"+alg+"/aggregate", prev, r, a, d)
+}
+
+// taggedHash returns SHA-256(SHA-256(tag) || SHA-256(tag) || data...), as
+// the tagged hashes of BIP 340.
+func taggedHash(tag string, data ...[]byte) []byte {
+	tagHash := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	_, _ = h.Write(tagHash[:])
+	_, _ = h.Write(tagHash[:])
+	for _, b := range data {
+		_, _ = h.Write(b)
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,347 @@
+package lsvid
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregate(t *testing.T) {
+	rootKey := newEd25519Key(t)
+	token, _ := signHops(t, rootKey, AlgSAEdDSA, AlgSAEdDSA, 4)
+	require.Equal(t, 5, token.Depth())
+	require.NoError(t, Validate(token))
+
+	// Only the outermost layer carries the aggregate, and every layer only
+	// carries its nonce commitment.
+	require.Len(t, token.Aggregate, aggregateSize)
+	for layer := token; layer != nil; layer = layer.Nested {
+		require.Len(t, layer.Signature, 32)
+		if layer != token {
+			require.Nil(t, layer.Aggregate)
+		}
+	}
+
+	encoded, err := Encode(token)
+	require.NoError(t, err)
+	decoded, err := Decode(encoded)
+	require.NoError(t, err)
+	require.NoError(t, Validate(decoded))
+
+	// The aggregate signature covers every layer of the run.
+	decoded.Nested.Nested.Payload.Iat--
+	var verr *ValidationError
+	require.True(t, errors.As(Validate(decoded), &verr))
+	require.Equal(t, ReasonBadSignature, verr.Reason)
+	require.Equal(t, 5, verr.Depth)
+}
+
+func TestAggregateRuns(t *testing.T) {
+	rootKey := newEd25519Key(t)
+
+	// SAEdDSA layers extending an EdDSA root.
+	token, _ := signHops(t, rootKey, AlgEdDSA, AlgSAEdDSA, 2)
+	require.NoError(t, Validate(token))
+	require.Nil(t, token.Root().Aggregate)
+
+	// An EdDSA layer extending a run keeps its aggregate, and covers it.
+	token, _ = signHops(t, rootKey, AlgSAEdDSA, AlgSAEdDSA, 1)
+	extended := extendHop(t, rootKey, token, AlgEdDSA, 1)
+	require.NotNil(t, extended.Nested.Aggregate)
+	require.NoError(t, Validate(extended))
+	extended.Nested.Aggregate = nil
+	require.Equal(t, ReasonBadSignature, ReasonOf(Validate(extended)))
+
+	// Runs are separated by layers signed with other algorithms.
+	token, _ = signHops(t, rootKey, AlgSAEdDSA, AlgEdDSA, 1)
+	extended = extendHop(t, rootKey, token, AlgSAEdDSA, 1)
+	require.NotNil(t, extended.Aggregate)
+	require.NotNil(t, extended.Nested.Nested.Aggregate)
+	require.NoError(t, Validate(extended))
+
+	// Extending a run does not modify the extended token.
+	token, _ = signHops(t, rootKey, AlgSAEdDSA, AlgSAEdDSA, 1)
+	extended = extendHop(t, rootKey, token, AlgSAEdDSA, 1)
+	require.Nil(t, extended.Nested.Aggregate)
+	require.NotNil(t, token.Aggregate)
+	require.NoError(t, Validate(extended))
+	require.NoError(t, Validate(token))
+}
+
+func TestAggregateFailures(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		modify func(token *Token)
+		reason Reason
+		depth  int
+		err    string
+	}{
+		{
+			name:   "missing aggregate",
+			modify: func(token *Token) { token.Aggregate = nil },
+			reason: ReasonBadSignature,
+			depth:  3,
+			err:    `layer issued by "spiffe://example.org/hop/1" missing aggregate signature`,
+		},
+		{
+			name:   "aggregate on an inner layer",
+			modify: func(token *Token) { token.Nested.Aggregate = token.Aggregate },
+			reason: ReasonMalformed,
+			depth:  2,
+			err:    `layer issued by "spiffe://example.org/hop/0" carries the aggregate signature of an outer layer`,
+		},
+		{
+			name: "tampered aggregate",
+			modify: func(token *Token) {
+				token.Aggregate = append([]byte(nil), token.Aggregate...)
+				token.Aggregate[0] ^= 1
+			},
+			reason: ReasonBadSignature,
+			depth:  3,
+			err:    `invalid aggregate signature on layer issued by "spiffe://example.org/hop/1": aggregate signature verification failed`,
+		},
+		{
+			name: "tampered chain hash",
+			modify: func(token *Token) {
+				token.Aggregate = append([]byte(nil), token.Aggregate...)
+				token.Aggregate[aggregateSize-1] ^= 1
+			},
+			reason: ReasonBadSignature,
+			depth:  3,
+			err:    `invalid aggregate signature on layer issued by "spiffe://example.org/hop/1": aggregate chain hash does not match the layers`,
+		},
+		{
+			name:   "truncated aggregate",
+			modify: func(token *Token) { token.Aggregate = token.Aggregate[:aggregateScalarSize] },
+			reason: ReasonBadSignature,
+			depth:  3,
+			err:    `invalid aggregate signature on layer issued by "spiffe://example.org/hop/1": aggregate signature must be 64 bytes long`,
+		},
+		{
+			name:   "malformed nonce commitment",
+			modify: func(token *Token) { token.Nested.Signature = make([]byte, 33) },
+			reason: ReasonBadSignature,
+			depth:  3,
+			err:    `invalid aggregate signature on layer issued by "spiffe://example.org/hop/1": malformed SAEdDSA nonce commitment`,
+		},
+		{
+			name: "swapped nonce commitments",
+			modify: func(token *Token) {
+				token.Signature, token.Nested.Signature = token.Nested.Signature, token.Signature
+			},
+			reason: ReasonBadSignature,
+			depth:  3,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			token, _ := signHops(t, newEd25519Key(t), AlgSAEdDSA, AlgSAEdDSA, 2)
+			tt.modify(token)
+			err := Validate(token)
+			var verr *ValidationError
+			require.True(t, errors.As(err, &verr))
+			require.Equal(t, tt.reason, verr.Reason)
+			require.Equal(t, tt.depth, verr.Depth)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+			}
+		})
+	}
+
+	t.Run("aggregate on a layer of another algorithm", func(t *testing.T) {
+		token, _ := signHops(t, newEd25519Key(t), AlgEdDSA, AlgEdDSA, 1)
+		token.Aggregate = make([]byte, aggregateSize)
+		err := Validate(token)
+		require.Equal(t, ReasonMalformed, ReasonOf(err))
+		require.EqualError(t, err, `layer issued by "spiffe://example.org/hop/0" carries an aggregate signature but is not signed with SAEdDSA`)
+	})
+}
+
+func TestAggregateSignFailures(t *testing.T) {
+	key := newKey(t)
+	_, err := Sign(&Payload{
+		Ver: Version,
+		Alg: AlgSAEdDSA,
+		Exp: time.Now().Add(time.Hour).Unix(),
+		Iss: &IDClaim{CN: tdID, PK: marshalKey(t, key)},
+		Sub: &IDClaim{CN: workloadID, PK: marshalKey(t, newKey(t))},
+		Aud: &IDClaim{CN: agentID},
+	}, key)
+	require.EqualError(t, err, `signature algorithm "SAEdDSA" does not match ES256 key`)
+
+	edKey := newEd25519Key(t)
+	token, _ := signHops(t, edKey, AlgSAEdDSA, AlgSAEdDSA, 0)
+	token.Aggregate = nil
+	_, err = Extend(token, &Payload{
+		Ver: Version,
+		Alg: AlgSAEdDSA,
+		Iss: &IDClaim{CN: workloadID},
+		Aud: &IDClaim{CN: serviceID},
+	}, newEd25519Key(t))
+	require.EqualError(t, err, "extended SAEdDSA layer missing aggregate signature")
+
+	// COSE layers cannot be signed with SAEdDSA.
+	_, err = Sign(&Payload{
+		Ver: Version,
+		Typ: TypCOSE,
+		Alg: AlgSAEdDSA,
+		Exp: time.Now().Add(time.Hour).Unix(),
+		Iss: &IDClaim{CN: tdID, PK: marshalKey(t, edKey)},
+		Sub: &IDClaim{CN: workloadID, PK: marshalKey(t, newKey(t))},
+		Aud: &IDClaim{CN: agentID},
+	}, edKey)
+	require.EqualError(t, err, `unable to sign LSVID: unsupported signature algorithm "SAEdDSA"`)
+}
+
+func TestAggregateVerifySignature(t *testing.T) {
+	rootKey := newEd25519Key(t)
+
+	// A run of a single layer is a plain Schnorr signature.
+	token, _ := signHops(t, rootKey, AlgSAEdDSA, AlgSAEdDSA, 0)
+	require.NoError(t, VerifySignature(token, rootKey.Public()))
+	// The chain hash commits to the keys of the issuers.
+	require.EqualError(t, VerifySignature(token, newEd25519Key(t).Public()), "aggregate chain hash does not match the layers")
+
+	token, hopKeys := signHops(t, rootKey, AlgSAEdDSA, AlgSAEdDSA, 1)
+	require.EqualError(t, VerifySignature(token, hopKeys[0].Public()), "SAEdDSA layer is verified with the aggregate signature of its run")
+}
+
+func TestAggregateIsSmaller(t *testing.T) {
+	rootKey := newEd25519Key(t)
+	for _, hops := range []int{4, 16} {
+		plain, _ := signHops(t, rootKey, AlgEdDSA, AlgEdDSA, hops)
+		aggregate, _ := signHops(t, rootKey, AlgSAEdDSA, AlgSAEdDSA, hops)
+		require.Less(t, signatureBytes(aggregate), signatureBytes(plain))
+	}
+}
+
+func BenchmarkAggregate(b *testing.B) {
+	for _, hops := range []int{1, 4, 16, 64} {
+		for _, alg := range []string{AlgES256, AlgEdDSA, AlgSAEdDSA} {
+			token, _ := signHops(b, newHopKey(b, alg), alg, alg, hops)
+			encoded, err := Encode(token)
+			require.NoError(b, err)
+			b.Run(fmt.Sprintf("%s/%d", alg, hops+1), func(b *testing.B) {
+				b.ReportMetric(float64(len(encoded)), "bytes")
+				b.ReportMetric(float64(signatureBytes(token)), "signature-bytes")
+				for i := 0; i < b.N; i++ {
+					require.NoError(b, Validate(token))
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkAggregateSignatures measures the verification of the signatures of
+// the chain alone: one verification per layer for plain algorithms, and one
+// multi-scalar multiplication for aggregating ones.
+func BenchmarkAggregateSignatures(b *testing.B) {
+	for _, hops := range []int{1, 4, 16, 64} {
+		for _, alg := range []string{AlgES256, AlgEdDSA, AlgSAEdDSA} {
+			rootKey := newHopKey(b, alg)
+			token, hopKeys := signHops(b, rootKey, alg, alg, hops)
+			keys := []crypto.PublicKey{rootKey.Public()}
+			for _, key := range hopKeys {
+				keys = append([]crypto.PublicKey{key.Public()}, keys...)
+			}
+			b.Run(fmt.Sprintf("%s/%d", alg, hops+1), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if isAggregateAlg(alg) {
+						run := newAggregateRun(alg)
+						for layer, j := token, 0; layer != nil; layer, j = layer.Nested, j+1 {
+							run.add(layer, keys[j])
+						}
+						require.NoError(b, run.verify())
+						continue
+					}
+					for layer, j := token, 0; layer != nil; layer, j = layer.Nested, j+1 {
+						require.NoError(b, VerifySignature(layer, keys[j]))
+					}
+				}
+			})
+		}
+	}
+}
+
+// signHops signs a workload LSVID with rootAlg and extends it the given number
+// of times with hopAlg, each hop proving its identity with its own LSVID. It
+// returns the token and the keys of the hops.
+func signHops(tb testing.TB, rootKey crypto.Signer, rootAlg, hopAlg string, hops int) (*Token, []crypto.Signer) {
+	now := time.Now()
+	sign := func(alg string, subjectKey crypto.Signer, subject, audience string) *Token {
+		token, err := Sign(&Payload{
+			Ver: Version,
+			Alg: alg,
+			Iat: now.Unix(),
+			Exp: now.Add(time.Hour).Unix(),
+			Iss: &IDClaim{CN: tdID, PK: marshalKey(tb, rootKey)},
+			Sub: &IDClaim{CN: subject, PK: marshalKey(tb, subjectKey)},
+			Aud: &IDClaim{CN: audience},
+		}, rootKey)
+		require.NoError(tb, err)
+		return token
+	}
+
+	token := sign(rootAlg, newKey(tb), workloadID, hopID(0))
+	var keys []crypto.Signer
+	for i := 0; i < hops; i++ {
+		key := newHopKey(tb, hopAlg)
+		token = extendHopWithKey(tb, rootKey, token, hopAlg, i, key)
+		keys = append(keys, key)
+	}
+	return token, keys
+}
+
+// extendHop extends the token on behalf of the given hop with a new key.
+func extendHop(tb testing.TB, rootKey crypto.Signer, token *Token, alg string, hop int) *Token {
+	return extendHopWithKey(tb, rootKey, token, alg, hop, newHopKey(tb, alg))
+}
+
+// newHopKey returns a new key fitting the signature algorithm of a hop.
+func newHopKey(tb testing.TB, alg string) crypto.Signer {
+	if alg == AlgEdDSA || alg == AlgSAEdDSA {
+		return newEd25519Key(tb)
+	}
+	return newKey(tb)
+}
+
+func extendHopWithKey(tb testing.TB, rootKey crypto.Signer, token *Token, alg string, hop int, key crypto.Signer) *Token {
+	now := time.Now()
+	id, err := Sign(&Payload{
+		Ver: Version,
+		Iat: now.Unix(),
+		Exp: now.Add(time.Hour).Unix(),
+		Iss: &IDClaim{CN: tdID, PK: marshalKey(tb, rootKey)},
+		Sub: &IDClaim{CN: hopID(hop), PK: marshalKey(tb, key)},
+		Aud: &IDClaim{CN: hopID(hop)},
+	}, rootKey)
+	require.NoError(tb, err)
+
+	extended, err := Extend(token, &Payload{
+		Ver: Version,
+		Alg: alg,
+		Iat: now.Unix(),
+		Iss: &IDClaim{CN: hopID(hop), ID: id},
+		Aud: &IDClaim{CN: hopID(hop + 1)},
+	}, key)
+	require.NoError(tb, err)
+	return extended
+}
+
+func hopID(i int) string {
+	return fmt.Sprintf("%s/hop/%d", tdID, i)
+}
+
+// signatureBytes returns the size of the signatures of the chain, excluding
+// those of the LSVIDs of the issuers.
+func signatureBytes(token *Token) int {
+	size := 0
+	for layer := token; layer != nil; layer = layer.Nested {
+		size += len(layer.Signature) + len(layer.Aggregate)
+	}
+	return size
+}
@@ -0,0 +1,154 @@
+package lsvid
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha512" // registers SHA-384 and SHA-512
+
+	"github.com/zeebo/errs"
+)
+
+const (
+	// AlgES256 identifies ECDSA P-256 signatures over SHA-256.
+	AlgES256 = "ES256"
+
+	// AlgES384 identifies ECDSA P-384 signatures over SHA-384.
+	AlgES384 = "ES384"
+
+	// AlgES512 identifies ECDSA P-521 signatures over SHA-512.
+	AlgES512 = "ES512"
+
+	// AlgRS256 identifies RSASSA-PKCS1-v1_5 signatures over SHA-256.
+	AlgRS256 = "RS256"
+
+	// AlgPS256 identifies RSASSA-PSS signatures over SHA-256.
+	AlgPS256 = "PS256"
+
+	// AlgEdDSA identifies Ed25519 signatures.
+	AlgEdDSA = "EdDSA"
+
+	// AlgSAEdDSA identifies Ed25519 signatures aggregated across consecutive
+	// layers. It is never chosen by default.
+	AlgSAEdDSA = "SAEdDSA"
+)
+
+// AlgorithmForKey returns the signature algorithm used by default with the
+// given public key. RSA keys default to RS256; PS256 must be requested
+// explicitly through the alg claim.
+func AlgorithmForKey(key crypto.PublicKey) (string, error) {
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		switch key.Params().BitSize {
+		case 256:
+			return AlgES256, nil
+		case 384:
+			return AlgES384, nil
+		case 521:
+			return AlgES512, nil
+		default:
+			return "", errs.New("unable to determine signature algorithm for EC public key size %d", key.Params().BitSize)
+		}
+	case *rsa.PublicKey:
+		// Prevent the use of keys smaller than 2048 bits
+		if key.Size() < 256 {
+			return "", errs.New("unsupported RSA key size: %d", key.Size())
+		}
+		return AlgRS256, nil
+	case ed25519.PublicKey:
+		return AlgEdDSA, nil
+	default:
+		return "", errs.New("unable to determine signature algorithm for public key type %T", key)
+	}
+}
+
+// CheckAlgorithm verifies that the signature algorithm can be used with the
+// key.
+func CheckAlgorithm(alg string, key crypto.PublicKey) error {
+	return checkAlgorithm(alg, key)
+}
+
+// checkAlgorithm verifies that the algorithm can be used with the key.
+func checkAlgorithm(alg string, key crypto.PublicKey) error {
+	if alg == "" {
+		return errs.New("missing signature algorithm")
+	}
+	expected, err := AlgorithmForKey(key)
+	if err != nil {
+		return err
+	}
+	if alg == expected || (alg == AlgPS256 && expected == AlgRS256) || (alg == AlgSAEdDSA && expected == AlgEdDSA) {
+		return nil
+	}
+	return errs.New("signature algorithm %q does not match %s key", alg, expected)
+}
+
+// signInput signs the signing input with the given algorithm. Signatures
+// other than EdDSA are computed over the digest of the input.
+func signInput(alg string, key crypto.Signer, input []byte) ([]byte, error) {
+	hash, opts, err := signerOpts(alg)
+	if err != nil {
+		return nil, err
+	}
+	return key.Sign(rand.Reader, digest(hash, input), opts)
+}
+
+// verifyInput verifies the signature over the signing input. The algorithm
+// must have been checked against the key with checkAlgorithm.
+func verifyInput(alg string, key crypto.PublicKey, input, signature []byte) error {
+	hash, opts, err := signerOpts(alg)
+	if err != nil {
+		return err
+	}
+	var ok bool
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		ok = ecdsa.VerifyASN1(key, digest(hash, input), signature)
+	case *rsa.PublicKey:
+		if pssOpts, isPSS := opts.(*rsa.PSSOptions); isPSS {
+			ok = rsa.VerifyPSS(key, hash, digest(hash, input), signature, pssOpts) == nil
+		} else {
+			ok = rsa.VerifyPKCS1v15(key, hash, digest(hash, input), signature) == nil
+		}
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(key, input, signature)
+	default:
+		return errs.New("unsupported public key type %T", key)
+	}
+	if !ok {
+		return errs.New("signature verification failed")
+	}
+	return nil
+}
+
+func signerOpts(alg string) (crypto.Hash, crypto.SignerOpts, error) {
+	switch alg {
+	case AlgES256, AlgRS256:
+		return crypto.SHA256, crypto.SHA256, nil
+	case AlgES384:
+		return crypto.SHA384, crypto.SHA384, nil
+	case AlgES512:
+		return crypto.SHA512, crypto.SHA512, nil
+	case AlgPS256:
+		return crypto.SHA256, &rsa.PSSOptions{
+			SaltLength: rsa.PSSSaltLengthEqualsHash,
+			Hash:       crypto.SHA256,
+		}, nil
+	case AlgEdDSA:
+		return 0, crypto.Hash(0), nil
+	default:
+		return 0, nil, errs.New("unsupported signature algorithm %q", alg)
+	}
+}
+
+// digest hashes the input, or returns it unchanged if no hash is used.
+func digest(hash crypto.Hash, input []byte) []byte {
+	if hash == 0 {
+		return input
+	}
+	h := hash.New()
+	_, _ = h.Write(input)
+	return h.Sum(nil)
+}
@@ -0,0 +1,125 @@
+package lsvid
+
+import (
+	"crypto"
+	"crypto/x509"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/zeebo/errs"
+)
+
+// Authority is an LSVID authority listed in a trust bundle document: a JWT
+// signing key of the trust domain, identified by the key ID that root layers
+// carry in their issuer claim.
+type Authority struct {
+	// Kid is the key ID of the authority.
+	Kid string `json:"kid"`
+	// PK is the DER encoded (PKIX) public key of the authority.
+	PK []byte `json:"pk"`
+	// Exp is the expiration of the authority, in seconds since the Unix
+	// epoch. Zero if the authority does not expire.
+	Exp int64 `json:"exp,omitempty"`
+}
+
+// ValidateBundle validates a trust bundle document and returns the ID of the
+// trust domain it lists the authorities of, along with the authorities that
+// have not expired, keyed by key ID.
+//
+// A trust bundle document is a root layer whose keys claim lists the LSVID
+// authorities of the trust domain in its subject claim. The issuer is the
+// trust domain vouching for them: the subject trust domain itself or, for a
+// federated trust domain, the trust domain of the verifier. The document must
+// be signed with the given key of the issuer trust domain, which the verifier
+// pins, and be within its validity period. The issuer key embedded in the
+// document, if any, must be the pinned key.
+func ValidateBundle(bundle *Token, trustDomainKey crypto.PublicKey, opts ...ValidateOption) (string, map[string]crypto.PublicKey, error) {
+	c := newValidateConfig(opts)
+
+	switch {
+	case bundle == nil:
+		return "", nil, errs.New("no trust bundle document to validate")
+	case trustDomainKey == nil:
+		return "", nil, errs.New("no trust domain key to validate the trust bundle document with")
+	case bundle.Nested != nil:
+		return "", nil, errs.New("trust bundle document must be a root layer")
+	}
+	if err := checkToken(bundle); err != nil {
+		return "", nil, err
+	}
+
+	iss, sub := bundle.Payload.Iss, bundle.Payload.Sub
+	switch {
+	case iss == nil:
+		return "", nil, errs.New("trust bundle document missing issuer")
+	case sub == nil:
+		return "", nil, errs.New("trust bundle document missing subject")
+	}
+	if _, err := bundleTrustDomain("issuer", iss); err != nil {
+		return "", nil, err
+	}
+	td, err := bundleTrustDomain("subject", sub)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(iss.PK) > 0 && !keyEquals(trustDomainKey, iss.PK) {
+		return "", nil, errs.New("trust bundle document is not signed by the trust domain key")
+	}
+	if err := VerifySignature(bundle, trustDomainKey); err != nil {
+		return "", nil, errs.New("invalid signature on trust bundle document: %v", err)
+	}
+	if err := c.checkTimes(bundle); err != nil {
+		return "", nil, err
+	}
+
+	now := c.clock.Now()
+	keys := make(map[string]crypto.PublicKey)
+	for _, authority := range bundle.Payload.Keys {
+		switch {
+		case authority == nil || authority.Kid == "":
+			return "", nil, errs.New("trust bundle document lists an authority without key ID")
+		case authority.Exp != 0 && !now.Before(time.Unix(authority.Exp, 0)):
+			continue
+		}
+		key, err := x509.ParsePKIXPublicKey(authority.PK)
+		if err != nil {
+			return "", nil, errs.New("unable to parse key %q of trust bundle document: %v", authority.Kid, err)
+		}
+		keys[authority.Kid] = key
+	}
+	if len(keys) == 0 {
+		return "", nil, errs.New("trust bundle document lists no current authority")
+	}
+
+	return td.IDString(), keys, nil
+}
+
+// bundleTrustDomain parses the trust domain ID of the issuer or subject claim
+// of a trust bundle document.
+func bundleTrustDomain(name string, claim *IDClaim) (spiffeid.TrustDomain, error) {
+	id, err := spiffeid.FromString(claim.CN)
+	if err != nil {
+		return spiffeid.TrustDomain{}, errs.New("invalid trust bundle document %s %q: %v", name, claim.CN, err)
+	}
+	if id.Path() != "" {
+		return spiffeid.TrustDomain{}, errs.New("trust bundle document %s %q is not a trust domain", name, claim.CN)
+	}
+	return id.TrustDomain(), nil
+}
+
+// ValidateWithBundle validates the token as Validate does, anchoring its root
+// layers in the authorities of the given trust bundle document, which is
+// first validated with the pinned trust domain key as ValidateBundle does.
+// Root layers issued by other trust domains are rejected. Options apply to
+// both the trust bundle document and the token.
+func ValidateWithBundle(token, bundle *Token, trustDomainKey crypto.PublicKey, opts ...ValidateOption) error {
+	trustDomainID, keys, err := ValidateBundle(bundle, trustDomainKey, opts...)
+	if err != nil {
+		return err
+	}
+	opts = append(opts[:len(opts):len(opts)], WithAuthorities(func(id string) (map[string]crypto.PublicKey, bool) {
+		return keys, id == trustDomainID
+	}))
+	return Validate(token, opts...)
+}
@@ -0,0 +1,206 @@
+package lsvid
+
+import (
+	"crypto"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/spiffe/spire/test/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateBundle(t *testing.T) {
+	clk := clock.NewMock(t)
+	tdKey := newKey(t)
+	currentKey := newKey(t)
+	previousKey := newKey(t)
+	expiredKey := newKey(t)
+
+	now := clk.Now()
+	bundle := signBundle(t, tdKey, now, []*Authority{
+		{Kid: "current", PK: marshalKey(t, currentKey), Exp: now.Add(2 * time.Hour).Unix()},
+		{Kid: "previous", PK: marshalKey(t, previousKey), Exp: now.Add(time.Minute).Unix()},
+		{Kid: "expired", PK: marshalKey(t, expiredKey), Exp: now.Add(-time.Minute).Unix()},
+	})
+
+	trustDomainID, keys, err := ValidateBundle(bundle, tdKey.Public(), WithClock(clk))
+	require.NoError(t, err)
+	require.Equal(t, tdID, trustDomainID)
+	require.Equal(t, map[string]crypto.PublicKey{
+		"current":  currentKey.Public(),
+		"previous": previousKey.Public(),
+	}, keys)
+
+	// The document survives a round trip through an LSVID document.
+	encoded, err := EncodeLSVID(&LSVID{Token: signRoot(t, currentKey, newKey(t), workloadID, agentID), Bundle: bundle})
+	require.NoError(t, err)
+	doc, err := DecodeLSVID(encoded)
+	require.NoError(t, err)
+	_, _, err = ValidateBundle(doc.Bundle, tdKey.Public(), WithClock(clk))
+	require.NoError(t, err)
+
+	// Previous authorities drop out once they expire.
+	clk.Add(time.Minute)
+	_, keys, err = ValidateBundle(bundle, tdKey.Public(), WithClock(clk))
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.Contains(t, keys, "current")
+
+	// The document itself expires.
+	clk.Add(time.Hour + DefaultClockSkew)
+	_, _, err = ValidateBundle(bundle, tdKey.Public(), WithClock(clk))
+	require.EqualError(t, err, `layer issued by "spiffe://example.org" has expired`)
+}
+
+func TestValidateBundleFailures(t *testing.T) {
+	tdKey := newKey(t)
+	otherKey := newKey(t)
+	now := time.Now()
+	authorities := []*Authority{{Kid: "kid", PK: marshalKey(t, newKey(t))}}
+
+	_, parseErr := x509.ParsePKIXPublicKey([]byte("bad"))
+	require.Error(t, parseErr)
+
+	modified := func(fn func(*Payload)) *Token {
+		bundle := signBundle(t, tdKey, now, authorities)
+		fn(bundle.Payload)
+		return resign(t, bundle, tdKey)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		bundle *Token
+		key    crypto.PublicKey
+		err    string
+	}{
+		{
+			name: "no bundle",
+			key:  tdKey.Public(),
+			err:  "no trust bundle document to validate",
+		},
+		{
+			name:   "no trust domain key",
+			bundle: signBundle(t, tdKey, now, authorities),
+			err:    "no trust domain key to validate the trust bundle document with",
+		},
+		{
+			name:   "not a root layer",
+			bundle: &Token{Nested: signBundle(t, tdKey, now, authorities), Payload: &Payload{Ver: Version}},
+			key:    tdKey.Public(),
+			err:    "trust bundle document must be a root layer",
+		},
+		{
+			name:   "missing subject",
+			bundle: modified(func(p *Payload) { p.Sub = nil }),
+			key:    tdKey.Public(),
+			err:    "trust bundle document missing subject",
+		},
+		{
+			name:   "issuer is not a trust domain",
+			bundle: modified(func(p *Payload) { p.Iss.CN = workloadID }),
+			key:    tdKey.Public(),
+			err:    `trust bundle document issuer "spiffe://example.org/workload" is not a trust domain`,
+		},
+		{
+			name:   "subject is not a trust domain",
+			bundle: modified(func(p *Payload) { p.Sub.CN = workloadID }),
+			key:    tdKey.Public(),
+			err:    `trust bundle document subject "spiffe://example.org/workload" is not a trust domain`,
+		},
+		{
+			name:   "invalid subject",
+			bundle: modified(func(p *Payload) { p.Sub.CN = "foo" }),
+			key:    tdKey.Public(),
+			err:    `invalid trust bundle document subject "foo": spiffeid: invalid scheme`,
+		},
+		{
+			name:   "embedded key is not the pinned key",
+			bundle: signBundle(t, tdKey, now, authorities),
+			key:    otherKey.Public(),
+			err:    "trust bundle document is not signed by the trust domain key",
+		},
+		{
+			name:   "not signed by the pinned key",
+			bundle: modified(func(p *Payload) { p.Iss.PK = nil }),
+			key:    otherKey.Public(),
+			err:    "invalid signature on trust bundle document: signature verification failed",
+		},
+		{
+			name:   "authority without key ID",
+			bundle: modified(func(p *Payload) { p.Keys = append(p.Keys, &Authority{PK: marshalKey(t, otherKey)}) }),
+			key:    tdKey.Public(),
+			err:    "trust bundle document lists an authority without key ID",
+		},
+		{
+			name:   "malformed authority key",
+			bundle: modified(func(p *Payload) { p.Keys = []*Authority{{Kid: "bad", PK: []byte("bad")}} }),
+			key:    tdKey.Public(),
+			err:    `unable to parse key "bad" of trust bundle document: ` + parseErr.Error(),
+		},
+		{
+			name:   "no current authority",
+			bundle: modified(func(p *Payload) { p.Keys = nil }),
+			key:    tdKey.Public(),
+			err:    "trust bundle document lists no current authority",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ValidateBundle(tt.bundle, tt.key)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestValidateWithBundle(t *testing.T) {
+	tdKey := newKey(t)
+	authorityKey := newKey(t)
+	otherKey := newKey(t)
+	now := time.Now()
+
+	bundle := signBundle(t, tdKey, now, []*Authority{
+		{Kid: "kid", PK: marshalKey(t, authorityKey)},
+	})
+
+	withKid := func(key crypto.Signer) *Token {
+		token := signRoot(t, key, newKey(t), workloadID, agentID)
+		token.Payload.Iss.Kid = "kid"
+		token.Payload.Iss.PK = nil
+		return resign(t, token, key)
+	}
+
+	require.NoError(t, ValidateWithBundle(withKid(authorityKey), bundle, tdKey.Public()))
+	require.EqualError(t, ValidateWithBundle(withKid(otherKey), bundle, tdKey.Public()), `invalid signature on layer issued by "spiffe://example.org": signature verification failed`)
+	require.EqualError(t, ValidateWithBundle(withKid(authorityKey), bundle, otherKey.Public()), "trust bundle document is not signed by the trust domain key")
+
+	// Root layers of other trust domains are not anchored in the bundle.
+	foreign := withKid(authorityKey)
+	foreign.Payload.Iss.CN = "spiffe://other.org"
+	foreign.Payload.Sub.CN = "spiffe://other.org/workload"
+	foreign = resign(t, foreign, authorityKey)
+	require.EqualError(t, ValidateWithBundle(foreign, bundle, tdKey.Public()), `no authorities found for trust domain "spiffe://other.org"`)
+
+	// A trust bundle document for a federated trust domain, signed with the
+	// pinned key, anchors the root layers of that trust domain only.
+	federated := signBundle(t, tdKey, now, []*Authority{
+		{Kid: "kid", PK: marshalKey(t, authorityKey)},
+	})
+	federated.Payload.Sub.CN = "spiffe://other.org"
+	federated = resign(t, federated, tdKey)
+	require.NoError(t, ValidateWithBundle(foreign, federated, tdKey.Public()))
+	require.EqualError(t, ValidateWithBundle(withKid(authorityKey), federated, tdKey.Public()), `no authorities found for trust domain "spiffe://example.org"`)
+}
+
+func signBundle(t *testing.T, key crypto.Signer, now time.Time, authorities []*Authority) *Token {
+	bundle, err := Sign(&Payload{
+		Ver:  Version,
+		Iat:  now.Unix(),
+		Exp:  now.Add(time.Hour).Unix(),
+		Iss:  &IDClaim{CN: tdID, PK: marshalKey(t, key)},
+		Sub:  &IDClaim{CN: tdID},
+		Keys: authorities,
+	}, key)
+	require.NoError(t, err)
+	return bundle
+}
@@ -0,0 +1,335 @@
+package lsvid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"sort"
+
+	"github.com/zeebo/errs"
+)
+
+// This file implements the subset of CBOR (RFC 8949) needed by the COSE
+// encoding of LSVIDs. Values are encoded deterministically, as described in
+// section 4.2.1 of RFC 8949: lengths are as short as possible, map entries
+// are sorted by the bytewise order of their encoded keys and indefinite
+// lengths are not used. The decoder only accepts definite lengths.
+//
+// Values are represented with the following Go types: nil, bool, int64,
+// float64, string, []byte, []interface{}, cborMap and cborTag.
+
+const (
+	cborMajorUint   = 0
+	cborMajorNegInt = 1
+	cborMajorBytes  = 2
+	cborMajorText   = 3
+	cborMajorArray  = 4
+	cborMajorMap    = 5
+	cborMajorTag    = 6
+	cborMajorSimple = 7
+
+	// cborMaxDepth bounds the nesting of decoded values.
+	cborMaxDepth = 512
+)
+
+// cborMap is a CBOR map. Keys are int64 or string values.
+type cborMap map[interface{}]interface{}
+
+// cborTag is a tagged CBOR value.
+type cborTag struct {
+	Number  uint64
+	Content interface{}
+}
+
+func cborMarshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := cborEncode(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func cborEncode(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteByte(cborMajorSimple<<5 | 22)
+	case bool:
+		if v {
+			buf.WriteByte(cborMajorSimple<<5 | 21)
+		} else {
+			buf.WriteByte(cborMajorSimple<<5 | 20)
+		}
+	case int:
+		cborEncodeInt(buf, int64(v))
+	case int8:
+		cborEncodeInt(buf, int64(v))
+	case int64:
+		cborEncodeInt(buf, v)
+	case float64:
+		// Integral numbers are encoded as integers, so that claims decoded
+		// from JSON, where every number is a float64, encode as they were
+		// signed.
+		if v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64 {
+			cborEncodeInt(buf, int64(v))
+			return nil
+		}
+		buf.WriteByte(cborMajorSimple<<5 | 27)
+		_ = binary.Write(buf, binary.BigEndian, math.Float64bits(v))
+	case string:
+		cborWriteHead(buf, cborMajorText, uint64(len(v)))
+		buf.WriteString(v)
+	case []byte:
+		cborWriteHead(buf, cborMajorBytes, uint64(len(v)))
+		buf.Write(v)
+	case []interface{}:
+		cborWriteHead(buf, cborMajorArray, uint64(len(v)))
+		for _, item := range v {
+			if err := cborEncode(buf, item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		m := make(cborMap, len(v))
+		for key, value := range v {
+			m[key] = value
+		}
+		return cborEncode(buf, m)
+	case cborMap:
+		return cborEncodeMap(buf, v)
+	case cborTag:
+		cborWriteHead(buf, cborMajorTag, v.Number)
+		return cborEncode(buf, v.Content)
+	default:
+		return errs.New("unable to encode %T as CBOR", v)
+	}
+	return nil
+}
+
+func cborEncodeInt(buf *bytes.Buffer, v int64) {
+	if v >= 0 {
+		cborWriteHead(buf, cborMajorUint, uint64(v))
+	} else {
+		cborWriteHead(buf, cborMajorNegInt, uint64(-(v + 1)))
+	}
+}
+
+func cborEncodeMap(buf *bytes.Buffer, m cborMap) error {
+	type entry struct {
+		key   []byte
+		value interface{}
+	}
+	entries := make([]entry, 0, len(m))
+	for key, value := range m {
+		switch key.(type) {
+		case int64, string:
+		default:
+			return errs.New("unsupported CBOR map key type %T", key)
+		}
+		encodedKey, err := cborMarshal(key)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry{key: encodedKey, value: value})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+
+	cborWriteHead(buf, cborMajorMap, uint64(len(entries)))
+	for _, e := range entries {
+		buf.Write(e.key)
+		if err := cborEncode(buf, e.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func cborWriteHead(buf *bytes.Buffer, major byte, n uint64) {
+	switch {
+	case n < 24:
+		buf.WriteByte(major<<5 | byte(n))
+	case n <= math.MaxUint8:
+		buf.WriteByte(major<<5 | 24)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(major<<5 | 25)
+		_ = binary.Write(buf, binary.BigEndian, uint16(n))
+	case n <= math.MaxUint32:
+		buf.WriteByte(major<<5 | 26)
+		_ = binary.Write(buf, binary.BigEndian, uint32(n))
+	default:
+		buf.WriteByte(major<<5 | 27)
+		_ = binary.Write(buf, binary.BigEndian, n)
+	}
+}
+
+// cborUnmarshal decodes a single CBOR data item, which must span the whole
+// input.
+func cborUnmarshal(data []byte) (interface{}, error) {
+	d := &cborDecoder{data: data}
+	v, err := d.decode(0)
+	if err != nil {
+		return nil, errs.New("invalid CBOR: %v", err)
+	}
+	if d.off != len(d.data) {
+		return nil, errs.New("invalid CBOR: %d trailing bytes", len(d.data)-d.off)
+	}
+	return v, nil
+}
+
+type cborDecoder struct {
+	data []byte
+	off  int
+}
+
+func (d *cborDecoder) decode(depth int) (interface{}, error) {
+	if depth > cborMaxDepth {
+		return nil, errs.New("maximum nesting depth exceeded")
+	}
+	major, info, n, err := d.readHead()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case cborMajorUint:
+		if n > math.MaxInt64 {
+			return nil, errs.New("integer overflows int64")
+		}
+		return int64(n), nil
+	case cborMajorNegInt:
+		if n > math.MaxInt64 {
+			return nil, errs.New("integer overflows int64")
+		}
+		return -int64(n) - 1, nil
+	case cborMajorBytes:
+		b, err := d.read(n)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{}, b...), nil
+	case cborMajorText:
+		b, err := d.read(n)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	case cborMajorArray:
+		// Every item takes at least one byte.
+		if n > uint64(len(d.data)-d.off) {
+			return nil, errs.New("array length exceeds input")
+		}
+		items := make([]interface{}, 0, n)
+		for i := uint64(0); i < n; i++ {
+			item, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case cborMajorMap:
+		if n > uint64(len(d.data)-d.off)/2 {
+			return nil, errs.New("map length exceeds input")
+		}
+		m := make(cborMap, n)
+		for i := uint64(0); i < n; i++ {
+			key, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, errs.New("unsupported map key type %T", key)
+			}
+			if _, ok := m[key]; ok {
+				return nil, errs.New("duplicate map key %v", key)
+			}
+			value, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = value
+		}
+		return m, nil
+	case cborMajorTag:
+		content, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return cborTag{Number: n, Content: content}, nil
+	default:
+		return d.decodeSimple(info, n)
+	}
+}
+
+func (d *cborDecoder) decodeSimple(info byte, n uint64) (interface{}, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22:
+		return nil, nil
+	case 25:
+		return float64(halfToFloat32(uint16(n))), nil
+	case 26:
+		return float64(math.Float32frombits(uint32(n))), nil
+	case 27:
+		return math.Float64frombits(n), nil
+	default:
+		return nil, errs.New("unsupported simple value %d", info)
+	}
+}
+
+func (d *cborDecoder) readHead() (major, info byte, n uint64, err error) {
+	b, err := d.read(1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	major, info = b[0]>>5, b[0]&0x1f
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info <= 27:
+		b, err := d.read(1 << (info - 24))
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		for _, c := range b {
+			n = n<<8 | uint64(c)
+		}
+		return major, info, n, nil
+	default:
+		return 0, 0, 0, errs.New("indefinite lengths are not supported")
+	}
+}
+
+func (d *cborDecoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.off) {
+		return nil, errs.New("unexpected end of input")
+	}
+	b := d.data[d.off : d.off+int(n)]
+	d.off += int(n)
+	return b, nil
+}
+
+// halfToFloat32 converts an IEEE 754 half-precision float.
+func halfToFloat32(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := uint32(h>>10) & 0x1f
+	frac := uint32(h) & 0x3ff
+	switch {
+	case exp == 0x1f:
+		return math.Float32frombits(sign | 0xff<<23 | frac<<13)
+	case exp == 0:
+		f := float32(frac) / (1 << 24)
+		if sign != 0 {
+			f = -f
+		}
+		return f
+	default:
+		return math.Float32frombits(sign | (exp+112)<<23 | frac<<13)
+	}
+}
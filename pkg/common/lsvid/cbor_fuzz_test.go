@@ -0,0 +1,65 @@
+//go:build go1.18
+// +build go1.18
+
+package lsvid
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func FuzzCBORUnmarshal(f *testing.F) {
+	for _, seed := range []string{
+		"00", "1b000000e8d4a51000", "3903e7", "fb3ff199999999999a", "f93e00", "f4", "f6",
+		"4401020304", "62c3bc", "8201820203", "a26161016162820203", "c11a514b67b0",
+		"a40a032004616102616201", "9fff", "9a0fffffff", "a201020103", "a1f502",
+	} {
+		data, err := hex.DecodeString(seed)
+		require.NoError(f, err)
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		v, err := cborUnmarshal(data)
+		if err != nil {
+			return
+		}
+		// Every decoded value can be encoded, and encoding is stable across
+		// a round trip.
+		encoded, err := cborMarshal(v)
+		require.NoError(t, err)
+		decoded, err := cborUnmarshal(encoded)
+		require.NoError(t, err)
+		reencoded, err := cborMarshal(decoded)
+		require.NoError(t, err)
+		require.Equal(t, encoded, reencoded)
+	})
+}
+
+func FuzzUnmarshalCOSE(f *testing.F) {
+	rootKey := newKey(f)
+	for _, token := range []*Token{
+		signCOSEChain(f, rootKey, newKey(f)),
+		signCOSEChain(f, rootKey, newEd25519Key(f)),
+	} {
+		data, err := MarshalCOSE(token)
+		require.NoError(f, err)
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		token, err := UnmarshalCOSE(data)
+		if err != nil {
+			return
+		}
+		// Accepted tokens are marshaled as they were signed, and decode to
+		// the same token.
+		encoded, err := MarshalCOSE(token)
+		require.NoError(t, err)
+		decoded, err := UnmarshalCOSE(encoded)
+		require.NoError(t, err)
+		require.Equal(t, token, decoded)
+	})
+}
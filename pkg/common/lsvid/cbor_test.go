@@ -0,0 +1,91 @@
+package lsvid
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCBOR(t *testing.T) {
+	// Test vectors from Appendix A of RFC 8949.
+	for _, tt := range []struct {
+		value   interface{}
+		encoded string
+	}{
+		{value: int64(0), encoded: "00"},
+		{value: int64(23), encoded: "17"},
+		{value: int64(24), encoded: "1818"},
+		{value: int64(1000), encoded: "1903e8"},
+		{value: int64(1000000), encoded: "1a000f4240"},
+		{value: int64(1000000000000), encoded: "1b000000e8d4a51000"},
+		{value: int64(-1), encoded: "20"},
+		{value: int64(-1000), encoded: "3903e7"},
+		{value: 1.1, encoded: "fb3ff199999999999a"},
+		{value: false, encoded: "f4"},
+		{value: true, encoded: "f5"},
+		{value: nil, encoded: "f6"},
+		{value: []byte{}, encoded: "40"},
+		{value: []byte{1, 2, 3, 4}, encoded: "4401020304"},
+		{value: "", encoded: "60"},
+		{value: "IETF", encoded: "6449455446"},
+		{value: "ü", encoded: "62c3bc"},
+		{value: []interface{}{}, encoded: "80"},
+		{value: []interface{}{int64(1), []interface{}{int64(2), int64(3)}}, encoded: "8201820203"},
+		{value: cborMap{}, encoded: "a0"},
+		{value: cborMap{int64(1): int64(2), int64(3): int64(4)}, encoded: "a201020304"},
+		{value: cborMap{"a": int64(1), "b": []interface{}{int64(2), int64(3)}}, encoded: "a26161016162820203"},
+		{value: cborTag{Number: 1, Content: int64(1363896240)}, encoded: "c11a514b67b0"},
+	} {
+		encoded, err := cborMarshal(tt.value)
+		require.NoError(t, err)
+		require.Equal(t, tt.encoded, hex.EncodeToString(encoded))
+
+		decoded, err := cborUnmarshal(encoded)
+		require.NoError(t, err)
+		require.Equal(t, tt.value, decoded)
+	}
+}
+
+func TestCBORDeterministicEncoding(t *testing.T) {
+	// Map entries are sorted by their encoded keys.
+	encoded, err := cborMarshal(cborMap{"b": int64(1), "a": int64(2), int64(10): int64(3), int64(-1): int64(4)})
+	require.NoError(t, err)
+	require.Equal(t, "a40a032004616102616201", hex.EncodeToString(encoded))
+
+	// Integral floats are encoded as integers.
+	encoded, err = cborMarshal(map[string]interface{}{"n": float64(42)})
+	require.NoError(t, err)
+	require.Equal(t, "a1616e182a", hex.EncodeToString(encoded))
+
+	// Shorter floats are decoded.
+	decoded, err := cborUnmarshal([]byte{0xf9, 0x3e, 0x00})
+	require.NoError(t, err)
+	require.Equal(t, 1.5, decoded)
+}
+
+func TestCBORDecodeFailures(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		encoded string
+		err     string
+	}{
+		{name: "empty", encoded: "", err: "invalid CBOR: unexpected end of input"},
+		{name: "trailing bytes", encoded: "0000", err: "invalid CBOR: 1 trailing bytes"},
+		{name: "truncated string", encoded: "6449", err: "invalid CBOR: unexpected end of input"},
+		{name: "indefinite length", encoded: "9fff", err: "invalid CBOR: indefinite lengths are not supported"},
+		{name: "array length exceeds input", encoded: "9a0fffffff", err: "invalid CBOR: array length exceeds input"},
+		{name: "duplicate map key", encoded: "a201020103", err: "invalid CBOR: duplicate map key 1"},
+		{name: "unsupported map key", encoded: "a1f502", err: "invalid CBOR: unsupported map key type bool"},
+		{name: "integer overflow", encoded: "1bffffffffffffffff", err: "invalid CBOR: integer overflows int64"},
+		{name: "unsupported simple value", encoded: "f7", err: "invalid CBOR: unsupported simple value 23"},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tt.encoded)
+			require.NoError(t, err)
+			_, err = cborUnmarshal(data)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
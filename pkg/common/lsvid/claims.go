@@ -0,0 +1,85 @@
+package lsvid
+
+import (
+	"net/url"
+
+	"github.com/zeebo/errs"
+)
+
+// WithKnownClaims sets the custom claims understood by the validator. Layers
+// listing other claims as critical are rejected.
+func WithKnownClaims(names ...string) ValidateOption {
+	return func(c *validateConfig) {
+		if c.knownClaims == nil {
+			c.knownClaims = make(map[string]struct{}, len(names))
+		}
+		for _, name := range names {
+			c.knownClaims[name] = struct{}{}
+		}
+	}
+}
+
+// Claims returns the custom claims of every layer of the token, merged from
+// the root layer outwards, so that outer layers override the claims of the
+// layers they extend.
+func (t *Token) Claims() map[string]interface{} {
+	var layers []*Token
+	for layer := t; layer != nil; layer = layer.Nested {
+		layers = append(layers, layer)
+	}
+
+	claims := make(map[string]interface{})
+	for i := len(layers) - 1; i >= 0; i-- {
+		if layers[i].Payload == nil {
+			continue
+		}
+		for name, value := range layers[i].Payload.Claims {
+			claims[name] = value
+		}
+	}
+	return claims
+}
+
+// CheckClaims verifies that custom claim names are namespaced and that every
+// critical claim is present in the layer.
+func CheckClaims(payload *Payload) error {
+	for name := range payload.Claims {
+		if err := checkClaimName(name); err != nil {
+			return err
+		}
+	}
+
+	crit := make(map[string]struct{}, len(payload.Crit))
+	for _, name := range payload.Crit {
+		if _, ok := crit[name]; ok {
+			return errs.New("critical claim %q listed more than once", name)
+		}
+		crit[name] = struct{}{}
+		if _, ok := payload.Claims[name]; !ok {
+			return errs.New("critical claim %q missing from payload", name)
+		}
+	}
+	return nil
+}
+
+// checkClaimName verifies that the custom claim name is an absolute URI, such
+// as "https://example.org/order_id" or "urn:example:scope", so that claims
+// defined by different parties cannot collide.
+func checkClaimName(name string) error {
+	u, err := url.Parse(name)
+	if err != nil || u.Scheme == "" || (u.Host == "" && u.Opaque == "") {
+		return errs.New("claim name %q is not an absolute URI", name)
+	}
+	return nil
+}
+
+// checkCriticalClaims verifies that every critical claim of the layer is
+// understood by the validator.
+func (c *validateConfig) checkCriticalClaims(layer *Token) error {
+	for _, name := range layer.Payload.Crit {
+		if _, ok := c.knownClaims[name]; !ok {
+			return errs.New("layer issued by %q has unknown critical claim %q", layer.Payload.Iss.CN, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,582 @@
+package lsvid
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/json"
+	"math/big"
+
+	"github.com/zeebo/errs"
+)
+
+// TypCOSE is the typ of LSVID layers encoded as COSE_Sign1 (RFC 9052)
+// structures rather than JSON. It is carried in the typ protected header
+// (RFC 9596) of every layer, and set in the typ claim of the payload of
+// decoded layers. Layers without a typ are encoded as JSON.
+const TypCOSE = "lsvid+cose"
+
+const (
+	coseSign1Tag = 18
+
+	coseHeaderAlg = 1
+	coseHeaderTyp = 16
+)
+
+// coseAlgs maps signature algorithms to their COSE algorithm identifiers.
+var coseAlgs = map[string]int64{
+	AlgES256: -7,
+	AlgES384: -35,
+	AlgES512: -36,
+	AlgRS256: -257,
+	AlgPS256: -37,
+	AlgEdDSA: -8,
+}
+
+// Labels of the claims of COSE layers. The labels of iss, sub, aud, exp, iat
+// and jti are those of the matching CWT claims (RFC 8392).
+const (
+	coseClaimIss    = 1
+	coseClaimSub    = 2
+	coseClaimAud    = 3
+	coseClaimExp    = 4
+	coseClaimIat    = 6
+	coseClaimJti    = 7
+	coseClaimVer    = 8
+	coseClaimClaims = 9
+	coseClaimCrit   = 10
+	coseClaimKeys   = 11
+	coseClaimNested = 12
+)
+
+// Labels of the members of identity claims and authorities in COSE layers.
+const (
+	coseIDCN  = 1
+	coseIDPK  = 2
+	coseIDID  = 3
+	coseIDX5T = 4
+	coseIDKid = 5
+
+	coseAuthorityKid = 1
+	coseAuthorityPK  = 2
+	coseAuthorityExp = 3
+)
+
+// MarshalCOSE serializes a token whose layers are typed TypCOSE into its
+// binary form: the COSE_Sign1 structure of the outermost layer, which
+// carries the layer it extends in its payload. Layers that were signed or
+// decoded by this package are marshaled as they were signed.
+func MarshalCOSE(token *Token) ([]byte, error) {
+	if token == nil || token.Payload == nil {
+		return nil, errs.New("no LSVID to marshal")
+	}
+	if token.Payload.Typ != TypCOSE {
+		return nil, errs.New("LSVID layer is not typed %q", TypCOSE)
+	}
+	protected, payload, err := coseParts(token)
+	if err != nil {
+		return nil, err
+	}
+	return cborMarshal(cborTag{
+		Number:  coseSign1Tag,
+		Content: []interface{}{protected, cborMap{}, payload, token.Signature},
+	})
+}
+
+// UnmarshalCOSE parses a token produced by MarshalCOSE. Tokens nested more
+// than MaxNesting layers deep are rejected.
+func UnmarshalCOSE(data []byte) (*Token, error) {
+	token, err := unmarshalCOSE(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkToken(token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// isCOSE returns true if the data starts with a COSE_Sign1 tag, which no
+// JSON document does.
+func isCOSE(data []byte) bool {
+	return len(data) > 0 && data[0] == 0xc0|coseSign1Tag
+}
+
+// coseSigningInput returns the bytes covered by the signature of a COSE
+// layer: its Sig_structure (RFC 9052, section 4.4), without external data.
+func coseSigningInput(token *Token) ([]byte, error) {
+	protected, payload, err := coseParts(token)
+	if err != nil {
+		return nil, err
+	}
+	return cborMarshal([]interface{}{"Signature1", protected, []byte{}, payload})
+}
+
+// coseParts returns the encoded protected header and payload of a COSE
+// layer: those it was signed or decoded with if any, or else its encoding.
+func coseParts(token *Token) (protected, payload []byte, err error) {
+	if token.encoding != nil {
+		return token.encoding.header, token.encoding.payload, nil
+	}
+	return encodeCOSEParts(token)
+}
+
+// encodeCOSEParts encodes the protected header and payload of a COSE layer.
+// The layers it extends and the LSVIDs embedded in its identity claims are
+// marshaled as they were signed or decoded, so that a layer is only encoded
+// once.
+func encodeCOSEParts(token *Token) (protected, payload []byte, err error) {
+	alg, ok := coseAlgs[token.Payload.Alg]
+	if !ok {
+		return nil, nil, errs.New("unsupported signature algorithm %q", token.Payload.Alg)
+	}
+	protected, err = cborMarshal(cborMap{
+		int64(coseHeaderAlg): alg,
+		int64(coseHeaderTyp): token.Payload.Typ,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	claims, err := payloadToCOSE(token.Payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	if token.Nested != nil {
+		nested, err := MarshalCOSE(token.Nested)
+		if err != nil {
+			return nil, nil, err
+		}
+		claims[int64(coseClaimNested)] = nested
+	}
+	payload, err = cborMarshal(claims)
+	if err != nil {
+		return nil, nil, errs.New("unable to marshal LSVID: %v", err)
+	}
+	return protected, payload, nil
+}
+
+func payloadToCOSE(p *Payload) (cborMap, error) {
+	m := cborMap{}
+	if p.Ver != 0 {
+		m[int64(coseClaimVer)] = int64(p.Ver)
+	}
+	if p.Iat != 0 {
+		m[int64(coseClaimIat)] = p.Iat
+	}
+	if p.Exp != 0 {
+		m[int64(coseClaimExp)] = p.Exp
+	}
+	for label, claim := range map[int64]*IDClaim{
+		coseClaimIss: p.Iss,
+		coseClaimSub: p.Sub,
+		coseClaimAud: p.Aud,
+	} {
+		if claim == nil {
+			continue
+		}
+		id, err := idClaimToCOSE(claim)
+		if err != nil {
+			return nil, err
+		}
+		m[label] = id
+	}
+	if p.Jti != "" {
+		m[int64(coseClaimJti)] = p.Jti
+	}
+	if len(p.Claims) > 0 {
+		// Custom claims go through JSON, so that they are encoded from the
+		// same values whether they were set by the caller or decoded.
+		data, err := json.Marshal(p.Claims)
+		if err != nil {
+			return nil, errs.New("unable to marshal LSVID claims: %v", err)
+		}
+		var claims map[string]interface{}
+		if err := json.Unmarshal(data, &claims); err != nil {
+			return nil, errs.New("unable to marshal LSVID claims: %v", err)
+		}
+		m[int64(coseClaimClaims)] = claims
+	}
+	if len(p.Crit) > 0 {
+		crit := make([]interface{}, 0, len(p.Crit))
+		for _, name := range p.Crit {
+			crit = append(crit, name)
+		}
+		m[int64(coseClaimCrit)] = crit
+	}
+	if len(p.Keys) > 0 {
+		keys := make([]interface{}, 0, len(p.Keys))
+		for _, key := range p.Keys {
+			if key == nil {
+				return nil, errs.New("LSVID authority is nil")
+			}
+			authority := cborMap{
+				int64(coseAuthorityKid): key.Kid,
+				int64(coseAuthorityPK):  key.PK,
+			}
+			if key.Exp != 0 {
+				authority[int64(coseAuthorityExp)] = key.Exp
+			}
+			keys = append(keys, authority)
+		}
+		m[int64(coseClaimKeys)] = keys
+	}
+	return m, nil
+}
+
+func idClaimToCOSE(claim *IDClaim) (cborMap, error) {
+	m := cborMap{}
+	if claim.CN != "" {
+		m[int64(coseIDCN)] = claim.CN
+	}
+	if len(claim.PK) > 0 {
+		m[int64(coseIDPK)] = claim.PK
+	}
+	if claim.ID != nil {
+		id, err := MarshalCOSE(claim.ID)
+		if err != nil {
+			return nil, errs.New("unable to marshal LSVID of %q: %v", claim.CN, err)
+		}
+		m[int64(coseIDID)] = id
+	}
+	if len(claim.X5T) > 0 {
+		m[int64(coseIDX5T)] = claim.X5T
+	}
+	if claim.Kid != "" {
+		m[int64(coseIDKid)] = claim.Kid
+	}
+	return m, nil
+}
+
+func unmarshalCOSE(data []byte) (*Token, error) {
+	token, err := decodeCOSE(data, 0)
+	if err != nil {
+		return nil, errs.New("unable to unmarshal LSVID: %v", err)
+	}
+	return token, nil
+}
+
+// decodeCOSE parses a COSE token enclosed in the given number of layers.
+func decodeCOSE(data []byte, nesting int) (*Token, error) {
+	if nesting++; nesting > MaxNesting {
+		return nil, errs.New("LSVID is nested more than %d layers deep", MaxNesting)
+	}
+	v, err := cborUnmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	tag, ok := v.(cborTag)
+	if !ok || tag.Number != coseSign1Tag {
+		return nil, errs.New("not a COSE_Sign1 structure")
+	}
+	parts, ok := tag.Content.([]interface{})
+	if !ok || len(parts) != 4 {
+		return nil, errs.New("COSE_Sign1 structure must be an array of 4 items")
+	}
+	protected, ok1 := parts[0].([]byte)
+	_, ok2 := parts[1].(cborMap)
+	payload, ok3 := parts[2].([]byte)
+	signature, ok4 := parts[3].([]byte)
+	if !ok1 || !ok2 || !ok3 || !ok4 {
+		return nil, errs.New("malformed COSE_Sign1 structure")
+	}
+
+	token := &Token{Payload: new(Payload), Signature: signature}
+	if err := decodeCOSEHeader(protected, token.Payload); err != nil {
+		return nil, err
+	}
+	if err := decodeCOSEPayload(payload, token, nesting); err != nil {
+		return nil, err
+	}
+
+	// The signature is verified over the received bytes, which must hold
+	// exactly the decoded layer: only layers encoded as this package would
+	// encode them are accepted. The nested layers were checked as they were
+	// decoded, and are re-encoded from the bytes they were received with.
+	reencodedProtected, reencodedPayload, err := encodeCOSEParts(token)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(protected, reencodedProtected) || !bytes.Equal(payload, reencodedPayload) {
+		return nil, errs.New("COSE layer is not deterministically encoded")
+	}
+	token.encoding = &layerEncoding{header: protected, payload: payload}
+	return token, nil
+}
+
+func decodeCOSEHeader(data []byte, payload *Payload) error {
+	v, err := cborUnmarshal(data)
+	if err != nil {
+		return err
+	}
+	header, ok := v.(cborMap)
+	if !ok {
+		return errs.New("protected header must be a map")
+	}
+	alg, ok := header[int64(coseHeaderAlg)].(int64)
+	if !ok {
+		return errs.New("protected header missing alg")
+	}
+	for name, id := range coseAlgs {
+		if id == alg {
+			payload.Alg = name
+		}
+	}
+	if payload.Alg == "" {
+		return errs.New("unsupported COSE algorithm %d", alg)
+	}
+	typ, ok := header[int64(coseHeaderTyp)].(string)
+	if !ok || typ != TypCOSE {
+		return errs.New("protected header typ must be %q", TypCOSE)
+	}
+	payload.Typ = typ
+	return nil
+}
+
+func decodeCOSEPayload(data []byte, token *Token, nesting int) error {
+	v, err := cborUnmarshal(data)
+	if err != nil {
+		return err
+	}
+	m, ok := v.(cborMap)
+	if !ok {
+		return errs.New("payload must be a map")
+	}
+	d := coseDecoder{m: m, nesting: nesting}
+	p := token.Payload
+
+	ver := d.int(coseClaimVer)
+	p.Ver = int8(ver)
+	if int64(p.Ver) != ver {
+		return errs.New("unsupported LSVID version %d", ver)
+	}
+	p.Iat = d.int(coseClaimIat)
+	p.Exp = d.int(coseClaimExp)
+	p.Jti = d.text(coseClaimJti)
+	p.Iss = d.idClaim(coseClaimIss)
+	p.Sub = d.idClaim(coseClaimSub)
+	p.Aud = d.idClaim(coseClaimAud)
+	if nested := d.bytes(coseClaimNested); nested != nil {
+		token.Nested, d.err = decodeCOSE(nested, nesting)
+	}
+	if crit, ok := d.array(coseClaimCrit); ok {
+		for _, item := range crit {
+			name, ok := item.(string)
+			if !ok {
+				return errs.New("crit must list claim names")
+			}
+			p.Crit = append(p.Crit, name)
+		}
+	}
+	if keys, ok := d.array(coseClaimKeys); ok {
+		for _, item := range keys {
+			authority, ok := item.(cborMap)
+			if !ok {
+				return errs.New("keys must list authorities")
+			}
+			ad := coseDecoder{m: authority}
+			p.Keys = append(p.Keys, &Authority{
+				Kid: ad.text(coseAuthorityKid),
+				PK:  ad.bytes(coseAuthorityPK),
+				Exp: ad.int(coseAuthorityExp),
+			})
+			if ad.err != nil {
+				return ad.err
+			}
+		}
+	}
+	if claims, ok := m[int64(coseClaimClaims)]; ok {
+		value, err := claimFromCOSE(claims)
+		if err != nil {
+			return err
+		}
+		if p.Claims, ok = value.(map[string]interface{}); !ok {
+			return errs.New("claims must be a map")
+		}
+	}
+	return d.err
+}
+
+// coseDecoder reads the members of a CBOR map, recording the first type
+// mismatch. The LSVIDs it decodes are enclosed in nesting layers.
+type coseDecoder struct {
+	m       cborMap
+	nesting int
+	err     error
+}
+
+func (d *coseDecoder) get(label int64) (interface{}, bool) {
+	v, ok := d.m[label]
+	if !ok || d.err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+func (d *coseDecoder) mismatch(label int64, kind string) {
+	if d.err == nil {
+		d.err = errs.New("member %d must be %s", label, kind)
+	}
+}
+
+func (d *coseDecoder) int(label int64) int64 {
+	v, ok := d.get(label)
+	if !ok {
+		return 0
+	}
+	i, ok := v.(int64)
+	if !ok {
+		d.mismatch(label, "an integer")
+	}
+	return i
+}
+
+func (d *coseDecoder) text(label int64) string {
+	v, ok := d.get(label)
+	if !ok {
+		return ""
+	}
+	s, ok := v.(string)
+	if !ok {
+		d.mismatch(label, "a text string")
+	}
+	return s
+}
+
+func (d *coseDecoder) bytes(label int64) []byte {
+	v, ok := d.get(label)
+	if !ok {
+		return nil
+	}
+	b, ok := v.([]byte)
+	if !ok {
+		d.mismatch(label, "a byte string")
+	}
+	return b
+}
+
+func (d *coseDecoder) array(label int64) ([]interface{}, bool) {
+	v, ok := d.get(label)
+	if !ok {
+		return nil, false
+	}
+	a, ok := v.([]interface{})
+	if !ok {
+		d.mismatch(label, "an array")
+	}
+	return a, ok
+}
+
+func (d *coseDecoder) idClaim(label int64) *IDClaim {
+	v, ok := d.get(label)
+	if !ok {
+		return nil
+	}
+	m, ok := v.(cborMap)
+	if !ok {
+		d.mismatch(label, "a map")
+		return nil
+	}
+	id := &coseDecoder{m: m, nesting: d.nesting}
+	claim := &IDClaim{
+		CN:  id.text(coseIDCN),
+		PK:  id.bytes(coseIDPK),
+		X5T: id.bytes(coseIDX5T),
+		Kid: id.text(coseIDKid),
+	}
+	if raw := id.bytes(coseIDID); raw != nil && id.err == nil {
+		claim.ID, id.err = decodeCOSE(raw, id.nesting)
+	}
+	if id.err != nil && d.err == nil {
+		d.err = id.err
+	}
+	return claim
+}
+
+// claimFromCOSE converts a decoded custom claim to the value encoding/json
+// would have decoded: integers become float64 and maps must have text keys.
+func claimFromCOSE(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), nil
+	case []byte, cborTag:
+		return nil, errs.New("unsupported claim value type %T", v)
+	case []interface{}:
+		items := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			value, err := claimFromCOSE(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		return items, nil
+	case cborMap:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			name, ok := key.(string)
+			if !ok {
+				return nil, errs.New("claim maps must have text keys")
+			}
+			value, err := claimFromCOSE(item)
+			if err != nil {
+				return nil, err
+			}
+			m[name] = value
+		}
+		return m, nil
+	default:
+		return v, nil
+	}
+}
+
+// hasRawSignature returns true if the ECDSA signature of the layer uses the
+// fixed size encoding shared by COSE and JWS rather than ASN.1 DER.
+func hasRawSignature(layer *Token) bool {
+	return layer.Payload.Typ == TypCOSE || layer.Payload.Typ == TypJWS
+}
+
+// coseSignature converts an ASN.1 DER encoded ECDSA signature to the fixed
+// size encoding of COSE and JWS. Other signatures are returned as is.
+func coseSignature(alg string, signature []byte) ([]byte, error) {
+	size := ecdsaSignatureSize(alg)
+	if size == 0 {
+		return signature, nil
+	}
+	var sig struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(signature, &sig); err != nil {
+		return nil, errs.New("malformed ECDSA signature: %v", err)
+	}
+	raw := make([]byte, 2*size)
+	sig.R.FillBytes(raw[:size])
+	sig.S.FillBytes(raw[size:])
+	return raw, nil
+}
+
+// derSignature converts a fixed size ECDSA signature of a COSE or JWS layer to
+// its ASN.1 DER encoding. Other signatures are returned as is.
+func derSignature(alg string, signature []byte) ([]byte, error) {
+	size := ecdsaSignatureSize(alg)
+	if size == 0 {
+		return signature, nil
+	}
+	if len(signature) != 2*size {
+		return nil, errs.New("ECDSA signature must be %d bytes long", 2*size)
+	}
+	return asn1.Marshal(struct{ R, S *big.Int }{
+		R: new(big.Int).SetBytes(signature[:size]),
+		S: new(big.Int).SetBytes(signature[size:]),
+	})
+}
+
+func ecdsaSignatureSize(alg string) int {
+	switch alg {
+	case AlgES256:
+		return 32
+	case AlgES384:
+		return 48
+	case AlgES512:
+		return 66
+	default:
+		return 0
+	}
+}
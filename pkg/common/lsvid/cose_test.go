@@ -0,0 +1,363 @@
+package lsvid
+
+import (
+	"crypto"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/spiffe/spire/test/testkey"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCOSEEncodeDecode(t *testing.T) {
+	rootKey := newKey(t)
+	agentKey := newKey(t)
+
+	token := signCOSEChain(t, rootKey, agentKey)
+	require.Equal(t, 2, token.Depth())
+
+	data, err := MarshalCOSE(token)
+	require.NoError(t, err)
+	require.Equal(t, byte(0xd2), data[0])
+
+	encoded, err := Encode(token)
+	require.NoError(t, err)
+	require.Equal(t, base64.RawURLEncoding.EncodeToString(data), encoded)
+
+	decoded, err := Decode(encoded)
+	require.NoError(t, err)
+	require.Equal(t, token, decoded)
+	require.Equal(t, TypCOSE, decoded.Payload.Typ)
+	require.Equal(t, TypCOSE, decoded.Nested.Payload.Typ)
+	require.NoError(t, Validate(decoded))
+
+	unmarshaled, err := UnmarshalCOSE(data)
+	require.NoError(t, err)
+	require.Equal(t, token, unmarshaled)
+
+	// Decoded layers are marshaled as they were received.
+	remarshaled, err := MarshalCOSE(unmarshaled)
+	require.NoError(t, err)
+	require.Equal(t, data, remarshaled)
+
+	// COSE tokens can still be carried in LSVID documents.
+	doc, err := EncodeLSVID(&LSVID{Token: token})
+	require.NoError(t, err)
+	decodedDoc, err := DecodeLSVID(doc)
+	require.NoError(t, err)
+	require.NoError(t, Validate(decodedDoc.Token))
+
+	// The signature covers the payload.
+	tampered := &Token{Nested: decoded.Nested, Payload: copyPayload(decoded.Payload), Signature: decoded.Signature}
+	tampered.Payload.Aud.CN = serviceID
+	require.Equal(t, ReasonBadSignature, ReasonOf(Validate(tampered)))
+
+	_, err = MarshalCOSE(signRoot(t, rootKey, newKey(t), workloadID, agentID))
+	require.EqualError(t, err, `LSVID layer is not typed "lsvid+cose"`)
+}
+
+func TestCOSEAlgorithms(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		key           crypto.Signer
+		alg           string
+		signatureSize int
+	}{
+		{name: "P-256", key: newKey(t), signatureSize: 64},
+		{name: "P-384", key: testkey.NewEC384(t), signatureSize: 96},
+		{name: "P-521", key: newP521Key(t), signatureSize: 132},
+		{name: "RSA", key: testkey.NewRSA2048(t), signatureSize: 256},
+		{name: "RSA with PSS", key: testkey.NewRSA2048(t), alg: AlgPS256, signatureSize: 256},
+		{name: "Ed25519", key: newEd25519Key(t), signatureSize: 64},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := Sign(&Payload{
+				Ver: Version,
+				Typ: TypCOSE,
+				Alg: tt.alg,
+				Exp: time.Now().Add(time.Hour).Unix(),
+				Iss: &IDClaim{CN: tdID, PK: marshalKey(t, tt.key)},
+				Sub: &IDClaim{CN: workloadID, PK: marshalKey(t, newKey(t))},
+				Aud: &IDClaim{CN: agentID},
+			}, tt.key)
+			require.NoError(t, err)
+			require.Len(t, token.Signature, tt.signatureSize)
+
+			encoded, err := Encode(token)
+			require.NoError(t, err)
+			decoded, err := Decode(encoded)
+			require.NoError(t, err)
+			require.NoError(t, Validate(decoded))
+		})
+	}
+}
+
+func TestCOSEKeyReference(t *testing.T) {
+	rootKey := newKey(t)
+	authorities := func(trustDomainID string) (map[string]crypto.PublicKey, bool) {
+		return map[string]crypto.PublicKey{"kid": rootKey.Public()}, trustDomainID == tdID
+	}
+
+	token, err := Sign(&Payload{
+		Ver: Version,
+		Typ: TypCOSE,
+		Exp: time.Now().Add(time.Hour).Unix(),
+		Iss: &IDClaim{CN: tdID, PK: marshalKey(t, rootKey), Kid: "kid"},
+		Sub: &IDClaim{CN: workloadID, PK: marshalKey(t, newKey(t))},
+		Aud: &IDClaim{CN: agentID},
+	}, rootKey)
+	require.NoError(t, err)
+	require.Nil(t, token.Payload.Iss.PK)
+
+	require.NoError(t, Validate(token, WithAuthorities(authorities)))
+	require.EqualError(t, Validate(token), "root LSVID layer missing issuer key")
+}
+
+func TestCOSEMixedEncodings(t *testing.T) {
+	rootKey := newKey(t)
+	agentKey := newKey(t)
+
+	jsonToken := signRoot(t, rootKey, agentKey, agentID, workloadID)
+	_, err := Extend(jsonToken, &Payload{
+		Ver: Version,
+		Typ: TypCOSE,
+		Iss: &IDClaim{CN: workloadID},
+		Aud: &IDClaim{CN: serviceID},
+	}, agentKey)
+	require.EqualError(t, err, `LSVID layer type "lsvid+cose" does not match type "" of the extended layer`)
+
+	coseToken := signCOSERoot(t, rootKey, newKey(t), workloadID, agentID)
+	_, err = Extend(coseToken, &Payload{
+		Ver: Version,
+		Iss: &IDClaim{CN: agentID, ID: signRoot(t, rootKey, agentKey, agentID, agentID)},
+		Aud: &IDClaim{CN: serviceID},
+	}, agentKey)
+	require.EqualError(t, err, `LSVID layer type "lsvid+cose" does not match type "" of the LSVID of issuer "spiffe://example.org/agent"`)
+
+	_, err = Sign(&Payload{Ver: Version, Typ: "other", Exp: time.Now().Add(time.Hour).Unix()}, rootKey)
+	require.EqualError(t, err, `unsupported LSVID type "other"`)
+}
+
+func TestCOSEDecodeFailures(t *testing.T) {
+	rootKey := newKey(t)
+	token := signCOSERoot(t, rootKey, newKey(t), workloadID, agentID)
+	_, payload, err := coseParts(token)
+	require.NoError(t, err)
+
+	sign1 := func(protected []byte) string {
+		data, err := cborMarshal(cborTag{
+			Number:  coseSign1Tag,
+			Content: []interface{}{protected, cborMap{}, payload, token.Signature},
+		})
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	header := func(m cborMap) []byte {
+		data, err := cborMarshal(m)
+		require.NoError(t, err)
+		return data
+	}
+	encode := func(v interface{}) string {
+		data, err := cborMarshal(v)
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		encoded string
+		err     string
+	}{
+		{
+			name:    "not a COSE_Sign1 structure",
+			encoded: encode(cborTag{Number: coseSign1Tag, Content: "LSVID"}),
+			err:     "unable to unmarshal LSVID: COSE_Sign1 structure must be an array of 4 items",
+		},
+		{
+			name:    "malformed COSE_Sign1 structure",
+			encoded: encode(cborTag{Number: coseSign1Tag, Content: []interface{}{"", cborMap{}, payload, token.Signature}}),
+			err:     "unable to unmarshal LSVID: malformed COSE_Sign1 structure",
+		},
+		{
+			name:    "missing typ",
+			encoded: sign1(header(cborMap{int64(coseHeaderAlg): int64(-7)})),
+			err:     `unable to unmarshal LSVID: protected header typ must be "lsvid+cose"`,
+		},
+		{
+			name:    "unsupported algorithm",
+			encoded: sign1(header(cborMap{int64(coseHeaderAlg): int64(-1), int64(coseHeaderTyp): TypCOSE})),
+			err:     "unable to unmarshal LSVID: unsupported COSE algorithm -1",
+		},
+		{
+			name:    "not deterministically encoded",
+			encoded: sign1(append([]byte{0xa2, 0x01, 0x38, 0x06, 0x10, 0x6a}, TypCOSE...)),
+			err:     "unable to unmarshal LSVID: COSE layer is not deterministically encoded",
+		},
+		{
+			name:    "truncated",
+			encoded: base64.RawURLEncoding.EncodeToString([]byte{0xd2, 0x84, 0x58, 0xff, 0, 0, 0, 0}),
+			err:     "unable to unmarshal LSVID: invalid CBOR: unexpected end of input",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(tt.encoded)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestCOSENesting(t *testing.T) {
+	rootKey := newKey(t)
+	extend := func(token *Token, layers int) *Token {
+		for i := 0; i < layers; i++ {
+			var err error
+			token, err = Extend(token, &Payload{
+				Ver: Version,
+				Iss: &IDClaim{CN: agentID, PK: marshalKey(t, rootKey)},
+				Aud: &IDClaim{CN: agentID},
+			}, rootKey)
+			require.NoError(t, err)
+		}
+		return token
+	}
+	marshal := func(token *Token) []byte {
+		data, err := MarshalCOSE(token)
+		require.NoError(t, err)
+		return data
+	}
+
+	deepest := extend(signCOSERoot(t, rootKey, newKey(t), agentID, agentID), MaxNesting-1)
+	require.Equal(t, MaxNesting, deepest.Depth())
+	decoded, err := UnmarshalCOSE(marshal(deepest))
+	require.NoError(t, err)
+	require.Equal(t, deepest, decoded)
+
+	_, err = UnmarshalCOSE(marshal(extend(deepest, 1)))
+	require.EqualError(t, err, "unable to unmarshal LSVID: LSVID is nested more than 64 layers deep")
+
+	// The layers of the LSVIDs embedded in identity claims count as well.
+	embedding, err := Extend(signCOSERoot(t, rootKey, newKey(t), agentID, agentID), &Payload{
+		Ver: Version,
+		Iss: &IDClaim{CN: agentID, ID: deepest},
+		Aud: &IDClaim{CN: agentID},
+	}, rootKey)
+	require.NoError(t, err)
+	_, err = UnmarshalCOSE(marshal(embedding))
+	require.EqualError(t, err, "unable to unmarshal LSVID: LSVID is nested more than 64 layers deep")
+
+	// Tokens carried as JSON are bounded alike.
+	doc, err := EncodeLSVID(&LSVID{Token: extend(deepest, 1)})
+	require.NoError(t, err)
+	_, err = DecodeLSVID(doc)
+	require.EqualError(t, err, "LSVID is nested more than 64 layers deep")
+}
+
+func TestCOSEIsSmaller(t *testing.T) {
+	rootKey := newKey(t)
+	agentKey := newKey(t)
+
+	jsonEncoded, err := Encode(signJSONChain(t, rootKey, agentKey))
+	require.NoError(t, err)
+	coseEncoded, err := Encode(signCOSEChain(t, rootKey, agentKey))
+	require.NoError(t, err)
+	require.Less(t, len(coseEncoded), len(jsonEncoded)*3/4)
+}
+
+func BenchmarkEncode(b *testing.B) {
+	benchmarkEncodings(b, func(b *testing.B, token *Token) {
+		var encoded string
+		for i := 0; i < b.N; i++ {
+			var err error
+			encoded, err = Encode(token)
+			require.NoError(b, err)
+		}
+		b.ReportMetric(float64(len(encoded)), "bytes")
+	})
+}
+
+func BenchmarkDecode(b *testing.B) {
+	benchmarkEncodings(b, func(b *testing.B, token *Token) {
+		encoded, err := Encode(token)
+		require.NoError(b, err)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, err := Decode(encoded)
+			require.NoError(b, err)
+		}
+	})
+}
+
+func BenchmarkValidate(b *testing.B) {
+	benchmarkEncodings(b, func(b *testing.B, token *Token) {
+		for i := 0; i < b.N; i++ {
+			require.NoError(b, Validate(token))
+		}
+	})
+}
+
+func benchmarkEncodings(b *testing.B, fn func(b *testing.B, token *Token)) {
+	rootKey := newKey(b)
+	agentKey := newKey(b)
+	b.Run("JSON", func(b *testing.B) {
+		fn(b, signJSONChain(b, rootKey, agentKey))
+	})
+	b.Run("COSE", func(b *testing.B) {
+		fn(b, signCOSEChain(b, rootKey, agentKey))
+	})
+}
+
+// signJSONChain and signCOSEChain sign the LSVID of a workload, as issued by
+// SPIRE: a root layer signed for the agent, extended by the agent to the
+// workload with the LSVID of the agent in its issuer claim.
+func signJSONChain(tb testing.TB, rootKey, agentKey crypto.Signer) *Token {
+	return signChain(tb, rootKey, agentKey, "")
+}
+
+func signCOSEChain(tb testing.TB, rootKey, agentKey crypto.Signer) *Token {
+	return signChain(tb, rootKey, agentKey, TypCOSE)
+}
+
+func signChain(tb testing.TB, rootKey, agentKey crypto.Signer, typ string) *Token {
+	sign := func(subjectKey crypto.Signer, subject, audience string) *Token {
+		now := time.Now()
+		token, err := Sign(&Payload{
+			Ver:    Version,
+			Typ:    typ,
+			Iat:    now.Unix(),
+			Exp:    now.Add(time.Hour).Unix(),
+			Iss:    &IDClaim{CN: tdID, PK: marshalKey(tb, rootKey)},
+			Sub:    &IDClaim{CN: subject, PK: marshalKey(tb, subjectKey), X5T: make([]byte, 32)},
+			Aud:    &IDClaim{CN: audience},
+			Claims: map[string]interface{}{"https://example.org/order_id": float64(42)},
+		}, rootKey)
+		require.NoError(tb, err)
+		return token
+	}
+
+	token, err := Extend(sign(newKey(tb), workloadID, agentID), &Payload{
+		Ver: Version,
+		Iat: time.Now().Unix(),
+		Iss: &IDClaim{CN: agentID, ID: sign(agentKey, agentID, agentID)},
+		Aud: &IDClaim{CN: workloadID},
+	}, agentKey)
+	require.NoError(tb, err)
+	return token
+}
+
+func signCOSERoot(t *testing.T, rootKey, subjectKey crypto.Signer, subject, audience string) *Token {
+	now := time.Now()
+	token, err := Sign(&Payload{
+		Ver: Version,
+		Typ: TypCOSE,
+		Iat: now.Unix(),
+		Exp: now.Add(time.Hour).Unix(),
+		Iss: &IDClaim{CN: tdID, PK: marshalKey(t, rootKey)},
+		Sub: &IDClaim{CN: subject, PK: marshalKey(t, subjectKey)},
+		Aud: &IDClaim{CN: audience},
+	}, rootKey)
+	require.NoError(t, err)
+	return token
+}
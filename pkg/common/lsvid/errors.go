@@ -0,0 +1,135 @@
+package lsvid
+
+import (
+	"errors"
+)
+
+// Reason identifies why an LSVID failed validation.
+type Reason string
+
+const (
+	// ReasonMalformed is returned for LSVIDs missing required claims or
+	// otherwise not well-formed.
+	ReasonMalformed Reason = "MALFORMED"
+
+	// ReasonUntrustedIssuer is returned when the key of the issuer of a
+	// layer cannot be resolved or is not trusted.
+	ReasonUntrustedIssuer Reason = "UNTRUSTED_ISSUER"
+
+	// ReasonBadSignature is returned when the signature of a layer does not
+	// verify with the key of its issuer.
+	ReasonBadSignature Reason = "BAD_SIGNATURE"
+
+	// ReasonExpired is returned when a layer has expired.
+	ReasonExpired Reason = "EXPIRED"
+
+	// ReasonNotYetValid is returned when a layer is issued in the future.
+	ReasonNotYetValid Reason = "NOT_YET_VALID"
+
+	// ReasonBrokenChain is returned when the issuer of a layer is not the
+	// audience of the layer it extends.
+	ReasonBrokenChain Reason = "BROKEN_CHAIN"
+
+	// ReasonExpiryIncreases is returned when a layer outlives the layer it
+	// extends.
+	ReasonExpiryIncreases Reason = "EXPIRY_INCREASES"
+
+	// ReasonUnknownCriticalClaim is returned when a layer lists a critical
+	// claim unknown to the validator.
+	ReasonUnknownCriticalClaim Reason = "UNKNOWN_CRITICAL_CLAIM"
+
+	// ReasonSubjectRotated is returned when the subject is no longer bound to
+	// one of its current X509-SVIDs.
+	ReasonSubjectRotated Reason = "SUBJECT_ROTATED"
+
+	// ReasonForeignIssuer is returned when the federation policy does not
+	// allow the foreign issuer of a layer.
+	ReasonForeignIssuer Reason = "FOREIGN_ISSUER_NOT_ALLOWED"
+
+	// ReasonChainTooDeep is returned when the token has more layers than the
+	// chain policy allows.
+	ReasonChainTooDeep Reason = "CHAIN_TOO_DEEP"
+
+	// ReasonIssuerNotAllowed is returned when the chain policy does not allow
+	// the issuer of a layer at its depth.
+	ReasonIssuerNotAllowed Reason = "ISSUER_NOT_ALLOWED"
+
+	// ReasonAudienceNotAllowed is returned when the chain policy does not
+	// allow the audience of a layer at its depth.
+	ReasonAudienceNotAllowed Reason = "AUDIENCE_NOT_ALLOWED"
+
+	// ReasonAudienceMismatch is returned when the outermost layer is not
+	// addressed to the required audience.
+	ReasonAudienceMismatch Reason = "AUDIENCE_MISMATCH"
+
+	// ReasonChainCycle is returned when the token is delivered to the same
+	// audience more than once.
+	ReasonChainCycle Reason = "CHAIN_CYCLE"
+
+	// ReasonRevoked is returned when a layer, its subject or its issuer is
+	// revoked.
+	ReasonRevoked Reason = "REVOKED"
+
+	// ReasonReplayed is returned when the token was already accepted by a
+	// validator using a replay cache.
+	ReasonReplayed Reason = "REPLAYED"
+
+	// ReasonInvalidProof is returned when the token is not presented with a
+	// valid proof of possession of the key of its holder.
+	ReasonInvalidProof Reason = "INVALID_PROOF"
+)
+
+// ValidationError is the error returned when an LSVID fails validation.
+type ValidationError struct {
+	// Reason identifies the failed check.
+	Reason Reason
+
+	// Depth is the depth of the failing layer, counted from the root layer,
+	// at depth 1, outwards. It is zero if the failure is not tied to a layer.
+	// Failures of an issuer LSVID are reported at the depth of the layer
+	// carrying it, with the reason of the failure.
+	Depth int
+
+	// Issuer is the issuer of the failing layer, if any.
+	Issuer string
+
+	err error
+}
+
+func (e *ValidationError) Error() string {
+	return e.err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.err
+}
+
+// ReasonOf returns the reason of the validation error in the chain of err, or
+// ReasonMalformed if there is none.
+func ReasonOf(err error) Reason {
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		return verr.Reason
+	}
+	return ReasonMalformed
+}
+
+func validationError(reason Reason, err error) *ValidationError {
+	return &ValidationError{Reason: reason, err: err}
+}
+
+// layerError ties err to the layer at the given depth. If err is already a
+// validation error, its reason is kept.
+func layerError(reason Reason, depth int, layer *Token, err error) *ValidationError {
+	verr := &ValidationError{Reason: reason, err: err}
+	var inner *ValidationError
+	if errors.As(err, &inner) {
+		verr.Reason = inner.Reason
+		verr.err = inner.err
+	}
+	verr.Depth = depth
+	if layer.Payload.Iss != nil {
+		verr.Issuer = layer.Payload.Iss.CN
+	}
+	return verr
+}
@@ -0,0 +1,82 @@
+package lsvid
+
+import (
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/zeebo/errs"
+)
+
+// ForeignIssuer allows issuers of a foreign trust domain to issue layers of an
+// LSVID at the given depths. Depths are counted from the root layer, at depth
+// 1, outwards.
+type ForeignIssuer struct {
+	// ID is the SPIFFE ID of the issuer. If empty, any issuer of the trust
+	// domain is allowed.
+	ID string
+
+	// MinDepth is the depth of the innermost layer the issuer may issue. It is
+	// not enforced if zero.
+	MinDepth int
+
+	// MaxDepth is the depth of the outermost layer the issuer may issue. It is
+	// not enforced if zero.
+	MaxDepth int
+}
+
+// FederationPolicy lists the issuers allowed in each foreign trust domain.
+// A trust domain mapped to no issuers allows any of its issuers at any depth.
+type FederationPolicy map[spiffeid.TrustDomain][]ForeignIssuer
+
+// WithFederationPolicy restricts the layers issued outside the given local
+// trust domain to the issuers allowed by the policy at the depth of the layer.
+// Layers issued by trust domains missing from the policy are rejected. The
+// policy also applies to the issuer LSVIDs embedded in the layers, whose
+// depths are counted within the issuer LSVID.
+func WithFederationPolicy(td spiffeid.TrustDomain, policy FederationPolicy) ValidateOption {
+	return func(c *validateConfig) {
+		c.trustDomain = td
+		c.federationPolicy = policy
+	}
+}
+
+// checkFederationPolicy verifies that the issuer of the layer, at the given
+// depth, is local or allowed by the federation policy.
+func (c *validateConfig) checkFederationPolicy(layer *Token, depth int) error {
+	if c.federationPolicy == nil {
+		return nil
+	}
+
+	iss := layer.Payload.Iss.CN
+	id, err := spiffeid.FromString(iss)
+	if err != nil {
+		return validationError(ReasonMalformed, errs.New("invalid issuer %q: %v", iss, err))
+	}
+	if id.MemberOf(c.trustDomain) {
+		return nil
+	}
+
+	issuers, ok := c.federationPolicy[id.TrustDomain()]
+	if !ok {
+		return errs.New("issuer %q belongs to trust domain %q, which is not federated", iss, id.TrustDomain().IDString())
+	}
+	if len(issuers) == 0 {
+		return nil
+	}
+	for _, issuer := range issuers {
+		if issuer.allows(iss, depth) {
+			return nil
+		}
+	}
+	return errs.New("foreign issuer %q is not allowed at depth %d", iss, depth)
+}
+
+func (i ForeignIssuer) allows(issuer string, depth int) bool {
+	switch {
+	case i.ID != "" && i.ID != issuer:
+		return false
+	case i.MinDepth != 0 && depth < i.MinDepth:
+		return false
+	case i.MaxDepth != 0 && depth > i.MaxDepth:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,124 @@
+package lsvid
+
+import (
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	partnerTDID       = "spiffe://partner.org"
+	partnerWorkloadID = "spiffe://partner.org/workload"
+)
+
+func TestValidateFederationPolicy(t *testing.T) {
+	exampleTD := spiffeid.RequireTrustDomainFromString(tdID)
+	partnerTD := spiffeid.RequireTrustDomainFromString(partnerTDID)
+
+	partnerKey := newKey(t)
+	partnerWorkloadKey := newKey(t)
+	rootKey := newKey(t)
+	serviceKey := newKey(t)
+
+	// A token minted in the partner trust domain, extended by its subject to
+	// a local service, which extends it again to a local workload.
+	token := signRoot(t, partnerKey, partnerWorkloadKey, partnerWorkloadID, partnerWorkloadID)
+	token.Payload.Iss.CN = partnerTDID
+	token = resign(t, token, partnerKey)
+	token, err := Extend(token, &Payload{
+		Ver: Version,
+		Alg: AlgES256,
+		Iss: &IDClaim{CN: partnerWorkloadID},
+		Aud: &IDClaim{CN: serviceID},
+	}, partnerWorkloadKey)
+	require.NoError(t, err)
+	token, err = Extend(token, &Payload{
+		Ver: Version,
+		Alg: AlgES256,
+		Iss: &IDClaim{CN: serviceID, ID: signRoot(t, rootKey, serviceKey, serviceID, serviceID)},
+		Aud: &IDClaim{CN: workloadID},
+	}, serviceKey)
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name   string
+		td     spiffeid.TrustDomain
+		policy FederationPolicy
+		err    string
+	}{
+		{
+			name:   "no foreign issuers allowed",
+			td:     exampleTD,
+			policy: FederationPolicy{},
+			err:    `issuer "spiffe://partner.org/workload" belongs to trust domain "spiffe://partner.org", which is not federated`,
+		},
+		{
+			name:   "any issuer of the trust domain",
+			td:     exampleTD,
+			policy: FederationPolicy{partnerTD: nil},
+		},
+		{
+			name:   "any issuer up to the depth of the extension",
+			td:     exampleTD,
+			policy: FederationPolicy{partnerTD: {{MaxDepth: 2}}},
+		},
+		{
+			name:   "any issuer at the root layer only",
+			td:     exampleTD,
+			policy: FederationPolicy{partnerTD: {{MaxDepth: 1}}},
+			err:    `foreign issuer "spiffe://partner.org/workload" is not allowed at depth 2`,
+		},
+		{
+			name: "issuers allowed at their depth",
+			td:   exampleTD,
+			policy: FederationPolicy{partnerTD: {
+				{ID: partnerTDID, MaxDepth: 1},
+				{ID: partnerWorkloadID, MinDepth: 2, MaxDepth: 2},
+			}},
+		},
+		{
+			name:   "issuer not allowed",
+			td:     exampleTD,
+			policy: FederationPolicy{partnerTD: {{ID: partnerTDID}}},
+			err:    `foreign issuer "spiffe://partner.org/workload" is not allowed at depth 2`,
+		},
+		{
+			name: "issuer below its minimum depth",
+			td:   exampleTD,
+			policy: FederationPolicy{partnerTD: {
+				{ID: partnerTDID, MinDepth: 2},
+				{ID: partnerWorkloadID},
+			}},
+			err: `foreign issuer "spiffe://partner.org" is not allowed at depth 1`,
+		},
+		{
+			name: "policy applies to issuer LSVIDs",
+			td:   partnerTD,
+			policy: FederationPolicy{exampleTD: {
+				{ID: serviceID, MinDepth: 3},
+			}},
+			err: `invalid issuer LSVID for "spiffe://example.org/service": foreign issuer "spiffe://example.org" is not allowed at depth 1`,
+		},
+		{
+			name: "issuer LSVIDs allowed",
+			td:   partnerTD,
+			policy: FederationPolicy{exampleTD: {
+				{ID: serviceID, MinDepth: 3},
+				{ID: tdID, MaxDepth: 1},
+			}},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, Validate(token))
+
+			err := Validate(token, WithFederationPolicy(tt.td, tt.policy))
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
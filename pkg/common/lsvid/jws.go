@@ -0,0 +1,389 @@
+package lsvid
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/zeebo/errs"
+)
+
+// TypJWS is the typ of LSVID layers encoded as JWS compact serializations
+// (RFC 7515) of JWT claims sets, for consumers that only have JOSE libraries.
+// It is carried in the typ header of every layer, and set in the typ claim of
+// the payload of decoded layers.
+const TypJWS = "lsvid+jwt"
+
+// MaxJWSLength is the maximum length of the JWS compact serializations
+// accepted by UnmarshalJWS and Decode, checked before they are decoded. Since
+// every JWS layer carries the layer it extends base64url encoded in its
+// nested claim, tokens grow by a third with every layer on top of its own
+// claims: the JWS encoding is only fit for short chains.
+const MaxJWSLength = 64 << 10
+
+// jwsAlgs lists the signature algorithms of JWS layers, which are named as
+// in JWA (RFC 7518, RFC 8037).
+var jwsAlgs = map[string]bool{
+	AlgES256: true,
+	AlgES384: true,
+	AlgES512: true,
+	AlgRS256: true,
+	AlgPS256: true,
+	AlgEdDSA: true,
+}
+
+// Names of the claims of JWS layers. The identity claims are carried by the
+// registered iss, sub and aud claims (RFC 7519), which hold their common
+// name, and by private claims suffixed with the name of their other members.
+// Custom claims, whose names are absolute URIs, are carried as public claims.
+const (
+	jwsClaimVer    = "ver"
+	jwsClaimIat    = "iat"
+	jwsClaimExp    = "exp"
+	jwsClaimJti    = "jti"
+	jwsClaimIss    = "iss"
+	jwsClaimSub    = "sub"
+	jwsClaimAud    = "aud"
+	jwsClaimCrit   = "crit"
+	jwsClaimKeys   = "keys"
+	jwsClaimNested = "nested"
+
+	jwsIDPK  = "_pk"
+	jwsIDID  = "_id"
+	jwsIDX5T = "_x5t#S256"
+	jwsIDKid = "_kid"
+)
+
+// MarshalJWS serializes a token whose layers are typed TypJWS into the JWS
+// compact serialization of its outermost layer, which carries the layer it
+// extends in its nested claim. Layers that were signed or decoded by this
+// package are marshaled as they were signed.
+func MarshalJWS(token *Token) (string, error) {
+	if token == nil || token.Payload == nil {
+		return "", errs.New("no LSVID to marshal")
+	}
+	if token.Payload.Typ != TypJWS {
+		return "", errs.New("LSVID layer is not typed %q", TypJWS)
+	}
+	input, err := jwsSigningInput(token)
+	if err != nil {
+		return "", err
+	}
+	return string(input) + "." + base64.RawURLEncoding.EncodeToString(token.Signature), nil
+}
+
+// UnmarshalJWS parses a token produced by MarshalJWS. Tokens longer than
+// MaxJWSLength or nested more than MaxNesting layers deep are rejected.
+func UnmarshalJWS(compact string) (*Token, error) {
+	token, err := unmarshalJWS(compact)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkToken(token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// isJWS returns true if the encoded token is a JWS compact serialization,
+// which is the only encoding holding dots.
+func isJWS(encoded string) bool {
+	return strings.Contains(encoded, ".")
+}
+
+// jwsSigningInput returns the bytes covered by the signature of a JWS layer:
+// the encoded header and payload, separated by a dot.
+func jwsSigningInput(token *Token) ([]byte, error) {
+	header, payload, err := jwsParts(token)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)), nil
+}
+
+// jwsParts returns the header and payload of a JWS layer: those it was signed
+// or decoded with if any, or else its encoding.
+func jwsParts(token *Token) (header, payload []byte, err error) {
+	if token.encoding != nil {
+		return token.encoding.header, token.encoding.payload, nil
+	}
+	return encodeJWSParts(token)
+}
+
+// encodeJWSParts serializes the header and payload of a JWS layer as
+// canonical JSON (RFC 8785). The layers it extends and the LSVIDs embedded in
+// its identity claims are marshaled as they were signed or decoded, so that a
+// layer is only encoded once.
+func encodeJWSParts(token *Token) (header, payload []byte, err error) {
+	if !jwsAlgs[token.Payload.Alg] {
+		return nil, nil, errs.New("unsupported signature algorithm %q", token.Payload.Alg)
+	}
+	h := map[string]interface{}{
+		"alg": token.Payload.Alg,
+		"typ": token.Payload.Typ,
+	}
+	// JOSE verifiers look up the key of the root issuer in the JWKS of its
+	// trust domain by key ID.
+	if iss := token.Payload.Iss; token.Nested == nil && iss != nil && iss.Kid != "" {
+		h["kid"] = iss.Kid
+	}
+	if header, err = marshalCanonical(h); err != nil {
+		return nil, nil, errs.New("unable to marshal LSVID: %v", err)
+	}
+
+	claims, err := payloadToJWS(token.Payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	if token.Nested != nil {
+		if claims[jwsClaimNested], err = MarshalJWS(token.Nested); err != nil {
+			return nil, nil, err
+		}
+	}
+	if payload, err = marshalCanonical(claims); err != nil {
+		return nil, nil, errs.New("unable to marshal LSVID: %v", err)
+	}
+	return header, payload, nil
+}
+
+func payloadToJWS(p *Payload) (map[string]interface{}, error) {
+	m := make(map[string]interface{}, len(p.Claims)+8)
+	for name, value := range p.Claims {
+		m[name] = value
+	}
+	m[jwsClaimVer] = p.Ver
+	if p.Iat != 0 {
+		m[jwsClaimIat] = p.Iat
+	}
+	if p.Exp != 0 {
+		m[jwsClaimExp] = p.Exp
+	}
+	if p.Jti != "" {
+		m[jwsClaimJti] = p.Jti
+	}
+	for name, claim := range map[string]*IDClaim{
+		jwsClaimIss: p.Iss,
+		jwsClaimSub: p.Sub,
+		jwsClaimAud: p.Aud,
+	} {
+		if claim == nil {
+			continue
+		}
+		m[name] = claim.CN
+		if len(claim.PK) > 0 {
+			m[name+jwsIDPK] = base64.RawURLEncoding.EncodeToString(claim.PK)
+		}
+		if claim.ID != nil {
+			id, err := MarshalJWS(claim.ID)
+			if err != nil {
+				return nil, errs.New("unable to marshal LSVID of %q: %v", claim.CN, err)
+			}
+			m[name+jwsIDID] = id
+		}
+		if len(claim.X5T) > 0 {
+			m[name+jwsIDX5T] = base64.RawURLEncoding.EncodeToString(claim.X5T)
+		}
+		if claim.Kid != "" {
+			m[name+jwsIDKid] = claim.Kid
+		}
+	}
+	if len(p.Crit) > 0 {
+		m[jwsClaimCrit] = p.Crit
+	}
+	if len(p.Keys) > 0 {
+		keys := make([]interface{}, 0, len(p.Keys))
+		for _, key := range p.Keys {
+			if key == nil {
+				return nil, errs.New("LSVID authority is nil")
+			}
+			authority := map[string]interface{}{
+				"kid": key.Kid,
+				"pk":  base64.RawURLEncoding.EncodeToString(key.PK),
+			}
+			if key.Exp != 0 {
+				authority["exp"] = key.Exp
+			}
+			keys = append(keys, authority)
+		}
+		m[jwsClaimKeys] = keys
+	}
+	return m, nil
+}
+
+func unmarshalJWS(compact string) (*Token, error) {
+	if len(compact) > MaxJWSLength {
+		return nil, errs.New("unable to unmarshal LSVID: JWS compact serialization is longer than %d bytes", MaxJWSLength)
+	}
+	token, err := decodeJWS(compact, 0)
+	if err != nil {
+		return nil, errs.New("unable to unmarshal LSVID: %v", err)
+	}
+	return token, nil
+}
+
+// decodeJWS parses a JWS token enclosed in the given number of layers.
+func decodeJWS(compact string, nesting int) (*Token, error) {
+	if nesting++; nesting > MaxNesting {
+		return nil, errs.New("LSVID is nested more than %d layers deep", MaxNesting)
+	}
+	parts := strings.Split(compact, ".")
+	if len(parts) != 3 {
+		return nil, errs.New("JWS compact serialization must have 3 parts")
+	}
+	var raw [3][]byte
+	for i, part := range parts {
+		var err error
+		if raw[i], err = base64.RawURLEncoding.DecodeString(part); err != nil {
+			return nil, errs.New("malformed JWS compact serialization: %v", err)
+		}
+	}
+
+	token := &Token{Payload: new(Payload), Signature: raw[2]}
+	if err := decodeJWSHeader(raw[0], token.Payload); err != nil {
+		return nil, err
+	}
+	if err := decodeJWSPayload(raw[1], token, nesting); err != nil {
+		return nil, err
+	}
+
+	// The signature is verified over the received header and payload, which
+	// must hold exactly the decoded layer: only layers encoded as this package
+	// would encode them are accepted. The nested layers were checked as they
+	// were decoded, and are re-encoded from the bytes they were received with.
+	header, payload, err := encodeJWSParts(token)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(raw[0], header) || !bytes.Equal(raw[1], payload) {
+		return nil, errs.New("JWS layer is not canonically encoded")
+	}
+	token.encoding = &layerEncoding{header: header, payload: payload}
+	return token, nil
+}
+
+func decodeJWSHeader(data []byte, payload *Payload) error {
+	var header struct {
+		Alg string `json:"alg"`
+		Typ string `json:"typ"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return errs.New("malformed header: %v", err)
+	}
+	if !jwsAlgs[header.Alg] {
+		return errs.New("unsupported JWS algorithm %q", header.Alg)
+	}
+	if header.Typ != TypJWS {
+		return errs.New("header typ must be %q", TypJWS)
+	}
+	payload.Alg = header.Alg
+	payload.Typ = header.Typ
+	return nil
+}
+
+func decodeJWSPayload(data []byte, token *Token, nesting int) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return errs.New("malformed payload: %v", err)
+	}
+	d := jwsDecoder{m: m, nesting: nesting}
+	p := token.Payload
+
+	d.value(jwsClaimVer, &p.Ver)
+	d.value(jwsClaimIat, &p.Iat)
+	d.value(jwsClaimExp, &p.Exp)
+	d.value(jwsClaimJti, &p.Jti)
+	p.Iss = d.idClaim(jwsClaimIss)
+	p.Sub = d.idClaim(jwsClaimSub)
+	p.Aud = d.idClaim(jwsClaimAud)
+	d.value(jwsClaimCrit, &p.Crit)
+
+	var nested string
+	if d.value(jwsClaimNested, &nested) && d.err == nil {
+		token.Nested, d.err = decodeJWS(nested, nesting)
+	}
+
+	var keys []struct {
+		Kid string `json:"kid"`
+		PK  string `json:"pk"`
+		Exp int64  `json:"exp"`
+	}
+	if d.value(jwsClaimKeys, &keys) {
+		for _, key := range keys {
+			pk, err := base64.RawURLEncoding.DecodeString(key.PK)
+			if err != nil {
+				return errs.New("malformed authority key: %v", err)
+			}
+			p.Keys = append(p.Keys, &Authority{Kid: key.Kid, PK: pk, Exp: key.Exp})
+		}
+	}
+	if d.err != nil {
+		return d.err
+	}
+
+	// The remaining claims are custom claims, which must be absolute URIs.
+	for name, raw := range d.m {
+		if err := checkClaimName(name); err != nil {
+			return errs.New("unknown claim %q", name)
+		}
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return errs.New("malformed claim %q: %v", name, err)
+		}
+		if p.Claims == nil {
+			p.Claims = make(map[string]interface{})
+		}
+		p.Claims[name] = value
+	}
+	return nil
+}
+
+// jwsDecoder reads and removes the members of a JWS payload, recording the
+// first malformed member. The LSVIDs it decodes are enclosed in nesting
+// layers.
+type jwsDecoder struct {
+	m       map[string]json.RawMessage
+	nesting int
+	err     error
+}
+
+func (d *jwsDecoder) value(name string, v interface{}) bool {
+	raw, ok := d.m[name]
+	if !ok {
+		return false
+	}
+	delete(d.m, name)
+	if err := json.Unmarshal(raw, v); err != nil && d.err == nil {
+		d.err = errs.New("malformed claim %q: %v", name, err)
+	}
+	return true
+}
+
+func (d *jwsDecoder) bytes(name string) []byte {
+	var s string
+	if !d.value(name, &s) {
+		return nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil && d.err == nil {
+		d.err = errs.New("malformed claim %q: %v", name, err)
+	}
+	return b
+}
+
+func (d *jwsDecoder) idClaim(name string) *IDClaim {
+	claim := new(IDClaim)
+	if !d.value(name, &claim.CN) {
+		return nil
+	}
+	claim.PK = d.bytes(name + jwsIDPK)
+	claim.X5T = d.bytes(name + jwsIDX5T)
+	d.value(name+jwsIDKid, &claim.Kid)
+	var id string
+	if d.value(name+jwsIDID, &id) && d.err == nil {
+		if claim.ID, d.err = decodeJWS(id, d.nesting); d.err != nil {
+			d.err = errs.New("invalid LSVID of %q: %v", claim.CN, d.err)
+		}
+	}
+	return claim
+}
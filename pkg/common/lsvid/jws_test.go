@@ -0,0 +1,268 @@
+package lsvid
+
+import (
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spiffe/spire/test/testkey"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+func TestJWSEncodeDecode(t *testing.T) {
+	rootKey := newKey(t)
+	agentKey := newKey(t)
+
+	token := signChain(t, rootKey, agentKey, TypJWS)
+	require.Equal(t, 2, token.Depth())
+
+	encoded, err := Encode(token)
+	require.NoError(t, err)
+	require.Equal(t, 2, strings.Count(encoded, "."))
+	marshaled, err := MarshalJWS(token)
+	require.NoError(t, err)
+	require.Equal(t, marshaled, encoded)
+
+	decoded, err := Decode(encoded)
+	require.NoError(t, err)
+	require.Equal(t, token, decoded)
+	require.Equal(t, TypJWS, decoded.Payload.Typ)
+	require.Equal(t, TypJWS, decoded.Nested.Payload.Typ)
+	require.NoError(t, Validate(decoded))
+
+	unmarshaled, err := UnmarshalJWS(encoded)
+	require.NoError(t, err)
+	require.Equal(t, token, unmarshaled)
+
+	// Decoded layers are marshaled as they were received.
+	remarshaled, err := MarshalJWS(unmarshaled)
+	require.NoError(t, err)
+	require.Equal(t, encoded, remarshaled)
+
+	// JWS tokens can still be carried in LSVID documents.
+	doc, err := EncodeLSVID(&LSVID{Token: token})
+	require.NoError(t, err)
+	decodedDoc, err := DecodeLSVID(doc)
+	require.NoError(t, err)
+	require.NoError(t, Validate(decodedDoc.Token))
+
+	// The signature covers the payload.
+	tampered := &Token{Nested: decoded.Nested, Payload: copyPayload(decoded.Payload), Signature: decoded.Signature}
+	tampered.Payload.Aud.CN = serviceID
+	require.Equal(t, ReasonBadSignature, ReasonOf(Validate(tampered)))
+
+	_, err = MarshalJWS(signRoot(t, rootKey, newKey(t), workloadID, agentID))
+	require.EqualError(t, err, `LSVID layer is not typed "lsvid+jwt"`)
+}
+
+func TestJWSLimits(t *testing.T) {
+	token := signChain(t, newKey(t), newKey(t), TypJWS)
+	encoded, err := MarshalJWS(token)
+	require.NoError(t, err)
+
+	_, err = Decode(encoded + strings.Repeat("A", MaxJWSLength-len(encoded)+1))
+	require.EqualError(t, err, "unable to unmarshal LSVID: JWS compact serialization is longer than 65536 bytes")
+
+	// The layers enclosing the token count towards its nesting.
+	_, err = decodeJWS(encoded, MaxNesting-1)
+	require.EqualError(t, err, `invalid LSVID of "spiffe://example.org/agent": LSVID is nested more than 64 layers deep`)
+}
+
+func TestJWSInterop(t *testing.T) {
+	rootKey := newKey(t)
+	agentKey := newKey(t)
+
+	encoded, err := Encode(signChain(t, rootKey, agentKey, TypJWS))
+	require.NoError(t, err)
+
+	// Any layer verifies as a JWT with a JOSE library, the nested layer being
+	// carried in the nested claim.
+	outer, err := jwt.ParseSigned(encoded)
+	require.NoError(t, err)
+	require.Equal(t, "ES256", outer.Headers[0].Algorithm)
+	require.Equal(t, TypJWS, outer.Headers[0].ExtraHeaders[jose.HeaderType])
+
+	var claims jwt.Claims
+	var lsvidClaims struct {
+		Ver    int    `json:"ver"`
+		IssID  string `json:"iss_id"`
+		Nested string `json:"nested"`
+	}
+	require.NoError(t, outer.Claims(agentKey.Public(), &claims, &lsvidClaims))
+	require.Equal(t, agentID, claims.Issuer)
+	require.Equal(t, jwt.Audience{workloadID}, claims.Audience)
+	require.NoError(t, claims.Validate(jwt.Expected{Audience: jwt.Audience{workloadID}}))
+	require.Equal(t, Version, lsvidClaims.Ver)
+	require.NotEmpty(t, lsvidClaims.IssID)
+
+	nested, err := jwt.ParseSigned(lsvidClaims.Nested)
+	require.NoError(t, err)
+	var nestedClaims jwt.Claims
+	var custom map[string]interface{}
+	require.NoError(t, nested.Claims(rootKey.Public(), &nestedClaims, &custom))
+	require.Equal(t, tdID, nestedClaims.Issuer)
+	require.Equal(t, workloadID, nestedClaims.Subject)
+	require.Equal(t, float64(42), custom["https://example.org/order_id"])
+
+	// The issuer LSVID is a JWT too.
+	id, err := jose.ParseSigned(lsvidClaims.IssID)
+	require.NoError(t, err)
+	_, err = id.Verify(rootKey.Public())
+	require.NoError(t, err)
+
+	// Tampering is detected by JOSE libraries.
+	parts := strings.Split(encoded, ".")
+	parts[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"iss":"spiffe://example.org/evil"}`))
+	tampered, err := jose.ParseSigned(strings.Join(parts, "."))
+	require.NoError(t, err)
+	_, err = tampered.Verify(agentKey.Public())
+	require.Error(t, err)
+}
+
+func TestJWSAlgorithms(t *testing.T) {
+	rsaKey := testkey.NewRSA2048(t)
+	for _, tt := range []struct {
+		name          string
+		key           crypto.Signer
+		alg           string
+		signatureSize int
+	}{
+		{name: "P-256", key: newKey(t), signatureSize: 64},
+		{name: "P-384", key: testkey.NewEC384(t), signatureSize: 96},
+		{name: "P-521", key: newP521Key(t), signatureSize: 132},
+		{name: "RSA", key: rsaKey, signatureSize: 256},
+		{name: "RSA with PSS", key: rsaKey, alg: AlgPS256, signatureSize: 256},
+		{name: "Ed25519", key: newEd25519Key(t), signatureSize: 64},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := Sign(&Payload{
+				Ver: Version,
+				Typ: TypJWS,
+				Alg: tt.alg,
+				Exp: time.Now().Add(time.Hour).Unix(),
+				Iss: &IDClaim{CN: tdID, PK: marshalKey(t, tt.key)},
+				Sub: &IDClaim{CN: workloadID, PK: marshalKey(t, newKey(t))},
+				Aud: &IDClaim{CN: agentID},
+			}, tt.key)
+			require.NoError(t, err)
+			require.Len(t, token.Signature, tt.signatureSize)
+
+			encoded, err := Encode(token)
+			require.NoError(t, err)
+			decoded, err := Decode(encoded)
+			require.NoError(t, err)
+			require.NoError(t, Validate(decoded))
+
+			jws, err := jose.ParseSigned(encoded)
+			require.NoError(t, err)
+			_, err = jws.Verify(tt.key.Public())
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestJWSKeyReference(t *testing.T) {
+	rootKey := newKey(t)
+	token, err := Sign(&Payload{
+		Ver: Version,
+		Typ: TypJWS,
+		Exp: time.Now().Add(time.Hour).Unix(),
+		Iss: &IDClaim{CN: tdID, PK: marshalKey(t, rootKey), Kid: "kid"},
+		Sub: &IDClaim{CN: workloadID, PK: marshalKey(t, newKey(t))},
+		Aud: &IDClaim{CN: agentID},
+	}, rootKey)
+	require.NoError(t, err)
+
+	// The key ID of the root issuer is in the header, for JWKS lookups.
+	encoded, err := Encode(token)
+	require.NoError(t, err)
+	jws, err := jose.ParseSigned(encoded)
+	require.NoError(t, err)
+	require.Equal(t, "kid", jws.Signatures[0].Header.KeyID)
+
+	decoded, err := Decode(encoded)
+	require.NoError(t, err)
+	require.Equal(t, token, decoded)
+}
+
+func TestJWSDecodeFailures(t *testing.T) {
+	rootKey := newKey(t)
+	token := signChain(t, rootKey, newKey(t), TypJWS)
+	encoded, err := Encode(token)
+	require.NoError(t, err)
+	parts := strings.Split(encoded, ".")
+	signature := parts[2]
+
+	b64 := func(s string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	}
+	withPayload := func(fn func(m map[string]interface{})) string {
+		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		require.NoError(t, err)
+		var m map[string]interface{}
+		require.NoError(t, json.Unmarshal(payload, &m))
+		fn(m)
+		data, err := marshalCanonical(m)
+		require.NoError(t, err)
+		return parts[0] + "." + base64.RawURLEncoding.EncodeToString(data) + "." + signature
+	}
+
+	for _, tt := range []struct {
+		name    string
+		encoded string
+		err     string
+	}{
+		{
+			name:    "too many parts",
+			encoded: encoded + ".",
+			err:     "unable to unmarshal LSVID: JWS compact serialization must have 3 parts",
+		},
+		{
+			name:    "malformed base64",
+			encoded: "!." + parts[1] + "." + signature,
+			err:     "unable to unmarshal LSVID: malformed JWS compact serialization: illegal base64 data at input byte 0",
+		},
+		{
+			name:    "unsupported algorithm",
+			encoded: b64(`{"alg":"none","typ":"lsvid+jwt"}`) + "." + parts[1] + ".",
+			err:     `unable to unmarshal LSVID: unsupported JWS algorithm "none"`,
+		},
+		{
+			name:    "missing typ",
+			encoded: b64(`{"alg":"ES256"}`) + "." + parts[1] + "." + signature,
+			err:     `unable to unmarshal LSVID: header typ must be "lsvid+jwt"`,
+		},
+		{
+			name:    "not canonically encoded",
+			encoded: b64(`{"typ":"lsvid+jwt","alg":"ES256"}`) + "." + parts[1] + "." + signature,
+			err:     "unable to unmarshal LSVID: JWS layer is not canonically encoded",
+		},
+		{
+			name:    "malformed claim",
+			encoded: withPayload(func(m map[string]interface{}) { m["exp"] = "tomorrow" }),
+			err:     `unable to unmarshal LSVID: malformed claim "exp": json: cannot unmarshal string into Go value of type int64`,
+		},
+		{
+			name:    "unknown claim",
+			encoded: withPayload(func(m map[string]interface{}) { m["scope"] = "all" }),
+			err:     `unable to unmarshal LSVID: unknown claim "scope"`,
+		},
+		{
+			name:    "identity member without identity",
+			encoded: withPayload(func(m map[string]interface{}) { delete(m, "iss") }),
+			err:     `unable to unmarshal LSVID: unknown claim "iss_id"`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(tt.encoded)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
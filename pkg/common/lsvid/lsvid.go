@@ -0,0 +1,420 @@
+// Package lsvid implements the Lightweight SVID (LSVID) format developed by
+// the SPIFFE Assertions and Tokens WG. An LSVID is a chain of signed layers:
+// the innermost layer is signed by the trust domain authority and binds a
+// SPIFFE ID to a public key, and every outer layer is signed by its issuer and
+// wraps the layer it extends.
+//
+// This package is the single source of truth for the LSVID wire format. The
+// server CA, the SVID API, the agent Workload API handler and external
+// workloads all share these types, so that they cannot drift apart.
+//
+// See doc/lsvid.md for the wire format and signing input specification.
+//
+// Ref document:
+// https://docs.google.com/document/d/15rfAkzNTQa1ycs-fn9hyIYV5HbznPBsxB-f0vxhNJ24/edit?usp=drive_link
+package lsvid
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	jsoncanonicalizer "github.com/cyberphone/json-canonicalization/go/src/webpki.org/jsoncanonicalizer"
+	"github.com/zeebo/errs"
+)
+
+// Version is the LSVID payload version produced by this package.
+const Version = 1
+
+// MaxNesting is the maximum number of layers of a decoded token, counting the
+// layers of the LSVIDs embedded in the identity claims of a layer along with
+// the layers enclosing them. Since decoding and validating a layer costs more
+// the deeper it is nested, deeper tokens are rejected while decoding.
+const MaxNesting = 64
+
+// LSVID is the document handed to workloads. It carries the LSVID token and,
+// optionally, the trust bundle document used to anchor it. See
+// ValidateWithBundle.
+type LSVID struct {
+	Token  *Token `json:"token"`
+	Bundle *Token `json:"bundle"`
+}
+
+// Token is a single LSVID layer. Nested points to the layer being extended
+// and is nil for the innermost (root) layer.
+type Token struct {
+	Nested    *Token   `json:"nested,omitempty"`
+	Payload   *Payload `json:"payload"`
+	Signature []byte   `json:"signature"`
+
+	// Aggregate is the aggregate signature of the run of SAEdDSA layers
+	// ending with this layer. It is only set on the outermost layer of a run,
+	// and is not covered by the signature of the layer. See AlgSAEdDSA.
+	Aggregate []byte `json:"aggregate,omitempty"`
+
+	// encoding holds the encoded header and payload of COSE and JWS layers
+	// that were signed or decoded, so that they are marshaled and verified as
+	// they were signed. Such layers must not be modified. See MarshalCOSE and
+	// MarshalJWS.
+	encoding *layerEncoding
+}
+
+// Payload holds the claims of an LSVID layer.
+type Payload struct {
+	Ver int8     `json:"ver,omitempty"`
+	Alg string   `json:"alg,omitempty"`
+	Iat int64    `json:"iat,omitempty"`
+	Exp int64    `json:"exp,omitempty"`
+	Iss *IDClaim `json:"iss,omitempty"`
+	Sub *IDClaim `json:"sub,omitempty"`
+	Aud *IDClaim `json:"aud,omitempty"`
+
+	// Jti uniquely identifies the layer. It is set when the layer is signed.
+	// See Token.ID.
+	Jti string `json:"jti,omitempty"`
+
+	// Typ selects the encoding of the layer: TypCOSE for COSE layers, TypJWS
+	// for JWS layers, empty for JSON layers. Every layer of a token shares
+	// the same encoding.
+	Typ string `json:"typ,omitempty"`
+
+	// Claims holds custom claims, keyed by absolute URIs, e.g.
+	// "https://example.org/order_id".
+	Claims map[string]interface{} `json:"claims,omitempty"`
+
+	// Crit lists the custom claims that validators must understand to accept
+	// the layer.
+	Crit []string `json:"crit,omitempty"`
+
+	// Keys lists the LSVID authorities of a trust domain. Only present on
+	// trust bundle documents. See ValidateBundle.
+	Keys []*Authority `json:"keys,omitempty"`
+}
+
+// IDClaim identifies a party in an LSVID layer.
+type IDClaim struct {
+	// CN is the SPIFFE ID of the party, e.g. spiffe://example.org/workload.
+	CN string `json:"cn,omitempty"`
+	// PK is the DER encoded (PKIX) public key of the party.
+	PK []byte `json:"pk,omitempty"`
+	// ID is an LSVID that identifies the party.
+	ID *Token `json:"id,omitempty"`
+	// X5T is the SHA-256 thumbprint of the X509-SVID the public key of the
+	// subject was bound to when the LSVID was issued. See X509SVIDThumbprint.
+	X5T []byte `json:"x5t,omitempty"`
+	// Kid is the key ID of the trust bundle JWT authority that signed a root
+	// layer, set on its issuer claim.
+	Kid string `json:"kid,omitempty"`
+}
+
+// Root returns the innermost layer of the token.
+func (t *Token) Root() *Token {
+	for t.Nested != nil {
+		t = t.Nested
+	}
+	return t
+}
+
+// Subject returns the subject claim of the token, which is set by the root
+// layer and shared by every layer that extends it.
+func (t *Token) Subject() *IDClaim {
+	if root := t.Root(); root.Payload != nil {
+		return root.Payload.Sub
+	}
+	return nil
+}
+
+// Depth returns the number of layers in the token.
+func (t *Token) Depth() int {
+	depth := 0
+	for ; t != nil; t = t.Nested {
+		depth++
+	}
+	return depth
+}
+
+// ExpiresAt returns the expiration time of the token. Since no layer may
+// outlive the layer it extends, this is the expiration of the outermost layer.
+func (t *Token) ExpiresAt() time.Time {
+	if t.Payload == nil || t.Payload.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(t.Payload.Exp, 0)
+}
+
+// X509SVIDThumbprint returns the thumbprint used to bind an LSVID subject to
+// the given X509-SVID: the SHA-256 digest of its DER encoding.
+func X509SVIDThumbprint(cert *x509.Certificate) []byte {
+	sum := sha256.Sum256(cert.Raw)
+	return sum[:]
+}
+
+// Encode serializes a token into its base64url (unpadded) JSON form or, if
+// its layers are typed TypCOSE, into its base64url (unpadded) COSE form, or,
+// if they are typed TypJWS, into its JWS compact serialization. See
+// MarshalCOSE and MarshalJWS.
+func Encode(token *Token) (string, error) {
+	if token != nil && token.Payload != nil {
+		switch token.Payload.Typ {
+		case TypCOSE:
+			data, err := MarshalCOSE(token)
+			if err != nil {
+				return "", err
+			}
+			return base64.RawURLEncoding.EncodeToString(data), nil
+		case TypJWS:
+			return MarshalJWS(token)
+		}
+	}
+	return encode(token)
+}
+
+// Decode parses a token produced by Encode, detecting whether it is encoded
+// as JSON, COSE or JWS.
+func Decode(encoded string) (*Token, error) {
+	if isJWS(encoded) {
+		return UnmarshalJWS(encoded)
+	}
+	data, err := decodeBase64(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if isCOSE(data) {
+		token, err := unmarshalCOSE(data)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkToken(token); err != nil {
+			return nil, err
+		}
+		return token, nil
+	}
+	return unmarshalToken(data)
+}
+
+// EncodeLSVID serializes an LSVID document into its base64url (unpadded)
+// JSON form.
+func EncodeLSVID(doc *LSVID) (string, error) {
+	return encode(doc)
+}
+
+// DecodeLSVID parses an LSVID document produced by EncodeLSVID.
+func DecodeLSVID(encoded string) (*LSVID, error) {
+	var raw struct {
+		Token  json.RawMessage `json:"token"`
+		Bundle json.RawMessage `json:"bundle"`
+	}
+	if err := decode(encoded, &raw); err != nil {
+		return nil, err
+	}
+	if isNullJSON(raw.Token) {
+		return nil, errs.New("LSVID document missing token")
+	}
+
+	doc := new(LSVID)
+	var err error
+	if doc.Token, err = unmarshalToken(raw.Token); err != nil {
+		return nil, err
+	}
+	if !isNullJSON(raw.Bundle) {
+		if doc.Bundle, err = unmarshalToken(raw.Bundle); err != nil {
+			return nil, errs.New("invalid bundle: %v", err)
+		}
+	}
+	return doc, nil
+}
+
+// EncodePayload serializes an unsigned payload, e.g. to submit it to the
+// server for signing.
+func EncodePayload(payload *Payload) (string, error) {
+	return encode(payload)
+}
+
+// DecodePayload parses a payload produced by EncodePayload.
+func DecodePayload(encoded string) (*Payload, error) {
+	payload := new(Payload)
+	if err := decode(encoded, payload); err != nil {
+		return nil, err
+	}
+	if err := checkPayload(payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+func encode(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", errs.New("unable to marshal LSVID: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decode(encoded string, v interface{}) error {
+	data, err := decodeBase64(encoded)
+	if err != nil {
+		return err
+	}
+	return unmarshalJSON(data, v)
+}
+
+func decodeBase64(encoded string) ([]byte, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errs.New("unable to decode LSVID: %v", err)
+	}
+	return data, nil
+}
+
+func unmarshalJSON(data []byte, v interface{}) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return errs.New("unable to unmarshal LSVID: %v", err)
+	}
+	return nil
+}
+
+// unmarshalToken parses a JSON token and checks that it is encoded as it
+// was signed. See checkCanonical.
+func unmarshalToken(data []byte) (*Token, error) {
+	token := new(Token)
+	if err := unmarshalJSON(data, token); err != nil {
+		return nil, err
+	}
+	if err := checkToken(token); err != nil {
+		return nil, err
+	}
+	if err := checkCanonical(data, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// checkCanonical verifies that the received JSON holds exactly the members
+// of the value it was decoded into. Signatures are verified over the
+// re-encoding of the decoded value, while verifiers written in other
+// languages canonicalize the received JSON: both must see the same bytes.
+// This rejects unknown and duplicate members, member names that only match
+// case-insensitively and explicit zero values.
+func checkCanonical(data []byte, v interface{}) error {
+	received, err := jsoncanonicalizer.Transform(data)
+	if err != nil {
+		return errs.New("unable to unmarshal LSVID: %v", err)
+	}
+	reencoded, err := marshalCanonical(v)
+	if err != nil {
+		return errs.New("unable to unmarshal LSVID: %v", err)
+	}
+	if !bytes.Equal(received, reencoded) {
+		return errs.New("unable to unmarshal LSVID: JSON is not encoded as signed")
+	}
+	return nil
+}
+
+// marshalCanonical serializes the value as canonical JSON (RFC 8785).
+func marshalCanonical(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return jsoncanonicalizer.Transform(data)
+}
+
+func isNullJSON(data json.RawMessage) bool {
+	return len(data) == 0 || string(data) == "null"
+}
+
+// checkToken verifies that every layer of the token is well formed, uses a
+// supported version and shares the encoding of the outermost layer.
+func checkToken(token *Token) error {
+	if err := checkNesting(token, 0); err != nil {
+		return err
+	}
+	for layer := token; layer != nil; layer = layer.Nested {
+		if layer.Payload == nil {
+			return errs.New("LSVID layer missing payload")
+		}
+		if err := checkPayload(layer.Payload); err != nil {
+			return err
+		}
+		if err := checkLayerType(layer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// layerEncoding is the encoded header and payload of a COSE or JWS layer: its
+// protected header and payload byte strings, or its JOSE header and JWT
+// claims set.
+type layerEncoding struct {
+	header  []byte
+	payload []byte
+}
+
+// setLayerEncoding encodes a COSE or JWS layer that is about to be signed, so
+// that it is marshaled as it was signed.
+func setLayerEncoding(token *Token) error {
+	var header, payload []byte
+	var err error
+	switch token.Payload.Typ {
+	case TypCOSE:
+		header, payload, err = encodeCOSEParts(token)
+	case TypJWS:
+		header, payload, err = encodeJWSParts(token)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	token.encoding = &layerEncoding{header: header, payload: payload}
+	return nil
+}
+
+// checkNesting verifies that the token, enclosed in the given number of
+// layers, is not nested more than MaxNesting layers deep.
+func checkNesting(token *Token, nesting int) error {
+	for layer := token; layer != nil; layer = layer.Nested {
+		if nesting++; nesting > MaxNesting {
+			return errs.New("LSVID is nested more than %d layers deep", MaxNesting)
+		}
+		if layer.Payload == nil {
+			continue
+		}
+		for _, claim := range []*IDClaim{layer.Payload.Iss, layer.Payload.Sub, layer.Payload.Aud} {
+			if claim == nil || claim.ID == nil {
+				continue
+			}
+			if err := checkNesting(claim.ID, nesting); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkPayload(payload *Payload) error {
+	if payload.Ver != Version {
+		return errs.New("unsupported LSVID version %d", payload.Ver)
+	}
+	if payload.Typ != "" && payload.Typ != TypCOSE && payload.Typ != TypJWS {
+		return errs.New("unsupported LSVID type %q", payload.Typ)
+	}
+	return CheckClaims(payload)
+}
+
+// checkLayerType verifies that the layer shares the encoding of the layer it
+// extends and of the LSVID of its issuer, if any.
+func checkLayerType(layer *Token) error {
+	typ := layer.Payload.Typ
+	if layer.Nested != nil && layer.Nested.Payload != nil && layer.Nested.Payload.Typ != typ {
+		return errs.New("LSVID layer type %q does not match type %q of the extended layer", typ, layer.Nested.Payload.Typ)
+	}
+	if iss := layer.Payload.Iss; iss != nil && iss.ID != nil && iss.ID.Payload != nil && iss.ID.Payload.Typ != typ {
+		return errs.New("LSVID layer type %q does not match type %q of the LSVID of issuer %q", typ, iss.ID.Payload.Typ, iss.CN)
+	}
+	return nil
+}
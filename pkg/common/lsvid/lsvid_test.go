@@ -0,0 +1,819 @@
+package lsvid
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spiffe/spire/test/clock"
+	"github.com/spiffe/spire/test/testkey"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	tdID       = "spiffe://example.org"
+	agentID    = "spiffe://example.org/agent"
+	workloadID = "spiffe://example.org/workload"
+	serviceID  = "spiffe://example.org/service"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	rootKey := newKey(t)
+	token := signRoot(t, rootKey, newKey(t), workloadID, agentID)
+
+	encoded, err := Encode(token)
+	require.NoError(t, err)
+
+	decoded, err := Decode(encoded)
+	require.NoError(t, err)
+	require.Equal(t, token, decoded)
+	require.NoError(t, Validate(decoded))
+}
+
+func TestEncodeDecodeLSVID(t *testing.T) {
+	rootKey := newKey(t)
+	doc := &LSVID{
+		Token:  signRoot(t, rootKey, newKey(t), workloadID, agentID),
+		Bundle: signRoot(t, rootKey, rootKey, tdID, tdID),
+	}
+
+	encoded, err := EncodeLSVID(doc)
+	require.NoError(t, err)
+
+	decoded, err := DecodeLSVID(encoded)
+	require.NoError(t, err)
+	require.Equal(t, doc, decoded)
+}
+
+func TestDecodeFailures(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		encoded string
+		err     string
+	}{
+		{
+			name:    "not base64",
+			encoded: "not base64!",
+			err:     "unable to decode LSVID",
+		},
+		{
+			name:    "not JSON",
+			encoded: "bm90IGpzb24",
+			err:     "unable to unmarshal LSVID",
+		},
+		{
+			name:    "missing payload",
+			encoded: encodeJSON(`{"signature":"AAAA"}`),
+			err:     "LSVID layer missing payload",
+		},
+		{
+			name:    "unsupported version",
+			encoded: encodeJSON(`{"payload":{"ver":2},"signature":"AAAA"}`),
+			err:     "unsupported LSVID version 2",
+		},
+		{
+			name:    "unsupported nested version",
+			encoded: encodeJSON(`{"nested":{"payload":{},"signature":"AAAA"},"payload":{"ver":1},"signature":"AAAA"}`),
+			err:     "unsupported LSVID version 0",
+		},
+		{
+			name:    "unknown member",
+			encoded: encodeJSON(`{"payload":{"ver":1,"scope":"all"},"signature":"AAAA"}`),
+			err:     "unable to unmarshal LSVID: JSON is not encoded as signed",
+		},
+		{
+			name:    "case-variant member name",
+			encoded: encodeJSON(`{"payload":{"Ver":1},"signature":"AAAA"}`),
+			err:     "unable to unmarshal LSVID: JSON is not encoded as signed",
+		},
+		{
+			name:    "duplicate member",
+			encoded: encodeJSON(`{"payload":{"ver":1,"exp":1,"exp":2},"signature":"AAAA"}`),
+			err:     "unable to unmarshal LSVID: Duplicate key: exp",
+		},
+		{
+			name:    "explicit zero value",
+			encoded: encodeJSON(`{"payload":{"ver":1,"crit":[]},"signature":"AAAA"}`),
+			err:     "unable to unmarshal LSVID: JSON is not encoded as signed",
+		},
+		{
+			name:    "null nested layer",
+			encoded: encodeJSON(`{"nested":null,"payload":{"ver":1},"signature":"AAAA"}`),
+			err:     "unable to unmarshal LSVID: JSON is not encoded as signed",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := Decode(tt.encoded)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.err)
+			require.Nil(t, token)
+		})
+	}
+}
+
+func TestDecodeRequiresSignedEncoding(t *testing.T) {
+	rootKey := newKey(t)
+	token := signRoot(t, rootKey, newKey(t), workloadID, agentID)
+	encoded, err := Encode(token)
+	require.NoError(t, err)
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+
+	// Members added to a signed token would be dropped by a Go decoder, but
+	// kept by a verifier canonicalizing the received JSON, so that both would
+	// disagree on the signing input.
+	for _, tampered := range []string{
+		strings.Replace(string(data), `"payload":{`, `"payload":{"https://example.org/admin":true,`, 1),
+		strings.Replace(string(data), `"aud":{`, `"AUD":{"cn":"spiffe://example.org/evil"},"aud":{`, 1),
+	} {
+		_, err := Decode(base64.RawURLEncoding.EncodeToString([]byte(tampered)))
+		require.EqualError(t, err, "unable to unmarshal LSVID: JSON is not encoded as signed")
+
+		doc := `{"token":` + tampered + `}`
+		_, err = DecodeLSVID(base64.RawURLEncoding.EncodeToString([]byte(doc)))
+		require.EqualError(t, err, "unable to unmarshal LSVID: JSON is not encoded as signed")
+
+		doc = `{"token":` + string(data) + `,"bundle":` + tampered + `}`
+		_, err = DecodeLSVID(base64.RawURLEncoding.EncodeToString([]byte(doc)))
+		require.EqualError(t, err, "invalid bundle: unable to unmarshal LSVID: JSON is not encoded as signed")
+	}
+
+	// The document wrapping the token is not signed, and may omit the bundle.
+	doc, err := DecodeLSVID(base64.RawURLEncoding.EncodeToString([]byte(`{"token":` + string(data) + `}`)))
+	require.NoError(t, err)
+	require.Equal(t, token, doc.Token)
+	require.Nil(t, doc.Bundle)
+}
+
+func TestExtendAndValidate(t *testing.T) {
+	rootKey := newKey(t)
+	agentKey := newKey(t)
+	workloadKey := newKey(t)
+
+	agentLSVID := signRoot(t, rootKey, agentKey, agentID, agentID)
+	workloadLSVID := signRoot(t, rootKey, workloadKey, workloadID, agentID)
+
+	// The agent extends the workload LSVID, proving its identity with its own
+	// LSVID.
+	extended, err := Extend(workloadLSVID, &Payload{
+		Ver: Version,
+		Alg: AlgES256,
+		Iss: &IDClaim{CN: agentID, ID: agentLSVID},
+		Aud: &IDClaim{CN: workloadID},
+	}, agentKey)
+	require.NoError(t, err)
+	require.NoError(t, Validate(extended))
+	require.Equal(t, 2, extended.Depth())
+	require.Equal(t, workloadID, extended.Subject().CN)
+
+	// The workload extends it again to delegate to a service.
+	delegated, err := Extend(extended, &Payload{
+		Ver: Version,
+		Alg: AlgES256,
+		Iss: &IDClaim{CN: workloadID},
+		Aud: &IDClaim{CN: serviceID},
+	}, workloadKey)
+	require.NoError(t, err)
+	require.NoError(t, Validate(delegated))
+	require.Equal(t, 3, delegated.Depth())
+	require.Equal(t, workloadLSVID, delegated.Root())
+}
+
+func TestValidateFailures(t *testing.T) {
+	rootKey := newKey(t)
+	agentKey := newKey(t)
+	workloadKey := newKey(t)
+
+	agentLSVID := signRoot(t, rootKey, agentKey, agentID, agentID)
+
+	for _, tt := range []struct {
+		name   string
+		token  func(t *testing.T) *Token
+		err    string
+		reason Reason
+		depth  int
+	}{
+		{
+			name: "tampered root payload",
+			token: func(t *testing.T) *Token {
+				token := signRoot(t, rootKey, workloadKey, workloadID, agentID)
+				token.Payload.Sub.CN = serviceID
+				return token
+			},
+			err:    "invalid signature on layer issued by \"spiffe://example.org\"",
+			reason: ReasonBadSignature,
+			depth:  1,
+		},
+		{
+			name: "root missing issuer key",
+			token: func(t *testing.T) *Token {
+				token, err := Sign(&Payload{
+					Ver: Version,
+					Exp: time.Now().Add(time.Hour).Unix(),
+					Iss: &IDClaim{CN: tdID},
+					Sub: &IDClaim{CN: workloadID},
+				}, rootKey)
+				require.NoError(t, err)
+				return token
+			},
+			err:    "root LSVID layer missing issuer key",
+			reason: ReasonUntrustedIssuer,
+			depth:  1,
+		},
+		{
+			name: "root missing subject",
+			token: func(t *testing.T) *Token {
+				token, err := Sign(&Payload{
+					Ver: Version,
+					Exp: time.Now().Add(time.Hour).Unix(),
+					Iss: &IDClaim{CN: tdID, PK: marshalKey(t, rootKey)},
+				}, rootKey)
+				require.NoError(t, err)
+				return token
+			},
+			err:    "root LSVID layer missing subject",
+			reason: ReasonMalformed,
+			depth:  0,
+		},
+		{
+			name: "broken aud -> iss link",
+			token: func(t *testing.T) *Token {
+				token, err := Extend(signRoot(t, rootKey, workloadKey, workloadID, serviceID), &Payload{
+					Ver: Version,
+					Iss: &IDClaim{CN: agentID, ID: agentLSVID},
+					Aud: &IDClaim{CN: workloadID},
+				}, agentKey)
+				require.NoError(t, err)
+				return token
+			},
+			err:    "issuer \"spiffe://example.org/agent\" is not the audience of the extended LSVID",
+			reason: ReasonBrokenChain,
+			depth:  2,
+		},
+		{
+			name: "signed by wrong key",
+			token: func(t *testing.T) *Token {
+				token, err := Extend(signRoot(t, rootKey, workloadKey, workloadID, agentID), &Payload{
+					Ver: Version,
+					Iss: &IDClaim{CN: agentID, ID: agentLSVID},
+					Aud: &IDClaim{CN: workloadID},
+				}, workloadKey)
+				require.NoError(t, err)
+				return token
+			},
+			err:    "invalid signature on layer issued by \"spiffe://example.org/agent\"",
+			reason: ReasonBadSignature,
+			depth:  2,
+		},
+		{
+			name: "issuer LSVID for another identity",
+			token: func(t *testing.T) *Token {
+				token, err := Extend(signRoot(t, rootKey, workloadKey, workloadID, serviceID), &Payload{
+					Ver: Version,
+					Iss: &IDClaim{CN: serviceID, ID: agentLSVID},
+					Aud: &IDClaim{CN: workloadID},
+				}, agentKey)
+				require.NoError(t, err)
+				return token
+			},
+			err:    "issuer LSVID does not identify \"spiffe://example.org/service\"",
+			reason: ReasonUntrustedIssuer,
+			depth:  2,
+		},
+		{
+			name: "missing expiration",
+			token: func(t *testing.T) *Token {
+				token := signRoot(t, rootKey, workloadKey, workloadID, agentID)
+				token.Payload.Exp = 0
+				return resign(t, token, rootKey)
+			},
+			err:    "layer issued by \"spiffe://example.org\" missing expiration",
+			reason: ReasonMalformed,
+			depth:  1,
+		},
+		{
+			name: "outlives extended LSVID",
+			token: func(t *testing.T) *Token {
+				token, err := Extend(signRoot(t, rootKey, workloadKey, workloadID, agentID), &Payload{
+					Ver: Version,
+					Iss: &IDClaim{CN: agentID, ID: agentLSVID},
+					Aud: &IDClaim{CN: workloadID},
+				}, agentKey)
+				require.NoError(t, err)
+				token.Payload.Exp++
+				return resign(t, token, agentKey)
+			},
+			err:    "layer issued by \"spiffe://example.org/agent\" outlives the extended LSVID",
+			reason: ReasonExpiryIncreases,
+			depth:  2,
+		},
+		{
+			name: "unknown issuer key",
+			token: func(t *testing.T) *Token {
+				token, err := Extend(signRoot(t, rootKey, workloadKey, workloadID, agentID), &Payload{
+					Ver: Version,
+					Iss: &IDClaim{CN: agentID},
+					Aud: &IDClaim{CN: workloadID},
+				}, agentKey)
+				require.NoError(t, err)
+				return token
+			},
+			err:    "unable to resolve key for issuer \"spiffe://example.org/agent\"",
+			reason: ReasonUntrustedIssuer,
+			depth:  2,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.token(t))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.err)
+
+			var verr *ValidationError
+			require.True(t, errors.As(err, &verr))
+			require.Equal(t, tt.reason, verr.Reason)
+			require.Equal(t, tt.depth, verr.Depth)
+		})
+	}
+}
+
+func TestSignFailures(t *testing.T) {
+	key := newKey(t)
+
+	_, err := Sign(nil, key)
+	require.EqualError(t, err, "no payload to sign")
+
+	_, err = Sign(&Payload{Ver: Version}, nil)
+	require.EqualError(t, err, "no key to sign with")
+
+	_, err = Sign(&Payload{}, key)
+	require.EqualError(t, err, "unsupported LSVID version 0")
+
+	_, err = Sign(&Payload{Ver: Version}, key)
+	require.EqualError(t, err, "payload missing expiration")
+
+	_, err = Extend(nil, &Payload{Ver: Version}, key)
+	require.EqualError(t, err, "no LSVID to extend")
+}
+
+func TestExtendCapsExpiration(t *testing.T) {
+	workloadKey := newKey(t)
+	token := signRoot(t, newKey(t), workloadKey, workloadID, workloadID)
+
+	// Without an expiration, the extension inherits the one of the token.
+	extended, err := Extend(token, &Payload{
+		Ver: Version,
+		Iss: &IDClaim{CN: workloadID},
+		Aud: &IDClaim{CN: serviceID},
+	}, workloadKey)
+	require.NoError(t, err)
+	require.Equal(t, token.Payload.Exp, extended.Payload.Exp)
+	require.Equal(t, token.ExpiresAt(), extended.ExpiresAt())
+
+	// An extension can not outlive the token it extends.
+	extended, err = Extend(token, &Payload{
+		Ver: Version,
+		Exp: token.Payload.Exp + 3600,
+		Iss: &IDClaim{CN: workloadID},
+		Aud: &IDClaim{CN: serviceID},
+	}, workloadKey)
+	require.NoError(t, err)
+	require.Equal(t, token.Payload.Exp, extended.Payload.Exp)
+
+	// But it can expire sooner.
+	extended, err = Extend(token, &Payload{
+		Ver: Version,
+		Exp: token.Payload.Exp - 60,
+		Iss: &IDClaim{CN: workloadID},
+		Aud: &IDClaim{CN: serviceID},
+	}, workloadKey)
+	require.NoError(t, err)
+	require.Equal(t, token.Payload.Exp-60, extended.Payload.Exp)
+	require.NoError(t, Validate(extended))
+}
+
+func TestSignDoesNotModifyPayload(t *testing.T) {
+	rootKey := newKey(t)
+	workloadKey := newKey(t)
+
+	// Signing a COSE root layer drops the issuer key referenced by key ID.
+	payload := &Payload{
+		Ver: Version,
+		Typ: TypCOSE,
+		Exp: time.Now().Add(time.Hour).Unix(),
+		Iss: &IDClaim{CN: tdID, PK: marshalKey(t, rootKey), Kid: "kid"},
+		Sub: &IDClaim{CN: workloadID, PK: marshalKey(t, workloadKey)},
+		Aud: &IDClaim{CN: workloadID},
+	}
+	original := copyPayload(payload)
+	token, err := Sign(payload, rootKey)
+	require.NoError(t, err)
+	require.Equal(t, original, payload)
+	require.Nil(t, token.Payload.Iss.PK)
+	require.NotEmpty(t, token.Payload.Jti)
+	require.Equal(t, AlgES256, token.Payload.Alg)
+
+	// The same payload can be used to extend a token more than once.
+	payload = &Payload{
+		Ver: Version,
+		Iss: &IDClaim{CN: workloadID},
+		Aud: &IDClaim{CN: serviceID},
+	}
+	original = copyPayload(payload)
+	first, err := Extend(token, payload, workloadKey)
+	require.NoError(t, err)
+	second, err := Extend(token, payload, workloadKey)
+	require.NoError(t, err)
+	require.Equal(t, original, payload)
+	require.Equal(t, token.Payload.Exp, first.Payload.Exp)
+	require.Equal(t, TypCOSE, first.Payload.Typ)
+	require.NotEqual(t, first.Payload.Jti, second.Payload.Jti)
+}
+
+func TestValidateExpiration(t *testing.T) {
+	clk := clock.NewMock(t)
+	workloadKey := newKey(t)
+	token := signRoot(t, newKey(t), workloadKey, workloadID, workloadID)
+	token, err := Extend(token, &Payload{
+		Ver: Version,
+		Iat: token.Payload.Iat,
+		Exp: token.Payload.Iat + 60,
+		Iss: &IDClaim{CN: workloadID},
+		Aud: &IDClaim{CN: serviceID},
+	}, workloadKey)
+	require.NoError(t, err)
+
+	issuedAt := time.Unix(token.Payload.Iat, 0)
+	expiresAt := token.ExpiresAt()
+
+	clk.Set(issuedAt)
+	require.NoError(t, Validate(token, WithClock(clk)))
+
+	// Within the default clock skew
+	clk.Set(expiresAt.Add(DefaultClockSkew))
+	require.NoError(t, Validate(token, WithClock(clk)))
+	clk.Set(issuedAt.Add(-DefaultClockSkew))
+	require.NoError(t, Validate(token, WithClock(clk)))
+
+	// Outside the default clock skew
+	clk.Set(expiresAt.Add(DefaultClockSkew + time.Second))
+	require.EqualError(t, Validate(token, WithClock(clk)), "layer issued by \"spiffe://example.org/workload\" has expired")
+	clk.Set(issuedAt.Add(-DefaultClockSkew - time.Second))
+	require.EqualError(t, Validate(token, WithClock(clk)), "layer issued by \"spiffe://example.org/workload\" is not valid yet")
+
+	// With a custom clock skew
+	clk.Set(expiresAt.Add(time.Second))
+	require.Error(t, Validate(token, WithClock(clk), WithClockSkew(0)))
+	clk.Set(expiresAt.Add(time.Hour))
+	require.NoError(t, Validate(token, WithClock(clk), WithClockSkew(time.Hour)))
+}
+
+func TestValidateX509SVIDBinding(t *testing.T) {
+	rootKey := newKey(t)
+	current := &x509.Certificate{Raw: []byte("current")}
+	rotated := &x509.Certificate{Raw: []byte("rotated")}
+
+	token := signRoot(t, rootKey, newKey(t), workloadID, agentID)
+	token.Payload.Sub.X5T = X509SVIDThumbprint(current)
+	token = resign(t, token, rootKey)
+
+	lookup := func(svids ...*x509.Certificate) X509SVIDLookup {
+		return func(spiffeID string) ([]*x509.Certificate, bool) {
+			require.Equal(t, workloadID, spiffeID)
+			return svids, svids != nil
+		}
+	}
+
+	require.NoError(t, Validate(token))
+	require.NoError(t, Validate(token, WithX509SVIDs(lookup())))
+	require.NoError(t, Validate(token, WithX509SVIDs(lookup(current))))
+	require.NoError(t, Validate(token, WithX509SVIDs(lookup(rotated, current))))
+	require.EqualError(t, Validate(token, WithX509SVIDs(lookup(rotated))), "subject \"spiffe://example.org/workload\" is no longer bound to a current X509-SVID")
+}
+
+func TestValidateAuthorities(t *testing.T) {
+	rootKey := newKey(t)
+	otherKey := newKey(t)
+	agentKey := newKey(t)
+
+	token := signRoot(t, rootKey, newKey(t), workloadID, agentID)
+
+	lookup := func(keys ...crypto.Signer) AuthorityLookup {
+		return func(trustDomainID string) (map[string]crypto.PublicKey, bool) {
+			require.Equal(t, tdID, trustDomainID)
+			if keys == nil {
+				return nil, false
+			}
+			authorities := make(map[string]crypto.PublicKey)
+			for i, key := range keys {
+				authorities[fmt.Sprintf("kid%d", i)] = key.Public()
+			}
+			return authorities, true
+		}
+	}
+
+	require.NoError(t, Validate(token, WithAuthorities(lookup(rootKey))))
+	require.NoError(t, Validate(token, WithAuthorities(lookup(otherKey, rootKey))))
+	require.EqualError(t, Validate(token, WithAuthorities(lookup())), `no authorities found for trust domain "spiffe://example.org"`)
+	require.EqualError(t, Validate(token, WithAuthorities(lookup(otherKey))), `root layer issuer key is not an authority of trust domain "spiffe://example.org"`)
+
+	// The trust domain may only issue LSVIDs for its own subjects.
+	foreignSubject := signRoot(t, rootKey, newKey(t), "spiffe://other.org/workload", agentID)
+	require.NoError(t, Validate(foreignSubject))
+	require.EqualError(t, Validate(foreignSubject, WithAuthorities(lookup(rootKey))), `root layer issuer "spiffe://example.org" may not issue LSVIDs for subject "spiffe://other.org/workload"`)
+
+	// The root of the issuer LSVID must be anchored too.
+	extended, err := Extend(signRoot(t, rootKey, newKey(t), workloadID, agentID), &Payload{
+		Ver: Version,
+		Iss: &IDClaim{CN: agentID, ID: signRoot(t, otherKey, agentKey, agentID, agentID)},
+		Aud: &IDClaim{CN: workloadID},
+	}, agentKey)
+	require.NoError(t, err)
+	require.NoError(t, Validate(extended, WithAuthorities(lookup(rootKey, otherKey))))
+	require.EqualError(t, Validate(extended, WithAuthorities(lookup(rootKey))), `invalid issuer LSVID for "spiffe://example.org/agent": root layer issuer key is not an authority of trust domain "spiffe://example.org"`)
+
+	// With a key ID, the root key is resolved from the bundle.
+	withKid := func(kid string, pk []byte) *Token {
+		token := signRoot(t, rootKey, newKey(t), workloadID, agentID)
+		token.Payload.Iss.Kid = kid
+		token.Payload.Iss.PK = pk
+		return resign(t, token, rootKey)
+	}
+	require.NoError(t, Validate(withKid("kid0", marshalKey(t, rootKey)), WithAuthorities(lookup(rootKey))))
+	require.NoError(t, Validate(withKid("kid1", nil), WithAuthorities(lookup(otherKey, rootKey))))
+	require.EqualError(t, Validate(withKid("kid1", nil), WithAuthorities(lookup(rootKey))), `key "kid1" not found for trust domain "spiffe://example.org"`)
+	require.EqualError(t, Validate(withKid("kid0", marshalKey(t, rootKey)), WithAuthorities(lookup(otherKey, rootKey))), `root layer issuer key does not match key "kid0" of trust domain "spiffe://example.org"`)
+	require.EqualError(t, Validate(withKid("kid0", nil), WithAuthorities(lookup(otherKey, rootKey))), `invalid signature on layer issued by "spiffe://example.org": signature verification failed`)
+	require.EqualError(t, Validate(withKid("kid0", nil)), "root LSVID layer missing issuer key")
+}
+
+func TestAlgorithms(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		key       crypto.Signer
+		alg       string
+		expectAlg string
+	}{
+		{name: "P-256", key: newKey(t), expectAlg: AlgES256},
+		{name: "P-384", key: testkey.NewEC384(t), expectAlg: AlgES384},
+		{name: "P-521", key: newP521Key(t), expectAlg: AlgES512},
+		{name: "RSA", key: testkey.NewRSA2048(t), expectAlg: AlgRS256},
+		{name: "RSA with PSS", key: testkey.NewRSA2048(t), alg: AlgPS256, expectAlg: AlgPS256},
+		{name: "Ed25519", key: newEd25519Key(t), expectAlg: AlgEdDSA},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			workloadKey := newKey(t)
+			token, err := Sign(&Payload{
+				Ver: Version,
+				Alg: tt.alg,
+				Exp: time.Now().Add(time.Hour).Unix(),
+				Iss: &IDClaim{CN: tdID, PK: marshalKey(t, tt.key)},
+				Sub: &IDClaim{CN: workloadID, PK: marshalKey(t, workloadKey)},
+				Aud: &IDClaim{CN: agentID},
+			}, tt.key)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectAlg, token.Payload.Alg)
+			require.NoError(t, Validate(token))
+
+			// The algorithm is covered by the signature.
+			token.Payload.Alg = AlgES256
+			if tt.expectAlg == AlgES256 {
+				token.Payload.Alg = AlgES384
+			}
+			require.Error(t, Validate(token))
+		})
+	}
+}
+
+func TestAlgorithmMismatch(t *testing.T) {
+	rootKey := newKey(t)
+	token := signRoot(t, rootKey, newKey(t), workloadID, agentID)
+
+	_, err := Sign(&Payload{Ver: Version, Alg: AlgES384, Exp: token.Payload.Exp}, rootKey)
+	require.EqualError(t, err, `signature algorithm "ES384" does not match ES256 key`)
+
+	_, err = Sign(&Payload{Ver: Version, Alg: AlgPS256, Exp: token.Payload.Exp}, rootKey)
+	require.EqualError(t, err, `signature algorithm "PS256" does not match ES256 key`)
+
+	_, err = Sign(&Payload{Ver: Version, Alg: AlgES256, Exp: token.Payload.Exp}, newEd25519Key(t))
+	require.EqualError(t, err, `signature algorithm "ES256" does not match EdDSA key`)
+
+	token.Payload.Alg = AlgES384
+	require.EqualError(t, Validate(resign(t, token, rootKey)), `invalid signature on layer issued by "spiffe://example.org": signature algorithm "ES384" does not match ES256 key`)
+
+	token.Payload.Alg = ""
+	require.EqualError(t, Validate(token), `invalid signature on layer issued by "spiffe://example.org": missing signature algorithm`)
+}
+
+func TestClaims(t *testing.T) {
+	workloadKey := newKey(t)
+	token := signRoot(t, newKey(t), workloadKey, workloadID, workloadID)
+
+	const (
+		orderClaim = "https://example.org/order_id"
+		scopeClaim = "urn:example:scope"
+	)
+
+	extended, err := Extend(token, &Payload{
+		Ver: Version,
+		Iss: &IDClaim{CN: workloadID},
+		Aud: &IDClaim{CN: workloadID},
+		Claims: map[string]interface{}{
+			orderClaim: "order-1",
+			scopeClaim: []interface{}{"read"},
+		},
+	}, workloadKey)
+	require.NoError(t, err)
+
+	delegated, err := Extend(extended, &Payload{
+		Ver: Version,
+		Iss: &IDClaim{CN: workloadID},
+		Aud: &IDClaim{CN: serviceID},
+		Claims: map[string]interface{}{
+			scopeClaim: []interface{}{"read", "write"},
+		},
+		Crit: []string{scopeClaim},
+	}, workloadKey)
+	require.NoError(t, err)
+
+	// Claims survive the encoding and are covered by the signature.
+	encoded, err := Encode(delegated)
+	require.NoError(t, err)
+	decoded, err := Decode(encoded)
+	require.NoError(t, err)
+	require.NoError(t, Validate(decoded, WithKnownClaims(scopeClaim)))
+
+	// Outer layers override the claims of the layers they extend.
+	require.Equal(t, map[string]interface{}{
+		orderClaim: "order-1",
+		scopeClaim: []interface{}{"read", "write"},
+	}, decoded.Claims())
+	require.Empty(t, token.Claims())
+
+	// Critical claims must be understood by the validator.
+	err = Validate(decoded)
+	require.EqualError(t, err, `layer issued by "spiffe://example.org/workload" has unknown critical claim "urn:example:scope"`)
+	err = Validate(decoded, WithKnownClaims(orderClaim))
+	require.EqualError(t, err, `layer issued by "spiffe://example.org/workload" has unknown critical claim "urn:example:scope"`)
+
+	// Tampering with a claim invalidates the signature.
+	decoded.Nested.Payload.Claims[orderClaim] = "order-2"
+	err = Validate(decoded, WithKnownClaims(scopeClaim))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "signature verification failed")
+}
+
+func TestClaimsFailures(t *testing.T) {
+	key := newKey(t)
+	for _, tt := range []struct {
+		name   string
+		claims map[string]interface{}
+		crit   []string
+		err    string
+	}{
+		{
+			name:   "name not namespaced",
+			claims: map[string]interface{}{"order_id": "1"},
+			err:    `claim name "order_id" is not an absolute URI`,
+		},
+		{
+			name:   "name without scheme",
+			claims: map[string]interface{}{"//example.org/order_id": "1"},
+			err:    `claim name "//example.org/order_id" is not an absolute URI`,
+		},
+		{
+			name: "critical claim missing",
+			crit: []string{"urn:example:scope"},
+			err:  `critical claim "urn:example:scope" missing from payload`,
+		},
+		{
+			name:   "critical claim duplicated",
+			claims: map[string]interface{}{"urn:example:scope": "read"},
+			crit:   []string{"urn:example:scope", "urn:example:scope"},
+			err:    `critical claim "urn:example:scope" listed more than once`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			payload := &Payload{
+				Ver:    Version,
+				Exp:    time.Now().Add(time.Hour).Unix(),
+				Iss:    &IDClaim{CN: tdID},
+				Sub:    &IDClaim{CN: workloadID},
+				Claims: tt.claims,
+				Crit:   tt.crit,
+			}
+			_, err := Sign(payload, key)
+			require.EqualError(t, err, tt.err)
+
+			encoded, err := EncodePayload(payload)
+			require.NoError(t, err)
+			_, err = DecodePayload(encoded)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestSigningInput(t *testing.T) {
+	root := &Token{
+		Payload: &Payload{
+			Ver: Version,
+			Alg: AlgES256,
+			Iat: 1,
+			Iss: &IDClaim{CN: tdID, PK: []byte{1, 2}},
+			Sub: &IDClaim{CN: workloadID, PK: []byte{3}},
+			Aud: &IDClaim{CN: agentID},
+		},
+		Signature: []byte{4},
+	}
+	input, err := SigningInput(root)
+	require.NoError(t, err)
+	require.Equal(t, `{"payload":{"alg":"ES256","aud":{"cn":"spiffe://example.org/agent"},"iat":1,"iss":{"cn":"spiffe://example.org","pk":"AQI="},"sub":{"cn":"spiffe://example.org/workload","pk":"Aw=="},"ver":1}}`, string(input))
+
+	extended := &Token{
+		Nested: root,
+		Payload: &Payload{
+			Ver: Version,
+			Iss: &IDClaim{CN: agentID},
+			Aud: &IDClaim{CN: serviceID},
+		},
+	}
+	input, err = SigningInput(extended)
+	require.NoError(t, err)
+	require.Equal(t, `{"nested":{"payload":{"alg":"ES256","aud":{"cn":"spiffe://example.org/agent"},"iat":1,"iss":{"cn":"spiffe://example.org","pk":"AQI="},"sub":{"cn":"spiffe://example.org/workload","pk":"Aw=="},"ver":1},"signature":"BA=="},"payload":{"aud":{"cn":"spiffe://example.org/service"},"iss":{"cn":"spiffe://example.org/agent"},"ver":1}}`, string(input))
+}
+
+func TestSignatureCoversSigningInput(t *testing.T) {
+	key := newKey(t)
+	token := signRoot(t, key, newKey(t), workloadID, agentID)
+
+	input, err := SigningInput(token)
+	require.NoError(t, err)
+	digest := sha256.Sum256(input)
+	require.True(t, ecdsa.VerifyASN1(key.Public().(*ecdsa.PublicKey), digest[:], token.Signature))
+}
+
+func signRoot(t *testing.T, rootKey, subjectKey crypto.Signer, subject, audience string) *Token {
+	now := time.Now()
+	token, err := Sign(&Payload{
+		Ver: Version,
+		Alg: AlgES256,
+		Iat: now.Unix(),
+		Exp: now.Add(time.Hour).Unix(),
+		Iss: &IDClaim{CN: tdID, PK: marshalKey(t, rootKey)},
+		Sub: &IDClaim{CN: subject, PK: marshalKey(t, subjectKey)},
+		Aud: &IDClaim{CN: audience},
+	}, rootKey)
+	require.NoError(t, err)
+	return token
+}
+
+// resign signs the token again after it was modified, bypassing the checks
+// performed by Sign and Extend.
+func resign(t *testing.T, token *Token, key crypto.Signer) *Token {
+	input, err := SigningInput(token)
+	require.NoError(t, err)
+	token.Signature, err = signInput(token.Payload.Alg, key, input)
+	require.NoError(t, err)
+	return token
+}
+
+func newKey(t testing.TB) crypto.Signer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func newP521Key(t *testing.T) crypto.Signer {
+	key, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func newEd25519Key(t testing.TB) crypto.Signer {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func marshalKey(t testing.TB, key crypto.Signer) []byte {
+	pk, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+	return pk
+}
+
+func encodeJSON(s string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
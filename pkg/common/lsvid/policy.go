@@ -0,0 +1,136 @@
+package lsvid
+
+import (
+	"path"
+
+	"github.com/zeebo/errs"
+)
+
+// ChainPolicy constrains the chain of layers of an LSVID, on top of the links
+// checked by Validate. Patterns are matched against SPIFFE IDs with
+// path.Match, so that "*" matches a single path segment.
+type ChainPolicy struct {
+	// MaxDepth is the maximum number of layers of the token. It is not
+	// enforced if zero.
+	MaxDepth int
+
+	// Hops constrains the issuers and audiences of the layers. A layer must
+	// satisfy every hop that applies to its depth.
+	Hops []ChainHop
+
+	// Audience is the audience the outermost layer must be addressed to. It
+	// is not enforced if empty.
+	Audience string
+
+	// AllowCycles allows the token to be delivered to the same audience more
+	// than once, e.g. back to an identity that already extended it.
+	AllowCycles bool
+}
+
+// ChainHop constrains the layers of an LSVID at a given depth.
+type ChainHop struct {
+	// Depth is the depth of the layers the hop applies to, counted from the
+	// root layer, at depth 1, outwards. If zero, the hop applies to every
+	// layer.
+	Depth int
+
+	// Issuers lists the patterns the issuer of the layers must match. Any
+	// issuer is allowed if empty.
+	Issuers []string
+
+	// Audiences lists the patterns the audience of the layers must match.
+	// Any audience is allowed if empty.
+	Audiences []string
+}
+
+// WithChainPolicy checks the token against the given chain policy once it is
+// otherwise valid. The policy does not apply to the issuer LSVIDs embedded in
+// the layers, which are validated on their own.
+func WithChainPolicy(policy ChainPolicy) ValidateOption {
+	return func(c *validateConfig) {
+		c.chainPolicy = &policy
+	}
+}
+
+// ValidatePatterns checks that the issuer and audience patterns of the policy
+// are well-formed.
+func (p ChainPolicy) ValidatePatterns() error {
+	for _, hop := range p.Hops {
+		for _, patterns := range [][]string{hop.Issuers, hop.Audiences} {
+			for _, pattern := range patterns {
+				if _, err := path.Match(pattern, ""); err != nil {
+					return errs.New("invalid pattern %q: %v", pattern, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkChainPolicy checks the token against the chain policy, if any.
+func (c *validateConfig) checkChainPolicy(token *Token) error {
+	p := c.chainPolicy
+	if p == nil {
+		return nil
+	}
+
+	depth := token.Depth()
+	if p.MaxDepth != 0 && depth > p.MaxDepth {
+		return layerError(ReasonChainTooDeep, depth, token, errs.New("LSVID has %d layers, more than the %d allowed", depth, p.MaxDepth))
+	}
+
+	aud := token.Payload.Aud
+	if p.Audience != "" && (aud == nil || aud.CN != p.Audience) {
+		cn := ""
+		if aud != nil {
+			cn = aud.CN
+		}
+		return layerError(ReasonAudienceMismatch, depth, token, errs.New("LSVID audience %q does not match %q", cn, p.Audience))
+	}
+
+	audiences := make(map[string]bool, depth)
+	layers := make([]*Token, depth)
+	for layer, i := token, depth-1; layer != nil; layer, i = layer.Nested, i-1 {
+		layers[i] = layer
+	}
+	for i, layer := range layers {
+		depth := i + 1
+		iss := layer.Payload.Iss.CN
+		aud := ""
+		if layer.Payload.Aud != nil {
+			aud = layer.Payload.Aud.CN
+		}
+
+		for _, hop := range p.Hops {
+			if hop.Depth != 0 && hop.Depth != depth {
+				continue
+			}
+			if !matchesAny(hop.Issuers, iss) {
+				return layerError(ReasonIssuerNotAllowed, depth, layer, errs.New("issuer %q is not allowed at depth %d", iss, depth))
+			}
+			if !matchesAny(hop.Audiences, aud) {
+				return layerError(ReasonAudienceNotAllowed, depth, layer, errs.New("audience %q of layer issued by %q is not allowed at depth %d", aud, iss, depth))
+			}
+		}
+
+		if !p.AllowCycles {
+			if audiences[aud] {
+				return layerError(ReasonChainCycle, depth, layer, errs.New("LSVID is delivered to %q more than once", aud))
+			}
+			audiences[aud] = true
+		}
+	}
+	return nil
+}
+
+func matchesAny(patterns []string, id string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, id); ok {
+			return true
+		}
+	}
+	return false
+}
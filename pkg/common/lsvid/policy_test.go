@@ -0,0 +1,186 @@
+package lsvid
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const backendID = "spiffe://example.org/backend"
+
+func TestValidateChainPolicy(t *testing.T) {
+	rootKey := newKey(t)
+	workloadKey := newKey(t)
+	serviceKey := newKey(t)
+
+	// The workload extends its LSVID to the service, which extends it to the
+	// given audience.
+	newToken := func(audience string) *Token {
+		token, err := Extend(signRoot(t, rootKey, workloadKey, workloadID, workloadID), &Payload{
+			Ver: Version,
+			Alg: AlgES256,
+			Iss: &IDClaim{CN: workloadID},
+			Aud: &IDClaim{CN: serviceID},
+		}, workloadKey)
+		require.NoError(t, err)
+		token, err = Extend(token, &Payload{
+			Ver: Version,
+			Alg: AlgES256,
+			Iss: &IDClaim{CN: serviceID, ID: signRoot(t, rootKey, serviceKey, serviceID, serviceID)},
+			Aud: &IDClaim{CN: audience},
+		}, serviceKey)
+		require.NoError(t, err)
+		return token
+	}
+	token := newToken(backendID)
+	cycle := newToken(workloadID)
+
+	for _, tt := range []struct {
+		name   string
+		token  *Token
+		policy ChainPolicy
+		err    string
+		reason Reason
+		depth  int
+	}{
+		{
+			name:  "empty policy",
+			token: token,
+		},
+		{
+			name:   "within maximum depth",
+			token:  token,
+			policy: ChainPolicy{MaxDepth: 3},
+		},
+		{
+			name:   "too deep",
+			token:  token,
+			policy: ChainPolicy{MaxDepth: 2},
+			err:    "LSVID has 3 layers, more than the 2 allowed",
+			reason: ReasonChainTooDeep,
+			depth:  3,
+		},
+		{
+			name:   "required audience",
+			token:  token,
+			policy: ChainPolicy{Audience: backendID},
+		},
+		{
+			name:   "audience mismatch",
+			token:  token,
+			policy: ChainPolicy{Audience: serviceID},
+			err:    `LSVID audience "spiffe://example.org/backend" does not match "spiffe://example.org/service"`,
+			reason: ReasonAudienceMismatch,
+			depth:  3,
+		},
+		{
+			name:  "issuers and audiences allowed",
+			token: token,
+			policy: ChainPolicy{Hops: []ChainHop{
+				{Depth: 1, Issuers: []string{tdID}},
+				{Depth: 2, Issuers: []string{"spiffe://example.org/*"}},
+				{Depth: 3, Issuers: []string{serviceID, agentID}},
+				{Audiences: []string{"spiffe://example.org/*"}},
+			}},
+		},
+		{
+			name:  "issuer not allowed",
+			token: token,
+			policy: ChainPolicy{Hops: []ChainHop{
+				{Depth: 3, Issuers: []string{agentID}},
+			}},
+			err:    `issuer "spiffe://example.org/service" is not allowed at depth 3`,
+			reason: ReasonIssuerNotAllowed,
+			depth:  3,
+		},
+		{
+			name:  "pattern does not match across path segments",
+			token: token,
+			policy: ChainPolicy{Hops: []ChainHop{
+				{Depth: 2, Issuers: []string{"spiffe://*"}},
+			}},
+			err:    `issuer "spiffe://example.org/workload" is not allowed at depth 2`,
+			reason: ReasonIssuerNotAllowed,
+			depth:  2,
+		},
+		{
+			name:  "audience not allowed",
+			token: token,
+			policy: ChainPolicy{Hops: []ChainHop{
+				{Audiences: []string{workloadID, serviceID}},
+			}},
+			err:    `audience "spiffe://example.org/backend" of layer issued by "spiffe://example.org/service" is not allowed at depth 3`,
+			reason: ReasonAudienceNotAllowed,
+			depth:  3,
+		},
+		{
+			name:   "cycle",
+			token:  cycle,
+			err:    `LSVID is delivered to "spiffe://example.org/workload" more than once`,
+			reason: ReasonChainCycle,
+			depth:  3,
+		},
+		{
+			name:   "cycle allowed",
+			token:  cycle,
+			policy: ChainPolicy{AllowCycles: true},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.policy.ValidatePatterns())
+
+			err := Validate(tt.token, WithChainPolicy(tt.policy))
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.err)
+
+			var verr *ValidationError
+			require.True(t, errors.As(err, &verr))
+			require.Equal(t, tt.reason, verr.Reason)
+			require.Equal(t, tt.depth, verr.Depth)
+		})
+	}
+
+	// Without a policy, cycles are not checked.
+	require.NoError(t, Validate(cycle))
+}
+
+func TestValidateChainPolicyPatterns(t *testing.T) {
+	policy := ChainPolicy{Hops: []ChainHop{
+		{Issuers: []string{tdID}, Audiences: []string{"spiffe://example.org/["}},
+	}}
+	require.EqualError(t, policy.ValidatePatterns(), `invalid pattern "spiffe://example.org/[": syntax error in pattern`)
+}
+
+func TestValidationErrorReason(t *testing.T) {
+	rootKey := newKey(t)
+	agentKey := newKey(t)
+
+	// An expired issuer LSVID is reported with its reason, at the depth of
+	// the layer carrying it.
+	expired := signRoot(t, rootKey, agentKey, agentID, agentID)
+	expired.Payload.Exp = expired.Payload.Iat - 3600
+	expired = resign(t, expired, rootKey)
+	token, err := Extend(signRoot(t, rootKey, newKey(t), workloadID, agentID), &Payload{
+		Ver: Version,
+		Alg: AlgES256,
+		Iss: &IDClaim{CN: agentID, ID: expired},
+		Aud: &IDClaim{CN: workloadID},
+	}, agentKey)
+	require.NoError(t, err)
+
+	err = Validate(token)
+	require.EqualError(t, err, `invalid issuer LSVID for "spiffe://example.org/agent": layer issued by "spiffe://example.org" has expired`)
+	require.Equal(t, ReasonExpired, ReasonOf(err))
+
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr))
+	require.Equal(t, 2, verr.Depth)
+	require.Equal(t, agentID, verr.Issuer)
+
+	require.Equal(t, ReasonMalformed, ReasonOf(errors.New("not a validation error")))
+}
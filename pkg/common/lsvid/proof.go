@@ -0,0 +1,239 @@
+package lsvid
+
+import (
+	"crypto"
+	"encoding/json"
+	"time"
+
+	jsoncanonicalizer "github.com/cyberphone/json-canonicalization/go/src/webpki.org/jsoncanonicalizer"
+	"github.com/zeebo/errs"
+)
+
+// Proof proves that the party presenting an LSVID holds the key the LSVID is
+// bound to, in the manner of OAuth 2.0 DPoP (RFC 9449). It is signed by the
+// holder over a nonce supplied by the verifier and the method and URI of the
+// request carrying the LSVID.
+type Proof struct {
+	Payload   *ProofPayload `json:"payload"`
+	Signature []byte        `json:"signature"`
+}
+
+// ProofPayload holds the claims of a proof of possession.
+type ProofPayload struct {
+	Alg string `json:"alg"`
+	Iat int64  `json:"iat"`
+
+	// Tid is the token ID of the outermost layer of the LSVID the proof is
+	// presented with. See Token.ID.
+	Tid string `json:"tid"`
+
+	// Nonce is the nonce supplied by the verifier.
+	Nonce string `json:"nonce"`
+
+	// Htm is the method of the request carrying the LSVID, e.g. "POST".
+	Htm string `json:"htm"`
+
+	// Htu is the URI of the request carrying the LSVID.
+	Htu string `json:"htu"`
+}
+
+// ProofRequest holds what a proof of possession covers: the nonce supplied by
+// the verifier and the method and URI of the request carrying the LSVID.
+type ProofRequest struct {
+	Nonce  string
+	Method string
+	URI    string
+}
+
+// CreateProof signs a proof of possession for the token with the key of its
+// holder. See HolderKey.
+func CreateProof(token *Token, req ProofRequest, key crypto.Signer) (*Proof, error) {
+	switch {
+	case token == nil || token.Payload == nil:
+		return nil, errs.New("no LSVID to prove possession of")
+	case key == nil:
+		return nil, errs.New("no key to sign with")
+	}
+	holder, holderKey, err := HolderKey(token)
+	if err != nil {
+		return nil, err
+	}
+	if !keyEquals(key.Public(), holderKey) {
+		return nil, errs.New("key does not match the key of holder %q", holder)
+	}
+
+	alg, err := AlgorithmForKey(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	proof := &Proof{
+		Payload: &ProofPayload{
+			Alg:   alg,
+			Iat:   time.Now().Unix(),
+			Tid:   token.ID(),
+			Nonce: req.Nonce,
+			Htm:   req.Method,
+			Htu:   req.URI,
+		},
+	}
+	input, err := proofSigningInput(proof.Payload)
+	if err != nil {
+		return nil, err
+	}
+	proof.Signature, err = signInput(alg, key, input)
+	if err != nil {
+		return nil, errs.New("unable to sign proof of possession: %v", err)
+	}
+	return proof, nil
+}
+
+// HolderKey returns the SPIFFE ID and the DER encoded (PKIX) public key of
+// the holder presenting the token to its outermost audience. This is the
+// subject of the root layer, unless the outermost layer was issued by
+// another party extending the token on behalf of the subject, in which case
+// it is the subject of the issuer LSVID of the outermost layer.
+//
+// The key is taken from the token as is: the token must be validated for the
+// key to be trusted.
+func HolderKey(token *Token) (string, []byte, error) {
+	subject := token.Subject()
+	if subject == nil {
+		return "", nil, errs.New("root LSVID layer missing subject")
+	}
+	iss := token.Payload.Iss
+	switch {
+	case token.Nested == nil || (iss != nil && iss.CN == subject.CN):
+		if len(subject.PK) == 0 {
+			return "", nil, errs.New("subject %q has no key", subject.CN)
+		}
+		return subject.CN, subject.PK, nil
+	case iss != nil && iss.ID != nil && iss.ID.Subject() != nil && iss.ID.Subject().CN == iss.CN:
+		if len(iss.ID.Subject().PK) == 0 {
+			return "", nil, errs.New("issuer %q has no key", iss.CN)
+		}
+		return iss.CN, iss.ID.Subject().PK, nil
+	default:
+		return "", nil, errs.New("unable to resolve the key of the holder of the LSVID")
+	}
+}
+
+// VerifyProof verifies that the proof was signed by the holder of the token
+// for the given request, and within the clock skew of the current time. The
+// token itself is not validated: use Validate with WithProof to do both.
+// Failures are reported as *ValidationError.
+func VerifyProof(token *Token, proof *Proof, req ProofRequest, opts ...ValidateOption) error {
+	c := newValidateConfig(opts)
+	if err := checkToken(token); err != nil {
+		return validationError(ReasonMalformed, err)
+	}
+	return c.verifyProof(token, proof, req)
+}
+
+// WithProof requires the token to be presented with a proof of possession
+// signed by its holder for the given request. See VerifyProof.
+func WithProof(proof *Proof, req ProofRequest) ValidateOption {
+	return func(c *validateConfig) {
+		c.proof = &proofConfig{proof: proof, req: req}
+	}
+}
+
+type proofConfig struct {
+	proof *Proof
+	req   ProofRequest
+}
+
+func (c *validateConfig) checkProof(token *Token) error {
+	if c.proof == nil {
+		return nil
+	}
+	return c.verifyProof(token, c.proof.proof, c.proof.req)
+}
+
+func (c *validateConfig) verifyProof(token *Token, proof *Proof, req ProofRequest) error {
+	if err := c.checkProofClaims(token, proof, req); err != nil {
+		return layerError(ReasonInvalidProof, token.Depth(), token, err)
+	}
+	return nil
+}
+
+func (c *validateConfig) checkProofClaims(token *Token, proof *Proof, req ProofRequest) error {
+	switch {
+	case proof == nil:
+		return errs.New("missing proof of possession")
+	case proof.Payload == nil:
+		return errs.New("proof of possession missing payload")
+	case proof.Payload.Tid != token.ID():
+		return errs.New("proof of possession is not bound to the LSVID")
+	case proof.Payload.Nonce != req.Nonce:
+		return errs.New("proof of possession nonce does not match")
+	case proof.Payload.Htm != req.Method:
+		return errs.New("proof of possession method %q does not match %q", proof.Payload.Htm, req.Method)
+	case proof.Payload.Htu != req.URI:
+		return errs.New("proof of possession URI %q does not match %q", proof.Payload.Htu, req.URI)
+	}
+
+	iat := time.Unix(proof.Payload.Iat, 0)
+	now := c.clock.Now()
+	if iat.Before(now.Add(-c.clockSkew)) || iat.After(now.Add(c.clockSkew)) {
+		return errs.New("proof of possession was not issued within %s of the current time", c.clockSkew)
+	}
+
+	holder, rawKey, err := HolderKey(token)
+	if err != nil {
+		return err
+	}
+	key, err := parseKey(holder, rawKey)
+	if err != nil {
+		return err
+	}
+	if err := checkAlgorithm(proof.Payload.Alg, key); err != nil {
+		return err
+	}
+	input, err := proofSigningInput(proof.Payload)
+	if err != nil {
+		return err
+	}
+	if err := verifyInput(proof.Payload.Alg, key, input, proof.Signature); err != nil {
+		return errs.New("invalid proof of possession signature for holder %q: %v", holder, err)
+	}
+	return nil
+}
+
+// EncodeProof encodes the proof as a base64url JSON document.
+func EncodeProof(proof *Proof) (string, error) {
+	return encode(proof)
+}
+
+// DecodeProof decodes a base64url JSON encoded proof. Failures are reported
+// as *ValidationError with ReasonInvalidProof.
+func DecodeProof(encoded string) (*Proof, error) {
+	data, err := decodeBase64(encoded)
+	if err != nil {
+		return nil, validationError(ReasonInvalidProof, err)
+	}
+	proof := new(Proof)
+	if err := unmarshalJSON(data, proof); err != nil {
+		return nil, validationError(ReasonInvalidProof, err)
+	}
+	if proof.Payload == nil {
+		return nil, validationError(ReasonInvalidProof, errs.New("proof of possession missing payload"))
+	}
+	if err := checkCanonical(data, proof); err != nil {
+		return nil, validationError(ReasonInvalidProof, err)
+	}
+	return proof, nil
+}
+
+// proofSigningInput returns the bytes covered by the signature of a proof:
+// the RFC 8785 (JCS) canonical serialization of its payload.
+func proofSigningInput(payload *ProofPayload) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, errs.New("unable to marshal proof of possession: %v", err)
+	}
+	canonical, err := jsoncanonicalizer.Transform(data)
+	if err != nil {
+		return nil, errs.New("unable to canonicalize proof of possession: %v", err)
+	}
+	return canonical, nil
+}
@@ -0,0 +1,171 @@
+package lsvid
+
+import (
+	"crypto"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spiffe/spire/test/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProof(t *testing.T) {
+	rootKey := newKey(t)
+	agentKey := newKey(t)
+	workloadKey := newKey(t)
+
+	root := signRoot(t, rootKey, workloadKey, workloadID, serviceID)
+
+	// The workload extends its own LSVID to the service.
+	ownRoot := signRoot(t, rootKey, workloadKey, workloadID, workloadID)
+	extendedBySubject, err := Extend(ownRoot, &Payload{
+		Ver: Version,
+		Iss: &IDClaim{CN: workloadID},
+		Aud: &IDClaim{CN: serviceID},
+	}, workloadKey)
+	require.NoError(t, err)
+
+	// The agent extends the LSVID of the workload to the service.
+	agentLSVID := signRoot(t, rootKey, agentKey, agentID, agentID)
+	agentRoot := signRoot(t, rootKey, workloadKey, workloadID, agentID)
+	extendedByAgent, err := Extend(agentRoot, &Payload{
+		Ver: Version,
+		Iss: &IDClaim{CN: agentID, ID: agentLSVID},
+		Aud: &IDClaim{CN: serviceID},
+	}, agentKey)
+	require.NoError(t, err)
+
+	req := ProofRequest{Nonce: "NONCE", Method: "POST", URI: "https://service.example.org/orders"}
+
+	for _, tt := range []struct {
+		name   string
+		token  *Token
+		key    crypto.Signer
+		holder string
+	}{
+		{name: "root layer", token: root, key: workloadKey, holder: workloadID},
+		{name: "extended by the subject", token: extendedBySubject, key: workloadKey, holder: workloadID},
+		{name: "extended by another issuer", token: extendedByAgent, key: agentKey, holder: agentID},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			holder, holderKey, err := HolderKey(tt.token)
+			require.NoError(t, err)
+			require.Equal(t, tt.holder, holder)
+			require.Equal(t, marshalKey(t, tt.key), holderKey)
+
+			proof, err := CreateProof(tt.token, req, tt.key)
+			require.NoError(t, err)
+			require.Equal(t, tt.token.ID(), proof.Payload.Tid)
+			require.NoError(t, VerifyProof(tt.token, proof, req))
+			require.NoError(t, Validate(tt.token, WithProof(proof, req)))
+
+			_, err = CreateProof(tt.token, req, newKey(t))
+			require.EqualError(t, err, `key does not match the key of holder "`+tt.holder+`"`)
+		})
+	}
+
+	proof, err := CreateProof(root, req, workloadKey)
+	require.NoError(t, err)
+	otherProof, err := CreateProof(signRoot(t, rootKey, workloadKey, workloadID, serviceID), req, workloadKey)
+	require.NoError(t, err)
+	tampered, err := CreateProof(root, req, workloadKey)
+	require.NoError(t, err)
+	tampered.Payload.Htu = "https://service.example.org/admin"
+
+	for _, tt := range []struct {
+		name  string
+		proof *Proof
+		req   ProofRequest
+		skew  time.Duration
+		err   string
+	}{
+		{
+			name: "missing proof",
+			req:  req,
+			err:  "missing proof of possession",
+		},
+		{
+			name:  "proof for another token",
+			proof: otherProof,
+			req:   req,
+			err:   "proof of possession is not bound to the LSVID",
+		},
+		{
+			name:  "nonce mismatch",
+			proof: proof,
+			req:   ProofRequest{Nonce: "OTHER", Method: req.Method, URI: req.URI},
+			err:   "proof of possession nonce does not match",
+		},
+		{
+			name:  "method mismatch",
+			proof: proof,
+			req:   ProofRequest{Nonce: req.Nonce, Method: "GET", URI: req.URI},
+			err:   `proof of possession method "POST" does not match "GET"`,
+		},
+		{
+			name:  "URI mismatch",
+			proof: proof,
+			req:   ProofRequest{Nonce: req.Nonce, Method: req.Method, URI: "https://other.example.org/orders"},
+			err:   `proof of possession URI "https://service.example.org/orders" does not match "https://other.example.org/orders"`,
+		},
+		{
+			name:  "tampered proof",
+			proof: tampered,
+			req:   ProofRequest{Nonce: req.Nonce, Method: req.Method, URI: "https://service.example.org/admin"},
+			err:   `invalid proof of possession signature for holder "spiffe://example.org/workload": signature verification failed`,
+		},
+		{
+			name:  "stale proof",
+			proof: proof,
+			req:   req,
+			skew:  -2 * time.Minute,
+			err:   "proof of possession was not issued within 1m0s of the current time",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clock.NewMock(t)
+			clk.Set(time.Unix(proof.Payload.Iat, 0).Add(-tt.skew))
+
+			err := VerifyProof(root, tt.proof, tt.req, WithClock(clk))
+			require.EqualError(t, err, tt.err)
+			require.Equal(t, ReasonInvalidProof, ReasonOf(err))
+
+			err = Validate(root, WithClock(clk), WithProof(tt.proof, tt.req))
+			require.EqualError(t, err, tt.err)
+			require.Equal(t, ReasonInvalidProof, ReasonOf(err))
+		})
+	}
+}
+
+func TestEncodeDecodeProof(t *testing.T) {
+	workloadKey := newKey(t)
+	root := signRoot(t, newKey(t), workloadKey, workloadID, serviceID)
+
+	proof, err := CreateProof(root, ProofRequest{Nonce: "NONCE", Method: "GET", URI: "https://service.example.org"}, workloadKey)
+	require.NoError(t, err)
+
+	encoded, err := EncodeProof(proof)
+	require.NoError(t, err)
+	decoded, err := DecodeProof(encoded)
+	require.NoError(t, err)
+	require.Equal(t, proof, decoded)
+
+	_, err = DecodeProof(encodeJSON(`{}`))
+	require.EqualError(t, err, "proof of possession missing payload")
+	require.Equal(t, ReasonInvalidProof, ReasonOf(err))
+
+	_, err = DecodeProof("not a proof")
+	require.Equal(t, ReasonInvalidProof, ReasonOf(err))
+
+	// Members that are not covered by the signature are rejected.
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+	tampered := strings.Replace(string(data), `"payload":{`, `"payload":{"Nonce":"OTHER",`, 1)
+	_, err = DecodeProof(base64.RawURLEncoding.EncodeToString([]byte(tampered)))
+	require.EqualError(t, err, "unable to unmarshal LSVID: JSON is not encoded as signed")
+	require.Equal(t, ReasonInvalidProof, ReasonOf(err))
+}
@@ -0,0 +1,68 @@
+package lsvid
+
+import (
+	"crypto"
+
+	"github.com/zeebo/errs"
+)
+
+// ReissueSigner returns the key of the issuer of the given layer, which signs
+// the layer again when it is reissued.
+type ReissueSigner func(layer *Token) (crypto.Signer, error)
+
+// Reissue returns a copy of the token whose layers, and those of the LSVIDs
+// embedded in their identity claims, use the encoding of the given typ. It is
+// meant for issuers reissuing the tokens they signed, such as SPIRE Server
+// reissuing in another encoding the LSVIDs and bundle documents signed with
+// its JWT keys. Since every encoding has its own signing input, signatures
+// cannot be carried over: every layer is signed again with the key returned
+// by signer, which must hold the key of the issuer of every layer. Holders
+// and validators of a token, which do not, cannot reissue it, and should
+// present and validate it in the encoding it was issued in.
+//
+// The claims, token IDs and algorithms of the layers are kept, so reissuing
+// the result in the original encoding yields a token equal to the original,
+// but for the signatures of non-deterministic algorithms. Reissue does not
+// validate the token, which should be validated first when it comes from an
+// untrusted party.
+func Reissue(token *Token, typ string, signer ReissueSigner) (*Token, error) {
+	if token == nil || token.Payload == nil {
+		return nil, errs.New("no LSVID to reissue")
+	}
+	if signer == nil {
+		return nil, errs.New("no signer to reissue with")
+	}
+
+	reissued := &Token{Payload: copyPayload(token.Payload)}
+	reissued.Payload.Typ = typ
+	if token.Nested != nil {
+		nested, err := Reissue(token.Nested, typ, signer)
+		if err != nil {
+			return nil, err
+		}
+		reissued.Nested = nested
+	}
+	for _, claim := range []**IDClaim{&reissued.Payload.Iss, &reissued.Payload.Sub, &reissued.Payload.Aud} {
+		if *claim == nil || (*claim).ID == nil {
+			continue
+		}
+		id, err := Reissue((*claim).ID, typ, signer)
+		if err != nil {
+			return nil, errs.New("unable to reissue LSVID of %q: %v", (*claim).CN, err)
+		}
+		(*claim).ID = id
+	}
+
+	key, err := signer(token)
+	if err != nil {
+		return nil, errs.New("unable to reissue LSVID layer issued by %q: %v", issuerName(token), err)
+	}
+	return sign(reissued, key)
+}
+
+func issuerName(layer *Token) string {
+	if layer.Payload.Iss == nil {
+		return ""
+	}
+	return layer.Payload.Iss.CN
+}
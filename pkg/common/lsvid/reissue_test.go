@@ -0,0 +1,88 @@
+package lsvid
+
+import (
+	"crypto"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReissue(t *testing.T) {
+	// Ed25519 signatures are deterministic, so reissuing round trips to the
+	// very same token.
+	rootKey := newEd25519Key(t)
+	agentKey := newEd25519Key(t)
+	signer := func(layer *Token) (crypto.Signer, error) {
+		switch layer.Payload.Iss.CN {
+		case tdID:
+			return rootKey, nil
+		case agentID:
+			return agentKey, nil
+		default:
+			return nil, errors.New("unknown issuer")
+		}
+	}
+
+	token := signJSONChain(t, rootKey, agentKey)
+	jwsToken, err := Reissue(token, TypJWS, signer)
+	require.NoError(t, err)
+	require.NoError(t, Validate(jwsToken))
+	require.Equal(t, TypJWS, jwsToken.Payload.Typ)
+	require.Equal(t, TypJWS, jwsToken.Nested.Payload.Typ)
+	require.Equal(t, TypJWS, jwsToken.Payload.Iss.ID.Payload.Typ)
+	require.Equal(t, token.Payload.Jti, jwsToken.Payload.Jti)
+
+	encoded, err := Encode(jwsToken)
+	require.NoError(t, err)
+	decoded, err := Decode(encoded)
+	require.NoError(t, err)
+
+	reissued, err := Reissue(decoded, "", signer)
+	require.NoError(t, err)
+	require.Equal(t, token, reissued)
+
+	// The reissued token does not share claims with the original one.
+	reissued.Payload.Iss.ID.Payload.Sub.CN = serviceID
+	require.Equal(t, agentID, token.Payload.Iss.ID.Payload.Sub.CN)
+
+	// Tokens are reissued in other encodings too.
+	coseToken, err := Reissue(token, TypCOSE, signer)
+	require.NoError(t, err)
+	require.NoError(t, Validate(coseToken))
+	reissued, err = Reissue(coseToken, "", signer)
+	require.NoError(t, err)
+	require.Equal(t, token, reissued)
+}
+
+func TestReissueFailures(t *testing.T) {
+	rootKey := newKey(t)
+	agentKey := newKey(t)
+	token := signJSONChain(t, rootKey, agentKey)
+
+	_, err := Reissue(nil, TypJWS, nil)
+	require.EqualError(t, err, "no LSVID to reissue")
+
+	_, err = Reissue(token, TypJWS, nil)
+	require.EqualError(t, err, "no signer to reissue with")
+
+	_, err = Reissue(token, TypJWS, func(layer *Token) (crypto.Signer, error) {
+		if layer.Payload.Iss.CN == agentID {
+			return nil, errors.New("no key")
+		}
+		return rootKey, nil
+	})
+	require.EqualError(t, err, `unable to reissue LSVID layer issued by "spiffe://example.org/agent": no key`)
+
+	// The algorithms of the layers are kept.
+	_, err = Reissue(token, TypJWS, func(layer *Token) (crypto.Signer, error) {
+		return newEd25519Key(t), nil
+	})
+	require.EqualError(t, err, `signature algorithm "ES256" does not match EdDSA key`)
+
+	// SAEdDSA has no JWS counterpart.
+	edKey := newEd25519Key(t)
+	aggregated, _ := signHops(t, edKey, AlgSAEdDSA, AlgSAEdDSA, 0)
+	_, err = Reissue(aggregated, TypJWS, func(*Token) (crypto.Signer, error) { return edKey, nil })
+	require.EqualError(t, err, `unable to sign LSVID: unsupported signature algorithm "SAEdDSA"`)
+}
@@ -0,0 +1,114 @@
+package lsvid
+
+import (
+	"sync"
+	"time"
+
+	"github.com/andres-erbsen/clock"
+	"github.com/zeebo/errs"
+)
+
+// replayPruneInterval is how often MemoryReplayCache drops expired token IDs.
+const replayPruneInterval = time.Minute
+
+// ReplayCache records the IDs of the tokens accepted by a validator, so that
+// each token is accepted only once.
+type ReplayCache interface {
+	// Seen records the token ID until the given expiration. It returns true
+	// if the token ID was already recorded and has not expired since.
+	Seen(id string, expiresAt time.Time) bool
+}
+
+// WithReplayCache rejects tokens whose ID was already recorded in the cache,
+// and records the ID of the tokens that pass every other check. The ID of the
+// outermost layer identifies the token and is recorded until the token
+// expires, allowing for the clock skew. See Token.ID.
+func WithReplayCache(cache ReplayCache) ValidateOption {
+	return func(c *validateConfig) {
+		c.replayCache = cache
+	}
+}
+
+// checkReplay verifies that the token was not accepted before.
+func (c *validateConfig) checkReplay(token *Token) error {
+	if c.replayCache == nil {
+		return nil
+	}
+	if c.replayCache.Seen(token.ID(), token.ExpiresAt().Add(c.clockSkew)) {
+		return layerError(ReasonReplayed, token.Depth(), token, errs.New("token %q was already presented", token.ID()))
+	}
+	return nil
+}
+
+// MemoryReplayCache is a ReplayCache held in memory. Expired token IDs are
+// dropped as new ones are recorded.
+type MemoryReplayCache struct {
+	clk clock.Clock
+
+	mu        sync.Mutex
+	ids       map[string]time.Time
+	nextPrune time.Time
+}
+
+// NewMemoryReplayCache returns an empty MemoryReplayCache.
+func NewMemoryReplayCache(clk clock.Clock) *MemoryReplayCache {
+	return &MemoryReplayCache{
+		clk: clk,
+		ids: make(map[string]time.Time),
+	}
+}
+
+// Seen records the token ID until the given expiration. It returns true if
+// the token ID was already recorded and has not expired since.
+func (c *MemoryReplayCache) Seen(id string, expiresAt time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clk.Now()
+	if exp, ok := c.ids[id]; ok && exp.After(now) {
+		return true
+	}
+	if now.After(c.nextPrune) {
+		c.prune(now)
+		c.nextPrune = now.Add(replayPruneInterval)
+	}
+	c.ids[id] = expiresAt
+	return false
+}
+
+// Entries returns the recorded token IDs that have not expired, along with
+// their expiration.
+func (c *MemoryReplayCache) Entries() map[string]time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clk.Now()
+	entries := make(map[string]time.Time, len(c.ids))
+	for id, exp := range c.ids {
+		if exp.After(now) {
+			entries[id] = exp
+		}
+	}
+	return entries
+}
+
+// Restore records the given token IDs until their expiration, e.g. to reload
+// the entries of a previous cache.
+func (c *MemoryReplayCache) Restore(entries map[string]time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for id, exp := range entries {
+		if current, ok := c.ids[id]; !ok || exp.After(current) {
+			c.ids[id] = exp
+		}
+	}
+}
+
+func (c *MemoryReplayCache) prune(now time.Time) {
+	for id, exp := range c.ids {
+		if !exp.After(now) {
+			delete(c.ids, id)
+		}
+	}
+}
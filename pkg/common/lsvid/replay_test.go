@@ -0,0 +1,94 @@
+package lsvid
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/spiffe/spire/test/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignSetsTokenID(t *testing.T) {
+	rootKey := newKey(t)
+	workloadKey := newKey(t)
+
+	first := signRoot(t, rootKey, workloadKey, workloadID, serviceID)
+	second := signRoot(t, rootKey, workloadKey, workloadID, serviceID)
+	require.Len(t, first.Payload.Jti, 22)
+	require.Equal(t, first.Payload.Jti, first.ID())
+	require.NotEqual(t, first.ID(), second.ID())
+
+	// The token ID survives a round trip and is covered by the signature.
+	encoded, err := Encode(first)
+	require.NoError(t, err)
+	decoded, err := Decode(encoded)
+	require.NoError(t, err)
+	require.Equal(t, first.ID(), decoded.ID())
+	decoded.Payload.Jti = second.Payload.Jti
+	require.Equal(t, ReasonBadSignature, ReasonOf(Validate(decoded)))
+
+	// Layers signed without a token ID are identified by their signature.
+	first.Payload.Jti = ""
+	require.Len(t, first.ID(), 43)
+}
+
+func TestValidateReplayCache(t *testing.T) {
+	clk := clock.NewMock(t)
+	rootKey := newKey(t)
+	workloadKey := newKey(t)
+
+	root := signRoot(t, rootKey, workloadKey, workloadID, serviceID)
+	other := signRoot(t, rootKey, workloadKey, workloadID, serviceID)
+
+	cache := NewMemoryReplayCache(clk)
+	validate := func(token *Token) error {
+		return Validate(token, WithClock(clk), WithReplayCache(cache))
+	}
+
+	require.NoError(t, validate(root))
+	require.NoError(t, validate(other))
+
+	err := validate(root)
+	require.EqualError(t, err, `token "`+root.ID()+`" was already presented`)
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr))
+	require.Equal(t, ReasonReplayed, verr.Reason)
+	require.Equal(t, 1, verr.Depth)
+	require.Equal(t, tdID, verr.Issuer)
+
+	// Tokens failing other checks are not recorded.
+	expired := signRoot(t, rootKey, workloadKey, workloadID, serviceID)
+	clk.Add(2 * time.Hour)
+	require.Equal(t, ReasonExpired, ReasonOf(validate(expired)))
+	require.NotContains(t, cache.Entries(), expired.ID())
+
+	// Token IDs are dropped once the tokens expire.
+	require.Empty(t, cache.Entries())
+}
+
+func TestMemoryReplayCache(t *testing.T) {
+	clk := clock.NewMock(t)
+	cache := NewMemoryReplayCache(clk)
+
+	exp := clk.Now().Add(time.Hour)
+	require.False(t, cache.Seen("A", exp))
+	require.True(t, cache.Seen("A", exp))
+	require.False(t, cache.Seen("B", clk.Now().Add(2*time.Minute)))
+	require.Equal(t, map[string]time.Time{"A": exp, "B": clk.Now().Add(2 * time.Minute)}, cache.Entries())
+
+	// B expires and is pruned when a new token ID is recorded.
+	clk.Add(3 * time.Minute)
+	require.False(t, cache.Seen("B", clk.Now().Add(time.Minute)))
+	require.False(t, cache.Seen("C", exp))
+	require.Len(t, cache.ids, 3)
+	clk.Add(2 * time.Minute)
+	require.False(t, cache.Seen("D", exp))
+	require.Equal(t, map[string]time.Time{"A": exp, "C": exp, "D": exp}, cache.Entries())
+	require.Len(t, cache.ids, 3)
+
+	restored := NewMemoryReplayCache(clk)
+	restored.Restore(cache.Entries())
+	require.True(t, restored.Seen("A", exp))
+	require.False(t, restored.Seen("B", exp))
+}
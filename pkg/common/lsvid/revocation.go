@@ -0,0 +1,111 @@
+package lsvid
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+
+	"github.com/zeebo/errs"
+)
+
+// RevocationKind identifies what a revocation matches.
+type RevocationKind string
+
+const (
+	// RevokeSPIFFEID revokes every layer whose subject or issuer is the given
+	// SPIFFE ID.
+	RevokeSPIFFEID RevocationKind = "SPIFFE_ID"
+
+	// RevokeKeyHash revokes every layer whose subject or issuer key hashes to
+	// the given value. See KeyHash.
+	RevokeKeyHash RevocationKind = "KEY_HASH"
+
+	// RevokeTokenID revokes the layer with the given token ID. See Token.ID.
+	RevokeTokenID RevocationKind = "TOKEN_ID"
+)
+
+// Revocation revokes the LSVID layers matching its kind and value.
+type Revocation struct {
+	Kind  RevocationKind
+	Value string
+}
+
+// RevocationList is a set of revocations.
+type RevocationList map[Revocation]struct{}
+
+// NewRevocationList returns the list of the given revocations.
+func NewRevocationList(revocations ...Revocation) RevocationList {
+	list := make(RevocationList, len(revocations))
+	for _, revocation := range revocations {
+		list[revocation] = struct{}{}
+	}
+	return list
+}
+
+// Contains returns true if the list holds a revocation of the given kind and
+// value.
+func (l RevocationList) Contains(kind RevocationKind, value string) bool {
+	_, ok := l[Revocation{Kind: kind, Value: value}]
+	return ok
+}
+
+// Validate checks that the kind is a known revocation kind.
+func (k RevocationKind) Validate() error {
+	switch k {
+	case RevokeSPIFFEID, RevokeKeyHash, RevokeTokenID:
+		return nil
+	default:
+		return errs.New("unknown revocation kind %q", k)
+	}
+}
+
+// WithRevocations rejects tokens containing a revoked layer. A layer is
+// revoked if its token ID is revoked, or if the SPIFFE ID or the key hash of
+// its subject or issuer is revoked. The revocations also apply to the issuer
+// LSVIDs embedded in the layers.
+func WithRevocations(list RevocationList) ValidateOption {
+	return func(c *validateConfig) {
+		c.revocations = list
+	}
+}
+
+// ID returns the token ID of the layer: its jti claim or, for layers signed
+// without one, the unpadded base64url encoded SHA-256 digest of its signature.
+func (t *Token) ID() string {
+	if t.Payload != nil && t.Payload.Jti != "" {
+		return t.Payload.Jti
+	}
+	sum := sha256.Sum256(t.Signature)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// KeyHash returns the hash identifying the given DER encoded (PKIX) public
+// key in revocations: the unpadded base64url encoded SHA-256 digest of the
+// key.
+func KeyHash(pk []byte) string {
+	sum := sha256.Sum256(pk)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// checkRevocations verifies that the layer is not revoked.
+func (c *validateConfig) checkRevocations(layer *Token) error {
+	if len(c.revocations) == 0 {
+		return nil
+	}
+
+	iss := layer.Payload.Iss
+	if c.revocations.Contains(RevokeTokenID, layer.ID()) {
+		return errs.New("layer issued by %q is revoked", iss.CN)
+	}
+	for _, claim := range []*IDClaim{iss, layer.Payload.Sub} {
+		if claim == nil {
+			continue
+		}
+		if c.revocations.Contains(RevokeSPIFFEID, claim.CN) {
+			return errs.New("SPIFFE ID %q is revoked", claim.CN)
+		}
+		if len(claim.PK) > 0 && c.revocations.Contains(RevokeKeyHash, KeyHash(claim.PK)) {
+			return errs.New("key of %q is revoked", claim.CN)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package lsvid
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRevocations(t *testing.T) {
+	rootKey := newKey(t)
+	agentKey := newKey(t)
+	workloadKey := newKey(t)
+
+	// The agent extends the LSVID of the workload, identified by its own
+	// LSVID, to the service.
+	agentLSVID := signRoot(t, rootKey, agentKey, agentID, agentID)
+	root := signRoot(t, rootKey, workloadKey, workloadID, agentID)
+	token, err := Extend(root, &Payload{
+		Ver: Version,
+		Alg: AlgES256,
+		Iss: &IDClaim{CN: agentID, ID: agentLSVID},
+		Aud: &IDClaim{CN: serviceID},
+	}, agentKey)
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name       string
+		revocation Revocation
+		err        string
+		depth      int
+	}{
+		{
+			name:       "other SPIFFE ID",
+			revocation: Revocation{Kind: RevokeSPIFFEID, Value: serviceID},
+		},
+		{
+			name:       "issuer LSVID token ID",
+			revocation: Revocation{Kind: RevokeTokenID, Value: agentLSVID.ID()},
+			err:        `invalid issuer LSVID for "spiffe://example.org/agent": layer issued by "spiffe://example.org" is revoked`,
+			depth:      2,
+		},
+		{
+			name:       "subject SPIFFE ID",
+			revocation: Revocation{Kind: RevokeSPIFFEID, Value: workloadID},
+			err:        `SPIFFE ID "spiffe://example.org/workload" is revoked`,
+			depth:      1,
+		},
+		{
+			name:       "issuer SPIFFE ID",
+			revocation: Revocation{Kind: RevokeSPIFFEID, Value: agentID},
+			err:        `invalid issuer LSVID for "spiffe://example.org/agent": SPIFFE ID "spiffe://example.org/agent" is revoked`,
+			depth:      2,
+		},
+		{
+			name:       "subject key hash",
+			revocation: Revocation{Kind: RevokeKeyHash, Value: KeyHash(marshalKey(t, workloadKey))},
+			err:        `key of "spiffe://example.org/workload" is revoked`,
+			depth:      1,
+		},
+		{
+			name:       "issuer LSVID subject key hash",
+			revocation: Revocation{Kind: RevokeKeyHash, Value: KeyHash(marshalKey(t, agentKey))},
+			err:        `invalid issuer LSVID for "spiffe://example.org/agent": key of "spiffe://example.org/agent" is revoked`,
+			depth:      2,
+		},
+		{
+			name:       "root token ID",
+			revocation: Revocation{Kind: RevokeTokenID, Value: root.ID()},
+			err:        `layer issued by "spiffe://example.org" is revoked`,
+			depth:      1,
+		},
+		{
+			name:       "outer token ID",
+			revocation: Revocation{Kind: RevokeTokenID, Value: token.ID()},
+			err:        `layer issued by "spiffe://example.org/agent" is revoked`,
+			depth:      2,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(token, WithRevocations(NewRevocationList(tt.revocation)))
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.err)
+
+			var verr *ValidationError
+			require.True(t, errors.As(err, &verr))
+			require.Equal(t, ReasonRevoked, verr.Reason)
+			require.Equal(t, tt.depth, verr.Depth)
+		})
+	}
+
+	require.NoError(t, Validate(token, WithRevocations(nil)))
+}
+
+func TestRevocationKindValidate(t *testing.T) {
+	require.NoError(t, RevokeSPIFFEID.Validate())
+	require.NoError(t, RevokeKeyHash.Validate())
+	require.NoError(t, RevokeTokenID.Validate())
+	require.EqualError(t, RevocationKind("SERIAL").Validate(), `unknown revocation kind "SERIAL"`)
+}
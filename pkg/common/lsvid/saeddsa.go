@@ -0,0 +1,157 @@
+package lsvid
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+
+	"filippo.io/edwards25519"
+	"github.com/zeebo/errs"
+)
+
+// saEdDSA implements SAEdDSA: the half-aggregation of Ed25519 signatures.
+// Each layer is signed with plain Ed25519 over its signing input M_j, so the
+// challenge of layer j is that of Ed25519,
+//
+//	c_j = SHA-512(R_j || A_j || M_j)
+//
+// reduced modulo the group order, and the signature is computed through the
+// crypto.Signer interface. Weights are z_j = SHA-512(h_j) reduced modulo the
+// group order for j > 1. Aggregates are verified with the cofactored
+// equation. See aggregate.go.
+type saEdDSA struct{}
+
+func (saEdDSA) sign(token *Token, prev []byte, key crypto.Signer) error {
+	pub, ok := key.Public().(ed25519.PublicKey)
+	if !ok {
+		return errs.New("%s requires an Ed25519 key", AlgSAEdDSA)
+	}
+
+	input, err := SigningInput(token)
+	if err != nil {
+		return err
+	}
+	d := sha256.Sum256(input)
+
+	signature, err := key.Sign(rand.Reader, input, crypto.Hash(0))
+	if err != nil {
+		return err
+	}
+	if len(signature) != ed25519.SignatureSize {
+		return errs.New("malformed Ed25519 signature")
+	}
+	r := signature[:32]
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(signature[32:])
+	if err != nil {
+		return errs.New("malformed Ed25519 signature: %v", err)
+	}
+
+	sum, h := edwards25519.NewScalar(), make([]byte, aggregateScalarSize)
+	if prev != nil {
+		if sum, h, err = parseSAEdDSAAggregate(prev); err != nil {
+			return err
+		}
+	}
+	h = aggregateHash(AlgSAEdDSA, h, r, pub, d[:])
+	z := edwards25519ScalarOne()
+	if prev != nil {
+		z = saEdDSAHashToScalar(h)
+	}
+	sum.MultiplyAdd(z, s, sum)
+
+	token.Signature = append([]byte(nil), r...)
+	token.Aggregate = append(sum.Bytes(), h...)
+	return nil
+}
+
+func (saEdDSA) verify(run *aggregateRun) error {
+	sum, expectedHash, err := parseSAEdDSAAggregate(run.layers[0].Aggregate)
+	if err != nil {
+		return err
+	}
+
+	h := make([]byte, aggregateScalarSize)
+	scalars := make([]*edwards25519.Scalar, 0, 2*len(run.layers)+1)
+	points := make([]*edwards25519.Point, 0, 2*len(run.layers)+1)
+	for i := len(run.layers) - 1; i >= 0; i-- {
+		layer := run.layers[i]
+		pub, ok := run.keys[i].(ed25519.PublicKey)
+		if !ok || len(pub) != ed25519.PublicKeySize {
+			return errs.New("signature algorithm %q does not match %T key", AlgSAEdDSA, run.keys[i])
+		}
+		a, err := new(edwards25519.Point).SetBytes(pub)
+		if err != nil {
+			return errs.New("malformed Ed25519 public key: %v", err)
+		}
+		if len(layer.Signature) != 32 {
+			return errs.New("malformed %s nonce commitment", AlgSAEdDSA)
+		}
+		r, err := new(edwards25519.Point).SetBytes(layer.Signature)
+		if err != nil {
+			return errs.New("malformed %s nonce commitment", AlgSAEdDSA)
+		}
+		input, err := SigningInput(layer)
+		if err != nil {
+			return err
+		}
+		d := sha256.Sum256(input)
+
+		h = aggregateHash(AlgSAEdDSA, h, layer.Signature, pub, d[:])
+		z := edwards25519ScalarOne()
+		if i != len(run.layers)-1 {
+			z = saEdDSAHashToScalar(h)
+		}
+		c := sha512.New()
+		_, _ = c.Write(layer.Signature)
+		_, _ = c.Write(pub)
+		_, _ = c.Write(input)
+		zc, err := edwards25519.NewScalar().SetUniformBytes(c.Sum(nil))
+		if err != nil {
+			return err
+		}
+		zc.Multiply(zc, z)
+
+		scalars = append(scalars, z, zc)
+		points = append(points, r, a)
+	}
+	if !bytes.Equal(h, expectedHash) {
+		return errs.New("aggregate chain hash does not match the layers")
+	}
+
+	// 8*(sum(z_j*R_j + z_j*c_j*A_j) - S*B) must be the identity.
+	scalars = append(scalars, edwards25519.NewScalar().Negate(sum))
+	points = append(points, edwards25519.NewGeneratorPoint())
+	check := new(edwards25519.Point).VarTimeMultiScalarMult(scalars, points)
+	if check.MultByCofactor(check).Equal(edwards25519.NewIdentityPoint()) != 1 {
+		return errs.New("aggregate signature verification failed")
+	}
+	return nil
+}
+
+func parseSAEdDSAAggregate(aggregate []byte) (*edwards25519.Scalar, []byte, error) {
+	scalar, h, err := splitAggregate(aggregate)
+	if err != nil {
+		return nil, nil, err
+	}
+	sum, err := edwards25519.NewScalar().SetCanonicalBytes(scalar)
+	if err != nil {
+		return nil, nil, errs.New("aggregate signature is out of range")
+	}
+	return sum, h, nil
+}
+
+func saEdDSAHashToScalar(h []byte) *edwards25519.Scalar {
+	wide := sha512.Sum512(h)
+	s, _ := edwards25519.NewScalar().SetUniformBytes(wide[:])
+	return s
+}
+
+func edwards25519ScalarOne() *edwards25519.Scalar {
+	one := make([]byte, 32)
+	one[0] = 1
+	s, _ := edwards25519.NewScalar().SetCanonicalBytes(one)
+	return s
+}
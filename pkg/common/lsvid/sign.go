@@ -0,0 +1,175 @@
+package lsvid
+
+import (
+	"crypto"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+
+	jsoncanonicalizer "github.com/cyberphone/json-canonicalization/go/src/webpki.org/jsoncanonicalizer"
+	"github.com/zeebo/errs"
+)
+
+// Sign creates a root LSVID layer by signing the payload with the given key.
+// The signature algorithm is taken from the alg claim, which must match the
+// key, or chosen from the key type if the claim is not set.
+//
+// Payloads typed TypCOSE are signed as COSE layers, and payloads typed TypJWS
+// as JWS layers. The issuer key of a COSE root layer is referenced by the key
+// ID of its issuer claim when it has one: the embedded issuer key is then
+// dropped, and the layer can only be validated with WithAuthorities.
+//
+// The claims filled in while signing are set on a copy of the payload, which
+// is left untouched.
+func Sign(payload *Payload, key crypto.Signer) (*Token, error) {
+	return sign(&Token{Payload: copyPayload(payload)}, key)
+}
+
+// Extend adds a new layer on top of the given token and signs it with the
+// given key. The new layer wraps the whole token, including its signature.
+// The expiration of the new layer is capped to that of the extended token,
+// which is also used when the payload does not set one. The new layer uses
+// the encoding of the extended token unless the payload sets its typ. As with
+// Sign, the payload is left untouched.
+func Extend(token *Token, payload *Payload, key crypto.Signer) (*Token, error) {
+	if token == nil || token.Payload == nil {
+		return nil, errs.New("no LSVID to extend")
+	}
+	payload = copyPayload(payload)
+	if payload != nil && (payload.Exp == 0 || payload.Exp > token.Payload.Exp) {
+		payload.Exp = token.Payload.Exp
+	}
+	if payload != nil && payload.Typ == "" {
+		payload.Typ = token.Payload.Typ
+	}
+	return sign(&Token{Nested: token, Payload: payload}, key)
+}
+
+// copyPayload returns a copy of the payload and of its identity claims, so
+// that signing can fill in claims without modifying the payload of the caller.
+func copyPayload(payload *Payload) *Payload {
+	if payload == nil {
+		return nil
+	}
+	copied := *payload
+	for _, claim := range []**IDClaim{&copied.Iss, &copied.Sub, &copied.Aud} {
+		if *claim != nil {
+			c := **claim
+			*claim = &c
+		}
+	}
+	return &copied
+}
+
+// sign fills in and signs the payload of the given layer, which must not be
+// shared with the caller. See copyPayload.
+func sign(token *Token, key crypto.Signer) (*Token, error) {
+	switch {
+	case token.Payload == nil:
+		return nil, errs.New("no payload to sign")
+	case key == nil:
+		return nil, errs.New("no key to sign with")
+	}
+	if err := checkPayload(token.Payload); err != nil {
+		return nil, err
+	}
+	if token.Payload.Exp == 0 {
+		return nil, errs.New("payload missing expiration")
+	}
+	if err := checkLayerType(token); err != nil {
+		return nil, err
+	}
+	if iss := token.Payload.Iss; token.Payload.Typ == TypCOSE && token.Nested == nil && iss != nil && iss.Kid != "" {
+		iss.PK = nil
+	}
+	if token.Payload.Jti == "" {
+		jti, err := newTokenID()
+		if err != nil {
+			return nil, err
+		}
+		token.Payload.Jti = jti
+	}
+
+	if token.Payload.Alg == "" {
+		alg, err := AlgorithmForKey(key.Public())
+		if err != nil {
+			return nil, err
+		}
+		token.Payload.Alg = alg
+	} else if err := checkAlgorithm(token.Payload.Alg, key.Public()); err != nil {
+		return nil, err
+	}
+
+	if isAggregateAlg(token.Payload.Alg) {
+		return signAggregate(token, key)
+	}
+
+	if err := setLayerEncoding(token); err != nil {
+		return nil, err
+	}
+	input, err := SigningInput(token)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := signInput(token.Payload.Alg, key, input)
+	if err != nil {
+		return nil, errs.New("unable to sign LSVID: %v", err)
+	}
+	if hasRawSignature(token) {
+		if signature, err = coseSignature(token.Payload.Alg, signature); err != nil {
+			return nil, errs.New("unable to sign LSVID: %v", err)
+		}
+	}
+	token.Signature = signature
+	return token, nil
+}
+
+// newTokenID returns a random token ID: 16 random bytes, unpadded base64url
+// encoded.
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", errs.New("unable to generate token ID: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// SigningInput returns the bytes covered by the signature of the given layer.
+// It is the RFC 8785 (JCS) canonical serialization of the JSON object
+//
+//	{"nested": <extended token>, "payload": <layer payload>}
+//
+// where "nested" is the complete extended token, signature included, and is
+// absent on the root layer. Byte fields are encoded as padded standard base64
+// strings, as in the encoded token.
+//
+// The signing input of layers typed TypCOSE is their COSE Sig_structure
+// instead, and that of layers typed TypJWS their JWS signing input. See
+// MarshalCOSE and MarshalJWS.
+func SigningInput(token *Token) ([]byte, error) {
+	if token.Payload != nil && token.Payload.Typ == TypCOSE {
+		return coseSigningInput(token)
+	}
+	if token.Payload != nil && token.Payload.Typ == TypJWS {
+		return jwsSigningInput(token)
+	}
+	data, err := json.Marshal(&signingInput{
+		Nested:  token.Nested,
+		Payload: token.Payload,
+	})
+	if err != nil {
+		return nil, errs.New("unable to marshal LSVID signing input: %v", err)
+	}
+
+	canonical, err := jsoncanonicalizer.Transform(data)
+	if err != nil {
+		return nil, errs.New("unable to canonicalize LSVID signing input: %v", err)
+	}
+	return canonical, nil
+}
+
+type signingInput struct {
+	Nested  *Token   `json:"nested,omitempty"`
+	Payload *Payload `json:"payload"`
+}
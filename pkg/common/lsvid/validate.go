@@ -0,0 +1,346 @@
+package lsvid
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"time"
+
+	"github.com/andres-erbsen/clock"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/zeebo/errs"
+)
+
+// DefaultClockSkew is the clock skew tolerated when validating the time claims
+// of an LSVID if not overridden with WithClockSkew.
+const DefaultClockSkew = time.Minute
+
+// X509SVIDLookup returns the current X509-SVIDs of the given SPIFFE ID. The
+// returned boolean is false if the X509-SVIDs of the SPIFFE ID are not known.
+type X509SVIDLookup func(spiffeID string) ([]*x509.Certificate, bool)
+
+// AuthorityLookup returns the JWT authorities of the given trust domain,
+// keyed by key ID. The returned boolean is false if the trust domain is not
+// known.
+type AuthorityLookup func(trustDomainID string) (map[string]crypto.PublicKey, bool)
+
+type validateConfig struct {
+	clock       clock.Clock
+	clockSkew   time.Duration
+	x509SVIDs   X509SVIDLookup
+	authorities AuthorityLookup
+	knownClaims map[string]struct{}
+
+	trustDomain      spiffeid.TrustDomain
+	federationPolicy FederationPolicy
+	chainPolicy      *ChainPolicy
+	revocations      RevocationList
+	replayCache      ReplayCache
+	proof            *proofConfig
+}
+
+// ValidateOption configures how an LSVID is validated.
+type ValidateOption func(*validateConfig)
+
+// WithClock sets the clock used to validate the time claims.
+func WithClock(clk clock.Clock) ValidateOption {
+	return func(c *validateConfig) {
+		c.clock = clk
+	}
+}
+
+// WithClockSkew sets the clock skew tolerated when validating the time claims.
+func WithClockSkew(skew time.Duration) ValidateOption {
+	return func(c *validateConfig) {
+		c.clockSkew = skew
+	}
+}
+
+// WithX509SVIDs checks that subjects bound to an X509-SVID are still bound to
+// one of their current X509-SVIDs, as returned by the lookup function, so that
+// LSVIDs stop validating once the X509-SVID of their subject rotates.
+//
+// The check only covers the subjects known to the lookup function, typically
+// the identities local to the validator. Subjects whose X509-SVIDs are unknown
+// are not checked: their LSVIDs stay valid until they expire, which the server
+// caps to the expiration of the X509-SVID they were issued for.
+func WithX509SVIDs(lookup X509SVIDLookup) ValidateOption {
+	return func(c *validateConfig) {
+		c.x509SVIDs = lookup
+	}
+}
+
+// WithAuthorities anchors every root layer in a trust bundle: the issuer key of
+// a root layer is the JWT authority returned by the lookup function for the
+// issuer trust domain under the key ID of the issuer claim or, if the claim
+// has no key ID, must be one of those authorities. The subject of a root layer
+// must then belong to the issuer trust domain. Without this option, the key
+// embedded in the root layer is trusted as is.
+func WithAuthorities(lookup AuthorityLookup) ValidateOption {
+	return func(c *validateConfig) {
+		c.authorities = lookup
+	}
+}
+
+// Validate verifies the signature and expiration of every layer of the token,
+// the aud -> iss link between each layer and the layer it extends, and that no
+// layer outlives the layer it extends. Layers listing critical claims not
+// declared with WithKnownClaims are rejected. Failures are reported as
+// *ValidationError.
+//
+// The root layer is verified with the key embedded in its issuer claim. Outer
+// layers are verified with the key of their issuer, taken from the LSVID in
+// the issuer claim or, when the subject extends its own token, from the
+// subject claim.
+func Validate(token *Token, opts ...ValidateOption) error {
+	c := newValidateConfig(opts)
+	if err := c.validate(token); err != nil {
+		return err
+	}
+	if err := c.checkChainPolicy(token); err != nil {
+		return err
+	}
+	if err := c.checkProof(token); err != nil {
+		return err
+	}
+	return c.checkReplay(token)
+}
+
+func newValidateConfig(opts []ValidateOption) *validateConfig {
+	c := &validateConfig{
+		clock:     clock.New(),
+		clockSkew: DefaultClockSkew,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// validate validates the token. Failures are reported as *ValidationError.
+func (c *validateConfig) validate(token *Token) error {
+	if token == nil {
+		return validationError(ReasonMalformed, errs.New("no LSVID to validate"))
+	}
+	if err := checkToken(token); err != nil {
+		return validationError(ReasonMalformed, err)
+	}
+
+	subject := token.Subject()
+	if subject == nil {
+		return validationError(ReasonMalformed, errs.New("root LSVID layer missing subject"))
+	}
+	if err := c.checkX509SVIDBinding(subject); err != nil {
+		return validationError(ReasonSubjectRotated, err)
+	}
+
+	var run *aggregateRun
+	runDepth := 0
+	depth := token.Depth()
+	for layer := token; layer != nil; layer, depth = layer.Nested, depth-1 {
+		if layer.Payload.Iss == nil {
+			return layerError(ReasonMalformed, depth, layer, errs.New("LSVID layer missing issuer"))
+		}
+		if err := c.checkFederationPolicy(layer, depth); err != nil {
+			return layerError(ReasonForeignIssuer, depth, layer, err)
+		}
+
+		key, err := c.issuerKey(layer, subject)
+		if err != nil {
+			return layerError(ReasonUntrustedIssuer, depth, layer, err)
+		}
+		switch {
+		case isAggregateAlg(layer.Payload.Alg):
+			// The signatures of a run of layers signed with an aggregating
+			// algorithm are verified at once, with the aggregate carried by
+			// the outermost layer of the run.
+			if run == nil {
+				if layer.Aggregate == nil {
+					return layerError(ReasonBadSignature, depth, layer, errs.New("layer issued by %q missing aggregate signature", layer.Payload.Iss.CN))
+				}
+				run, runDepth = newAggregateRun(layer.Payload.Alg), depth
+			} else if layer.Aggregate != nil {
+				return layerError(ReasonMalformed, depth, layer, errs.New("layer issued by %q carries the aggregate signature of an outer layer", layer.Payload.Iss.CN))
+			}
+			run.add(layer, key)
+			if !extendsRun(layer) {
+				top := run.layers[0]
+				if err := run.verify(); err != nil {
+					return layerError(ReasonBadSignature, runDepth, top, errs.New("invalid aggregate signature on layer issued by %q: %v", top.Payload.Iss.CN, err))
+				}
+				run = nil
+			}
+		case layer.Aggregate != nil:
+			return layerError(ReasonMalformed, depth, layer, errs.New("layer issued by %q carries an aggregate signature but is not signed with %s", layer.Payload.Iss.CN, AlgSAEdDSA))
+		default:
+			if err := VerifySignature(layer, key); err != nil {
+				return layerError(ReasonBadSignature, depth, layer, errs.New("invalid signature on layer issued by %q: %v", layer.Payload.Iss.CN, err))
+			}
+		}
+		if err := c.checkTimes(layer); err != nil {
+			return layerError(ReasonMalformed, depth, layer, err)
+		}
+		if err := c.checkCriticalClaims(layer); err != nil {
+			return layerError(ReasonUnknownCriticalClaim, depth, layer, err)
+		}
+		if err := c.checkRevocations(layer); err != nil {
+			return layerError(ReasonRevoked, depth, layer, err)
+		}
+
+		if layer.Nested != nil {
+			aud := layer.Nested.Payload.Aud
+			if aud == nil || aud.CN != layer.Payload.Iss.CN {
+				return layerError(ReasonBrokenChain, depth, layer, errs.New("issuer %q is not the audience of the extended LSVID", layer.Payload.Iss.CN))
+			}
+			if layer.Payload.Exp > layer.Nested.Payload.Exp {
+				return layerError(ReasonExpiryIncreases, depth, layer, errs.New("layer issued by %q outlives the extended LSVID", layer.Payload.Iss.CN))
+			}
+		}
+	}
+	return nil
+}
+
+// checkX509SVIDBinding checks the X5T binding of the subject against its
+// current X509-SVIDs. See WithX509SVIDs for the subjects left unchecked.
+func (c *validateConfig) checkX509SVIDBinding(subject *IDClaim) error {
+	if c.x509SVIDs == nil || subject == nil || len(subject.X5T) == 0 {
+		return nil
+	}
+	svids, ok := c.x509SVIDs(subject.CN)
+	if !ok {
+		return nil
+	}
+	for _, svid := range svids {
+		if bytes.Equal(X509SVIDThumbprint(svid), subject.X5T) {
+			return nil
+		}
+	}
+	return errs.New("subject %q is no longer bound to a current X509-SVID", subject.CN)
+}
+
+func (c *validateConfig) checkTimes(layer *Token) error {
+	now := c.clock.Now()
+	iss := layer.Payload.Iss.CN
+	switch {
+	case layer.Payload.Exp == 0:
+		return validationError(ReasonMalformed, errs.New("layer issued by %q missing expiration", iss))
+	case now.Add(-c.clockSkew).After(time.Unix(layer.Payload.Exp, 0)):
+		return validationError(ReasonExpired, errs.New("layer issued by %q has expired", iss))
+	case now.Add(c.clockSkew).Before(time.Unix(layer.Payload.Iat, 0)):
+		return validationError(ReasonNotYetValid, errs.New("layer issued by %q is not valid yet", iss))
+	}
+	return nil
+}
+
+// issuerKey resolves the public key of the issuer of the given layer.
+func (c *validateConfig) issuerKey(layer *Token, subject *IDClaim) (crypto.PublicKey, error) {
+	iss := layer.Payload.Iss
+	switch {
+	case layer.Nested == nil:
+		return c.rootKey(iss, subject)
+	case iss.ID != nil:
+		if err := c.validate(iss.ID); err != nil {
+			return nil, validationError(ReasonOf(err), errs.New("invalid issuer LSVID for %q: %v", iss.CN, err))
+		}
+		issSubject := iss.ID.Subject()
+		if issSubject.CN != iss.CN {
+			return nil, errs.New("issuer LSVID does not identify %q", iss.CN)
+		}
+		return parseKey(iss.CN, issSubject.PK)
+	case subject.CN == iss.CN:
+		return parseKey(iss.CN, subject.PK)
+	default:
+		return nil, errs.New("unable to resolve key for issuer %q", iss.CN)
+	}
+}
+
+// rootKey resolves the key of the issuer of a root layer. With authorities,
+// the key is resolved from the bundle of the issuer trust domain, by key ID
+// when the issuer claim carries one, in which case the embedded key, if any,
+// must be that authority, and the subject must belong to the issuer trust
+// domain. Otherwise, the embedded key is trusted as is.
+func (c *validateConfig) rootKey(iss, subject *IDClaim) (crypto.PublicKey, error) {
+	if c.authorities == nil {
+		if len(iss.PK) == 0 {
+			return nil, errs.New("root LSVID layer missing issuer key")
+		}
+		return parseKey(iss.CN, iss.PK)
+	}
+
+	id, err := spiffeid.FromString(iss.CN)
+	if err != nil {
+		return nil, errs.New("invalid root layer issuer %q: %v", iss.CN, err)
+	}
+	td := id.TrustDomain().IDString()
+	if subjectID, err := spiffeid.FromString(subject.CN); err != nil || !subjectID.MemberOf(id.TrustDomain()) {
+		return nil, errs.New("root layer issuer %q may not issue LSVIDs for subject %q", iss.CN, subject.CN)
+	}
+	authorities, ok := c.authorities(td)
+	if !ok {
+		return nil, errs.New("no authorities found for trust domain %q", td)
+	}
+
+	if iss.Kid != "" {
+		key, ok := authorities[iss.Kid]
+		if !ok {
+			return nil, errs.New("key %q not found for trust domain %q", iss.Kid, td)
+		}
+		if len(iss.PK) > 0 && !keyEquals(key, iss.PK) {
+			return nil, errs.New("root layer issuer key does not match key %q of trust domain %q", iss.Kid, td)
+		}
+		return key, nil
+	}
+
+	if len(iss.PK) == 0 {
+		return nil, errs.New("root LSVID layer missing issuer key")
+	}
+	for _, authority := range authorities {
+		if keyEquals(authority, iss.PK) {
+			return authority, nil
+		}
+	}
+	return nil, errs.New("root layer issuer key is not an authority of trust domain %q", td)
+}
+
+func parseKey(issuer string, rawKey []byte) (crypto.PublicKey, error) {
+	key, err := x509.ParsePKIXPublicKey(rawKey)
+	if err != nil {
+		return nil, errs.New("unable to parse key of issuer %q: %v", issuer, err)
+	}
+	return key, nil
+}
+
+func keyEquals(key crypto.PublicKey, rawKey []byte) bool {
+	marshaled, err := x509.MarshalPKIXPublicKey(key)
+	return err == nil && bytes.Equal(marshaled, rawKey)
+}
+
+// VerifySignature verifies the signature of a single layer with the given
+// issuer key. Unlike Validate, it does not resolve the issuer key nor check
+// the claims or the nested layers. SAEdDSA layers can only be verified on
+// their own if they are the only layer of their run.
+func VerifySignature(layer *Token, key crypto.PublicKey) error {
+	if isAggregateAlg(layer.Payload.Alg) {
+		if layer.Aggregate == nil || extendsRun(layer) {
+			return errs.New("%s layer is verified with the aggregate signature of its run", layer.Payload.Alg)
+		}
+		run := newAggregateRun(layer.Payload.Alg)
+		run.add(layer, key)
+		return run.verify()
+	}
+	if err := checkAlgorithm(layer.Payload.Alg, key); err != nil {
+		return err
+	}
+
+	input, err := SigningInput(layer)
+	if err != nil {
+		return err
+	}
+	signature := layer.Signature
+	if hasRawSignature(layer) {
+		if signature, err = derSignature(layer.Payload.Alg, signature); err != nil {
+			return err
+		}
+	}
+	return verifyInput(layer.Payload.Alg, key, input, signature)
+}
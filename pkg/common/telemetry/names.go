@@ -379,6 +379,12 @@ const (
 	// RevisionNumber tags a registration entry revision number
 	RevisionNumber = "revision_number"
 
+	// RevocationKind tags the kind of an LSVID revocation
+	RevocationKind = "revocation_kind"
+
+	// RevocationValue tags the value of an LSVID revocation
+	RevocationValue = "revocation_value"
+
 	// Schema tags database schema version
 	Schema = "schema"
 
@@ -565,6 +571,10 @@ const (
 	// to add clarity
 	JWTSVID = "jwt_svid"
 
+	// LSVIDRevocation functionality related to an LSVID revocation; should be
+	// used with other tags to add clarity
+	LSVIDRevocation = "lsvid_revocation"
+
 	// Limit tags a limit
 	Limit = "limit"
 
@@ -0,0 +1,26 @@
+package datastore
+
+import "github.com/spiffe/spire/pkg/common/telemetry"
+
+// Call Counters (timing and success metrics)
+// Allows adding labels in-code
+
+// StartCreateLSVIDRevocationCall return metric
+// for server's datastore, on creating an LSVID revocation.
+func StartCreateLSVIDRevocationCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.LSVIDRevocation, telemetry.Create)
+}
+
+// StartDeleteLSVIDRevocationCall return metric
+// for server's datastore, on deleting an LSVID revocation.
+func StartDeleteLSVIDRevocationCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.LSVIDRevocation, telemetry.Delete)
+}
+
+// StartListLSVIDRevocationsCall return metric
+// for server's datastore, on listing LSVID revocations.
+func StartListLSVIDRevocationsCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.LSVIDRevocation, telemetry.List)
+}
+
+// End Call Counters
@@ -47,6 +47,12 @@ func (w metricsWrapper) CreateJoinToken(ctx context.Context, token *datastore.Jo
 	return w.ds.CreateJoinToken(ctx, token)
 }
 
+func (w metricsWrapper) CreateLSVIDRevocation(ctx context.Context, revocation *datastore.LSVIDRevocation) (_ *datastore.LSVIDRevocation, err error) {
+	callCounter := StartCreateLSVIDRevocationCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.CreateLSVIDRevocation(ctx, revocation)
+}
+
 func (w metricsWrapper) CreateRegistrationEntry(ctx context.Context, entry *common.RegistrationEntry) (_ *common.RegistrationEntry, err error) {
 	callCounter := StartCreateRegistrationCall(w.m)
 	defer callCounter.Done(&err)
@@ -95,6 +101,12 @@ func (w metricsWrapper) DeleteJoinToken(ctx context.Context, token string) (err
 	return w.ds.DeleteJoinToken(ctx, token)
 }
 
+func (w metricsWrapper) DeleteLSVIDRevocation(ctx context.Context, kind, value string) (err error) {
+	callCounter := StartDeleteLSVIDRevocationCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.DeleteLSVIDRevocation(ctx, kind, value)
+}
+
 func (w metricsWrapper) DeleteRegistrationEntry(ctx context.Context, entryID string) (_ *common.RegistrationEntry, err error) {
 	callCounter := StartDeleteRegistrationCall(w.m)
 	defer callCounter.Done(&err)
@@ -149,6 +161,12 @@ func (w metricsWrapper) ListBundles(ctx context.Context, req *datastore.ListBund
 	return w.ds.ListBundles(ctx, req)
 }
 
+func (w metricsWrapper) ListLSVIDRevocations(ctx context.Context, req *datastore.ListLSVIDRevocationsRequest) (_ *datastore.ListLSVIDRevocationsResponse, err error) {
+	callCounter := StartListLSVIDRevocationsCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.ListLSVIDRevocations(ctx, req)
+}
+
 func (w metricsWrapper) ListNodeSelectors(ctx context.Context, req *datastore.ListNodeSelectorsRequest) (_ *datastore.ListNodeSelectorsResponse, err error) {
 	callCounter := StartListNodeSelectorsCall(w.m)
 	defer callCounter.Done(&err)
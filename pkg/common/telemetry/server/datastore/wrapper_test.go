@@ -69,6 +69,10 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.join_token.create",
 			methodName: "CreateJoinToken",
 		},
+		{
+			key:        "datastore.lsvid_revocation.create",
+			methodName: "CreateLSVIDRevocation",
+		},
 		{
 			key:        "datastore.registration_entry.create",
 			methodName: "CreateRegistrationEntry",
@@ -93,6 +97,10 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.join_token.delete",
 			methodName: "DeleteJoinToken",
 		},
+		{
+			key:        "datastore.lsvid_revocation.delete",
+			methodName: "DeleteLSVIDRevocation",
+		},
 		{
 			key:        "datastore.registration_entry.delete",
 			methodName: "DeleteRegistrationEntry",
@@ -129,6 +137,10 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.bundle.list",
 			methodName: "ListBundles",
 		},
+		{
+			key:        "datastore.lsvid_revocation.list",
+			methodName: "ListLSVIDRevocations",
+		},
 		{
 			key:        "datastore.node.selectors.list",
 			methodName: "ListNodeSelectors",
@@ -293,6 +305,10 @@ func (ds *fakeDataStore) CreateJoinToken(context.Context, *datastore.JoinToken)
 	return ds.err
 }
 
+func (ds *fakeDataStore) CreateLSVIDRevocation(context.Context, *datastore.LSVIDRevocation) (*datastore.LSVIDRevocation, error) {
+	return &datastore.LSVIDRevocation{}, ds.err
+}
+
 func (ds *fakeDataStore) CreateRegistrationEntry(context.Context, *common.RegistrationEntry) (*common.RegistrationEntry, error) {
 	return &common.RegistrationEntry{}, ds.err
 }
@@ -317,6 +333,10 @@ func (ds *fakeDataStore) DeleteJoinToken(context.Context, string) error {
 	return ds.err
 }
 
+func (ds *fakeDataStore) DeleteLSVIDRevocation(context.Context, string, string) error {
+	return ds.err
+}
+
 func (ds *fakeDataStore) DeleteRegistrationEntry(context.Context, string) (*common.RegistrationEntry, error) {
 	return &common.RegistrationEntry{}, ds.err
 }
@@ -353,6 +373,10 @@ func (ds *fakeDataStore) ListBundles(context.Context, *datastore.ListBundlesRequ
 	return &datastore.ListBundlesResponse{}, ds.err
 }
 
+func (ds *fakeDataStore) ListLSVIDRevocations(context.Context, *datastore.ListLSVIDRevocationsRequest) (*datastore.ListLSVIDRevocationsResponse, error) {
+	return &datastore.ListLSVIDRevocationsResponse{}, ds.err
+}
+
 func (ds *fakeDataStore) ListNodeSelectors(context.Context, *datastore.ListNodeSelectorsRequest) (*datastore.ListNodeSelectorsResponse, error) {
 	return &datastore.ListNodeSelectorsResponse{}, ds.err
 }
@@ -0,0 +1,509 @@
+package lsvid
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	core "github.com/spiffe/spire/pkg/common/lsvid"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/pkg/server/ca"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	lsvidv1 "github.com/spiffe/spire/proto/spire/api/server/lsvid/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RegisterService registers the service on the gRPC server.
+func RegisterService(s *grpc.Server, service *Service) {
+	lsvidv1.RegisterLSVIDServer(s, service)
+}
+
+// Config is the service configuration
+type Config struct {
+	EntryFetcher api.AuthorizedEntryFetcher
+	ServerCA     ca.ServerCA
+	TrustDomain  spiffeid.TrustDomain
+	DataStore    datastore.DataStore
+}
+
+// New creates a new LSVID service
+func New(config Config) *Service {
+	return &Service{
+		ca: config.ServerCA,
+		ef: config.EntryFetcher,
+		td: config.TrustDomain,
+		ds: config.DataStore,
+	}
+}
+
+// Service implements the v1 LSVID service
+type Service struct {
+	lsvidv1.UnsafeLSVIDServer
+
+	ca ca.ServerCA
+	ef api.AuthorizedEntryFetcher
+	td spiffeid.TrustDomain
+	ds datastore.DataStore
+}
+
+// NewLSVID signs a root LSVID layer for the subject in the request. The
+// subject must be the calling agent or the SPIFFE ID of a registration entry
+// authorized for the agent, and its key must be bound to a valid X509-SVID
+// issued by the server. Only the subject key and the audience
+// are taken from the request; the remaining claims are set by the server, with
+// the trust domain JWT key as root issuer key.
+func (s *Service) NewLSVID(ctx context.Context, req *lsvidv1.NewLSVIDRequest) (*lsvidv1.NewLSVIDResponse, error) {
+	log := rpccontext.Logger(ctx)
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{
+		telemetry.RegistrationID: req.EntryId,
+		telemetry.SPIFFEID:       req.SpiffeId,
+		telemetry.Audience:       req.Audience,
+	})
+
+	if err := rpccontext.RateLimit(ctx, 1); err != nil {
+		return nil, api.MakeErr(log, status.Code(err), "rejecting request due to LSVID signing request rate limiting", err)
+	}
+
+	switch {
+	case req.SpiffeId == "":
+		return nil, api.MakeErr(log, codes.InvalidArgument, "missing subject SPIFFE ID", nil)
+	case len(req.PublicKey) == 0:
+		return nil, api.MakeErr(log, codes.InvalidArgument, "missing subject public key", nil)
+	case req.Audience == "":
+		return nil, api.MakeErr(log, codes.InvalidArgument, "missing audience", nil)
+	}
+	if _, err := x509.ParsePKIXPublicKey(req.PublicKey); err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "malformed subject public key", err)
+	}
+
+	spiffeID, ttl, err := s.authorizeSubject(ctx, log, req.EntryId, req.SpiffeId)
+	if err != nil {
+		return nil, err
+	}
+	log = log.WithField(telemetry.SPIFFEID, spiffeID.String())
+
+	x509SVID, err := s.verifyBinding(ctx, log, req.X509Svid, spiffeID, req.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.ca.SignLSVID(ctx, ca.LSVIDParams{
+		Payload: &core.Payload{
+			Ver: core.Version,
+			Sub: &core.IDClaim{
+				CN: spiffeID.String(),
+				PK: req.PublicKey,
+			},
+			Aud: &core.IDClaim{
+				CN: req.Audience,
+			},
+		},
+		TTL:      ttl,
+		X509SVID: x509SVID,
+	})
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to sign LSVID", err)
+	}
+
+	encoded, err := core.Encode(token)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to encode LSVID", err)
+	}
+	rpccontext.AuditRPCWithFields(ctx, logrus.Fields{
+		telemetry.ExpiresAt: token.Payload.Exp,
+	})
+
+	return &lsvidv1.NewLSVIDResponse{
+		Lsvid: &lsvidv1.Token{
+			Token:     encoded,
+			SpiffeId:  spiffeID.String(),
+			IssuedAt:  token.Payload.Iat,
+			ExpiresAt: token.Payload.Exp,
+		},
+	}, nil
+}
+
+// MintLSVID signs a root LSVID layer for any SPIFFE ID in the trust domain,
+// with the subject key, audience, TTL and custom claims in the request. Unlike
+// NewLSVID, the subject key is not bound to an X509-SVID.
+func (s *Service) MintLSVID(ctx context.Context, req *lsvidv1.MintLSVIDRequest) (*lsvidv1.MintLSVIDResponse, error) {
+	log := rpccontext.Logger(ctx)
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{
+		telemetry.SPIFFEID: req.SpiffeId,
+		telemetry.Audience: req.Audience,
+		telemetry.TTL:      req.Ttl,
+	})
+
+	spiffeID, err := spiffeid.FromString(req.SpiffeId)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "invalid SPIFFE ID", err)
+	}
+	if err := api.VerifyTrustDomainWorkloadID(s.td, spiffeID); err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "invalid SPIFFE ID", err)
+	}
+	log = log.WithField(telemetry.SPIFFEID, spiffeID.String())
+
+	switch {
+	case len(req.PublicKey) == 0:
+		return nil, api.MakeErr(log, codes.InvalidArgument, "missing subject public key", nil)
+	case req.Audience == "":
+		return nil, api.MakeErr(log, codes.InvalidArgument, "missing audience", nil)
+	}
+	if _, err := x509.ParsePKIXPublicKey(req.PublicKey); err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "malformed subject public key", err)
+	}
+
+	payload := &core.Payload{
+		Ver: core.Version,
+		Sub: &core.IDClaim{
+			CN: spiffeID.String(),
+			PK: req.PublicKey,
+		},
+		Aud: &core.IDClaim{
+			CN: req.Audience,
+		},
+		Crit: req.CriticalClaims,
+	}
+	if req.Claims != "" {
+		if err := json.Unmarshal([]byte(req.Claims), &payload.Claims); err != nil {
+			return nil, api.MakeErr(log, codes.InvalidArgument, "malformed custom claims", err)
+		}
+	}
+	if err := core.CheckClaims(payload); err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "invalid custom claims", err)
+	}
+
+	token, err := s.ca.SignLSVID(ctx, ca.LSVIDParams{
+		Payload: payload,
+		TTL:     time.Duration(req.Ttl) * time.Second,
+	})
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to sign LSVID", err)
+	}
+
+	encoded, err := core.Encode(token)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to encode LSVID", err)
+	}
+	rpccontext.AuditRPCWithFields(ctx, logrus.Fields{
+		telemetry.ExpiresAt: token.Payload.Exp,
+	})
+
+	return &lsvidv1.MintLSVIDResponse{
+		Lsvid: &lsvidv1.Token{
+			Token:     encoded,
+			SpiffeId:  spiffeID.String(),
+			IssuedAt:  token.Payload.Iat,
+			ExpiresAt: token.Payload.Exp,
+		},
+	}, nil
+}
+
+// GetLSVIDBundle signs a trust bundle document listing the JWT authorities of
+// the trust bundle of the server, or of the requested federated trust domain,
+// that have not expired, with their key ID and expiration.
+func (s *Service) GetLSVIDBundle(ctx context.Context, req *lsvidv1.GetLSVIDBundleRequest) (*lsvidv1.GetLSVIDBundleResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	td := s.td
+	if req.TrustDomain != "" {
+		var err error
+		td, err = spiffeid.TrustDomainFromString(req.TrustDomain)
+		if err != nil {
+			return nil, api.MakeErr(log, codes.InvalidArgument, "malformed trust domain", err)
+		}
+		log = log.WithField(telemetry.TrustDomainID, td.IDString())
+	}
+
+	bundle, err := s.ds.FetchBundle(ctx, td.IDString())
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to fetch bundle", err)
+	}
+	if bundle == nil {
+		return nil, api.MakeErr(log, codes.NotFound, "bundle not found", nil)
+	}
+
+	now := time.Now()
+	var authorities []*core.Authority
+	for _, key := range bundle.JwtSigningKeys {
+		if key.NotAfter != 0 && !now.Before(time.Unix(key.NotAfter, 0)) {
+			continue
+		}
+		authorities = append(authorities, &core.Authority{
+			Kid: key.Kid,
+			PK:  key.PkixBytes,
+			Exp: key.NotAfter,
+		})
+	}
+
+	token, err := s.ca.SignLSVIDBundle(ctx, ca.LSVIDBundleParams{
+		TrustDomain: td,
+		Authorities: authorities,
+	})
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to sign LSVID trust bundle document", err)
+	}
+
+	encoded, err := core.Encode(token)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to encode LSVID trust bundle document", err)
+	}
+
+	return &lsvidv1.GetLSVIDBundleResponse{
+		Bundle:    encoded,
+		ExpiresAt: token.Payload.Exp,
+		IssuedAt:  token.Payload.Iat,
+	}, nil
+}
+
+// RevokeLSVID stores a revocation of the LSVID layers matching the kind and
+// value in the request. The value must be a SPIFFE ID, or a key hash or token
+// ID as computed by the lsvid package, depending on the kind.
+func (s *Service) RevokeLSVID(ctx context.Context, req *lsvidv1.RevokeLSVIDRequest) (*lsvidv1.RevokeLSVIDResponse, error) {
+	log := rpccontext.Logger(ctx)
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{
+		telemetry.RevocationKind:  req.Kind,
+		telemetry.RevocationValue: req.Value,
+	})
+
+	if err := validateRevocation(req.Kind, req.Value); err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "invalid revocation", err)
+	}
+
+	revocation, err := s.ds.CreateLSVIDRevocation(ctx, &datastore.LSVIDRevocation{
+		Kind:  req.Kind,
+		Value: req.Value,
+	})
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.AlreadyExists:
+		return nil, api.MakeErr(log, codes.AlreadyExists, "revocation already exists", nil)
+	default:
+		return nil, api.MakeErr(log, codes.Internal, "failed to create revocation", err)
+	}
+
+	return &lsvidv1.RevokeLSVIDResponse{
+		Revocation: revocationToProto(revocation),
+	}, nil
+}
+
+// ListLSVIDRevocations lists the LSVID revocations, paginated when the
+// request sets a page size.
+func (s *Service) ListLSVIDRevocations(ctx context.Context, req *lsvidv1.ListLSVIDRevocationsRequest) (*lsvidv1.ListLSVIDRevocationsResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	listReq := &datastore.ListLSVIDRevocationsRequest{}
+	if req.PageSize > 0 {
+		listReq.Pagination = &datastore.Pagination{
+			PageSize: req.PageSize,
+			Token:    req.PageToken,
+		}
+	}
+
+	dsResp, err := s.ds.ListLSVIDRevocations(ctx, listReq)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to list revocations", err)
+	}
+
+	resp := &lsvidv1.ListLSVIDRevocationsResponse{}
+	if dsResp.Pagination != nil {
+		resp.NextPageToken = dsResp.Pagination.Token
+	}
+	for _, revocation := range dsResp.Revocations {
+		resp.Revocations = append(resp.Revocations, revocationToProto(revocation))
+	}
+	return resp, nil
+}
+
+// DeleteLSVIDRevocation deletes the LSVID revocation with the kind and value
+// in the request.
+func (s *Service) DeleteLSVIDRevocation(ctx context.Context, req *lsvidv1.DeleteLSVIDRevocationRequest) (*lsvidv1.DeleteLSVIDRevocationResponse, error) {
+	log := rpccontext.Logger(ctx)
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{
+		telemetry.RevocationKind:  req.Kind,
+		telemetry.RevocationValue: req.Value,
+	})
+
+	switch {
+	case req.Kind == "":
+		return nil, api.MakeErr(log, codes.InvalidArgument, "missing revocation kind", nil)
+	case req.Value == "":
+		return nil, api.MakeErr(log, codes.InvalidArgument, "missing revocation value", nil)
+	}
+
+	err := s.ds.DeleteLSVIDRevocation(ctx, req.Kind, req.Value)
+	switch status.Code(err) {
+	case codes.OK:
+		return &lsvidv1.DeleteLSVIDRevocationResponse{}, nil
+	case codes.NotFound:
+		return nil, api.MakeErr(log, codes.NotFound, "revocation not found", nil)
+	default:
+		return nil, api.MakeErr(log, codes.Internal, "failed to delete revocation", err)
+	}
+}
+
+// authorizeSubject checks that the calling agent is authorized to obtain an
+// LSVID for the given subject, either because it is the agent itself or the
+// SPIFFE ID of a registration entry authorized for the agent. When an entry ID
+// is provided, the subject must match that entry. LSVIDs for the trust domain
+// itself are only signed by the server, as trust bundle documents. It returns
+// the subject SPIFFE ID and the TTL of the matching entry, if any.
+func (s *Service) authorizeSubject(ctx context.Context, log logrus.FieldLogger, entryID, subject string) (spiffeid.ID, time.Duration, error) {
+	callerID, ok := rpccontext.CallerID(ctx)
+	if !ok {
+		return spiffeid.ID{}, 0, api.MakeErr(log, codes.Internal, "caller ID missing from request context", nil)
+	}
+
+	spiffeID, err := spiffeid.FromString(subject)
+	if err != nil {
+		return spiffeid.ID{}, 0, api.MakeErr(log, codes.InvalidArgument, "malformed subject SPIFFE ID", err)
+	}
+	if spiffeID == s.td.ID() {
+		return spiffeid.ID{}, 0, api.MakeErr(log, codes.PermissionDenied, "LSVIDs for the trust domain are only signed by the server", nil)
+	}
+	if entryID == "" && spiffeID == callerID {
+		return spiffeID, 0, nil
+	}
+
+	entries, err := s.ef.FetchAuthorizedEntries(ctx, callerID)
+	if err != nil {
+		return spiffeid.ID{}, 0, api.MakeErr(log, codes.Internal, "failed to fetch registration entries", err)
+	}
+
+	if entryID != "" {
+		for _, entry := range entries {
+			if entry.Id != entryID {
+				continue
+			}
+			if !subjectMatches(s.td, entry, spiffeID) {
+				return spiffeid.ID{}, 0, api.MakeErr(log, codes.PermissionDenied, fmt.Sprintf("subject %q does not match entry", subject), nil)
+			}
+			return spiffeID, time.Duration(entry.Ttl) * time.Second, nil
+		}
+		return spiffeid.ID{}, 0, api.MakeErr(log, codes.NotFound, "entry not found or not authorized", nil)
+	}
+
+	for _, entry := range entries {
+		if subjectMatches(s.td, entry, spiffeID) {
+			return spiffeID, time.Duration(entry.Ttl) * time.Second, nil
+		}
+	}
+	return spiffeid.ID{}, 0, api.MakeErr(log, codes.PermissionDenied, fmt.Sprintf("no entry authorizes subject %q", subject), nil)
+}
+
+// verifyBinding verifies that the certificate chain is a valid X509-SVID for
+// the given SPIFFE ID, issued by the server, whose public key is the subject
+// key. It returns the leaf certificate.
+func (s *Service) verifyBinding(ctx context.Context, log logrus.FieldLogger, rawChain [][]byte, spiffeID spiffeid.ID, subjectKey []byte) (*x509.Certificate, error) {
+	if len(rawChain) == 0 {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "missing X509-SVID binding the subject public key", nil)
+	}
+
+	var chain []*x509.Certificate
+	for _, rawCert := range rawChain {
+		cert, err := x509.ParseCertificate(rawCert)
+		if err != nil {
+			return nil, api.MakeErr(log, codes.InvalidArgument, "malformed X509-SVID", err)
+		}
+		chain = append(chain, cert)
+	}
+	leaf := chain[0]
+
+	bundle, err := s.ds.FetchBundle(ctx, s.td.IDString())
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to fetch bundle", err)
+	}
+	if bundle == nil {
+		return nil, api.MakeErr(log, codes.NotFound, "bundle not found", nil)
+	}
+	roots := x509.NewCertPool()
+	for _, rootCA := range bundle.RootCas {
+		cert, err := x509.ParseCertificate(rootCA.DerBytes)
+		if err != nil {
+			return nil, api.MakeErr(log, codes.Internal, "malformed bundle", err)
+		}
+		roots.AddCert(cert)
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "X509-SVID was not issued by the server CA", err)
+	}
+
+	switch {
+	case leaf.IsCA:
+		return nil, api.MakeErr(log, codes.InvalidArgument, "X509-SVID is a CA certificate", nil)
+	case len(leaf.URIs) != 1 || leaf.URIs[0].String() != spiffeID.String():
+		return nil, api.MakeErr(log, codes.InvalidArgument, "X509-SVID SPIFFE ID does not match the subject", nil)
+	}
+
+	leafKey, err := x509.MarshalPKIXPublicKey(leaf.PublicKey)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "malformed X509-SVID", err)
+	}
+	if !bytes.Equal(leafKey, subjectKey) {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "subject public key does not match the X509-SVID", nil)
+	}
+
+	return leaf, nil
+}
+
+// validateRevocation checks that the value of a revocation is well-formed for
+// its kind.
+func validateRevocation(kind, value string) error {
+	if err := core.RevocationKind(kind).Validate(); err != nil {
+		return err
+	}
+	switch {
+	case value == "":
+		return errors.New("missing value")
+	case len(value) > datastore.MaxLSVIDRevocationValueLength:
+		return fmt.Errorf("value is longer than %d bytes", datastore.MaxLSVIDRevocationValueLength)
+	}
+
+	switch core.RevocationKind(kind) {
+	case core.RevokeSPIFFEID:
+		if _, err := spiffeid.FromString(value); err != nil {
+			return err
+		}
+	case core.RevokeKeyHash:
+		hash, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil || len(hash) != sha256.Size {
+			return fmt.Errorf("%s %q is not an unpadded base64url encoded SHA-256 digest", kind, value)
+		}
+	}
+	return nil
+}
+
+func revocationToProto(revocation *datastore.LSVIDRevocation) *lsvidv1.Revocation {
+	return &lsvidv1.Revocation{
+		Kind:      revocation.Kind,
+		Value:     revocation.Value,
+		CreatedAt: revocation.CreatedAt.Unix(),
+	}
+}
+
+func subjectMatches(td spiffeid.TrustDomain, entry *types.Entry, spiffeID spiffeid.ID) bool {
+	entryID, err := api.TrustDomainMemberIDFromProto(td, entry.SpiffeId)
+	return err == nil && entryID == spiffeID
+}
@@ -0,0 +1,823 @@
+package lsvid_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/lsvid"
+	"github.com/spiffe/spire/pkg/server/api"
+	lsvidapi "github.com/spiffe/spire/pkg/server/api/lsvid/v1"
+	"github.com/spiffe/spire/pkg/server/api/middleware"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/pkg/server/ca"
+	lsvidv1 "github.com/spiffe/spire/proto/spire/api/server/lsvid/v1"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/fakes/fakedatastore"
+	"github.com/spiffe/spire/test/fakes/fakeserverca"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testkey"
+	"github.com/spiffe/spire/test/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	testKey    = testkey.MustEC256()
+	td         = spiffeid.RequireTrustDomainFromString("example.org")
+	agentID    = td.NewID("agent")
+	workloadID = td.NewID("workload1")
+)
+
+func TestServiceNewLSVID(t *testing.T) {
+	test := setupServiceTest(t)
+	defer test.Cleanup()
+
+	workloadEntry := &types.Entry{
+		Id:       "workload-entry-id",
+		ParentId: api.ProtoFromID(agentID),
+		SpiffeId: api.ProtoFromID(workloadID),
+	}
+	workloadEntryWithTTL := &types.Entry{
+		Id:       "workload-entry-ttl-id",
+		ParentId: api.ProtoFromID(agentID),
+		SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload-ttl"},
+		Ttl:      10,
+	}
+	test.ef.entries = []*types.Entry{workloadEntry, workloadEntryWithTTL}
+
+	_, err := test.ds.AppendBundle(context.Background(), &common.Bundle{
+		TrustDomainId: td.IDString(),
+		RootCas:       []*common.Certificate{{DerBytes: test.ca.Bundle()[0].Raw}},
+	})
+	require.NoError(t, err)
+
+	jwtKey := test.ca.JWTKey()
+	now := test.ca.Clock().Now()
+	rootKey, err := x509.MarshalPKIXPublicKey(jwtKey.Signer.Public())
+	require.NoError(t, err)
+	subjectKey, err := x509.MarshalPKIXPublicKey(testKey.Public())
+	require.NoError(t, err)
+	caKey, err := x509.MarshalPKIXPublicKey(test.ca.Bundle()[0].PublicKey)
+	require.NoError(t, err)
+
+	// X509-SVIDs expire before the default LSVID TTL, so LSVIDs bound to them
+	// are capped to their lifetime.
+	x509SVIDTTL := test.ca.LSVIDTTL() / 2
+	x509SVIDs := map[string][]*x509.Certificate{
+		td.IDString(): test.ca.Bundle(),
+	}
+	for _, id := range []string{workloadID.String(), "spiffe://example.org/workload-ttl", agentID.String(), "spiffe://example.org/other"} {
+		svid, err := test.ca.SignX509SVID(context.Background(), ca.X509SVIDParams{
+			SpiffeID:  spiffeid.RequireFromString(id),
+			PublicKey: testKey.Public(),
+			TTL:       x509SVIDTTL,
+		})
+		require.NoError(t, err)
+		x509SVIDs[id] = svid
+	}
+
+	foreignTemplate, err := util.NewSVIDTemplate(test.ca.Clock(), workloadID.String())
+	require.NoError(t, err)
+	foreignSVID, _, err := util.SelfSign(foreignTemplate)
+	require.NoError(t, err)
+
+	otherKeySVID, err := test.ca.SignX509SVID(context.Background(), ca.X509SVIDParams{
+		SpiffeID:  workloadID,
+		PublicKey: testkey.MustEC256().Public(),
+	})
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name        string
+		entryID     string
+		spiffeID    string
+		publicKey   []byte
+		audience    string
+		chain       []*x509.Certificate
+		failMinting bool
+		code        codes.Code
+		err         string
+		expectTTL   time.Duration
+	}{
+		{
+			name:      "success for entry",
+			entryID:   workloadEntry.Id,
+			spiffeID:  workloadID.String(),
+			expectTTL: x509SVIDTTL,
+		},
+		{
+			name:      "success for entry with TTL",
+			entryID:   workloadEntryWithTTL.Id,
+			spiffeID:  "spiffe://example.org/workload-ttl",
+			expectTTL: 10 * time.Second,
+		},
+		{
+			name:      "success for subject without entry ID",
+			spiffeID:  workloadID.String(),
+			expectTTL: x509SVIDTTL,
+		},
+		{
+			name:      "success for calling agent",
+			spiffeID:  agentID.String(),
+			expectTTL: x509SVIDTTL,
+		},
+		{
+			name:     "missing SPIFFE ID",
+			spiffeID: "",
+			code:     codes.InvalidArgument,
+			err:      "missing subject SPIFFE ID",
+		},
+		{
+			name:      "missing public key",
+			spiffeID:  workloadID.String(),
+			publicKey: []byte{},
+			code:      codes.InvalidArgument,
+			err:       "missing subject public key",
+		},
+		{
+			name:      "malformed public key",
+			spiffeID:  workloadID.String(),
+			publicKey: []byte("not a key"),
+			code:      codes.InvalidArgument,
+			err:       "malformed subject public key",
+		},
+		{
+			name:     "missing audience",
+			spiffeID: workloadID.String(),
+			audience: "-",
+			code:     codes.InvalidArgument,
+			err:      "missing audience",
+		},
+		{
+			name:     "malformed SPIFFE ID",
+			spiffeID: "not a SPIFFE ID",
+			code:     codes.InvalidArgument,
+			err:      "malformed subject SPIFFE ID",
+		},
+		{
+			name:     "entry not found",
+			entryID:  "non-existent-entry",
+			spiffeID: workloadID.String(),
+			code:     codes.NotFound,
+			err:      "entry not found or not authorized",
+		},
+		{
+			name:     "subject does not match entry",
+			entryID:  workloadEntry.Id,
+			spiffeID: "spiffe://example.org/other",
+			code:     codes.PermissionDenied,
+			err:      `subject "spiffe://example.org/other" does not match entry`,
+		},
+		{
+			name:     "subject not authorized",
+			spiffeID: "spiffe://example.org/other",
+			code:     codes.PermissionDenied,
+			err:      `no entry authorizes subject "spiffe://example.org/other"`,
+		},
+		{
+			name:     "agent ID with entry ID",
+			entryID:  workloadEntry.Id,
+			spiffeID: agentID.String(),
+			code:     codes.PermissionDenied,
+			err:      `subject "spiffe://example.org/agent" does not match entry`,
+		},
+		{
+			name:     "missing X509-SVID",
+			spiffeID: workloadID.String(),
+			chain:    []*x509.Certificate{},
+			code:     codes.InvalidArgument,
+			err:      "missing X509-SVID binding the subject public key",
+		},
+		{
+			name:     "X509-SVID not issued by the server",
+			spiffeID: workloadID.String(),
+			chain:    []*x509.Certificate{foreignSVID},
+			code:     codes.InvalidArgument,
+			err:      "X509-SVID was not issued by the server CA",
+		},
+		{
+			name:     "X509-SVID for another SPIFFE ID",
+			spiffeID: workloadID.String(),
+			chain:    x509SVIDs[agentID.String()],
+			code:     codes.InvalidArgument,
+			err:      "X509-SVID SPIFFE ID does not match the subject",
+		},
+		{
+			name:     "X509-SVID for another key",
+			spiffeID: workloadID.String(),
+			chain:    otherKeySVID,
+			code:     codes.InvalidArgument,
+			err:      "subject public key does not match the X509-SVID",
+		},
+		{
+			name:      "trust domain bound to CA certificate",
+			spiffeID:  td.IDString(),
+			publicKey: caKey,
+			code:      codes.PermissionDenied,
+			err:       "LSVIDs for the trust domain are only signed by the server",
+		},
+		{
+			name:      "trust domain with entry ID",
+			entryID:   workloadEntry.Id,
+			spiffeID:  td.IDString(),
+			publicKey: caKey,
+			code:      codes.PermissionDenied,
+			err:       "LSVIDs for the trust domain are only signed by the server",
+		},
+		{
+			name:      "subject bound to CA certificate",
+			spiffeID:  workloadID.String(),
+			publicKey: caKey,
+			chain:     test.ca.Bundle(),
+			code:      codes.InvalidArgument,
+			err:       "X509-SVID is a CA certificate",
+		},
+		{
+			name:        "fails minting",
+			entryID:     workloadEntry.Id,
+			spiffeID:    workloadID.String(),
+			failMinting: true,
+			code:        codes.Internal,
+			err:         "JWT key is not available for signing",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.ca.SetJWTKey(jwtKey)
+			if tt.failMinting {
+				test.ca.SetJWTKey(nil)
+			}
+			test.rateLimiter.count = 1
+			test.rateLimiter.err = nil
+			test.withCallerID = true
+
+			publicKey := tt.publicKey
+			if publicKey == nil {
+				publicKey = subjectKey
+			}
+			audience := tt.audience
+			switch audience {
+			case "":
+				audience = "AUDIENCE"
+			case "-":
+				audience = ""
+			}
+			chain := tt.chain
+			if chain == nil {
+				chain = x509SVIDs[tt.spiffeID]
+			}
+			var rawChain [][]byte
+			for _, cert := range chain {
+				rawChain = append(rawChain, cert.Raw)
+			}
+
+			resp, err := test.client.NewLSVID(context.Background(), &lsvidv1.NewLSVIDRequest{
+				EntryId:   tt.entryID,
+				SpiffeId:  tt.spiffeID,
+				PublicKey: publicKey,
+				Audience:  audience,
+				X509Svid:  rawChain,
+			})
+			if tt.err != "" {
+				spiretest.RequireGRPCStatusContains(t, err, tt.code, tt.err)
+				require.Nil(t, resp)
+				return
+			}
+			require.NoError(t, err)
+
+			token, err := lsvid.Decode(resp.Lsvid.Token)
+			require.NoError(t, err)
+			require.NoError(t, lsvid.Validate(token, lsvid.WithClock(test.ca.Clock())))
+			require.Nil(t, token.Nested)
+			require.Equal(t, &lsvid.IDClaim{CN: "spiffe://example.org", PK: rootKey, Kid: jwtKey.Kid}, token.Payload.Iss)
+			require.Equal(t, &lsvid.IDClaim{
+				CN:  tt.spiffeID,
+				PK:  publicKey,
+				X5T: lsvid.X509SVIDThumbprint(x509SVIDs[tt.spiffeID][0]),
+			}, token.Payload.Sub)
+			require.Equal(t, &lsvid.IDClaim{CN: "AUDIENCE"}, token.Payload.Aud)
+			require.Equal(t, now.Unix(), token.Payload.Iat)
+			require.Equal(t, now.Add(tt.expectTTL).Unix(), token.Payload.Exp)
+			require.Equal(t, tt.spiffeID, resp.Lsvid.SpiffeId)
+			require.Equal(t, token.Payload.Iat, resp.Lsvid.IssuedAt)
+			require.Equal(t, token.Payload.Exp, resp.Lsvid.ExpiresAt)
+		})
+	}
+}
+
+func TestServiceMintLSVID(t *testing.T) {
+	test := setupServiceTest(t)
+	defer test.Cleanup()
+
+	jwtKey := test.ca.JWTKey()
+	now := test.ca.Clock().Now()
+	rootKey, err := x509.MarshalPKIXPublicKey(jwtKey.Signer.Public())
+	require.NoError(t, err)
+	subjectKey, err := x509.MarshalPKIXPublicKey(testKey.Public())
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name         string
+		spiffeID     string
+		publicKey    []byte
+		audience     string
+		ttl          int32
+		claims       string
+		crit         []string
+		failMinting  bool
+		code         codes.Code
+		err          string
+		expectTTL    time.Duration
+		expectClaims map[string]interface{}
+	}{
+		{
+			name:      "success with defaults",
+			spiffeID:  workloadID.String(),
+			expectTTL: test.ca.LSVIDTTL(),
+		},
+		{
+			name:      "success with TTL",
+			spiffeID:  workloadID.String(),
+			ttl:       10,
+			expectTTL: 10 * time.Second,
+		},
+		{
+			name:         "success with custom claims",
+			spiffeID:     workloadID.String(),
+			claims:       `{"https://example.org/job":"batch","urn:example:scope":["read"]}`,
+			crit:         []string{"https://example.org/job"},
+			expectTTL:    test.ca.LSVIDTTL(),
+			expectClaims: map[string]interface{}{"https://example.org/job": "batch", "urn:example:scope": []interface{}{"read"}},
+		},
+		{
+			name:     "malformed SPIFFE ID",
+			spiffeID: "not a SPIFFE ID",
+			code:     codes.InvalidArgument,
+			err:      "invalid SPIFFE ID",
+		},
+		{
+			name:     "SPIFFE ID in another trust domain",
+			spiffeID: "spiffe://another.org/workload",
+			code:     codes.InvalidArgument,
+			err:      `invalid SPIFFE ID: "spiffe://another.org/workload" is not a member of trust domain "example.org"`,
+		},
+		{
+			name:     "SPIFFE ID in the reserved namespace",
+			spiffeID: "spiffe://example.org/spire/agent/join_token/token",
+			code:     codes.InvalidArgument,
+			err:      "path is in the reserved namespace",
+		},
+		{
+			name:      "missing public key",
+			spiffeID:  workloadID.String(),
+			publicKey: []byte{},
+			code:      codes.InvalidArgument,
+			err:       "missing subject public key",
+		},
+		{
+			name:      "malformed public key",
+			spiffeID:  workloadID.String(),
+			publicKey: []byte("not a key"),
+			code:      codes.InvalidArgument,
+			err:       "malformed subject public key",
+		},
+		{
+			name:     "missing audience",
+			spiffeID: workloadID.String(),
+			audience: "-",
+			code:     codes.InvalidArgument,
+			err:      "missing audience",
+		},
+		{
+			name:     "malformed custom claims",
+			spiffeID: workloadID.String(),
+			claims:   `["not an object"]`,
+			code:     codes.InvalidArgument,
+			err:      "malformed custom claims",
+		},
+		{
+			name:     "custom claim name is not a URI",
+			spiffeID: workloadID.String(),
+			claims:   `{"job":"batch"}`,
+			code:     codes.InvalidArgument,
+			err:      `invalid custom claims: claim name "job" is not an absolute URI`,
+		},
+		{
+			name:     "critical claim missing",
+			spiffeID: workloadID.String(),
+			crit:     []string{"https://example.org/job"},
+			code:     codes.InvalidArgument,
+			err:      `invalid custom claims: critical claim "https://example.org/job" missing from payload`,
+		},
+		{
+			name:        "fails minting",
+			spiffeID:    workloadID.String(),
+			failMinting: true,
+			code:        codes.Internal,
+			err:         "failed to sign LSVID: JWT key is not available for signing",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.ca.SetJWTKey(jwtKey)
+			if tt.failMinting {
+				test.ca.SetJWTKey(nil)
+			}
+
+			publicKey := tt.publicKey
+			if publicKey == nil {
+				publicKey = subjectKey
+			}
+			audience := tt.audience
+			switch audience {
+			case "":
+				audience = "AUDIENCE"
+			case "-":
+				audience = ""
+			}
+
+			resp, err := test.client.MintLSVID(context.Background(), &lsvidv1.MintLSVIDRequest{
+				SpiffeId:       tt.spiffeID,
+				PublicKey:      publicKey,
+				Audience:       audience,
+				Ttl:            tt.ttl,
+				Claims:         tt.claims,
+				CriticalClaims: tt.crit,
+			})
+			if tt.err != "" {
+				spiretest.RequireGRPCStatusContains(t, err, tt.code, tt.err)
+				require.Nil(t, resp)
+				return
+			}
+			require.NoError(t, err)
+
+			token, err := lsvid.Decode(resp.Lsvid.Token)
+			require.NoError(t, err)
+			require.NoError(t, lsvid.Validate(token, lsvid.WithClock(test.ca.Clock()), lsvid.WithKnownClaims(tt.crit...)))
+			require.Nil(t, token.Nested)
+			require.Equal(t, &lsvid.IDClaim{CN: "spiffe://example.org", PK: rootKey, Kid: jwtKey.Kid}, token.Payload.Iss)
+			require.Equal(t, &lsvid.IDClaim{CN: tt.spiffeID, PK: publicKey}, token.Payload.Sub)
+			require.Equal(t, &lsvid.IDClaim{CN: "AUDIENCE"}, token.Payload.Aud)
+			require.Equal(t, tt.expectClaims, token.Payload.Claims)
+			require.Equal(t, tt.crit, token.Payload.Crit)
+			require.Equal(t, now.Unix(), token.Payload.Iat)
+			require.Equal(t, now.Add(tt.expectTTL).Unix(), token.Payload.Exp)
+			require.Equal(t, tt.spiffeID, resp.Lsvid.SpiffeId)
+			require.Equal(t, token.Payload.Iat, resp.Lsvid.IssuedAt)
+			require.Equal(t, token.Payload.Exp, resp.Lsvid.ExpiresAt)
+		})
+	}
+}
+
+func TestServiceGetLSVIDBundle(t *testing.T) {
+	test := setupServiceTest(t)
+	defer test.Cleanup()
+
+	ctx := context.Background()
+
+	// No bundle yet
+	resp, err := test.client.GetLSVIDBundle(ctx, &lsvidv1.GetLSVIDBundleRequest{})
+	spiretest.RequireGRPCStatus(t, err, codes.NotFound, "bundle not found")
+	require.Nil(t, resp)
+
+	jwtKey := test.ca.JWTKey()
+	currentKey, err := x509.MarshalPKIXPublicKey(jwtKey.Signer.Public())
+	require.NoError(t, err)
+	previousKey, err := x509.MarshalPKIXPublicKey(testKey.Public())
+	require.NoError(t, err)
+
+	now := time.Now()
+	_, err = test.ds.AppendBundle(ctx, &common.Bundle{
+		TrustDomainId: td.IDString(),
+		RootCas:       []*common.Certificate{{DerBytes: test.ca.Bundle()[0].Raw}},
+		JwtSigningKeys: []*common.PublicKey{
+			{Kid: jwtKey.Kid, PkixBytes: currentKey, NotAfter: now.Add(time.Hour).Unix()},
+			{Kid: "previous", PkixBytes: previousKey, NotAfter: now.Add(time.Minute).Unix()},
+			{Kid: "expired", PkixBytes: previousKey, NotAfter: now.Add(-time.Minute).Unix()},
+		},
+	})
+	require.NoError(t, err)
+
+	resp, err = test.client.GetLSVIDBundle(ctx, &lsvidv1.GetLSVIDBundleRequest{})
+	require.NoError(t, err)
+
+	bundle, err := lsvid.Decode(resp.Bundle)
+	require.NoError(t, err)
+	require.Equal(t, bundle.Payload.Exp, resp.ExpiresAt)
+	require.Equal(t, bundle.Payload.Iat, resp.IssuedAt)
+	require.Equal(t, []*lsvid.Authority{
+		{Kid: jwtKey.Kid, PK: currentKey, Exp: now.Add(time.Hour).Unix()},
+		{Kid: "previous", PK: previousKey, Exp: now.Add(time.Minute).Unix()},
+	}, bundle.Payload.Keys)
+
+	// The document is anchored in the X509 CA of the server.
+	trustDomainID, keys, err := lsvid.ValidateBundle(bundle, test.ca.Bundle()[0].PublicKey, lsvid.WithClock(test.ca.Clock()))
+	require.NoError(t, err)
+	require.Equal(t, td.IDString(), trustDomainID)
+	require.Len(t, keys, 2)
+
+	// Federated trust domain
+	resp, err = test.client.GetLSVIDBundle(ctx, &lsvidv1.GetLSVIDBundleRequest{TrustDomain: "domain.test"})
+	spiretest.RequireGRPCStatus(t, err, codes.NotFound, "bundle not found")
+	require.Nil(t, resp)
+
+	_, err = test.ds.AppendBundle(ctx, &common.Bundle{
+		TrustDomainId: "spiffe://domain.test",
+		RootCas:       []*common.Certificate{{DerBytes: test.ca.Bundle()[0].Raw}},
+		JwtSigningKeys: []*common.PublicKey{
+			{Kid: "federated", PkixBytes: previousKey, NotAfter: now.Add(time.Hour).Unix()},
+		},
+	})
+	require.NoError(t, err)
+
+	resp, err = test.client.GetLSVIDBundle(ctx, &lsvidv1.GetLSVIDBundleRequest{TrustDomain: "domain.test"})
+	require.NoError(t, err)
+	bundle, err = lsvid.Decode(resp.Bundle)
+	require.NoError(t, err)
+	trustDomainID, keys, err = lsvid.ValidateBundle(bundle, test.ca.Bundle()[0].PublicKey, lsvid.WithClock(test.ca.Clock()))
+	require.NoError(t, err)
+	require.Equal(t, "spiffe://domain.test", trustDomainID)
+	require.Equal(t, map[string]crypto.PublicKey{"federated": testKey.Public()}, keys)
+
+	resp, err = test.client.GetLSVIDBundle(ctx, &lsvidv1.GetLSVIDBundleRequest{TrustDomain: "Bad Domain"})
+	spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, `malformed trust domain: spiffeid: unable to parse: parse "spiffe://Bad Domain": invalid character " " in host name`)
+	require.Nil(t, resp)
+
+	// Fails to sign
+	x509CA := test.ca.X509CA()
+	test.ca.SetX509CA(nil)
+	defer test.ca.SetX509CA(x509CA)
+	resp, err = test.client.GetLSVIDBundle(ctx, &lsvidv1.GetLSVIDBundleRequest{})
+	spiretest.RequireGRPCStatus(t, err, codes.Internal, "failed to sign LSVID trust bundle document: X509 CA is not available for signing")
+	require.Nil(t, resp)
+}
+
+func TestServiceLSVIDRevocations(t *testing.T) {
+	test := setupServiceTest(t)
+	defer test.Cleanup()
+
+	ctx := context.Background()
+	subjectKey, err := x509.MarshalPKIXPublicKey(testKey.Public())
+	require.NoError(t, err)
+	keyHash := lsvid.KeyHash(subjectKey)
+
+	for _, tt := range []struct {
+		name  string
+		kind  string
+		value string
+		code  codes.Code
+		err   string
+	}{
+		{
+			name:  "SPIFFE ID",
+			kind:  "SPIFFE_ID",
+			value: workloadID.String(),
+		},
+		{
+			name:  "key hash",
+			kind:  "KEY_HASH",
+			value: keyHash,
+		},
+		{
+			name:  "token ID",
+			kind:  "TOKEN_ID",
+			value: "3q2-7wAAAAAAAAAAAAAAAA",
+		},
+		{
+			name:  "already revoked",
+			kind:  "KEY_HASH",
+			value: keyHash,
+			code:  codes.AlreadyExists,
+			err:   "revocation already exists",
+		},
+		{
+			name:  "unknown kind",
+			kind:  "SERIAL",
+			value: "1",
+			code:  codes.InvalidArgument,
+			err:   `invalid revocation: unknown revocation kind "SERIAL"`,
+		},
+		{
+			name: "missing value",
+			kind: "TOKEN_ID",
+			code: codes.InvalidArgument,
+			err:  "invalid revocation: missing value",
+		},
+		{
+			name:  "malformed SPIFFE ID",
+			kind:  "SPIFFE_ID",
+			value: "workload",
+			code:  codes.InvalidArgument,
+			err:   "invalid revocation: spiffeid: invalid scheme",
+		},
+		{
+			name:  "malformed key hash",
+			kind:  "KEY_HASH",
+			value: "not-a-digest",
+			code:  codes.InvalidArgument,
+			err:   `invalid revocation: KEY_HASH "not-a-digest" is not an unpadded base64url encoded SHA-256 digest`,
+		},
+		{
+			name:  "value too long",
+			kind:  "TOKEN_ID",
+			value: strings.Repeat("a", 2049),
+			code:  codes.InvalidArgument,
+			err:   "invalid revocation: value is longer than 2048 bytes",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := test.client.RevokeLSVID(ctx, &lsvidv1.RevokeLSVIDRequest{
+				Kind:  tt.kind,
+				Value: tt.value,
+			})
+			if tt.err != "" {
+				spiretest.RequireGRPCStatus(t, err, tt.code, tt.err)
+				require.Nil(t, resp)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.kind, resp.Revocation.Kind)
+			require.Equal(t, tt.value, resp.Revocation.Value)
+			require.NotZero(t, resp.Revocation.CreatedAt)
+		})
+	}
+
+	listResp, err := test.client.ListLSVIDRevocations(ctx, &lsvidv1.ListLSVIDRevocationsRequest{})
+	require.NoError(t, err)
+	require.Len(t, listResp.Revocations, 3)
+	require.Equal(t, workloadID.String(), listResp.Revocations[0].Value)
+	require.Equal(t, keyHash, listResp.Revocations[1].Value)
+	require.Equal(t, "3q2-7wAAAAAAAAAAAAAAAA", listResp.Revocations[2].Value)
+	require.Empty(t, listResp.NextPageToken)
+
+	// Revocations are listed in pages
+	listResp, err = test.client.ListLSVIDRevocations(ctx, &lsvidv1.ListLSVIDRevocationsRequest{PageSize: 2})
+	require.NoError(t, err)
+	require.Len(t, listResp.Revocations, 2)
+	require.Equal(t, workloadID.String(), listResp.Revocations[0].Value)
+	require.NotEmpty(t, listResp.NextPageToken)
+	listResp, err = test.client.ListLSVIDRevocations(ctx, &lsvidv1.ListLSVIDRevocationsRequest{
+		PageSize:  2,
+		PageToken: listResp.NextPageToken,
+	})
+	require.NoError(t, err)
+	require.Len(t, listResp.Revocations, 1)
+	require.Equal(t, "3q2-7wAAAAAAAAAAAAAAAA", listResp.Revocations[0].Value)
+
+	_, err = test.client.DeleteLSVIDRevocation(ctx, &lsvidv1.DeleteLSVIDRevocationRequest{
+		Kind:  "SPIFFE_ID",
+		Value: workloadID.String(),
+	})
+	require.NoError(t, err)
+
+	_, err = test.client.DeleteLSVIDRevocation(ctx, &lsvidv1.DeleteLSVIDRevocationRequest{
+		Kind:  "SPIFFE_ID",
+		Value: workloadID.String(),
+	})
+	spiretest.RequireGRPCStatus(t, err, codes.NotFound, "revocation not found")
+
+	_, err = test.client.DeleteLSVIDRevocation(ctx, &lsvidv1.DeleteLSVIDRevocationRequest{
+		Kind: "SPIFFE_ID",
+	})
+	spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, "missing revocation value")
+
+	listResp, err = test.client.ListLSVIDRevocations(ctx, &lsvidv1.ListLSVIDRevocationsRequest{})
+	require.NoError(t, err)
+	require.Len(t, listResp.Revocations, 2)
+	require.Equal(t, "KEY_HASH", listResp.Revocations[0].Kind)
+
+	// Datastore failures
+	test.ds.SetNextError(errors.New("oh no"))
+	listResp, err = test.client.ListLSVIDRevocations(ctx, &lsvidv1.ListLSVIDRevocationsRequest{})
+	spiretest.RequireGRPCStatus(t, err, codes.Internal, "failed to list revocations: oh no")
+	require.Nil(t, listResp)
+}
+
+func TestServiceNewLSVIDRateLimit(t *testing.T) {
+	test := setupServiceTest(t)
+	defer test.Cleanup()
+
+	test.rateLimiter.count = 1
+	test.rateLimiter.err = status.Error(codes.Unknown, "rate limit fails")
+	test.withCallerID = true
+
+	resp, err := test.client.NewLSVID(context.Background(), &lsvidv1.NewLSVIDRequest{
+		SpiffeId: workloadID.String(),
+	})
+	spiretest.RequireGRPCStatus(t, err, codes.Unknown, "rejecting request due to LSVID signing request rate limiting: rate limit fails")
+	require.Nil(t, resp)
+}
+
+type serviceTest struct {
+	client       lsvidv1.LSVIDClient
+	ef           *entryFetcher
+	ca           *fakeserverca.CA
+	ds           *fakedatastore.DataStore
+	logHook      *test.Hook
+	rateLimiter  *fakeRateLimiter
+	withCallerID bool
+	done         func()
+}
+
+func (c *serviceTest) Cleanup() {
+	c.done()
+}
+
+func setupServiceTest(t *testing.T) *serviceTest {
+	ca := fakeserverca.New(t, td, &fakeserverca.Options{})
+	ef := &entryFetcher{}
+	ds := fakedatastore.New(t)
+
+	rateLimiter := &fakeRateLimiter{}
+	service := lsvidapi.New(lsvidapi.Config{
+		EntryFetcher: ef,
+		ServerCA:     ca,
+		TrustDomain:  td,
+		DataStore:    ds,
+	})
+
+	log, logHook := test.NewNullLogger()
+	registerFn := func(s *grpc.Server) {
+		lsvidapi.RegisterService(s, service)
+	}
+
+	test := &serviceTest{
+		ca:          ca,
+		ef:          ef,
+		ds:          ds,
+		logHook:     logHook,
+		rateLimiter: rateLimiter,
+	}
+
+	ppMiddleware := middleware.Preprocess(func(ctx context.Context, fullMethod string, req interface{}) (context.Context, error) {
+		ctx = rpccontext.WithLogger(ctx, log)
+		ctx = rpccontext.WithRateLimiter(ctx, rateLimiter)
+		if test.withCallerID {
+			ctx = rpccontext.WithCallerID(ctx, agentID)
+		}
+		return ctx, nil
+	})
+
+	unaryInterceptor, streamInterceptor := middleware.Interceptors(middleware.Chain(
+		ppMiddleware,
+		// Add audit log with uds tracking disabled
+		middleware.WithAuditLog(false),
+	))
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(unaryInterceptor),
+		grpc.StreamInterceptor(streamInterceptor),
+	)
+
+	conn, done := spiretest.NewAPIServerWithMiddleware(t, registerFn, server)
+	test.client = lsvidv1.NewLSVIDClient(conn)
+	test.done = done
+
+	return test
+}
+
+type entryFetcher struct {
+	err     string
+	entries []*types.Entry
+}
+
+func (f *entryFetcher) FetchAuthorizedEntries(ctx context.Context, agentID spiffeid.ID) ([]*types.Entry, error) {
+	if f.err != "" {
+		return nil, status.Error(codes.Internal, f.err)
+	}
+
+	caller, ok := rpccontext.CallerID(ctx)
+	if !ok {
+		return nil, errors.New("no caller ID on context")
+	}
+
+	if caller != agentID {
+		return nil, fmt.Errorf("provided caller id is different to expected")
+	}
+
+	return f.entries, nil
+}
+
+type fakeRateLimiter struct {
+	count int
+	err   error
+}
+
+func (f *fakeRateLimiter) RateLimit(ctx context.Context, count int) error {
+	if f.count != count {
+		return fmt.Errorf("rate limiter got %d but expected %d", count, f.count)
+	}
+
+	return f.err
+}
@@ -11,7 +11,7 @@ import (
 	svidv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/svid/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/pkg/common/idutil"
-	// "github.com/spiffe/spire/pkg/common/jwtsvid"
+	"github.com/spiffe/spire/pkg/common/jwtsvid"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/pkg/server/api"
@@ -21,41 +21,8 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
-
-	"fmt"
-	"encoding/base64"
-	"encoding/json"
-	"crypto/ecdsa"
 )
 
-
-type IDClaim struct {
-	CN	string		`json:"cn,omitempty"`
-	PK	[]byte		`json:"pk,omitempty"`
-	ID	*Token		`json:"id,omitempty"`
-}
-
-type Payload struct {
-	Ver int8		`json:"ver,omitempty"`
-	Alg string		`json:"alg,omitempty"`
-	Iat	int64		`json:"iat,omitempty"`
-	Iss	*IDClaim	`json:"iss,omitempty"`
-	Sub	*IDClaim	`json:"sub,omitempty"`
-	Aud	*IDClaim	`json:"aud,omitempty"`
-}
-
-type Token struct {	
-	Nested		*Token		`json:"nested,omitempty"`
-	Payload		*Payload	`json:"payload"`
-	Signature	[]byte		`json:"signature"`
-}
-
-type LSVID struct {
-	Token		*Token		`json:"token"`
-	Bundle		*Token		`json:"bundle"`
-}
-
-
 // RegisterService registers the service on the gRPC server.
 func RegisterService(s *grpc.Server, service *Service) {
 	svidv1.RegisterSVIDServer(s, service)
@@ -162,32 +129,16 @@ func (s *Service) MintX509SVID(ctx context.Context, req *svidv1.MintX509SVIDRequ
 }
 
 func (s *Service) MintJWTSVID(ctx context.Context, req *svidv1.MintJWTSVIDRequest) (*svidv1.MintJWTSVIDResponse, error) {
-
-	// log := rpccontext.Logger(ctx)
-
-	var lsvidPayloads []string
-	
-	// if req.Audience[0] != "" {
-	// 	lsvidPayloads = append(lsvidPayloads, req.Audience[0])
-	// 	log.Info("appended lsvidPayloads: ", lsvidPayloads)
-	// }
-
-	// lsvidPayloads = s.getTrustBundleLSVID(ctx)
-
-	// lsvidPayloads = append(lsvidPayloads, req.Audience[0])
-
-
-	lsvid, err := s.ca.SignLSVID(ctx, lsvidPayloads)
+	rpccontext.AddRPCAuditFields(ctx, s.fieldsFromJWTSvidParams(req.Id, req.Audience, req.Ttl))
+	jwtsvid, err := s.mintJWTSVID(ctx, req.Id, req.Audience, req.Ttl)
 	if err != nil {
 		return nil, err
 	}
+	rpccontext.AuditRPC(ctx)
 
 	return &svidv1.MintJWTSVIDResponse{
-		Svid: &types.JWTSVID{
-			Token: lsvid,
-		},
+		Svid: jwtsvid,
 	}, nil
-
 }
 
 func (s *Service) BatchNewX509SVID(ctx context.Context, req *svidv1.BatchNewX509SVIDRequest) (*svidv1.BatchNewX509SVIDResponse, error) {
@@ -317,76 +268,75 @@ func (s *Service) newX509SVID(ctx context.Context, param *svidv1.NewX509SVIDPara
 	}
 }
 
-func (s *Service) NewJWTSVID(ctx context.Context, req *svidv1.NewJWTSVIDRequest) (resp *svidv1.NewJWTSVIDResponse, err error) {
-	
+func (s *Service) mintJWTSVID(ctx context.Context, protoID *types.SPIFFEID, audience []string, ttl int32) (*types.JWTSVID, error) {
 	log := rpccontext.Logger(ctx)
 
-	if err := rpccontext.RateLimit(ctx, 1); err != nil {
-		return nil, api.MakeErr(log, status.Code(err), "rejecting request due to JWT signing request rate limiting", err)
+	id, err := api.TrustDomainWorkloadIDFromProto(s.td, protoID)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "invalid SPIFFE ID", err)
 	}
 
-	// Add root public key to iss.PK
-    // Unmarshal the decoded byte slice into your struct
+	log = log.WithField(telemetry.SPIFFEID, id.String())
 
-	log.Printf("req.Audience: %s\n", req.Audience[0])
-	tmp, err := base64.RawURLEncoding.DecodeString(req.Audience[0])
-	if err 	!= nil {
-		return nil, api.MakeErr(log, codes.NotFound, "Error decoding: %s\n", err)
+	if len(audience) == 0 {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "at least one audience is required", nil)
 	}
 
-
-    var decPayload *Payload
-    err = json.Unmarshal(tmp, &decPayload)
-    if err != nil {
-        return nil, api.MakeErr(log, codes.NotFound, "error unmarshalling LSVID: %v", err)
-    }
-
-	// Marshal the public key to DER format
-	log.Printf("JWTPubKey result: %v\n", s.ca.JWTPubKey())
-	rootPK, err := x509.MarshalPKIXPublicKey(s.ca.JWTPubKey())
+	token, err := s.ca.SignJWTSVID(ctx, ca.JWTSVIDParams{
+		SpiffeID: id,
+		TTL:      time.Duration(ttl) * time.Second,
+		Audience: audience,
+	})
 	if err != nil {
-		return nil, api.MakeErr(log, codes.Internal, "Failed to marshal public key:", err)
+		return nil, api.MakeErr(log, codes.Internal, "failed to sign JWT-SVID", err)
 	}
 
-	decPayload.Iss.PK = rootPK
-
-	jsonData, err := json.Marshal(decPayload)
+	issuedAt, expiresAt, err := jwtsvid.GetTokenExpiry(token)
 	if err != nil {
-		return nil, api.MakeErr(log, codes.NotFound, "Error marshaling data to JSON", nil)
+		return nil, api.MakeErr(log, codes.Internal, "failed to get JWT-SVID expiry", err)
 	}
-	// // Add Trust bundle LSVID payload as first LSVID
-	// // payload := s.getTrustBundleLSVID(ctx)
-	// payload, err := s.getBundleLSVIDPayload(ctx)
-	// if err != nil {
-	// 	return nil, api.MakeErr(log, codes.Internal, "failed retrieving bundle LSVID payload", err)
-	// }
 
-	// // Append all received LSVIDs
-	// payload = append(payload, req.Audience...)
+	return &types.JWTSVID{
+		Token:     token,
+		Id:        api.ProtoFromID(id),
+		ExpiresAt: expiresAt.Unix(),
+		IssuedAt:  issuedAt.Unix(),
+	}, nil
+}
 
-	// Request signature of all LSVID payloads
+func (s *Service) NewJWTSVID(ctx context.Context, req *svidv1.NewJWTSVIDRequest) (resp *svidv1.NewJWTSVIDResponse, err error) {
+	log := rpccontext.Logger(ctx)
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{
+		telemetry.RegistrationID: req.EntryId,
+		telemetry.Audience:       strings.Join(req.Audience, ","),
+	})
+
+	if err := rpccontext.RateLimit(ctx, 1); err != nil {
+		return nil, api.MakeErr(log, status.Code(err), "rejecting request due to JWT signing request rate limiting", err)
+	}
 
-	// fmt.Printf("Value to be signed: %v\n", jsonData)
-	lsvid, err := s.ca.SignLSVID(ctx, []string{base64.RawURLEncoding.EncodeToString(jsonData)})
+	// Fetch authorized entries
+	entriesMap, err := s.fetchEntries(ctx, log)
 	if err != nil {
-		return nil, api.MakeErr(log, codes.Internal, "failed to sign JWT-SVID", err)
+		return nil, err
 	}
-	fmt.Printf("Signed LSVID: %s\n", lsvid)
 
-	outLSVID := &types.JWTSVID{
-		Token:		lsvid,
-		IssuedAt:	time.Now().Unix(),
-		ExpiresAt:	time.Now().Add(3 * time.Minute).Unix(), 
+	entry, ok := entriesMap[req.EntryId]
+	if !ok {
+		return nil, api.MakeErr(log, codes.NotFound, "entry not found or not authorized", nil)
 	}
-	// log.Debug("resulting outLSVID: ", outLSVID)
-	fmt.Printf("resulting outLSVID: %s\n", outLSVID)
 
-	response := &svidv1.NewJWTSVIDResponse{
-		Svid: outLSVID,
+	jwtsvid, err := s.mintJWTSVID(ctx, entry.SpiffeId, req.Audience, entry.Ttl)
+	if err != nil {
+		return nil, err
 	}
-	// log.Debug("resulting response: ", response)
+	rpccontext.AuditRPCWithFields(ctx, logrus.Fields{
+		telemetry.TTL: entry.Ttl,
+	})
 
-	return response, nil
+	return &svidv1.NewJWTSVIDResponse{
+		Svid: jwtsvid,
+	}, nil
 }
 
 func (s *Service) NewDownstreamX509CA(ctx context.Context, req *svidv1.NewDownstreamX509CARequest) (*svidv1.NewDownstreamX509CAResponse, error) {
@@ -477,141 +427,3 @@ func parseAndCheckCSR(ctx context.Context, csrBytes []byte) (*x509.CertificateRe
 
 	return csr, nil
 }
-
-func (s *Service) getTrustBundleLSVID(ctx context.Context) []string {
-
-	log := rpccontext.Logger(ctx)
-	bundle, err := s.ds.FetchBundle(ctx, s.td.IDString())
-	if err != nil || bundle == nil {
-		return nil
-	}
-
-	var tbList []string
-	for i:=0;i<len(bundle.RootCas);i++ {
-		cert, err := x509.ParseCertificate(bundle.RootCas[i].DerBytes)
-		if err != nil {
-			return nil
-		}
-		
-		tmpLSR, err := s.cert2LSR(ctx, cert)
-		if err != nil {
-			return nil
-		}
-
-		log.Info("Generated trust bundle LSVID payload: ", tmpLSR)
-		tbList = append(tbList, tmpLSR)
-	}
-	return tbList
-}
-
-// func (s *Service) getBundleLSVIDPayload(ctx context.Context) ([]string, error) {
-
-// 	log := rpccontext.Logger(ctx)
-// 	tbLSR, err := s.cert2LSR(ctx)
-// 	if err != nil {
-// 		return nil, api.MakeErr(log, status.Code(err), "Error generating LSR", err)
-// 	}
-// 	return []string{tbLSR}, nil
-// }
-
-// Create an LSVID sign request given a x509 certificate.
-// Format: version.issuer.subject.subjpublickey.expiration.signature
-func (s *Service) cert2LSR(ctx context.Context, cert ...*x509.Certificate) (string, error) {
-
-	log := rpccontext.Logger(ctx)
-	var tmpPayload *Payload
-	var jsonData []byte
-	var sub  string
-	var pub interface{}
-
-	// if len(cert) == 0 {
-	// 	sub = s.td.IDString()
-
-	// 	// Get the CA pub key using JWTKey
-	// 	cakey := s.ca.JWTPubKey()
-	// 	if cakey == nil {
-	// 		return "", api.MakeErr(log, codes.NotFound, "JWTPubKey not found", nil)
-	// 	}
-	// 	// pub = cakey.(*ecdsa.PublicKey)
-
-	// 	// Get CA pub key using X509CA
-	// 	// cakey := s.ca.X509PubKey()
-	// 	// if cakey == nil {
-	// 	// 	return "", api.MakeErr(log, codes.NotFound, "JWTPubKey not found", nil)
-	// 	// }
-	// 	// pub = cakey.(*ecdsa.PublicKey)
-		
-	// 	// generate encoded public key
-	// 	tmppk, err := x509.MarshalPKIXPublicKey(pub)
-	// 	if err != nil {
-	// 		return "", api.MakeErr(log, codes.NotFound, "Error Marshalling Public Key", nil)
-	// 	}
-	// 	capubkey :=  base64.RawURLEncoding.EncodeToString(tmppk)
-
-	// 	tmpPayload = &Payload{
-	// 		Ver:	0,
-	// 		Alg:	"ES256",
-	// 		Iat:	time.Now().Round(0).Unix(),
-	// 		Iss:	&IDClaim{
-	// 			CN:	s.td.IDString(),
-	// 			PK:	cakey,
-	// 		},
-	// 		Sub:	&IDClaim{
-	// 			CN:	s.td.IDString(),
-	// 			PK:	tmppk,
-	// 		},
-	// 	}
-
-	// 	jsonData, err = json.Marshal(tmpPayload)
-	// 	if err != nil {
-	// 		return "", api.MakeErr(log, codes.NotFound, "Error marshaling data to JSON", nil)
-	// 	}
-
-	// } else {	 
-		// Get the CA pub key using JWTKey
-		cakey := s.ca.JWTPubKey()
-		if cakey == nil {
-			return "", api.MakeErr(log, codes.NotFound, "JWTPubKey not found", nil)
-		}
-		capub := cakey.(*ecdsa.PublicKey)
-		// generate encoded public key
-		tmpPKCA, err := x509.MarshalPKIXPublicKey(capub)
-		if err != nil {
-			return "", api.MakeErr(log, codes.NotFound, "Error Marshalling Public Key", nil)
-		}
-		// capubkey :=  base64.RawURLEncoding.EncodeToString(tmpPKCA)
-
-		sub = cert[0].URIs[0].String()
-		pub = cert[0].PublicKey.(*ecdsa.PublicKey)
-		// generate encoded public key
-		tmpPKSub, err := x509.MarshalPKIXPublicKey(pub)
-		if err != nil {
-			return "", api.MakeErr(log, codes.NotFound, "Error Marshalling Public Key", nil)
-		}
-		// pubkey :=  base64.RawURLEncoding.EncodeToString(tmpPKSub)
-
-		tmpPayload = &Payload{
-			Ver:	1,
-			Alg:	"ES256",
-			Iat:	time.Now().Round(0).Unix(),
-			Iss:	&IDClaim{
-				CN:	s.td.IDString(),
-				PK:	tmpPKCA,
-			},
-			Sub:	&IDClaim{
-				CN:	sub,
-				PK:	tmpPKSub,
-			},
-		}
-
-		jsonData, err = json.Marshal(tmpPayload)
-		if err != nil {
-			return "", api.MakeErr(log, codes.NotFound, "Error marshaling data to JSON", nil)
-		}
-	// }
-	
-	fmt.Printf("LSVID payload : %s\n", jsonData)
-	fmt.Printf("Encoded LSVID payload : %s\n", base64.RawURLEncoding.EncodeToString([]byte(jsonData)))
-
-	return fmt.Sprintf("%s", jsonData), nil
-}
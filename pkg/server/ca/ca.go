@@ -15,45 +15,14 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/common/health"
 	"github.com/spiffe/spire/pkg/common/jwtsvid"
+	"github.com/spiffe/spire/pkg/common/lsvid"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	telemetry_server "github.com/spiffe/spire/pkg/common/telemetry/server"
 	"github.com/spiffe/spire/pkg/common/x509util"
-	// "github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/zeebo/errs"
-
-	hash256 "crypto/sha256"
-	// "strings"
-	"encoding/base64"
-	// "bytes"
-	"encoding/json"
 )
 
-type IDClaim struct {
-	CN	string		`json:"cn,omitempty"`
-	PK	[]byte		`json:"pk,omitempty"`
-	ID	*Token		`json:"id,omitempty"`
-}
-
-type Payload struct {
-	Ver int8		`json:"ver,omitempty"`
-	Alg string		`json:"alg,omitempty"`
-	Iat	int64		`json:"iat,omitempty"`
-	Iss	*IDClaim	`json:"iss,omitempty"`
-	Sub	*IDClaim	`json:"sub,omitempty"`
-	Aud	*IDClaim	`json:"aud,omitempty"`
-}
-
-type Token struct {	
-	Nested		*Token		`json:"nested,omitempty"`
-	Payload		*Payload	`json:"payload"`
-	Signature	[]byte		`json:"signature"`
-}
-
-type LSVID struct {
-	Token		*Token		`json:"token"`
-	Bundle		*Token		`json:"bundle"`
-}
-
 const (
 	// DefaultX509SVIDTTL is the TTL given to X509 SVIDs if not overridden by
 	// the server config.
@@ -62,15 +31,25 @@ const (
 	// DefaultJWTSVIDTTL is the TTL given to JWT SVIDs if a different TTL is
 	// not provided in the signing request.
 	DefaultJWTSVIDTTL = time.Minute * 5
+
+	// DefaultLSVIDTTL is the TTL given to LSVIDs if not overridden by the
+	// server config or the signing request.
+	DefaultLSVIDTTL = time.Minute * 5
+
+	// DefaultLSVIDBundleTTL is the TTL given to LSVID trust bundle documents
+	// if not overridden.
+	DefaultLSVIDBundleTTL = time.Hour
 )
 
 // ServerCA is an interface for Server CAs
 type ServerCA interface {
 	SignX509SVID(ctx context.Context, params X509SVIDParams) ([]*x509.Certificate, error)
 	SignX509CASVID(ctx context.Context, params X509CASVIDParams) ([]*x509.Certificate, error)
-	SignLSVID(ctx context.Context, payloads []string) (string, error)
-	JWTPubKey() (crypto.PublicKey)
-	X509PubKey() (crypto.PublicKey)
+	SignJWTSVID(ctx context.Context, params JWTSVIDParams) (string, error)
+	SignLSVID(ctx context.Context, params LSVIDParams) (*lsvid.Token, error)
+	SignLSVIDBundle(ctx context.Context, params LSVIDBundleParams) (*lsvid.Token, error)
+	JWTPubKey() crypto.PublicKey
+	X509PubKey() crypto.PublicKey
 }
 
 // X509SVIDParams are parameters relevant to X509 SVID creation
@@ -119,6 +98,41 @@ type JWTSVIDParams struct {
 	Audience []string
 }
 
+// LSVIDParams are parameters relevant to LSVID creation
+type LSVIDParams struct {
+	// Payload of the root LSVID layer. Its issuer, issued at and expiration
+	// claims are set by the CA, the issuer being the trust domain with the
+	// key ID and public key of the current JWT key.
+	Payload *lsvid.Payload
+
+	// TTL is the desired time-to-live of the LSVID. Regardless of the TTL, the
+	// lifetime of the LSVID will be capped to that of the signing key.
+	TTL time.Duration
+
+	// X509SVID is the X509-SVID holding the subject key, if any. The subject
+	// is bound to it and the lifetime of the LSVID capped to that of the
+	// certificate.
+	X509SVID *x509.Certificate
+}
+
+// LSVIDBundleParams are parameters relevant to LSVID trust bundle document
+// creation
+type LSVIDBundleParams struct {
+	// TrustDomain is the trust domain the authorities belong to, which is the
+	// subject of the document. Defaults to the trust domain of the CA. Other
+	// trust domains are federated trust domains whose authorities the CA
+	// vouches for.
+	TrustDomain spiffeid.TrustDomain
+
+	// Authorities are the LSVID authorities of the trust domain, i.e. its JWT
+	// signing keys, listed in the document.
+	Authorities []*lsvid.Authority
+
+	// TTL is the desired time-to-live of the document. Regardless of the TTL,
+	// the lifetime of the document will be capped to that of the X509 CA.
+	TTL time.Duration
+}
+
 type X509CA struct {
 	// Signer is used to sign child certificates.
 	Signer crypto.Signer
@@ -149,6 +163,7 @@ type Config struct {
 	TrustDomain   spiffeid.TrustDomain
 	X509SVIDTTL   time.Duration
 	JWTSVIDTTL    time.Duration
+	LSVIDTTL      time.Duration
 	JWTIssuer     string
 	Clock         clock.Clock
 	CASubject     pkix.Name
@@ -172,6 +187,9 @@ func NewCA(config Config) *CA {
 	if config.JWTSVIDTTL <= 0 {
 		config.JWTSVIDTTL = DefaultJWTSVIDTTL
 	}
+	if config.LSVIDTTL <= 0 {
+		config.LSVIDTTL = DefaultLSVIDTTL
+	}
 	if config.Clock == nil {
 		config.Clock = clock.New()
 	}
@@ -320,54 +338,146 @@ func (ca *CA) SignX509CASVID(ctx context.Context, params X509CASVIDParams) ([]*x
 	return makeSVIDCertChain(x509CA, cert), nil
 }
 
-func (ca *CA) SignLSVID(ctx context.Context, payloads []string) (string, error) {
+func (ca *CA) SignJWTSVID(ctx context.Context, params JWTSVIDParams) (string, error) {
+	jwtKey := ca.JWTKey()
+	if jwtKey == nil {
+		return "", errs.New("JWT key is not available for signing")
+	}
+
+	if err := api.VerifyTrustDomainWorkloadID(ca.c.TrustDomain, params.SpiffeID); err != nil {
+		return "", err
+	}
 
-	var encLSVID string
-	// signKey := ca.X509CA() //preferably
-	signKey := ca.JWTKey()
-	if signKey == nil {
-		return "", errs.New("Key is not available for signing")
+	if params.TTL <= 0 {
+		params.TTL = ca.c.JWTSVIDTTL
 	}
+	_, expiresAt := ca.capLifetime(params.TTL, jwtKey.NotAfter)
 
-	if len(payloads) == 0 {
-		return "", errs.New("No payloads to sign")
+	token, err := ca.jwtSigner.SignToken(params.SpiffeID.String(), params.Audience, expiresAt, jwtKey.Signer, jwtKey.Kid)
+	if err != nil {
+		return "", errs.New("unable to sign JWT SVID: %v", err)
 	}
 
-	tmp, err := base64.RawURLEncoding.DecodeString(payloads[0])
-	if err 	!= nil {
-		return "", errs.New("Error decoding: %s\n", err)
+	ca.c.Log.WithFields(logrus.Fields{
+		telemetry.Audience:   params.Audience,
+		telemetry.Expiration: expiresAt.Format(time.RFC3339),
+		telemetry.SPIFFEID:   params.SpiffeID.String(),
+	}).Debug("Signed JWT SVID")
+
+	telemetry_server.IncrServerCASignJWTSVIDCounter(ca.c.Metrics)
+
+	return token, nil
+}
+
+// SignLSVID signs the given payload as a root LSVID layer using the current
+// JWT key. The key ID of the JWT key is set in the issuer claim so that
+// validators can resolve the key from the trust bundle.
+func (ca *CA) SignLSVID(ctx context.Context, params LSVIDParams) (*lsvid.Token, error) {
+	jwtKey := ca.JWTKey()
+	if jwtKey == nil {
+		return nil, errs.New("JWT key is not available for signing")
+	}
+	if params.Payload == nil {
+		return nil, errs.New("no LSVID payload to sign")
 	}
-	fmt.Printf("Payload to be hashed: %s\n", tmp)
-	hash 	:= hash256.Sum256(tmp)
 
-	fmt.Printf("Corresponding Public key: %s\n", ca.JWTPubKey())
-	s, err 	:= signKey.Signer.Sign(rand.Reader, hash[:], crypto.SHA256)
-	if err 	!= nil {
-		return "", errs.New("Error signing: %s\n", err)
+	if params.TTL <= 0 {
+		params.TTL = ca.c.LSVIDTTL
 	}
+	_, expiresAt := ca.capLifetime(params.TTL, jwtKey.NotAfter)
 
-	// Concatenate payload and signature
-	var decPayload Payload
-	err = json.Unmarshal(tmp, &decPayload)
+	issuerKey, err := x509.MarshalPKIXPublicKey(jwtKey.Signer.Public())
 	if err != nil {
-		return "", errs.New("error unmarshaling LSVID payload: %v", err)
+		return nil, errs.New("unable to marshal JWT public key: %v", err)
+	}
+
+	payload := *params.Payload
+	payload.Iss = &lsvid.IDClaim{
+		CN:  ca.c.TrustDomain.IDString(),
+		PK:  issuerKey,
+		Kid: jwtKey.Kid,
+	}
+	if params.X509SVID != nil {
+		if payload.Sub == nil {
+			return nil, errs.New("no LSVID subject to bind to the X509-SVID")
+		}
+		if expiresAt.After(params.X509SVID.NotAfter) {
+			expiresAt = params.X509SVID.NotAfter
+		}
+		sub := *payload.Sub
+		sub.X5T = lsvid.X509SVIDThumbprint(params.X509SVID)
+		payload.Sub = &sub
+	}
+	payload.Iat = ca.c.Clock.Now().Unix()
+	payload.Exp = expiresAt.Unix()
+
+	token, err := lsvid.Sign(&payload, jwtKey.Signer)
+	if err != nil {
+		return nil, errs.New("unable to sign LSVID: %v", err)
+	}
+
+	if !health.IsCheck(ctx) && payload.Sub != nil {
+		ca.c.Log.WithFields(logrus.Fields{
+			telemetry.SPIFFEID:   payload.Sub.CN,
+			telemetry.Expiration: expiresAt.Format(time.RFC3339),
+		}).Debug("Signed LSVID")
+	}
+
+	return token, nil
+}
+
+// SignLSVIDBundle signs a trust bundle document listing the given LSVID
+// authorities using the current X509 CA key. The issuer claim carries the
+// public key of the X509 CA and the thumbprint of its certificate, so that
+// verifiers can check the document against the X509 authority they pin.
+func (ca *CA) SignLSVIDBundle(ctx context.Context, params LSVIDBundleParams) (*lsvid.Token, error) {
+	x509CA := ca.X509CA()
+	if x509CA == nil {
+		return nil, errs.New("X509 CA is not available for signing")
+	}
+	if len(params.Authorities) == 0 {
+		return nil, errs.New("no LSVID authorities to sign")
 	}
 
-	// Create the resulting LSVID
-	outputLSVID := Token{
-		Payload:	&decPayload,
-		Signature:	s,
+	if params.TrustDomain.IsZero() {
+		params.TrustDomain = ca.c.TrustDomain
+	}
+	if params.TTL <= 0 {
+		params.TTL = DefaultLSVIDBundleTTL
 	}
-	fmt.Printf("Decoded LSVID: %v\n\n", outputLSVID)
+	_, expiresAt := ca.capLifetime(params.TTL, x509CA.Certificate.NotAfter)
 
-	encLSVID, err = ca.EncodeLSVID(outputLSVID)
+	issuerKey, err := x509.MarshalPKIXPublicKey(x509CA.Signer.Public())
 	if err != nil {
-		return "", errs.New("error encoding LSVID: %v", err)
+		return nil, errs.New("unable to marshal X509 CA public key: %v", err)
 	}
 
-	fmt.Printf("Encoded LSVID: %v\n\n", encLSVID)
+	token, err := lsvid.Sign(&lsvid.Payload{
+		Ver: lsvid.Version,
+		Iat: ca.c.Clock.Now().Unix(),
+		Exp: expiresAt.Unix(),
+		Iss: &lsvid.IDClaim{
+			CN:  ca.c.TrustDomain.IDString(),
+			PK:  issuerKey,
+			X5T: lsvid.X509SVIDThumbprint(x509CA.Certificate),
+		},
+		Sub: &lsvid.IDClaim{
+			CN: params.TrustDomain.IDString(),
+		},
+		Keys: params.Authorities,
+	}, x509CA.Signer)
+	if err != nil {
+		return nil, errs.New("unable to sign LSVID trust bundle document: %v", err)
+	}
 
-	return encLSVID, nil
+	if !health.IsCheck(ctx) {
+		ca.c.Log.WithFields(logrus.Fields{
+			telemetry.TrustDomainID: params.TrustDomain.IDString(),
+			telemetry.Expiration:    expiresAt.Format(time.RFC3339),
+		}).Debug("Signed LSVID trust bundle document")
+	}
+
+	return token, nil
 }
 
 func (ca *CA) capLifetime(ttl time.Duration, expirationCap time.Time) (notBefore, notAfter time.Time) {
@@ -394,53 +504,17 @@ func createCertificate(template, parent *x509.Certificate, pub, priv interface{}
 }
 
 func (ca *CA) JWTPubKey() crypto.PublicKey {
-	ca.mu.RLock()
-	defer ca.mu.RUnlock()
-
-	jwtKey := ca.jwtKey
-
+	jwtKey := ca.JWTKey()
+	if jwtKey == nil {
+		return nil
+	}
 	return jwtKey.Signer.Public()
-
 }
 
 func (ca *CA) X509PubKey() crypto.PublicKey {
-	ca.mu.RLock()
-	defer ca.mu.RUnlock()
-
-	cakey := ca.X509CA()
-
-	return cakey.Signer.Public()
-
-}
-
-
-func (ca *CA) EncodeLSVID(lsvid Token) (string, error) {
-	// Marshal the LSVID struct into JSON
-	lsvidJSON, err := json.Marshal(lsvid)
-	if err != nil {
-		return "", errs.New("error marshaling LSVID to JSON: %v", err)
+	x509CA := ca.X509CA()
+	if x509CA == nil {
+		return nil
 	}
-
-	// Encode the JSON byte slice to Base64.RawURLEncoded string
-	encLSVID := base64.RawURLEncoding.EncodeToString(lsvidJSON)
-
-	return encLSVID, nil
+	return x509CA.Signer.Public()
 }
-
-func (ca *CA) DecodeLSVID(encLSVID string) (Token, error) {
-
-	// Decode the base64.RawURLEncoded LSVID
-	decoded, err := base64.RawURLEncoding.DecodeString(encLSVID)
-	if err != nil {
-		return Token{}, errs.New("error decoding LSVID: %v", err)
-	}
-
-	// Unmarshal the byte slice into your struct
-	var decLSVID Token
-	err = json.Unmarshal(decoded, &decLSVID)
-	if err != nil {
-		return Token{}, errs.New("error unmarshaling LSVID: %v", err)
-	}
-
-	return decLSVID, nil
-}
\ No newline at end of file
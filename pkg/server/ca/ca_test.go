@@ -2,6 +2,7 @@ package ca
 
 import (
 	"context"
+	"crypto"
 	"crypto/rand"
 	"crypto/x509"
 	"crypto/x509/pkix"
@@ -13,11 +14,13 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/common/health"
 	"github.com/spiffe/spire/pkg/common/jwtsvid"
+	"github.com/spiffe/spire/pkg/common/lsvid"
 	"github.com/spiffe/spire/pkg/common/pemutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakehealthchecker"
+	"github.com/spiffe/spire/test/testkey"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
@@ -306,6 +309,138 @@ func (s *CATestSuite) TestSignJWTSVIDValidatesJSR() {
 	s.Require().EqualError(err, "unable to sign JWT SVID: audience is required")
 }
 
+func (s *CATestSuite) TestSignLSVIDNoJWTKeySet() {
+	s.ca.SetJWTKey(nil)
+	_, err := s.ca.SignLSVID(ctx, s.createLSVIDParams(0))
+	s.Require().EqualError(err, "JWT key is not available for signing")
+}
+
+func (s *CATestSuite) TestSignLSVIDUsesDefaultTTLIfTTLUnspecified() {
+	token, err := s.ca.SignLSVID(ctx, s.createLSVIDParams(0))
+	s.Require().NoError(err)
+	s.Require().Equal(s.clock.Now().Unix(), token.Payload.Iat)
+	s.Require().Equal(s.clock.Now().Add(DefaultLSVIDTTL).Unix(), token.Payload.Exp)
+	s.Require().NoError(lsvid.Validate(token, lsvid.WithClock(s.clock)))
+}
+
+func (s *CATestSuite) TestSignLSVIDSetsIssuer() {
+	params := s.createLSVIDParams(0)
+	params.Payload.Iss = &lsvid.IDClaim{CN: "spiffe://example.org/ignored"}
+
+	token, err := s.ca.SignLSVID(ctx, params)
+	s.Require().NoError(err)
+	rootKey, err := x509.MarshalPKIXPublicKey(testSigner.Public())
+	s.Require().NoError(err)
+	s.Require().Equal(&lsvid.IDClaim{
+		CN:  "spiffe://example.org",
+		PK:  rootKey,
+		Kid: "KID",
+	}, token.Payload.Iss)
+	s.Require().NoError(lsvid.Validate(token,
+		lsvid.WithClock(s.clock),
+		lsvid.WithAuthorities(func(trustDomainID string) (map[string]crypto.PublicKey, bool) {
+			return map[string]crypto.PublicKey{"KID": testSigner.Public()}, trustDomainID == "spiffe://example.org"
+		}),
+	))
+}
+
+func (s *CATestSuite) TestSignLSVIDUsesTTLIfSpecified() {
+	token, err := s.ca.SignLSVID(ctx, s.createLSVIDParams(time.Minute+time.Second))
+	s.Require().NoError(err)
+	s.Require().Equal(s.clock.Now().Unix(), token.Payload.Iat)
+	s.Require().Equal(s.clock.Now().Add(time.Minute+time.Second).Unix(), token.Payload.Exp)
+}
+
+func (s *CATestSuite) TestSignLSVIDCapsTTLToKeyExpiry() {
+	token, err := s.ca.SignLSVID(ctx, s.createLSVIDParams(time.Hour))
+	s.Require().NoError(err)
+	s.Require().Equal(s.clock.Now().Unix(), token.Payload.Iat)
+	s.Require().Equal(s.clock.Now().Add(10*time.Minute).Unix(), token.Payload.Exp)
+}
+
+func (s *CATestSuite) TestSignLSVIDBindsX509SVID() {
+	svid, err := s.ca.SignX509SVID(ctx, X509SVIDParams{
+		SpiffeID:  trustDomainExample.NewID("workload"),
+		PublicKey: testSigner.Public(),
+		TTL:       time.Minute,
+	})
+	s.Require().NoError(err)
+
+	params := s.createLSVIDParams(0)
+	params.X509SVID = svid[0]
+	token, err := s.ca.SignLSVID(ctx, params)
+	s.Require().NoError(err)
+	s.Require().Equal(lsvid.X509SVIDThumbprint(svid[0]), token.Payload.Sub.X5T)
+	s.Require().Equal(svid[0].NotAfter.Unix(), token.Payload.Exp)
+	s.Require().Nil(params.Payload.Sub.X5T)
+}
+
+func (s *CATestSuite) TestSignLSVIDUsesJWTKeyAlgorithm() {
+	rsaKey := testkey.NewRSA2048(s.T())
+	s.ca.SetJWTKey(&JWTKey{
+		Signer:   rsaKey,
+		Kid:      "KID",
+		NotAfter: s.clock.Now().Add(10 * time.Minute),
+	})
+	token, err := s.ca.SignLSVID(ctx, s.createLSVIDParams(0))
+	s.Require().NoError(err)
+	s.Require().Equal(lsvid.AlgRS256, token.Payload.Alg)
+	s.Require().NoError(lsvid.Validate(token, lsvid.WithClock(s.clock)))
+}
+
+func (s *CATestSuite) TestSignLSVIDBundleNoCASet() {
+	s.ca.SetX509CA(nil)
+	_, err := s.ca.SignLSVIDBundle(ctx, s.createLSVIDBundleParams(0))
+	s.Require().EqualError(err, "X509 CA is not available for signing")
+}
+
+func (s *CATestSuite) TestSignLSVIDBundleNoAuthorities() {
+	_, err := s.ca.SignLSVIDBundle(ctx, LSVIDBundleParams{})
+	s.Require().EqualError(err, "no LSVID authorities to sign")
+}
+
+func (s *CATestSuite) TestSignLSVIDBundle() {
+	params := s.createLSVIDBundleParams(time.Minute)
+	bundle, err := s.ca.SignLSVIDBundle(ctx, params)
+	s.Require().NoError(err)
+
+	caKey, err := x509.MarshalPKIXPublicKey(testSigner.Public())
+	s.Require().NoError(err)
+	s.Require().Equal(&lsvid.IDClaim{
+		CN:  "spiffe://example.org",
+		PK:  caKey,
+		X5T: lsvid.X509SVIDThumbprint(s.caCert),
+	}, bundle.Payload.Iss)
+	s.Require().Equal(&lsvid.IDClaim{CN: "spiffe://example.org"}, bundle.Payload.Sub)
+	s.Require().Equal(params.Authorities, bundle.Payload.Keys)
+	s.Require().Equal(s.clock.Now().Unix(), bundle.Payload.Iat)
+	s.Require().Equal(s.clock.Now().Add(time.Minute).Unix(), bundle.Payload.Exp)
+
+	trustDomainID, keys, err := lsvid.ValidateBundle(bundle, testSigner.Public(), lsvid.WithClock(s.clock))
+	s.Require().NoError(err)
+	s.Require().Equal("spiffe://example.org", trustDomainID)
+	s.Require().Equal(map[string]crypto.PublicKey{"KID": testSigner.Public()}, keys)
+}
+
+func (s *CATestSuite) TestSignLSVIDBundleForFederatedTrustDomain() {
+	params := s.createLSVIDBundleParams(time.Minute)
+	params.TrustDomain = spiffeid.RequireTrustDomainFromString("domain.test")
+	bundle, err := s.ca.SignLSVIDBundle(ctx, params)
+	s.Require().NoError(err)
+	s.Require().Equal("spiffe://example.org", bundle.Payload.Iss.CN)
+	s.Require().Equal(&lsvid.IDClaim{CN: "spiffe://domain.test"}, bundle.Payload.Sub)
+
+	trustDomainID, _, err := lsvid.ValidateBundle(bundle, testSigner.Public(), lsvid.WithClock(s.clock))
+	s.Require().NoError(err)
+	s.Require().Equal("spiffe://domain.test", trustDomainID)
+}
+
+func (s *CATestSuite) TestSignLSVIDBundleCapsTTLToCAExpiry() {
+	bundle, err := s.ca.SignLSVIDBundle(ctx, s.createLSVIDBundleParams(0))
+	s.Require().NoError(err)
+	s.Require().Equal(s.caCert.NotAfter.Unix(), bundle.Payload.Exp)
+}
+
 func (s *CATestSuite) TestSignX509CASVIDNoCASet() {
 	s.ca.SetX509CA(nil)
 	_, err := s.ca.SignX509CASVID(ctx, s.createX509CASVIDParams(trustDomainExample))
@@ -397,6 +532,17 @@ func (s *CATestSuite) setJWTKey() {
 	})
 }
 
+func (s *CATestSuite) createLSVIDBundleParams(ttl time.Duration) LSVIDBundleParams {
+	pk, err := x509.MarshalPKIXPublicKey(testSigner.Public())
+	s.Require().NoError(err)
+	return LSVIDBundleParams{
+		Authorities: []*lsvid.Authority{
+			{Kid: "KID", PK: pk, Exp: s.clock.Now().Add(10 * time.Minute).Unix()},
+		},
+		TTL: ttl,
+	}
+}
+
 func (s *CATestSuite) createX509SVIDParams() X509SVIDParams {
 	return s.createX509SVIDParamsInDomain(trustDomainExample)
 }
@@ -423,6 +569,19 @@ func (s *CATestSuite) createJWTSVIDParams(trustDomain spiffeid.TrustDomain, ttl
 	}
 }
 
+func (s *CATestSuite) createLSVIDParams(ttl time.Duration) LSVIDParams {
+	subjectKey, err := x509.MarshalPKIXPublicKey(testSigner.Public())
+	s.Require().NoError(err)
+	return LSVIDParams{
+		Payload: &lsvid.Payload{
+			Ver: lsvid.Version,
+			Sub: &lsvid.IDClaim{CN: trustDomainExample.NewID("workload").String(), PK: subjectKey},
+			Aud: &lsvid.IDClaim{CN: "AUDIENCE"},
+		},
+		TTL: ttl,
+	}
+}
+
 func (s *CATestSuite) createCACertificate(cn string, parent *x509.Certificate) *x509.Certificate {
 	keyID, err := x509util.GetSubjectKeyID(testSigner.Public())
 	s.Require().NoError(err)
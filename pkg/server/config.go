@@ -55,6 +55,9 @@ type Config struct {
 	// SVIDTTL is default time-to-live for SVIDs
 	SVIDTTL time.Duration
 
+	// LSVIDTTL is default time-to-live for LSVIDs
+	LSVIDTTL time.Duration
+
 	// CATTL is the time-to-live for the server CA. This only applies to
 	// self-signed CA certificates, otherwise it is up to the upstream CA.
 	CATTL time.Duration
@@ -58,6 +58,11 @@ type DataStore interface {
 	ListFederationRelationships(context.Context, *ListFederationRelationshipsRequest) (*ListFederationRelationshipsResponse, error)
 	DeleteFederationRelationship(context.Context, spiffeid.TrustDomain) error
 	UpdateFederationRelationship(context.Context, *FederationRelationship, *types.FederationRelationshipMask) (*FederationRelationship, error)
+
+	// LSVID revocations
+	CreateLSVIDRevocation(context.Context, *LSVIDRevocation) (*LSVIDRevocation, error)
+	DeleteLSVIDRevocation(ctx context.Context, kind, value string) error
+	ListLSVIDRevocations(context.Context, *ListLSVIDRevocationsRequest) (*ListLSVIDRevocationsResponse, error)
 }
 
 // DataConsistency indicates the required data consistency for a read operation.
@@ -123,6 +128,27 @@ type JoinToken struct {
 	Expiry time.Time
 }
 
+// LSVIDRevocation revokes the LSVID layers matching its kind and value. See
+// the lsvid.RevocationKind constants for the supported kinds.
+type LSVIDRevocation struct {
+	Kind      string
+	Value     string
+	CreatedAt time.Time
+}
+
+// MaxLSVIDRevocationValueLength is the maximum length of the value of an
+// LSVID revocation, that of the longest SPIFFE ID.
+const MaxLSVIDRevocationValueLength = 2048
+
+type ListLSVIDRevocationsRequest struct {
+	Pagination *Pagination
+}
+
+type ListLSVIDRevocationsResponse struct {
+	Revocations []*LSVIDRevocation
+	Pagination  *Pagination
+}
+
 type Pagination struct {
 	Token    string
 	PageSize int32
@@ -19,7 +19,7 @@ import (
 
 const (
 	// the latest schema version of the database in the code
-	latestSchemaVersion = 17
+	latestSchemaVersion = 18
 )
 
 var (
@@ -176,6 +176,7 @@ func initDB(db *gorm.DB, dbType string, log logrus.FieldLogger) (err error) {
 		&Migration{},
 		&DNSName{},
 		&FederatedTrustDomain{},
+		&LSVIDRevocation{},
 	}
 
 	if err := tableOptionsForDialect(tx, dbType).AutoMigrate(tables...).Error; err != nil {
@@ -237,6 +238,7 @@ func migrateVersion(tx *gorm.DB, currVersion int, log logrus.FieldLogger) (versi
 		migrateToV15,
 		migrateToV16,
 		migrateToV17,
+		migrateToV18,
 	}
 
 	if currVersion >= len(migrations) {
@@ -489,6 +491,13 @@ func migrateToV17(tx *gorm.DB) error {
 	return nil
 }
 
+func migrateToV18(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&LSVIDRevocation{}).Error; err != nil {
+		return sqlError.Wrap(err)
+	}
+	return nil
+}
+
 func addFederatedRegistrationEntriesRegisteredEntryIDIndex(tx *gorm.DB) error {
 	// GORM creates the federated_registration_entries implicitly with a primary
 	// key tuple (bundle_id, registered_entry_id). Unfortunately, MySQL5 does
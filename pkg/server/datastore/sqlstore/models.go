@@ -102,6 +102,23 @@ type JoinToken struct {
 	Expiry int64
 }
 
+// LSVIDRevocation holds an LSVID revocation
+type LSVIDRevocation struct {
+	Model
+
+	Kind  string `gorm:"size:32;unique_index:idx_lsvid_revocation"`
+	Value string `gorm:"size:2048"`
+
+	// ValueHash is the hex encoded SHA-256 digest of the value, which is too
+	// long to be part of an index in every database.
+	ValueHash string `gorm:"size:64;unique_index:idx_lsvid_revocation"`
+}
+
+// TableName gets table name of LSVIDRevocation
+func (LSVIDRevocation) TableName() string {
+	return "lsvid_revocations"
+}
+
 type Selector struct {
 	Model
 
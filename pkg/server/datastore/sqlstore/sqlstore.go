@@ -3,7 +3,9 @@ package sqlstore
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/url"
@@ -494,6 +496,48 @@ func (ds *Plugin) ListFederationRelationships(ctx context.Context, req *datastor
 	return resp, nil
 }
 
+// CreateLSVIDRevocation stores the given LSVID revocation.
+func (ds *Plugin) CreateLSVIDRevocation(ctx context.Context, revocation *datastore.LSVIDRevocation) (newRevocation *datastore.LSVIDRevocation, err error) {
+	switch {
+	case revocation == nil || revocation.Kind == "" || revocation.Value == "":
+		return nil, status.Error(codes.InvalidArgument, "revocation kind and value are required")
+	case len(revocation.Value) > datastore.MaxLSVIDRevocationValueLength:
+		return nil, status.Errorf(codes.InvalidArgument, "revocation value is longer than %d bytes", datastore.MaxLSVIDRevocationValueLength)
+	}
+
+	if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		newRevocation, err = createLSVIDRevocation(tx, revocation)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return newRevocation, nil
+}
+
+// DeleteLSVIDRevocation deletes the LSVID revocation with the given kind and
+// value.
+func (ds *Plugin) DeleteLSVIDRevocation(ctx context.Context, kind, value string) error {
+	if kind == "" || value == "" {
+		return status.Error(codes.InvalidArgument, "revocation kind and value are required")
+	}
+
+	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		err = deleteLSVIDRevocation(tx, kind, value)
+		return err
+	})
+}
+
+// ListLSVIDRevocations lists the LSVID revocations, optionally paginated.
+func (ds *Plugin) ListLSVIDRevocations(ctx context.Context, req *datastore.ListLSVIDRevocationsRequest) (resp *datastore.ListLSVIDRevocationsResponse, err error) {
+	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+		resp, err = listLSVIDRevocations(tx, req)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 // UpdateFederationRelationship updates the given federation relationship.
 // Attributes are only updated if the correspondent mask value is set to true.
 func (ds *Plugin) UpdateFederationRelationship(ctx context.Context, fr *datastore.FederationRelationship, mask *types.FederationRelationshipMask) (newFr *datastore.FederationRelationship, err error) {
@@ -3275,6 +3319,74 @@ func pruneJoinTokens(tx *gorm.DB, expiresBefore time.Time) error {
 	return nil
 }
 
+func createLSVIDRevocation(tx *gorm.DB, revocation *datastore.LSVIDRevocation) (*datastore.LSVIDRevocation, error) {
+	model := LSVIDRevocation{
+		Kind:      revocation.Kind,
+		Value:     revocation.Value,
+		ValueHash: lsvidRevocationValueHash(revocation.Value),
+	}
+
+	if err := tx.Create(&model).Error; err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+
+	return modelToLSVIDRevocation(model), nil
+}
+
+func deleteLSVIDRevocation(tx *gorm.DB, kind, value string) error {
+	var model LSVIDRevocation
+	if err := tx.Find(&model, "kind = ? AND value_hash = ?", kind, lsvidRevocationValueHash(value)).Error; err != nil {
+		return sqlError.Wrap(err)
+	}
+
+	if err := tx.Delete(&model).Error; err != nil {
+		return sqlError.Wrap(err)
+	}
+
+	return nil
+}
+
+func listLSVIDRevocations(tx *gorm.DB, req *datastore.ListLSVIDRevocationsRequest) (*datastore.ListLSVIDRevocationsResponse, error) {
+	p := req.Pagination
+	if p != nil {
+		var err error
+		if tx, err = applyPagination(p, tx); err != nil {
+			return nil, err
+		}
+	} else {
+		tx = tx.Order("id asc")
+	}
+
+	var models []LSVIDRevocation
+	if err := tx.Find(&models).Error; err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+
+	if p != nil {
+		p.Token = ""
+		// Set token only if the page is not empty; an empty page ends the listing
+		if len(models) > 0 {
+			p.Token = fmt.Sprint(models[len(models)-1].ID)
+		}
+	}
+
+	resp := &datastore.ListLSVIDRevocationsResponse{
+		Pagination:  p,
+		Revocations: make([]*datastore.LSVIDRevocation, 0, len(models)),
+	}
+	for _, model := range models {
+		resp.Revocations = append(resp.Revocations, modelToLSVIDRevocation(model))
+	}
+	return resp, nil
+}
+
+// lsvidRevocationValueHash returns the hex encoded SHA-256 digest of the
+// value of an LSVID revocation.
+func lsvidRevocationValueHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
 func createFederationRelationship(tx *gorm.DB, fr *datastore.FederationRelationship) (*datastore.FederationRelationship, error) {
 	model := FederatedTrustDomain{
 		TrustDomain:           fr.TrustDomain.String(),
@@ -3653,6 +3765,14 @@ func modelToJoinToken(model JoinToken) *datastore.JoinToken {
 	}
 }
 
+func modelToLSVIDRevocation(model LSVIDRevocation) *datastore.LSVIDRevocation {
+	return &datastore.LSVIDRevocation{
+		Kind:      model.Kind,
+		Value:     model.Value,
+		CreatedAt: model.CreatedAt,
+	}
+}
+
 func makeFederatesWith(tx *gorm.DB, ids []string) ([]*Bundle, error) {
 	var bundles []*Bundle
 	if err := tx.Where("trust_domain in (?)", ids).Find(&bundles).Error; err != nil {
@@ -3216,6 +3216,108 @@ func (s *PluginSuite) TestPruneJoinTokens() {
 	s.Nil(resp)
 }
 
+func (s *PluginSuite) TestCreateAndListLSVIDRevocations() {
+	resp, err := s.ds.ListLSVIDRevocations(ctx, &datastore.ListLSVIDRevocationsRequest{})
+	s.Require().NoError(err)
+	s.Empty(resp.Revocations)
+
+	subject, err := s.ds.CreateLSVIDRevocation(ctx, &datastore.LSVIDRevocation{
+		Kind:  "SPIFFE_ID",
+		Value: "spiffe://example.org/workload",
+	})
+	s.Require().NoError(err)
+	s.Equal("SPIFFE_ID", subject.Kind)
+	s.Equal("spiffe://example.org/workload", subject.Value)
+	s.False(subject.CreatedAt.IsZero())
+
+	token, err := s.ds.CreateLSVIDRevocation(ctx, &datastore.LSVIDRevocation{
+		Kind:  "TOKEN_ID",
+		Value: "spiffe://example.org/workload",
+	})
+	s.Require().NoError(err)
+
+	// The same revocation can't be created twice
+	_, err = s.ds.CreateLSVIDRevocation(ctx, &datastore.LSVIDRevocation{
+		Kind:  "SPIFFE_ID",
+		Value: "spiffe://example.org/workload",
+	})
+	s.Require().Error(err)
+	s.Equal(codes.AlreadyExists, status.Code(err))
+
+	_, err = s.ds.CreateLSVIDRevocation(ctx, &datastore.LSVIDRevocation{Kind: "SPIFFE_ID"})
+	s.Require().EqualError(err, "rpc error: code = InvalidArgument desc = revocation kind and value are required")
+
+	_, err = s.ds.CreateLSVIDRevocation(ctx, &datastore.LSVIDRevocation{
+		Kind:  "TOKEN_ID",
+		Value: strings.Repeat("a", datastore.MaxLSVIDRevocationValueLength+1),
+	})
+	s.Require().EqualError(err, "rpc error: code = InvalidArgument desc = revocation value is longer than 2048 bytes")
+
+	// Values up to the longest SPIFFE ID are stored in full
+	long, err := s.ds.CreateLSVIDRevocation(ctx, &datastore.LSVIDRevocation{
+		Kind:  "SPIFFE_ID",
+		Value: "spiffe://example.org/" + strings.Repeat("a", datastore.MaxLSVIDRevocationValueLength-len("spiffe://example.org/")),
+	})
+	s.Require().NoError(err)
+
+	resp, err = s.ds.ListLSVIDRevocations(ctx, &datastore.ListLSVIDRevocationsRequest{})
+	s.Require().NoError(err)
+	s.Nil(resp.Pagination)
+	revocations := resp.Revocations
+	s.Require().Len(revocations, 3)
+	s.Equal(subject.Value, revocations[0].Value)
+	s.Equal(subject.Kind, revocations[0].Kind)
+	s.Equal(token.Kind, revocations[1].Kind)
+	s.True(subject.CreatedAt.Equal(revocations[0].CreatedAt))
+	s.Equal(long.Value, revocations[2].Value)
+
+	// Revocations are listed in pages
+	pagination := &datastore.Pagination{PageSize: 2}
+	var paged []*datastore.LSVIDRevocation
+	for pages := 0; ; pages++ {
+		s.Require().Less(pages, 3)
+		resp, err = s.ds.ListLSVIDRevocations(ctx, &datastore.ListLSVIDRevocationsRequest{Pagination: pagination})
+		s.Require().NoError(err)
+		paged = append(paged, resp.Revocations...)
+		if resp.Pagination.Token == "" {
+			break
+		}
+		pagination = resp.Pagination
+	}
+	s.Equal(revocations, paged)
+
+	_, err = s.ds.ListLSVIDRevocations(ctx, &datastore.ListLSVIDRevocationsRequest{
+		Pagination: &datastore.Pagination{PageSize: 2, Token: "bad"},
+	})
+	s.Require().EqualError(err, "rpc error: code = InvalidArgument desc = could not parse token 'bad'")
+}
+
+func (s *PluginSuite) TestDeleteLSVIDRevocation() {
+	_, err := s.ds.CreateLSVIDRevocation(ctx, &datastore.LSVIDRevocation{
+		Kind:  "KEY_HASH",
+		Value: "hash1",
+	})
+	s.Require().NoError(err)
+	_, err = s.ds.CreateLSVIDRevocation(ctx, &datastore.LSVIDRevocation{
+		Kind:  "KEY_HASH",
+		Value: "hash2",
+	})
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.ds.DeleteLSVIDRevocation(ctx, "KEY_HASH", "hash1"))
+
+	err = s.ds.DeleteLSVIDRevocation(ctx, "KEY_HASH", "hash1")
+	s.Require().EqualError(err, "rpc error: code = NotFound desc = datastore-sql: record not found")
+
+	err = s.ds.DeleteLSVIDRevocation(ctx, "", "hash2")
+	s.Require().EqualError(err, "rpc error: code = InvalidArgument desc = revocation kind and value are required")
+
+	resp, err := s.ds.ListLSVIDRevocations(ctx, &datastore.ListLSVIDRevocationsRequest{})
+	s.Require().NoError(err)
+	s.Require().Len(resp.Revocations, 1)
+	s.Equal("hash2", resp.Revocations[0].Value)
+}
+
 func (s *PluginSuite) TestDeleteFederationRelationship() {
 	testCases := []struct {
 		name        string
@@ -4057,6 +4159,11 @@ func (s *PluginSuite) TestMigration() {
 			s.Require().True(s.ds.db.Dialect().HasColumn("federated_trust_domains", "endpoint_spiffe_id"))
 			s.Require().True(s.ds.db.Dialect().HasColumn("federated_trust_domains", "implicit"))
 			s.Require().True(s.ds.db.Dialect().HasIndex("federated_trust_domains", "uix_federated_trust_domains_trust_domain"))
+		case 17:
+			s.Require().True(s.ds.db.Dialect().HasColumn("lsvid_revocations", "kind"))
+			s.Require().True(s.ds.db.Dialect().HasColumn("lsvid_revocations", "value"))
+			s.Require().True(s.ds.db.Dialect().HasColumn("lsvid_revocations", "value_hash"))
+			s.Require().True(s.ds.db.Dialect().HasIndex("lsvid_revocations", "idx_lsvid_revocation"))
 		default:
 			s.T().Fatalf("no migration test added for version %d", i)
 		}
@@ -18,6 +18,7 @@ import (
 	debugv1 "github.com/spiffe/spire/pkg/server/api/debug/v1"
 	entryv1 "github.com/spiffe/spire/pkg/server/api/entry/v1"
 	healthv1 "github.com/spiffe/spire/pkg/server/api/health/v1"
+	lsvidv1 "github.com/spiffe/spire/pkg/server/api/lsvid/v1"
 	svidv1 "github.com/spiffe/spire/pkg/server/api/svid/v1"
 	trustdomainv1 "github.com/spiffe/spire/pkg/server/api/trustdomain/v1"
 	"github.com/spiffe/spire/pkg/server/authpolicy"
@@ -144,6 +145,12 @@ func (c *Config) makeAPIServers(entryFetcher api.AuthorizedEntryFetcher) APIServ
 			TrustDomain: c.TrustDomain,
 			DataStore:   ds,
 		}),
+		LSVIDServer: lsvidv1.New(lsvidv1.Config{
+			TrustDomain:  c.TrustDomain,
+			EntryFetcher: entryFetcher,
+			ServerCA:     c.ServerCA,
+			DataStore:    ds,
+		}),
 		SVIDServer: svidv1.New(svidv1.Config{
 			TrustDomain:  c.TrustDomain,
 			EntryFetcher: entryFetcher,
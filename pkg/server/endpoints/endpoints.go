@@ -36,6 +36,7 @@ import (
 	"github.com/spiffe/spire/pkg/server/cache/dscache"
 	"github.com/spiffe/spire/pkg/server/datastore"
 	"github.com/spiffe/spire/pkg/server/svid"
+	lsvidv1 "github.com/spiffe/spire/proto/spire/api/server/lsvid/v1"
 )
 
 const (
@@ -80,6 +81,7 @@ type APIServers struct {
 	DebugServer       debugv1_pb.DebugServer
 	EntryServer       entryv1.EntryServer
 	HealthServer      grpc_health_v1.HealthServer
+	LSVIDServer       lsvidv1.LSVIDServer
 	SVIDServer        svidv1.SVIDServer
 	TrustDomainServer trustdomainv1.TrustDomainServer
 }
@@ -155,6 +157,8 @@ func (e *Endpoints) ListenAndServe(ctx context.Context) error {
 	entryv1.RegisterEntryServer(udsServer, e.APIServers.EntryServer)
 	svidv1.RegisterSVIDServer(tcpServer, e.APIServers.SVIDServer)
 	svidv1.RegisterSVIDServer(udsServer, e.APIServers.SVIDServer)
+	lsvidv1.RegisterLSVIDServer(tcpServer, e.APIServers.LSVIDServer)
+	lsvidv1.RegisterLSVIDServer(udsServer, e.APIServers.LSVIDServer)
 	trustdomainv1.RegisterTrustDomainServer(tcpServer, e.APIServers.TrustDomainServer)
 	trustdomainv1.RegisterTrustDomainServer(udsServer, e.APIServers.TrustDomainServer)
 
@@ -27,6 +27,7 @@ import (
 	"github.com/spiffe/spire/pkg/server/datastore"
 	"github.com/spiffe/spire/pkg/server/endpoints/bundle"
 	"github.com/spiffe/spire/pkg/server/svid"
+	lsvidv1 "github.com/spiffe/spire/proto/spire/api/server/lsvid/v1"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakedatastore"
@@ -116,6 +117,7 @@ func TestNew(t *testing.T) {
 	assert.NotNil(t, endpoints.APIServers.DebugServer)
 	assert.NotNil(t, endpoints.APIServers.EntryServer)
 	assert.NotNil(t, endpoints.APIServers.HealthServer)
+	assert.NotNil(t, endpoints.APIServers.LSVIDServer)
 	assert.NotNil(t, endpoints.APIServers.SVIDServer)
 	assert.NotNil(t, endpoints.BundleEndpointServer)
 	assert.Equal(t, cat.GetDataStore(), endpoints.DataStore)
@@ -220,6 +222,7 @@ func TestListenAndServe(t *testing.T) {
 			DebugServer:       &debugv1.UnimplementedDebugServer{},
 			EntryServer:       &entryv1.UnimplementedEntryServer{},
 			HealthServer:      &grpc_health_v1.UnimplementedHealthServer{},
+			LSVIDServer:       &lsvidv1.UnimplementedLSVIDServer{},
 			SVIDServer:        &svidv1.UnimplementedSVIDServer{},
 			TrustDomainServer: &trustdomainv1.UnimplementedTrustDomainServer{},
 		},
@@ -305,6 +308,9 @@ func TestListenAndServe(t *testing.T) {
 	t.Run("SVID", func(t *testing.T) {
 		testSVIDAPI(ctx, t, udsConn, noauthConn, agentConn, adminConn, downstreamConn)
 	})
+	t.Run("LSVID", func(t *testing.T) {
+		testLSVIDAPI(ctx, t, udsConn, noauthConn, agentConn, adminConn, downstreamConn)
+	})
 	t.Run("TrustDomain", func(t *testing.T) {
 		testTrustDomainAPI(ctx, t, udsConn, noauthConn, agentConn, adminConn, downstreamConn)
 	})
@@ -681,6 +687,63 @@ func testSVIDAPI(ctx context.Context, t *testing.T, udsConn, noauthConn, agentCo
 	})
 }
 
+func testLSVIDAPI(ctx context.Context, t *testing.T, udsConn, noauthConn, agentConn, adminConn, downstreamConn *grpc.ClientConn) {
+	t.Run("UDS", func(t *testing.T) {
+		testAuthorization(ctx, t, lsvidv1.NewLSVIDClient(udsConn), map[string]bool{
+			"NewLSVID":              false,
+			"MintLSVID":             true,
+			"GetLSVIDBundle":        true,
+			"RevokeLSVID":           true,
+			"ListLSVIDRevocations":  true,
+			"DeleteLSVIDRevocation": true,
+		})
+	})
+
+	t.Run("NoAuth", func(t *testing.T) {
+		testAuthorization(ctx, t, lsvidv1.NewLSVIDClient(noauthConn), map[string]bool{
+			"NewLSVID":              false,
+			"MintLSVID":             false,
+			"GetLSVIDBundle":        false,
+			"RevokeLSVID":           false,
+			"ListLSVIDRevocations":  false,
+			"DeleteLSVIDRevocation": false,
+		})
+	})
+
+	t.Run("Agent", func(t *testing.T) {
+		testAuthorization(ctx, t, lsvidv1.NewLSVIDClient(agentConn), map[string]bool{
+			"NewLSVID":              true,
+			"MintLSVID":             false,
+			"GetLSVIDBundle":        true,
+			"RevokeLSVID":           false,
+			"ListLSVIDRevocations":  true,
+			"DeleteLSVIDRevocation": false,
+		})
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		testAuthorization(ctx, t, lsvidv1.NewLSVIDClient(adminConn), map[string]bool{
+			"NewLSVID":              false,
+			"MintLSVID":             true,
+			"GetLSVIDBundle":        true,
+			"RevokeLSVID":           true,
+			"ListLSVIDRevocations":  true,
+			"DeleteLSVIDRevocation": true,
+		})
+	})
+
+	t.Run("Downstream", func(t *testing.T) {
+		testAuthorization(ctx, t, lsvidv1.NewLSVIDClient(downstreamConn), map[string]bool{
+			"NewLSVID":              false,
+			"MintLSVID":             false,
+			"GetLSVIDBundle":        false,
+			"RevokeLSVID":           false,
+			"ListLSVIDRevocations":  false,
+			"DeleteLSVIDRevocation": false,
+		})
+	})
+}
+
 func testTrustDomainAPI(ctx context.Context, t *testing.T, udsConn, noauthConn, agentConn, adminConn, downstreamConn *grpc.ClientConn) {
 	t.Run("UDS", func(t *testing.T) {
 		testAuthorization(ctx, t, trustdomainv1.NewTrustDomainClient(udsConn), map[string]bool{
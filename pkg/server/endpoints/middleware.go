@@ -142,6 +142,12 @@ func RateLimits(config RateLimitConfig) map[string]api.RateLimiter {
 		"/spire.api.server.svid.v1.SVID/BatchNewX509SVID":                                csrLimit,
 		"/spire.api.server.svid.v1.SVID/NewJWTSVID":                                      jsrLimit,
 		"/spire.api.server.svid.v1.SVID/NewDownstreamX509CA":                             csrLimit,
+		"/spire.api.server.lsvid.v1.LSVID/NewLSVID":                                      jsrLimit,
+		"/spire.api.server.lsvid.v1.LSVID/MintLSVID":                                     noLimit,
+		"/spire.api.server.lsvid.v1.LSVID/GetLSVIDBundle":                                noLimit,
+		"/spire.api.server.lsvid.v1.LSVID/RevokeLSVID":                                   noLimit,
+		"/spire.api.server.lsvid.v1.LSVID/ListLSVIDRevocations":                          noLimit,
+		"/spire.api.server.lsvid.v1.LSVID/DeleteLSVIDRevocation":                         noLimit,
 		"/spire.api.server.bundle.v1.Bundle/GetBundle":                                   noLimit,
 		"/spire.api.server.bundle.v1.Bundle/AppendBundle":                                noLimit,
 		"/spire.api.server.bundle.v1.Bundle/PublishJWTAuthority":                         pushJWTKeyLimit,
@@ -253,6 +253,7 @@ func (s *Server) newCA(metrics telemetry.Metrics, healthChecker health.Checker)
 		Log:           s.config.Log.WithField(telemetry.SubsystemName, telemetry.CA),
 		Metrics:       metrics,
 		X509SVIDTTL:   s.config.SVIDTTL,
+		LSVIDTTL:      s.config.LSVIDTTL,
 		JWTIssuer:     s.config.JWTIssuer,
 		TrustDomain:   s.config.TrustDomain,
 		CASubject:     s.config.CASubject,
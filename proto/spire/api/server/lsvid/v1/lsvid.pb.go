@@ -0,0 +1,1270 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        v3.14.0
+// source: spire/api/server/lsvid/v1/lsvid.proto
+
+package lsvidv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type NewLSVIDRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Optional. ID of the registration entry the LSVID is requested for. If
+	// set, the subject must be the SPIFFE ID of the entry.
+	EntryId string `protobuf:"bytes,1,opt,name=entry_id,json=entryId,proto3" json:"entry_id,omitempty"`
+	// Required. SPIFFE ID of the LSVID subject.
+	SpiffeId string `protobuf:"bytes,2,opt,name=spiffe_id,json=spiffeId,proto3" json:"spiffe_id,omitempty"`
+	// Required. PKIX encoded public key of the LSVID subject.
+	PublicKey []byte `protobuf:"bytes,3,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	// Required. The audience of the LSVID.
+	Audience string `protobuf:"bytes,4,opt,name=audience,proto3" json:"audience,omitempty"`
+	// Required. ASN.1 DER encoded certificate chain of the X509-SVID holding
+	// the subject public key, leaf first.
+	X509Svid [][]byte `protobuf:"bytes,5,rep,name=x509_svid,json=x509Svid,proto3" json:"x509_svid,omitempty"`
+}
+
+func (x *NewLSVIDRequest) Reset() {
+	*x = NewLSVIDRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NewLSVIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewLSVIDRequest) ProtoMessage() {}
+
+func (x *NewLSVIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewLSVIDRequest.ProtoReflect.Descriptor instead.
+func (*NewLSVIDRequest) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_lsvid_v1_lsvid_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *NewLSVIDRequest) GetEntryId() string {
+	if x != nil {
+		return x.EntryId
+	}
+	return ""
+}
+
+func (x *NewLSVIDRequest) GetSpiffeId() string {
+	if x != nil {
+		return x.SpiffeId
+	}
+	return ""
+}
+
+func (x *NewLSVIDRequest) GetPublicKey() []byte {
+	if x != nil {
+		return x.PublicKey
+	}
+	return nil
+}
+
+func (x *NewLSVIDRequest) GetAudience() string {
+	if x != nil {
+		return x.Audience
+	}
+	return ""
+}
+
+func (x *NewLSVIDRequest) GetX509Svid() [][]byte {
+	if x != nil {
+		return x.X509Svid
+	}
+	return nil
+}
+
+type NewLSVIDResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The signed LSVID.
+	Lsvid *Token `protobuf:"bytes,1,opt,name=lsvid,proto3" json:"lsvid,omitempty"`
+}
+
+func (x *NewLSVIDResponse) Reset() {
+	*x = NewLSVIDResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NewLSVIDResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewLSVIDResponse) ProtoMessage() {}
+
+func (x *NewLSVIDResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewLSVIDResponse.ProtoReflect.Descriptor instead.
+func (*NewLSVIDResponse) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_lsvid_v1_lsvid_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *NewLSVIDResponse) GetLsvid() *Token {
+	if x != nil {
+		return x.Lsvid
+	}
+	return nil
+}
+
+type MintLSVIDRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Required. SPIFFE ID of the LSVID subject.
+	SpiffeId string `protobuf:"bytes,1,opt,name=spiffe_id,json=spiffeId,proto3" json:"spiffe_id,omitempty"`
+	// Required. PKIX encoded public key of the LSVID subject.
+	PublicKey []byte `protobuf:"bytes,2,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	// Required. The audience of the LSVID.
+	Audience string `protobuf:"bytes,3,opt,name=audience,proto3" json:"audience,omitempty"`
+	// Optional. Desired TTL of the LSVID, in seconds. The server default is
+	// used if unset. The TTL is capped to the lifetime of the signing key.
+	Ttl int32 `protobuf:"varint,4,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	// Optional. JSON object holding the custom claims of the LSVID, keyed by
+	// absolute URIs.
+	Claims string `protobuf:"bytes,5,opt,name=claims,proto3" json:"claims,omitempty"`
+	// Optional. Custom claims that validators must understand to accept the
+	// LSVID. Each one must be present in the custom claims.
+	CriticalClaims []string `protobuf:"bytes,6,rep,name=critical_claims,json=criticalClaims,proto3" json:"critical_claims,omitempty"`
+}
+
+func (x *MintLSVIDRequest) Reset() {
+	*x = MintLSVIDRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MintLSVIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MintLSVIDRequest) ProtoMessage() {}
+
+func (x *MintLSVIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MintLSVIDRequest.ProtoReflect.Descriptor instead.
+func (*MintLSVIDRequest) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_lsvid_v1_lsvid_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *MintLSVIDRequest) GetSpiffeId() string {
+	if x != nil {
+		return x.SpiffeId
+	}
+	return ""
+}
+
+func (x *MintLSVIDRequest) GetPublicKey() []byte {
+	if x != nil {
+		return x.PublicKey
+	}
+	return nil
+}
+
+func (x *MintLSVIDRequest) GetAudience() string {
+	if x != nil {
+		return x.Audience
+	}
+	return ""
+}
+
+func (x *MintLSVIDRequest) GetTtl() int32 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+func (x *MintLSVIDRequest) GetClaims() string {
+	if x != nil {
+		return x.Claims
+	}
+	return ""
+}
+
+func (x *MintLSVIDRequest) GetCriticalClaims() []string {
+	if x != nil {
+		return x.CriticalClaims
+	}
+	return nil
+}
+
+type MintLSVIDResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The signed LSVID.
+	Lsvid *Token `protobuf:"bytes,1,opt,name=lsvid,proto3" json:"lsvid,omitempty"`
+}
+
+func (x *MintLSVIDResponse) Reset() {
+	*x = MintLSVIDResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MintLSVIDResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MintLSVIDResponse) ProtoMessage() {}
+
+func (x *MintLSVIDResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MintLSVIDResponse.ProtoReflect.Descriptor instead.
+func (*MintLSVIDResponse) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_lsvid_v1_lsvid_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *MintLSVIDResponse) GetLsvid() *Token {
+	if x != nil {
+		return x.Lsvid
+	}
+	return nil
+}
+
+type GetLSVIDBundleRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Optional. The federated trust domain to return the trust bundle
+	// document of (e.g. "domain.test"). If unset, the document of the trust
+	// domain of the server is returned.
+	TrustDomain string `protobuf:"bytes,1,opt,name=trust_domain,json=trustDomain,proto3" json:"trust_domain,omitempty"`
+}
+
+func (x *GetLSVIDBundleRequest) Reset() {
+	*x = GetLSVIDBundleRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetLSVIDBundleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLSVIDBundleRequest) ProtoMessage() {}
+
+func (x *GetLSVIDBundleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLSVIDBundleRequest.ProtoReflect.Descriptor instead.
+func (*GetLSVIDBundleRequest) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_lsvid_v1_lsvid_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetLSVIDBundleRequest) GetTrustDomain() string {
+	if x != nil {
+		return x.TrustDomain
+	}
+	return ""
+}
+
+type GetLSVIDBundleResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The encoded trust bundle document.
+	Bundle string `protobuf:"bytes,1,opt,name=bundle,proto3" json:"bundle,omitempty"`
+	// Expiration of the trust bundle document (seconds since Unix epoch).
+	ExpiresAt int64 `protobuf:"varint,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// Issuance of the trust bundle document (seconds since Unix epoch).
+	IssuedAt int64 `protobuf:"varint,3,opt,name=issued_at,json=issuedAt,proto3" json:"issued_at,omitempty"`
+}
+
+func (x *GetLSVIDBundleResponse) Reset() {
+	*x = GetLSVIDBundleResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetLSVIDBundleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLSVIDBundleResponse) ProtoMessage() {}
+
+func (x *GetLSVIDBundleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLSVIDBundleResponse.ProtoReflect.Descriptor instead.
+func (*GetLSVIDBundleResponse) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_lsvid_v1_lsvid_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetLSVIDBundleResponse) GetBundle() string {
+	if x != nil {
+		return x.Bundle
+	}
+	return ""
+}
+
+func (x *GetLSVIDBundleResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *GetLSVIDBundleResponse) GetIssuedAt() int64 {
+	if x != nil {
+		return x.IssuedAt
+	}
+	return 0
+}
+
+type RevokeLSVIDRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Required. What the revocation matches: "SPIFFE_ID" for the layers
+	// whose subject or issuer has the SPIFFE ID in value, "KEY_HASH" for the
+	// layers whose subject or issuer key has the hash in value, or
+	// "TOKEN_ID" for the layer with the token ID in value.
+	Kind string `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	// Required. The revoked SPIFFE ID, key hash or token ID.
+	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *RevokeLSVIDRequest) Reset() {
+	*x = RevokeLSVIDRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RevokeLSVIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeLSVIDRequest) ProtoMessage() {}
+
+func (x *RevokeLSVIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeLSVIDRequest.ProtoReflect.Descriptor instead.
+func (*RevokeLSVIDRequest) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_lsvid_v1_lsvid_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RevokeLSVIDRequest) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *RevokeLSVIDRequest) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type RevokeLSVIDResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The created revocation.
+	Revocation *Revocation `protobuf:"bytes,1,opt,name=revocation,proto3" json:"revocation,omitempty"`
+}
+
+func (x *RevokeLSVIDResponse) Reset() {
+	*x = RevokeLSVIDResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RevokeLSVIDResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeLSVIDResponse) ProtoMessage() {}
+
+func (x *RevokeLSVIDResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeLSVIDResponse.ProtoReflect.Descriptor instead.
+func (*RevokeLSVIDResponse) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_lsvid_v1_lsvid_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RevokeLSVIDResponse) GetRevocation() *Revocation {
+	if x != nil {
+		return x.Revocation
+	}
+	return nil
+}
+
+type ListLSVIDRevocationsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Optional. The maximum number of results to return. If zero, all the
+	// revocations are returned.
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// Optional. The next_page_token value returned from a previous request, if
+	// any.
+	PageToken string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+}
+
+func (x *ListLSVIDRevocationsRequest) Reset() {
+	*x = ListLSVIDRevocationsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListLSVIDRevocationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLSVIDRevocationsRequest) ProtoMessage() {}
+
+func (x *ListLSVIDRevocationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLSVIDRevocationsRequest.ProtoReflect.Descriptor instead.
+func (*ListLSVIDRevocationsRequest) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_lsvid_v1_lsvid_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListLSVIDRevocationsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListLSVIDRevocationsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListLSVIDRevocationsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The LSVID revocations.
+	Revocations []*Revocation `protobuf:"bytes,1,rep,name=revocations,proto3" json:"revocations,omitempty"`
+	// The page token for the next request. Empty if there are no more
+	// results.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+}
+
+func (x *ListLSVIDRevocationsResponse) Reset() {
+	*x = ListLSVIDRevocationsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListLSVIDRevocationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLSVIDRevocationsResponse) ProtoMessage() {}
+
+func (x *ListLSVIDRevocationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLSVIDRevocationsResponse.ProtoReflect.Descriptor instead.
+func (*ListLSVIDRevocationsResponse) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_lsvid_v1_lsvid_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListLSVIDRevocationsResponse) GetRevocations() []*Revocation {
+	if x != nil {
+		return x.Revocations
+	}
+	return nil
+}
+
+func (x *ListLSVIDRevocationsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type DeleteLSVIDRevocationRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Required. The kind of the revocation.
+	Kind string `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	// Required. The value of the revocation.
+	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *DeleteLSVIDRevocationRequest) Reset() {
+	*x = DeleteLSVIDRevocationRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteLSVIDRevocationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteLSVIDRevocationRequest) ProtoMessage() {}
+
+func (x *DeleteLSVIDRevocationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteLSVIDRevocationRequest.ProtoReflect.Descriptor instead.
+func (*DeleteLSVIDRevocationRequest) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_lsvid_v1_lsvid_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DeleteLSVIDRevocationRequest) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *DeleteLSVIDRevocationRequest) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type DeleteLSVIDRevocationResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *DeleteLSVIDRevocationResponse) Reset() {
+	*x = DeleteLSVIDRevocationResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteLSVIDRevocationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteLSVIDRevocationResponse) ProtoMessage() {}
+
+func (x *DeleteLSVIDRevocationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteLSVIDRevocationResponse.ProtoReflect.Descriptor instead.
+func (*DeleteLSVIDRevocationResponse) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_lsvid_v1_lsvid_proto_rawDescGZIP(), []int{11}
+}
+
+type Revocation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// What the revocation matches. See RevokeLSVIDRequest.
+	Kind string `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	// The revoked SPIFFE ID, key hash or token ID.
+	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// Creation of the revocation (seconds since Unix epoch).
+	CreatedAt int64 `protobuf:"varint,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+}
+
+func (x *Revocation) Reset() {
+	*x = Revocation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Revocation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Revocation) ProtoMessage() {}
+
+func (x *Revocation) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Revocation.ProtoReflect.Descriptor instead.
+func (*Revocation) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_lsvid_v1_lsvid_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Revocation) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Revocation) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *Revocation) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+type Token struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The encoded LSVID.
+	Token string `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	// SPIFFE ID of the LSVID subject.
+	SpiffeId string `protobuf:"bytes,2,opt,name=spiffe_id,json=spiffeId,proto3" json:"spiffe_id,omitempty"`
+	// Expiration of the LSVID (seconds since Unix epoch).
+	ExpiresAt int64 `protobuf:"varint,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// Issuance of the LSVID (seconds since Unix epoch).
+	IssuedAt int64 `protobuf:"varint,4,opt,name=issued_at,json=issuedAt,proto3" json:"issued_at,omitempty"`
+}
+
+func (x *Token) Reset() {
+	*x = Token{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Token) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Token) ProtoMessage() {}
+
+func (x *Token) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Token.ProtoReflect.Descriptor instead.
+func (*Token) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_lsvid_v1_lsvid_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Token) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *Token) GetSpiffeId() string {
+	if x != nil {
+		return x.SpiffeId
+	}
+	return ""
+}
+
+func (x *Token) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *Token) GetIssuedAt() int64 {
+	if x != nil {
+		return x.IssuedAt
+	}
+	return 0
+}
+
+var File_spire_api_server_lsvid_v1_lsvid_proto protoreflect.FileDescriptor
+
+var file_spire_api_server_lsvid_v1_lsvid_proto_rawDesc = []byte{
+	0x0a, 0x25, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x73, 0x65, 0x72, 0x76,
+	0x65, 0x72, 0x2f, 0x6c, 0x73, 0x76, 0x69, 0x64, 0x2f, 0x76, 0x31, 0x2f, 0x6c, 0x73, 0x76, 0x69,
+	0x64, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x19, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x6c, 0x73, 0x76, 0x69, 0x64, 0x2e,
+	0x76, 0x31, 0x22, 0xa1, 0x01, 0x0a, 0x0f, 0x4e, 0x65, 0x77, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x49,
+	0x64, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x70, 0x69, 0x66, 0x66, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x70, 0x69, 0x66, 0x66, 0x65, 0x49, 0x64, 0x12, 0x1d,
+	0x0a, 0x0a, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x09, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x12, 0x1a, 0x0a,
+	0x08, 0x61, 0x75, 0x64, 0x69, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x61, 0x75, 0x64, 0x69, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x78, 0x35, 0x30,
+	0x39, 0x5f, 0x73, 0x76, 0x69, 0x64, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x08, 0x78, 0x35,
+	0x30, 0x39, 0x53, 0x76, 0x69, 0x64, 0x22, 0x4a, 0x0a, 0x10, 0x4e, 0x65, 0x77, 0x4c, 0x53, 0x56,
+	0x49, 0x44, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x36, 0x0a, 0x05, 0x6c, 0x73,
+	0x76, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x73, 0x70, 0x69, 0x72,
+	0x65, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x6c, 0x73, 0x76,
+	0x69, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x05, 0x6c, 0x73, 0x76,
+	0x69, 0x64, 0x22, 0xbd, 0x01, 0x0a, 0x10, 0x4d, 0x69, 0x6e, 0x74, 0x4c, 0x53, 0x56, 0x49, 0x44,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x70, 0x69, 0x66, 0x66,
+	0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x70, 0x69, 0x66,
+	0x66, 0x65, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x5f, 0x6b,
+	0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63,
+	0x4b, 0x65, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x75, 0x64, 0x69, 0x65, 0x6e, 0x63, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x61, 0x75, 0x64, 0x69, 0x65, 0x6e, 0x63, 0x65, 0x12,
+	0x10, 0x0a, 0x03, 0x74, 0x74, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x74, 0x74,
+	0x6c, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x63, 0x72, 0x69,
+	0x74, 0x69, 0x63, 0x61, 0x6c, 0x5f, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x73, 0x18, 0x06, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x0e, 0x63, 0x72, 0x69, 0x74, 0x69, 0x63, 0x61, 0x6c, 0x43, 0x6c, 0x61, 0x69,
+	0x6d, 0x73, 0x22, 0x4b, 0x0a, 0x11, 0x4d, 0x69, 0x6e, 0x74, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x36, 0x0a, 0x05, 0x6c, 0x73, 0x76, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x6c, 0x73, 0x76, 0x69, 0x64, 0x2e,
+	0x76, 0x31, 0x2e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x05, 0x6c, 0x73, 0x76, 0x69, 0x64, 0x22,
+	0x3a, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x42, 0x75, 0x6e, 0x64, 0x6c,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x74, 0x72, 0x75, 0x73,
+	0x74, 0x5f, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
+	0x74, 0x72, 0x75, 0x73, 0x74, 0x44, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x22, 0x6c, 0x0a, 0x16, 0x47,
+	0x65, 0x74, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x42, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x62, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x12, 0x1d, 0x0a,
+	0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x12, 0x1b, 0x0a, 0x09,
+	0x69, 0x73, 0x73, 0x75, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x08, 0x69, 0x73, 0x73, 0x75, 0x65, 0x64, 0x41, 0x74, 0x22, 0x3e, 0x0a, 0x12, 0x52, 0x65, 0x76,
+	0x6f, 0x6b, 0x65, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x12, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6b,
+	0x69, 0x6e, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x5c, 0x0a, 0x13, 0x52, 0x65, 0x76,
+	0x6f, 0x6b, 0x65, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x45, 0x0a, 0x0a, 0x72, 0x65, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x6c, 0x73, 0x76, 0x69, 0x64, 0x2e, 0x76, 0x31,
+	0x2e, 0x52, 0x65, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x72, 0x65, 0x76,
+	0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x59, 0x0a, 0x1b, 0x4c, 0x69, 0x73, 0x74, 0x4c,
+	0x53, 0x56, 0x49, 0x44, 0x52, 0x65, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x73,
+	0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x53,
+	0x69, 0x7a, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65,
+	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b,
+	0x65, 0x6e, 0x22, 0x8f, 0x01, 0x0a, 0x1c, 0x4c, 0x69, 0x73, 0x74, 0x4c, 0x53, 0x56, 0x49, 0x44,
+	0x52, 0x65, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x47, 0x0a, 0x0b, 0x72, 0x65, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x73, 0x70, 0x69, 0x72, 0x65,
+	0x2e, 0x61, 0x70, 0x69, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x6c, 0x73, 0x76, 0x69,
+	0x64, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x0b, 0x72, 0x65, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x26, 0x0a, 0x0f,
+	0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x54,
+	0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x48, 0x0a, 0x1c, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4c, 0x53,
+	0x56, 0x49, 0x44, 0x52, 0x65, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x1f,
+	0x0a, 0x1d, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x52, 0x65, 0x76,
+	0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x55, 0x0a, 0x0a, 0x52, 0x65, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a,
+	0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6b, 0x69, 0x6e,
+	0x64, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x63, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x22, 0x76, 0x0a, 0x05, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12,
+	0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x70, 0x69, 0x66, 0x66, 0x65, 0x5f,
+	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x70, 0x69, 0x66, 0x66, 0x65,
+	0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41,
+	0x74, 0x12, 0x1b, 0x0a, 0x09, 0x69, 0x73, 0x73, 0x75, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x69, 0x73, 0x73, 0x75, 0x65, 0x64, 0x41, 0x74, 0x32, 0xd0,
+	0x05, 0x0a, 0x05, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x12, 0x63, 0x0a, 0x08, 0x4e, 0x65, 0x77, 0x4c,
+	0x53, 0x56, 0x49, 0x44, 0x12, 0x2a, 0x2e, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x6c, 0x73, 0x76, 0x69, 0x64, 0x2e, 0x76, 0x31,
+	0x2e, 0x4e, 0x65, 0x77, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x2b, 0x2e, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x73, 0x65, 0x72,
+	0x76, 0x65, 0x72, 0x2e, 0x6c, 0x73, 0x76, 0x69, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x4e, 0x65, 0x77,
+	0x4c, 0x53, 0x56, 0x49, 0x44, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x66, 0x0a,
+	0x09, 0x4d, 0x69, 0x6e, 0x74, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x12, 0x2b, 0x2e, 0x73, 0x70, 0x69,
+	0x72, 0x65, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x6c, 0x73,
+	0x76, 0x69, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x69, 0x6e, 0x74, 0x4c, 0x53, 0x56, 0x49, 0x44,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2c, 0x2e, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e,
+	0x61, 0x70, 0x69, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x6c, 0x73, 0x76, 0x69, 0x64,
+	0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x69, 0x6e, 0x74, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x75, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x4c, 0x53, 0x56, 0x49,
+	0x44, 0x42, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x12, 0x30, 0x2e, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e,
+	0x61, 0x70, 0x69, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x6c, 0x73, 0x76, 0x69, 0x64,
+	0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x42, 0x75, 0x6e, 0x64,
+	0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x31, 0x2e, 0x73, 0x70, 0x69, 0x72,
+	0x65, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x6c, 0x73, 0x76,
+	0x69, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x42, 0x75,
+	0x6e, 0x64, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x6c, 0x0a, 0x0b,
+	0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x12, 0x2d, 0x2e, 0x73, 0x70,
+	0x69, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x6c,
+	0x73, 0x76, 0x69, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x4c, 0x53,
+	0x56, 0x49, 0x44, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2e, 0x2e, 0x73, 0x70, 0x69,
+	0x72, 0x65, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x6c, 0x73,
+	0x76, 0x69, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x4c, 0x53, 0x56,
+	0x49, 0x44, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x87, 0x01, 0x0a, 0x14, 0x4c,
+	0x69, 0x73, 0x74, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x52, 0x65, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x12, 0x36, 0x2e, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x69, 0x2e,
+	0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x6c, 0x73, 0x76, 0x69, 0x64, 0x2e, 0x76, 0x31, 0x2e,
+	0x4c, 0x69, 0x73, 0x74, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x52, 0x65, 0x76, 0x6f, 0x63, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x37, 0x2e, 0x73, 0x70,
+	0x69, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x6c,
+	0x73, 0x76, 0x69, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x4c, 0x53, 0x56, 0x49,
+	0x44, 0x52, 0x65, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x8a, 0x01, 0x0a, 0x15, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4c,
+	0x53, 0x56, 0x49, 0x44, 0x52, 0x65, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x37,
+	0x2e, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65,
+	0x72, 0x2e, 0x6c, 0x73, 0x76, 0x69, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74,
+	0x65, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x52, 0x65, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x38, 0x2e, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e,
+	0x61, 0x70, 0x69, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x6c, 0x73, 0x76, 0x69, 0x64,
+	0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x52,
+	0x65, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x42, 0x41, 0x5a, 0x3f, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
+	0x73, 0x70, 0x69, 0x66, 0x66, 0x65, 0x2f, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2f, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x73, 0x65, 0x72,
+	0x76, 0x65, 0x72, 0x2f, 0x6c, 0x73, 0x76, 0x69, 0x64, 0x2f, 0x76, 0x31, 0x3b, 0x6c, 0x73, 0x76,
+	0x69, 0x64, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_spire_api_server_lsvid_v1_lsvid_proto_rawDescOnce sync.Once
+	file_spire_api_server_lsvid_v1_lsvid_proto_rawDescData = file_spire_api_server_lsvid_v1_lsvid_proto_rawDesc
+)
+
+func file_spire_api_server_lsvid_v1_lsvid_proto_rawDescGZIP() []byte {
+	file_spire_api_server_lsvid_v1_lsvid_proto_rawDescOnce.Do(func() {
+		file_spire_api_server_lsvid_v1_lsvid_proto_rawDescData = protoimpl.X.CompressGZIP(file_spire_api_server_lsvid_v1_lsvid_proto_rawDescData)
+	})
+	return file_spire_api_server_lsvid_v1_lsvid_proto_rawDescData
+}
+
+var file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_spire_api_server_lsvid_v1_lsvid_proto_goTypes = []interface{}{
+	(*NewLSVIDRequest)(nil),               // 0: spire.api.server.lsvid.v1.NewLSVIDRequest
+	(*NewLSVIDResponse)(nil),              // 1: spire.api.server.lsvid.v1.NewLSVIDResponse
+	(*MintLSVIDRequest)(nil),              // 2: spire.api.server.lsvid.v1.MintLSVIDRequest
+	(*MintLSVIDResponse)(nil),             // 3: spire.api.server.lsvid.v1.MintLSVIDResponse
+	(*GetLSVIDBundleRequest)(nil),         // 4: spire.api.server.lsvid.v1.GetLSVIDBundleRequest
+	(*GetLSVIDBundleResponse)(nil),        // 5: spire.api.server.lsvid.v1.GetLSVIDBundleResponse
+	(*RevokeLSVIDRequest)(nil),            // 6: spire.api.server.lsvid.v1.RevokeLSVIDRequest
+	(*RevokeLSVIDResponse)(nil),           // 7: spire.api.server.lsvid.v1.RevokeLSVIDResponse
+	(*ListLSVIDRevocationsRequest)(nil),   // 8: spire.api.server.lsvid.v1.ListLSVIDRevocationsRequest
+	(*ListLSVIDRevocationsResponse)(nil),  // 9: spire.api.server.lsvid.v1.ListLSVIDRevocationsResponse
+	(*DeleteLSVIDRevocationRequest)(nil),  // 10: spire.api.server.lsvid.v1.DeleteLSVIDRevocationRequest
+	(*DeleteLSVIDRevocationResponse)(nil), // 11: spire.api.server.lsvid.v1.DeleteLSVIDRevocationResponse
+	(*Revocation)(nil),                    // 12: spire.api.server.lsvid.v1.Revocation
+	(*Token)(nil),                         // 13: spire.api.server.lsvid.v1.Token
+}
+var file_spire_api_server_lsvid_v1_lsvid_proto_depIdxs = []int32{
+	13, // 0: spire.api.server.lsvid.v1.NewLSVIDResponse.lsvid:type_name -> spire.api.server.lsvid.v1.Token
+	13, // 1: spire.api.server.lsvid.v1.MintLSVIDResponse.lsvid:type_name -> spire.api.server.lsvid.v1.Token
+	12, // 2: spire.api.server.lsvid.v1.RevokeLSVIDResponse.revocation:type_name -> spire.api.server.lsvid.v1.Revocation
+	12, // 3: spire.api.server.lsvid.v1.ListLSVIDRevocationsResponse.revocations:type_name -> spire.api.server.lsvid.v1.Revocation
+	0,  // 4: spire.api.server.lsvid.v1.LSVID.NewLSVID:input_type -> spire.api.server.lsvid.v1.NewLSVIDRequest
+	2,  // 5: spire.api.server.lsvid.v1.LSVID.MintLSVID:input_type -> spire.api.server.lsvid.v1.MintLSVIDRequest
+	4,  // 6: spire.api.server.lsvid.v1.LSVID.GetLSVIDBundle:input_type -> spire.api.server.lsvid.v1.GetLSVIDBundleRequest
+	6,  // 7: spire.api.server.lsvid.v1.LSVID.RevokeLSVID:input_type -> spire.api.server.lsvid.v1.RevokeLSVIDRequest
+	8,  // 8: spire.api.server.lsvid.v1.LSVID.ListLSVIDRevocations:input_type -> spire.api.server.lsvid.v1.ListLSVIDRevocationsRequest
+	10, // 9: spire.api.server.lsvid.v1.LSVID.DeleteLSVIDRevocation:input_type -> spire.api.server.lsvid.v1.DeleteLSVIDRevocationRequest
+	1,  // 10: spire.api.server.lsvid.v1.LSVID.NewLSVID:output_type -> spire.api.server.lsvid.v1.NewLSVIDResponse
+	3,  // 11: spire.api.server.lsvid.v1.LSVID.MintLSVID:output_type -> spire.api.server.lsvid.v1.MintLSVIDResponse
+	5,  // 12: spire.api.server.lsvid.v1.LSVID.GetLSVIDBundle:output_type -> spire.api.server.lsvid.v1.GetLSVIDBundleResponse
+	7,  // 13: spire.api.server.lsvid.v1.LSVID.RevokeLSVID:output_type -> spire.api.server.lsvid.v1.RevokeLSVIDResponse
+	9,  // 14: spire.api.server.lsvid.v1.LSVID.ListLSVIDRevocations:output_type -> spire.api.server.lsvid.v1.ListLSVIDRevocationsResponse
+	11, // 15: spire.api.server.lsvid.v1.LSVID.DeleteLSVIDRevocation:output_type -> spire.api.server.lsvid.v1.DeleteLSVIDRevocationResponse
+	10, // [10:16] is the sub-list for method output_type
+	4,  // [4:10] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_spire_api_server_lsvid_v1_lsvid_proto_init() }
+func file_spire_api_server_lsvid_v1_lsvid_proto_init() {
+	if File_spire_api_server_lsvid_v1_lsvid_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NewLSVIDRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NewLSVIDResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MintLSVIDRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MintLSVIDResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetLSVIDBundleRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetLSVIDBundleResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RevokeLSVIDRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RevokeLSVIDResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListLSVIDRevocationsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListLSVIDRevocationsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteLSVIDRevocationRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteLSVIDRevocationResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Revocation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Token); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_spire_api_server_lsvid_v1_lsvid_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_spire_api_server_lsvid_v1_lsvid_proto_goTypes,
+		DependencyIndexes: file_spire_api_server_lsvid_v1_lsvid_proto_depIdxs,
+		MessageInfos:      file_spire_api_server_lsvid_v1_lsvid_proto_msgTypes,
+	}.Build()
+	File_spire_api_server_lsvid_v1_lsvid_proto = out.File
+	file_spire_api_server_lsvid_v1_lsvid_proto_rawDesc = nil
+	file_spire_api_server_lsvid_v1_lsvid_proto_goTypes = nil
+	file_spire_api_server_lsvid_v1_lsvid_proto_depIdxs = nil
+}
@@ -0,0 +1,339 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package lsvidv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// LSVIDClient is the client API for LSVID service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type LSVIDClient interface {
+	// Signs a root LSVID layer for the calling agent or for the SPIFFE ID of
+	// a registration entry authorized for the agent. The subject public key
+	// must be bound to a valid X509-SVID issued by the server. LSVIDs for the
+	// trust domain itself are only signed as trust bundle documents.
+	//
+	// The caller must be an agent.
+	NewLSVID(ctx context.Context, in *NewLSVIDRequest, opts ...grpc.CallOption) (*NewLSVIDResponse, error)
+	// Mints a root LSVID layer for an arbitrary SPIFFE ID in the trust
+	// domain of the server, e.g. for services that run outside of any agent.
+	// The subject public key is not bound to an X509-SVID.
+	//
+	// The caller must be local or present an admin X509-SVID.
+	MintLSVID(ctx context.Context, in *MintLSVIDRequest, opts ...grpc.CallOption) (*MintLSVIDResponse, error)
+	// Returns the trust bundle document of the trust domain of the server or
+	// of a federated trust domain, signed with the X509 CA key of the server.
+	// The document lists the LSVID authorities of the trust domain that have
+	// not expired, so that offline verifiers can anchor root layers signed by
+	// current and previous keys.
+	//
+	// The caller must be an agent, local or present an admin X509-SVID.
+	GetLSVIDBundle(ctx context.Context, in *GetLSVIDBundleRequest, opts ...grpc.CallOption) (*GetLSVIDBundleResponse, error)
+	// Revokes the LSVID layers matching the given kind and value. Agents
+	// receive the revocations when they synchronize with the server and
+	// reject any LSVID containing a revoked layer.
+	//
+	// The caller must be local or present an admin X509-SVID.
+	RevokeLSVID(ctx context.Context, in *RevokeLSVIDRequest, opts ...grpc.CallOption) (*RevokeLSVIDResponse, error)
+	// Lists the LSVID revocations.
+	//
+	// The caller must be an agent, local or present an admin X509-SVID.
+	ListLSVIDRevocations(ctx context.Context, in *ListLSVIDRevocationsRequest, opts ...grpc.CallOption) (*ListLSVIDRevocationsResponse, error)
+	// Deletes an LSVID revocation.
+	//
+	// The caller must be local or present an admin X509-SVID.
+	DeleteLSVIDRevocation(ctx context.Context, in *DeleteLSVIDRevocationRequest, opts ...grpc.CallOption) (*DeleteLSVIDRevocationResponse, error)
+}
+
+type lSVIDClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLSVIDClient(cc grpc.ClientConnInterface) LSVIDClient {
+	return &lSVIDClient{cc}
+}
+
+func (c *lSVIDClient) NewLSVID(ctx context.Context, in *NewLSVIDRequest, opts ...grpc.CallOption) (*NewLSVIDResponse, error) {
+	out := new(NewLSVIDResponse)
+	err := c.cc.Invoke(ctx, "/spire.api.server.lsvid.v1.LSVID/NewLSVID", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lSVIDClient) MintLSVID(ctx context.Context, in *MintLSVIDRequest, opts ...grpc.CallOption) (*MintLSVIDResponse, error) {
+	out := new(MintLSVIDResponse)
+	err := c.cc.Invoke(ctx, "/spire.api.server.lsvid.v1.LSVID/MintLSVID", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lSVIDClient) GetLSVIDBundle(ctx context.Context, in *GetLSVIDBundleRequest, opts ...grpc.CallOption) (*GetLSVIDBundleResponse, error) {
+	out := new(GetLSVIDBundleResponse)
+	err := c.cc.Invoke(ctx, "/spire.api.server.lsvid.v1.LSVID/GetLSVIDBundle", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lSVIDClient) RevokeLSVID(ctx context.Context, in *RevokeLSVIDRequest, opts ...grpc.CallOption) (*RevokeLSVIDResponse, error) {
+	out := new(RevokeLSVIDResponse)
+	err := c.cc.Invoke(ctx, "/spire.api.server.lsvid.v1.LSVID/RevokeLSVID", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lSVIDClient) ListLSVIDRevocations(ctx context.Context, in *ListLSVIDRevocationsRequest, opts ...grpc.CallOption) (*ListLSVIDRevocationsResponse, error) {
+	out := new(ListLSVIDRevocationsResponse)
+	err := c.cc.Invoke(ctx, "/spire.api.server.lsvid.v1.LSVID/ListLSVIDRevocations", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lSVIDClient) DeleteLSVIDRevocation(ctx context.Context, in *DeleteLSVIDRevocationRequest, opts ...grpc.CallOption) (*DeleteLSVIDRevocationResponse, error) {
+	out := new(DeleteLSVIDRevocationResponse)
+	err := c.cc.Invoke(ctx, "/spire.api.server.lsvid.v1.LSVID/DeleteLSVIDRevocation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LSVIDServer is the server API for LSVID service.
+// All implementations must embed UnimplementedLSVIDServer
+// for forward compatibility
+type LSVIDServer interface {
+	// Signs a root LSVID layer for the calling agent or for the SPIFFE ID of
+	// a registration entry authorized for the agent. The subject public key
+	// must be bound to a valid X509-SVID issued by the server. LSVIDs for the
+	// trust domain itself are only signed as trust bundle documents.
+	//
+	// The caller must be an agent.
+	NewLSVID(context.Context, *NewLSVIDRequest) (*NewLSVIDResponse, error)
+	// Mints a root LSVID layer for an arbitrary SPIFFE ID in the trust
+	// domain of the server, e.g. for services that run outside of any agent.
+	// The subject public key is not bound to an X509-SVID.
+	//
+	// The caller must be local or present an admin X509-SVID.
+	MintLSVID(context.Context, *MintLSVIDRequest) (*MintLSVIDResponse, error)
+	// Returns the trust bundle document of the trust domain of the server or
+	// of a federated trust domain, signed with the X509 CA key of the server.
+	// The document lists the LSVID authorities of the trust domain that have
+	// not expired, so that offline verifiers can anchor root layers signed by
+	// current and previous keys.
+	//
+	// The caller must be an agent, local or present an admin X509-SVID.
+	GetLSVIDBundle(context.Context, *GetLSVIDBundleRequest) (*GetLSVIDBundleResponse, error)
+	// Revokes the LSVID layers matching the given kind and value. Agents
+	// receive the revocations when they synchronize with the server and
+	// reject any LSVID containing a revoked layer.
+	//
+	// The caller must be local or present an admin X509-SVID.
+	RevokeLSVID(context.Context, *RevokeLSVIDRequest) (*RevokeLSVIDResponse, error)
+	// Lists the LSVID revocations.
+	//
+	// The caller must be an agent, local or present an admin X509-SVID.
+	ListLSVIDRevocations(context.Context, *ListLSVIDRevocationsRequest) (*ListLSVIDRevocationsResponse, error)
+	// Deletes an LSVID revocation.
+	//
+	// The caller must be local or present an admin X509-SVID.
+	DeleteLSVIDRevocation(context.Context, *DeleteLSVIDRevocationRequest) (*DeleteLSVIDRevocationResponse, error)
+	mustEmbedUnimplementedLSVIDServer()
+}
+
+// UnimplementedLSVIDServer must be embedded to have forward compatible implementations.
+type UnimplementedLSVIDServer struct {
+}
+
+func (UnimplementedLSVIDServer) NewLSVID(context.Context, *NewLSVIDRequest) (*NewLSVIDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NewLSVID not implemented")
+}
+func (UnimplementedLSVIDServer) MintLSVID(context.Context, *MintLSVIDRequest) (*MintLSVIDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MintLSVID not implemented")
+}
+func (UnimplementedLSVIDServer) GetLSVIDBundle(context.Context, *GetLSVIDBundleRequest) (*GetLSVIDBundleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLSVIDBundle not implemented")
+}
+func (UnimplementedLSVIDServer) RevokeLSVID(context.Context, *RevokeLSVIDRequest) (*RevokeLSVIDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeLSVID not implemented")
+}
+func (UnimplementedLSVIDServer) ListLSVIDRevocations(context.Context, *ListLSVIDRevocationsRequest) (*ListLSVIDRevocationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLSVIDRevocations not implemented")
+}
+func (UnimplementedLSVIDServer) DeleteLSVIDRevocation(context.Context, *DeleteLSVIDRevocationRequest) (*DeleteLSVIDRevocationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteLSVIDRevocation not implemented")
+}
+func (UnimplementedLSVIDServer) mustEmbedUnimplementedLSVIDServer() {}
+
+// UnsafeLSVIDServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LSVIDServer will
+// result in compilation errors.
+type UnsafeLSVIDServer interface {
+	mustEmbedUnimplementedLSVIDServer()
+}
+
+func RegisterLSVIDServer(s grpc.ServiceRegistrar, srv LSVIDServer) {
+	s.RegisterService(&LSVID_ServiceDesc, srv)
+}
+
+func _LSVID_NewLSVID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NewLSVIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LSVIDServer).NewLSVID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/spire.api.server.lsvid.v1.LSVID/NewLSVID",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LSVIDServer).NewLSVID(ctx, req.(*NewLSVIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LSVID_MintLSVID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MintLSVIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LSVIDServer).MintLSVID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/spire.api.server.lsvid.v1.LSVID/MintLSVID",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LSVIDServer).MintLSVID(ctx, req.(*MintLSVIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LSVID_GetLSVIDBundle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLSVIDBundleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LSVIDServer).GetLSVIDBundle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/spire.api.server.lsvid.v1.LSVID/GetLSVIDBundle",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LSVIDServer).GetLSVIDBundle(ctx, req.(*GetLSVIDBundleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LSVID_RevokeLSVID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeLSVIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LSVIDServer).RevokeLSVID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/spire.api.server.lsvid.v1.LSVID/RevokeLSVID",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LSVIDServer).RevokeLSVID(ctx, req.(*RevokeLSVIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LSVID_ListLSVIDRevocations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLSVIDRevocationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LSVIDServer).ListLSVIDRevocations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/spire.api.server.lsvid.v1.LSVID/ListLSVIDRevocations",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LSVIDServer).ListLSVIDRevocations(ctx, req.(*ListLSVIDRevocationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LSVID_DeleteLSVIDRevocation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteLSVIDRevocationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LSVIDServer).DeleteLSVIDRevocation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/spire.api.server.lsvid.v1.LSVID/DeleteLSVIDRevocation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LSVIDServer).DeleteLSVIDRevocation(ctx, req.(*DeleteLSVIDRevocationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// LSVID_ServiceDesc is the grpc.ServiceDesc for LSVID service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LSVID_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "spire.api.server.lsvid.v1.LSVID",
+	HandlerType: (*LSVIDServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "NewLSVID",
+			Handler:    _LSVID_NewLSVID_Handler,
+		},
+		{
+			MethodName: "MintLSVID",
+			Handler:    _LSVID_MintLSVID_Handler,
+		},
+		{
+			MethodName: "GetLSVIDBundle",
+			Handler:    _LSVID_GetLSVIDBundle_Handler,
+		},
+		{
+			MethodName: "RevokeLSVID",
+			Handler:    _LSVID_RevokeLSVID_Handler,
+		},
+		{
+			MethodName: "ListLSVIDRevocations",
+			Handler:    _LSVID_ListLSVIDRevocations_Handler,
+		},
+		{
+			MethodName: "DeleteLSVIDRevocation",
+			Handler:    _LSVID_DeleteLSVIDRevocation_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "spire/api/server/lsvid/v1/lsvid.proto",
+}
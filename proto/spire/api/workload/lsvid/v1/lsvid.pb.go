@@ -0,0 +1,961 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        v3.14.0
+// source: spire/api/workload/lsvid/v1/lsvid.proto
+
+package lsvidv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type LSVIDRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Optional. SPIFFE ID of the identity to fetch the LSVID for. If unset,
+	// the LSVIDs of all the identities of the caller are returned.
+	SpiffeId string `protobuf:"bytes,1,opt,name=spiffe_id,json=spiffeId,proto3" json:"spiffe_id,omitempty"`
+	// Optional. If set, the LSVID trust bundle document of the trust domain
+	// of the agent is embedded in the returned LSVID documents, so that they
+	// can be validated offline with the trust domain key alone.
+	IncludeBundle bool `protobuf:"varint,2,opt,name=include_bundle,json=includeBundle,proto3" json:"include_bundle,omitempty"`
+}
+
+func (x *LSVIDRequest) Reset() {
+	*x = LSVIDRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LSVIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LSVIDRequest) ProtoMessage() {}
+
+func (x *LSVIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LSVIDRequest.ProtoReflect.Descriptor instead.
+func (*LSVIDRequest) Descriptor() ([]byte, []int) {
+	return file_spire_api_workload_lsvid_v1_lsvid_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *LSVIDRequest) GetSpiffeId() string {
+	if x != nil {
+		return x.SpiffeId
+	}
+	return ""
+}
+
+func (x *LSVIDRequest) GetIncludeBundle() bool {
+	if x != nil {
+		return x.IncludeBundle
+	}
+	return false
+}
+
+type LSVIDResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The LSVIDs of the caller.
+	Lsvids []*LSVID `protobuf:"bytes,1,rep,name=lsvids,proto3" json:"lsvids,omitempty"`
+}
+
+func (x *LSVIDResponse) Reset() {
+	*x = LSVIDResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LSVIDResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LSVIDResponse) ProtoMessage() {}
+
+func (x *LSVIDResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LSVIDResponse.ProtoReflect.Descriptor instead.
+func (*LSVIDResponse) Descriptor() ([]byte, []int) {
+	return file_spire_api_workload_lsvid_v1_lsvid_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *LSVIDResponse) GetLsvids() []*LSVID {
+	if x != nil {
+		return x.Lsvids
+	}
+	return nil
+}
+
+type LSVID struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// SPIFFE ID of the LSVID subject.
+	SpiffeId string `protobuf:"bytes,1,opt,name=spiffe_id,json=spiffeId,proto3" json:"spiffe_id,omitempty"`
+	// The encoded LSVID document, holding the LSVID and, if requested, the
+	// LSVID trust bundle document it is anchored in.
+	Lsvid string `protobuf:"bytes,2,opt,name=lsvid,proto3" json:"lsvid,omitempty"`
+	// Expiration of the LSVID (seconds since Unix epoch).
+	ExpiresAt int64 `protobuf:"varint,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+}
+
+func (x *LSVID) Reset() {
+	*x = LSVID{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LSVID) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LSVID) ProtoMessage() {}
+
+func (x *LSVID) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LSVID.ProtoReflect.Descriptor instead.
+func (*LSVID) Descriptor() ([]byte, []int) {
+	return file_spire_api_workload_lsvid_v1_lsvid_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *LSVID) GetSpiffeId() string {
+	if x != nil {
+		return x.SpiffeId
+	}
+	return ""
+}
+
+func (x *LSVID) GetLsvid() string {
+	if x != nil {
+		return x.Lsvid
+	}
+	return ""
+}
+
+func (x *LSVID) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+type LSVIDBundlesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *LSVIDBundlesRequest) Reset() {
+	*x = LSVIDBundlesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LSVIDBundlesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LSVIDBundlesRequest) ProtoMessage() {}
+
+func (x *LSVIDBundlesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LSVIDBundlesRequest.ProtoReflect.Descriptor instead.
+func (*LSVIDBundlesRequest) Descriptor() ([]byte, []int) {
+	return file_spire_api_workload_lsvid_v1_lsvid_proto_rawDescGZIP(), []int{3}
+}
+
+type LSVIDBundlesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The JWT authorities used to validate root LSVID layers, as JWKS
+	// documents, keyed by trust domain ID.
+	Bundles map[string][]byte `protobuf:"bytes,1,rep,name=bundles,proto3" json:"bundles,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// The encoded LSVID trust bundle documents of the trust domain of the
+	// agent and of the federated trust domains, signed by the server and
+	// keyed by trust domain ID. Documents that are not available are
+	// omitted.
+	LsvidBundles map[string]string `protobuf:"bytes,2,rep,name=lsvid_bundles,json=lsvidBundles,proto3" json:"lsvid_bundles,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *LSVIDBundlesResponse) Reset() {
+	*x = LSVIDBundlesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LSVIDBundlesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LSVIDBundlesResponse) ProtoMessage() {}
+
+func (x *LSVIDBundlesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LSVIDBundlesResponse.ProtoReflect.Descriptor instead.
+func (*LSVIDBundlesResponse) Descriptor() ([]byte, []int) {
+	return file_spire_api_workload_lsvid_v1_lsvid_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *LSVIDBundlesResponse) GetBundles() map[string][]byte {
+	if x != nil {
+		return x.Bundles
+	}
+	return nil
+}
+
+func (x *LSVIDBundlesResponse) GetLsvidBundles() map[string]string {
+	if x != nil {
+		return x.LsvidBundles
+	}
+	return nil
+}
+
+type ValidateLSVIDRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Required. The audience the outermost layer must be addressed to.
+	Audience string `protobuf:"bytes,1,opt,name=audience,proto3" json:"audience,omitempty"`
+	// Required. The encoded LSVID, either as a bare token or as a document
+	// returned by FetchLSVID.
+	Lsvid string `protobuf:"bytes,2,opt,name=lsvid,proto3" json:"lsvid,omitempty"`
+	// Optional. If set, the LSVID must be presented with a valid proof of
+	// possession signed by its holder.
+	ProofOfPossession *ProofOfPossession `protobuf:"bytes,3,opt,name=proof_of_possession,json=proofOfPossession,proto3" json:"proof_of_possession,omitempty"`
+}
+
+func (x *ValidateLSVIDRequest) Reset() {
+	*x = ValidateLSVIDRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValidateLSVIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateLSVIDRequest) ProtoMessage() {}
+
+func (x *ValidateLSVIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateLSVIDRequest.ProtoReflect.Descriptor instead.
+func (*ValidateLSVIDRequest) Descriptor() ([]byte, []int) {
+	return file_spire_api_workload_lsvid_v1_lsvid_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ValidateLSVIDRequest) GetAudience() string {
+	if x != nil {
+		return x.Audience
+	}
+	return ""
+}
+
+func (x *ValidateLSVIDRequest) GetLsvid() string {
+	if x != nil {
+		return x.Lsvid
+	}
+	return ""
+}
+
+func (x *ValidateLSVIDRequest) GetProofOfPossession() *ProofOfPossession {
+	if x != nil {
+		return x.ProofOfPossession
+	}
+	return nil
+}
+
+type ProofOfPossession struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Required. The encoded proof of possession.
+	Proof string `protobuf:"bytes,1,opt,name=proof,proto3" json:"proof,omitempty"`
+	// Required. The nonce the proof must be signed over, as supplied by the
+	// caller to the holder.
+	Nonce string `protobuf:"bytes,2,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	// Required. The method of the request the LSVID was presented with.
+	Method string `protobuf:"bytes,3,opt,name=method,proto3" json:"method,omitempty"`
+	// Required. The URI of the request the LSVID was presented with.
+	Uri string `protobuf:"bytes,4,opt,name=uri,proto3" json:"uri,omitempty"`
+}
+
+func (x *ProofOfPossession) Reset() {
+	*x = ProofOfPossession{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProofOfPossession) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProofOfPossession) ProtoMessage() {}
+
+func (x *ProofOfPossession) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProofOfPossession.ProtoReflect.Descriptor instead.
+func (*ProofOfPossession) Descriptor() ([]byte, []int) {
+	return file_spire_api_workload_lsvid_v1_lsvid_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ProofOfPossession) GetProof() string {
+	if x != nil {
+		return x.Proof
+	}
+	return ""
+}
+
+func (x *ProofOfPossession) GetNonce() string {
+	if x != nil {
+		return x.Nonce
+	}
+	return ""
+}
+
+func (x *ProofOfPossession) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *ProofOfPossession) GetUri() string {
+	if x != nil {
+		return x.Uri
+	}
+	return ""
+}
+
+type ValidateLSVIDResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// SPIFFE ID of the LSVID subject.
+	SpiffeId string `protobuf:"bytes,1,opt,name=spiffe_id,json=spiffeId,proto3" json:"spiffe_id,omitempty"`
+	// The standard claims of the outermost layer and the custom claims of
+	// every layer.
+	Claims *structpb.Struct `protobuf:"bytes,2,opt,name=claims,proto3" json:"claims,omitempty"`
+}
+
+func (x *ValidateLSVIDResponse) Reset() {
+	*x = ValidateLSVIDResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValidateLSVIDResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateLSVIDResponse) ProtoMessage() {}
+
+func (x *ValidateLSVIDResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateLSVIDResponse.ProtoReflect.Descriptor instead.
+func (*ValidateLSVIDResponse) Descriptor() ([]byte, []int) {
+	return file_spire_api_workload_lsvid_v1_lsvid_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ValidateLSVIDResponse) GetSpiffeId() string {
+	if x != nil {
+		return x.SpiffeId
+	}
+	return ""
+}
+
+func (x *ValidateLSVIDResponse) GetClaims() *structpb.Struct {
+	if x != nil {
+		return x.Claims
+	}
+	return nil
+}
+
+type ExtendLSVIDRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Required. The encoded LSVID to extend, either as a bare token or as a
+	// document returned by FetchLSVID. Its outermost layer must be addressed
+	// to one of the identities of the caller.
+	Lsvid string `protobuf:"bytes,1,opt,name=lsvid,proto3" json:"lsvid,omitempty"`
+	// Required. The audience of the new layer.
+	Audience string `protobuf:"bytes,2,opt,name=audience,proto3" json:"audience,omitempty"`
+	// Optional. The signature algorithm of the new layer, which must match
+	// the key of the X509-SVID of the caller. Defaults to the algorithm of
+	// that key. SAEdDSA aggregates the signatures of consecutive SAEdDSA
+	// layers.
+	Alg string `protobuf:"bytes,3,opt,name=alg,proto3" json:"alg,omitempty"`
+}
+
+func (x *ExtendLSVIDRequest) Reset() {
+	*x = ExtendLSVIDRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExtendLSVIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtendLSVIDRequest) ProtoMessage() {}
+
+func (x *ExtendLSVIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtendLSVIDRequest.ProtoReflect.Descriptor instead.
+func (*ExtendLSVIDRequest) Descriptor() ([]byte, []int) {
+	return file_spire_api_workload_lsvid_v1_lsvid_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ExtendLSVIDRequest) GetLsvid() string {
+	if x != nil {
+		return x.Lsvid
+	}
+	return ""
+}
+
+func (x *ExtendLSVIDRequest) GetAudience() string {
+	if x != nil {
+		return x.Audience
+	}
+	return ""
+}
+
+func (x *ExtendLSVIDRequest) GetAlg() string {
+	if x != nil {
+		return x.Alg
+	}
+	return ""
+}
+
+type ExtendLSVIDResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// SPIFFE ID of the identity of the caller that issued the new layer.
+	SpiffeId string `protobuf:"bytes,1,opt,name=spiffe_id,json=spiffeId,proto3" json:"spiffe_id,omitempty"`
+	// The extended LSVID, as a bare token.
+	Lsvid string `protobuf:"bytes,2,opt,name=lsvid,proto3" json:"lsvid,omitempty"`
+	// Expiration of the extended LSVID (seconds since Unix epoch).
+	ExpiresAt int64 `protobuf:"varint,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+}
+
+func (x *ExtendLSVIDResponse) Reset() {
+	*x = ExtendLSVIDResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExtendLSVIDResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtendLSVIDResponse) ProtoMessage() {}
+
+func (x *ExtendLSVIDResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtendLSVIDResponse.ProtoReflect.Descriptor instead.
+func (*ExtendLSVIDResponse) Descriptor() ([]byte, []int) {
+	return file_spire_api_workload_lsvid_v1_lsvid_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ExtendLSVIDResponse) GetSpiffeId() string {
+	if x != nil {
+		return x.SpiffeId
+	}
+	return ""
+}
+
+func (x *ExtendLSVIDResponse) GetLsvid() string {
+	if x != nil {
+		return x.Lsvid
+	}
+	return ""
+}
+
+func (x *ExtendLSVIDResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+var File_spire_api_workload_lsvid_v1_lsvid_proto protoreflect.FileDescriptor
+
+var file_spire_api_workload_lsvid_v1_lsvid_proto_rawDesc = []byte{
+	0x0a, 0x27, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x77, 0x6f, 0x72, 0x6b,
+	0x6c, 0x6f, 0x61, 0x64, 0x2f, 0x6c, 0x73, 0x76, 0x69, 0x64, 0x2f, 0x76, 0x31, 0x2f, 0x6c, 0x73,
+	0x76, 0x69, 0x64, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x1b, 0x73, 0x70, 0x69, 0x72, 0x65,
+	0x2e, 0x61, 0x70, 0x69, 0x2e, 0x77, 0x6f, 0x72, 0x6b, 0x6c, 0x6f, 0x61, 0x64, 0x2e, 0x6c, 0x73,
+	0x76, 0x69, 0x64, 0x2e, 0x76, 0x31, 0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x22, 0x52, 0x0a, 0x0c, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x70, 0x69, 0x66, 0x66, 0x65, 0x5f, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x70, 0x69, 0x66, 0x66, 0x65, 0x49,
+	0x64, 0x12, 0x25, 0x0a, 0x0e, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x5f, 0x62, 0x75, 0x6e,
+	0x64, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x69, 0x6e, 0x63, 0x6c, 0x75,
+	0x64, 0x65, 0x42, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x22, 0x4b, 0x0a, 0x0d, 0x4c, 0x53, 0x56, 0x49,
+	0x44, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3a, 0x0a, 0x06, 0x6c, 0x73, 0x76,
+	0x69, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x73, 0x70, 0x69, 0x72,
+	0x65, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x77, 0x6f, 0x72, 0x6b, 0x6c, 0x6f, 0x61, 0x64, 0x2e, 0x6c,
+	0x73, 0x76, 0x69, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x52, 0x06, 0x6c,
+	0x73, 0x76, 0x69, 0x64, 0x73, 0x22, 0x59, 0x0a, 0x05, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x12, 0x1b,
+	0x0a, 0x09, 0x73, 0x70, 0x69, 0x66, 0x66, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x73, 0x70, 0x69, 0x66, 0x66, 0x65, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x6c,
+	0x73, 0x76, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x73, 0x76, 0x69,
+	0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74,
+	0x22, 0x15, 0x0a, 0x13, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x42, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0xd7, 0x02, 0x0a, 0x14, 0x4c, 0x53, 0x56, 0x49,
+	0x44, 0x42, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x58, 0x0a, 0x07, 0x62, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x3e, 0x2e, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x77, 0x6f,
+	0x72, 0x6b, 0x6c, 0x6f, 0x61, 0x64, 0x2e, 0x6c, 0x73, 0x76, 0x69, 0x64, 0x2e, 0x76, 0x31, 0x2e,
+	0x4c, 0x53, 0x56, 0x49, 0x44, 0x42, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x42, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x07, 0x62, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x12, 0x68, 0x0a, 0x0d, 0x6c, 0x73,
+	0x76, 0x69, 0x64, 0x5f, 0x62, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x43, 0x2e, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x77, 0x6f,
+	0x72, 0x6b, 0x6c, 0x6f, 0x61, 0x64, 0x2e, 0x6c, 0x73, 0x76, 0x69, 0x64, 0x2e, 0x76, 0x31, 0x2e,
+	0x4c, 0x53, 0x56, 0x49, 0x44, 0x42, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x4c, 0x73, 0x76, 0x69, 0x64, 0x42, 0x75, 0x6e, 0x64, 0x6c, 0x65,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0c, 0x6c, 0x73, 0x76, 0x69, 0x64, 0x42, 0x75, 0x6e,
+	0x64, 0x6c, 0x65, 0x73, 0x1a, 0x3a, 0x0a, 0x0c, 0x42, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01,
+	0x1a, 0x3f, 0x0a, 0x11, 0x4c, 0x73, 0x76, 0x69, 0x64, 0x42, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x73,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38,
+	0x01, 0x22, 0xa8, 0x01, 0x0a, 0x14, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x4c, 0x53,
+	0x56, 0x49, 0x44, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x75,
+	0x64, 0x69, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x61, 0x75,
+	0x64, 0x69, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x73, 0x76, 0x69, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x73, 0x76, 0x69, 0x64, 0x12, 0x5e, 0x0a, 0x13,
+	0x70, 0x72, 0x6f, 0x6f, 0x66, 0x5f, 0x6f, 0x66, 0x5f, 0x70, 0x6f, 0x73, 0x73, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2e, 0x2e, 0x73, 0x70, 0x69, 0x72,
+	0x65, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x77, 0x6f, 0x72, 0x6b, 0x6c, 0x6f, 0x61, 0x64, 0x2e, 0x6c,
+	0x73, 0x76, 0x69, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x4f, 0x66, 0x50,
+	0x6f, 0x73, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x11, 0x70, 0x72, 0x6f, 0x6f, 0x66,
+	0x4f, 0x66, 0x50, 0x6f, 0x73, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x69, 0x0a, 0x11,
+	0x50, 0x72, 0x6f, 0x6f, 0x66, 0x4f, 0x66, 0x50, 0x6f, 0x73, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x72, 0x6f, 0x6f, 0x66, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x70, 0x72, 0x6f, 0x6f, 0x66, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x6f, 0x6e, 0x63, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x12, 0x16, 0x0a,
+	0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d,
+	0x65, 0x74, 0x68, 0x6f, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x69, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x69, 0x22, 0x65, 0x0a, 0x15, 0x56, 0x61, 0x6c, 0x69, 0x64,
+	0x61, 0x74, 0x65, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x1b, 0x0a, 0x09, 0x73, 0x70, 0x69, 0x66, 0x66, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x70, 0x69, 0x66, 0x66, 0x65, 0x49, 0x64, 0x12, 0x2f, 0x0a,
+	0x06, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x52, 0x06, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x73, 0x22, 0x58,
+	0x0a, 0x12, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x64, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x73, 0x76, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x73, 0x76, 0x69, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x75,
+	0x64, 0x69, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x61, 0x75,
+	0x64, 0x69, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x61, 0x6c, 0x67, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x61, 0x6c, 0x67, 0x22, 0x67, 0x0a, 0x13, 0x45, 0x78, 0x74, 0x65,
+	0x6e, 0x64, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x1b, 0x0a, 0x09, 0x73, 0x70, 0x69, 0x66, 0x66, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x73, 0x70, 0x69, 0x66, 0x66, 0x65, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05,
+	0x6c, 0x73, 0x76, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x73, 0x76,
+	0x69, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41,
+	0x74, 0x32, 0xca, 0x04, 0x0a, 0x10, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x57, 0x6f, 0x72, 0x6b, 0x6c,
+	0x6f, 0x61, 0x64, 0x41, 0x50, 0x49, 0x12, 0x63, 0x0a, 0x0a, 0x46, 0x65, 0x74, 0x63, 0x68, 0x4c,
+	0x53, 0x56, 0x49, 0x44, 0x12, 0x29, 0x2e, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x77, 0x6f, 0x72, 0x6b, 0x6c, 0x6f, 0x61, 0x64, 0x2e, 0x6c, 0x73, 0x76, 0x69, 0x64, 0x2e,
+	0x76, 0x31, 0x2e, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x2a, 0x2e, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x77, 0x6f, 0x72, 0x6b,
+	0x6c, 0x6f, 0x61, 0x64, 0x2e, 0x6c, 0x73, 0x76, 0x69, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x53,
+	0x56, 0x49, 0x44, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x6b, 0x0a, 0x10, 0x46,
+	0x65, 0x74, 0x63, 0x68, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12,
+	0x29, 0x2e, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x77, 0x6f, 0x72, 0x6b,
+	0x6c, 0x6f, 0x61, 0x64, 0x2e, 0x6c, 0x73, 0x76, 0x69, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x53,
+	0x56, 0x49, 0x44, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2a, 0x2e, 0x73, 0x70, 0x69,
+	0x72, 0x65, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x77, 0x6f, 0x72, 0x6b, 0x6c, 0x6f, 0x61, 0x64, 0x2e,
+	0x6c, 0x73, 0x76, 0x69, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x7a, 0x0a, 0x11, 0x46, 0x65, 0x74, 0x63,
+	0x68, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x42, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x12, 0x30, 0x2e,
+	0x73, 0x70, 0x69, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x77, 0x6f, 0x72, 0x6b, 0x6c, 0x6f,
+	0x61, 0x64, 0x2e, 0x6c, 0x73, 0x76, 0x69, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x53, 0x56, 0x49,
+	0x44, 0x42, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x31, 0x2e, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x77, 0x6f, 0x72, 0x6b,
+	0x6c, 0x6f, 0x61, 0x64, 0x2e, 0x6c, 0x73, 0x76, 0x69, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x53,
+	0x56, 0x49, 0x44, 0x42, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x30, 0x01, 0x12, 0x76, 0x0a, 0x0d, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65,
+	0x4c, 0x53, 0x56, 0x49, 0x44, 0x12, 0x31, 0x2e, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e, 0x61, 0x70,
+	0x69, 0x2e, 0x77, 0x6f, 0x72, 0x6b, 0x6c, 0x6f, 0x61, 0x64, 0x2e, 0x6c, 0x73, 0x76, 0x69, 0x64,
+	0x2e, 0x76, 0x31, 0x2e, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x4c, 0x53, 0x56, 0x49,
+	0x44, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x32, 0x2e, 0x73, 0x70, 0x69, 0x72, 0x65,
+	0x2e, 0x61, 0x70, 0x69, 0x2e, 0x77, 0x6f, 0x72, 0x6b, 0x6c, 0x6f, 0x61, 0x64, 0x2e, 0x6c, 0x73,
+	0x76, 0x69, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x4c,
+	0x53, 0x56, 0x49, 0x44, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x70, 0x0a, 0x0b,
+	0x45, 0x78, 0x74, 0x65, 0x6e, 0x64, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x12, 0x2f, 0x2e, 0x73, 0x70,
+	0x69, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x77, 0x6f, 0x72, 0x6b, 0x6c, 0x6f, 0x61, 0x64,
+	0x2e, 0x6c, 0x73, 0x76, 0x69, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x64,
+	0x4c, 0x53, 0x56, 0x49, 0x44, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x30, 0x2e, 0x73,
+	0x70, 0x69, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x77, 0x6f, 0x72, 0x6b, 0x6c, 0x6f, 0x61,
+	0x64, 0x2e, 0x6c, 0x73, 0x76, 0x69, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x74, 0x65, 0x6e,
+	0x64, 0x4c, 0x53, 0x56, 0x49, 0x44, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x43,
+	0x5a, 0x41, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x70, 0x69,
+	0x66, 0x66, 0x65, 0x2f, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f,
+	0x73, 0x70, 0x69, 0x72, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x77, 0x6f, 0x72, 0x6b, 0x6c, 0x6f,
+	0x61, 0x64, 0x2f, 0x6c, 0x73, 0x76, 0x69, 0x64, 0x2f, 0x76, 0x31, 0x3b, 0x6c, 0x73, 0x76, 0x69,
+	0x64, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_spire_api_workload_lsvid_v1_lsvid_proto_rawDescOnce sync.Once
+	file_spire_api_workload_lsvid_v1_lsvid_proto_rawDescData = file_spire_api_workload_lsvid_v1_lsvid_proto_rawDesc
+)
+
+func file_spire_api_workload_lsvid_v1_lsvid_proto_rawDescGZIP() []byte {
+	file_spire_api_workload_lsvid_v1_lsvid_proto_rawDescOnce.Do(func() {
+		file_spire_api_workload_lsvid_v1_lsvid_proto_rawDescData = protoimpl.X.CompressGZIP(file_spire_api_workload_lsvid_v1_lsvid_proto_rawDescData)
+	})
+	return file_spire_api_workload_lsvid_v1_lsvid_proto_rawDescData
+}
+
+var file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_spire_api_workload_lsvid_v1_lsvid_proto_goTypes = []interface{}{
+	(*LSVIDRequest)(nil),          // 0: spire.api.workload.lsvid.v1.LSVIDRequest
+	(*LSVIDResponse)(nil),         // 1: spire.api.workload.lsvid.v1.LSVIDResponse
+	(*LSVID)(nil),                 // 2: spire.api.workload.lsvid.v1.LSVID
+	(*LSVIDBundlesRequest)(nil),   // 3: spire.api.workload.lsvid.v1.LSVIDBundlesRequest
+	(*LSVIDBundlesResponse)(nil),  // 4: spire.api.workload.lsvid.v1.LSVIDBundlesResponse
+	(*ValidateLSVIDRequest)(nil),  // 5: spire.api.workload.lsvid.v1.ValidateLSVIDRequest
+	(*ProofOfPossession)(nil),     // 6: spire.api.workload.lsvid.v1.ProofOfPossession
+	(*ValidateLSVIDResponse)(nil), // 7: spire.api.workload.lsvid.v1.ValidateLSVIDResponse
+	(*ExtendLSVIDRequest)(nil),    // 8: spire.api.workload.lsvid.v1.ExtendLSVIDRequest
+	(*ExtendLSVIDResponse)(nil),   // 9: spire.api.workload.lsvid.v1.ExtendLSVIDResponse
+	nil,                           // 10: spire.api.workload.lsvid.v1.LSVIDBundlesResponse.BundlesEntry
+	nil,                           // 11: spire.api.workload.lsvid.v1.LSVIDBundlesResponse.LsvidBundlesEntry
+	(*structpb.Struct)(nil),       // 12: google.protobuf.Struct
+}
+var file_spire_api_workload_lsvid_v1_lsvid_proto_depIdxs = []int32{
+	2,  // 0: spire.api.workload.lsvid.v1.LSVIDResponse.lsvids:type_name -> spire.api.workload.lsvid.v1.LSVID
+	10, // 1: spire.api.workload.lsvid.v1.LSVIDBundlesResponse.bundles:type_name -> spire.api.workload.lsvid.v1.LSVIDBundlesResponse.BundlesEntry
+	11, // 2: spire.api.workload.lsvid.v1.LSVIDBundlesResponse.lsvid_bundles:type_name -> spire.api.workload.lsvid.v1.LSVIDBundlesResponse.LsvidBundlesEntry
+	6,  // 3: spire.api.workload.lsvid.v1.ValidateLSVIDRequest.proof_of_possession:type_name -> spire.api.workload.lsvid.v1.ProofOfPossession
+	12, // 4: spire.api.workload.lsvid.v1.ValidateLSVIDResponse.claims:type_name -> google.protobuf.Struct
+	0,  // 5: spire.api.workload.lsvid.v1.LSVIDWorkloadAPI.FetchLSVID:input_type -> spire.api.workload.lsvid.v1.LSVIDRequest
+	0,  // 6: spire.api.workload.lsvid.v1.LSVIDWorkloadAPI.FetchLSVIDStream:input_type -> spire.api.workload.lsvid.v1.LSVIDRequest
+	3,  // 7: spire.api.workload.lsvid.v1.LSVIDWorkloadAPI.FetchLSVIDBundles:input_type -> spire.api.workload.lsvid.v1.LSVIDBundlesRequest
+	5,  // 8: spire.api.workload.lsvid.v1.LSVIDWorkloadAPI.ValidateLSVID:input_type -> spire.api.workload.lsvid.v1.ValidateLSVIDRequest
+	8,  // 9: spire.api.workload.lsvid.v1.LSVIDWorkloadAPI.ExtendLSVID:input_type -> spire.api.workload.lsvid.v1.ExtendLSVIDRequest
+	1,  // 10: spire.api.workload.lsvid.v1.LSVIDWorkloadAPI.FetchLSVID:output_type -> spire.api.workload.lsvid.v1.LSVIDResponse
+	1,  // 11: spire.api.workload.lsvid.v1.LSVIDWorkloadAPI.FetchLSVIDStream:output_type -> spire.api.workload.lsvid.v1.LSVIDResponse
+	4,  // 12: spire.api.workload.lsvid.v1.LSVIDWorkloadAPI.FetchLSVIDBundles:output_type -> spire.api.workload.lsvid.v1.LSVIDBundlesResponse
+	7,  // 13: spire.api.workload.lsvid.v1.LSVIDWorkloadAPI.ValidateLSVID:output_type -> spire.api.workload.lsvid.v1.ValidateLSVIDResponse
+	9,  // 14: spire.api.workload.lsvid.v1.LSVIDWorkloadAPI.ExtendLSVID:output_type -> spire.api.workload.lsvid.v1.ExtendLSVIDResponse
+	10, // [10:15] is the sub-list for method output_type
+	5,  // [5:10] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_spire_api_workload_lsvid_v1_lsvid_proto_init() }
+func file_spire_api_workload_lsvid_v1_lsvid_proto_init() {
+	if File_spire_api_workload_lsvid_v1_lsvid_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LSVIDRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LSVIDResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LSVID); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LSVIDBundlesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LSVIDBundlesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidateLSVIDRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProofOfPossession); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidateLSVIDResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExtendLSVIDRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExtendLSVIDResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_spire_api_workload_lsvid_v1_lsvid_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_spire_api_workload_lsvid_v1_lsvid_proto_goTypes,
+		DependencyIndexes: file_spire_api_workload_lsvid_v1_lsvid_proto_depIdxs,
+		MessageInfos:      file_spire_api_workload_lsvid_v1_lsvid_proto_msgTypes,
+	}.Build()
+	File_spire_api_workload_lsvid_v1_lsvid_proto = out.File
+	file_spire_api_workload_lsvid_v1_lsvid_proto_rawDesc = nil
+	file_spire_api_workload_lsvid_v1_lsvid_proto_goTypes = nil
+	file_spire_api_workload_lsvid_v1_lsvid_proto_depIdxs = nil
+}
@@ -0,0 +1,316 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package lsvidv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// LSVIDWorkloadAPIClient is the client API for LSVIDWorkloadAPI service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type LSVIDWorkloadAPIClient interface {
+	// Fetches the LSVIDs of the caller.
+	FetchLSVID(ctx context.Context, in *LSVIDRequest, opts ...grpc.CallOption) (*LSVIDResponse, error)
+	// Fetches the LSVIDs of the caller, and new ones when the identities of
+	// the caller change or the LSVIDs reach half of their lifetime.
+	FetchLSVIDStream(ctx context.Context, in *LSVIDRequest, opts ...grpc.CallOption) (LSVIDWorkloadAPI_FetchLSVIDStreamClient, error)
+	// Fetches the bundles used to validate LSVIDs, and updates when they
+	// change.
+	FetchLSVIDBundles(ctx context.Context, in *LSVIDBundlesRequest, opts ...grpc.CallOption) (LSVIDWorkloadAPI_FetchLSVIDBundlesClient, error)
+	// Validates an LSVID.
+	ValidateLSVID(ctx context.Context, in *ValidateLSVIDRequest, opts ...grpc.CallOption) (*ValidateLSVIDResponse, error)
+	// Extends an LSVID addressed to the caller to a new audience. The new
+	// layer is signed with the key of the X509-SVID of the caller.
+	ExtendLSVID(ctx context.Context, in *ExtendLSVIDRequest, opts ...grpc.CallOption) (*ExtendLSVIDResponse, error)
+}
+
+type lSVIDWorkloadAPIClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLSVIDWorkloadAPIClient(cc grpc.ClientConnInterface) LSVIDWorkloadAPIClient {
+	return &lSVIDWorkloadAPIClient{cc}
+}
+
+func (c *lSVIDWorkloadAPIClient) FetchLSVID(ctx context.Context, in *LSVIDRequest, opts ...grpc.CallOption) (*LSVIDResponse, error) {
+	out := new(LSVIDResponse)
+	err := c.cc.Invoke(ctx, "/spire.api.workload.lsvid.v1.LSVIDWorkloadAPI/FetchLSVID", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lSVIDWorkloadAPIClient) FetchLSVIDStream(ctx context.Context, in *LSVIDRequest, opts ...grpc.CallOption) (LSVIDWorkloadAPI_FetchLSVIDStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LSVIDWorkloadAPI_ServiceDesc.Streams[0], "/spire.api.workload.lsvid.v1.LSVIDWorkloadAPI/FetchLSVIDStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &lSVIDWorkloadAPIFetchLSVIDStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LSVIDWorkloadAPI_FetchLSVIDStreamClient interface {
+	Recv() (*LSVIDResponse, error)
+	grpc.ClientStream
+}
+
+type lSVIDWorkloadAPIFetchLSVIDStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *lSVIDWorkloadAPIFetchLSVIDStreamClient) Recv() (*LSVIDResponse, error) {
+	m := new(LSVIDResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *lSVIDWorkloadAPIClient) FetchLSVIDBundles(ctx context.Context, in *LSVIDBundlesRequest, opts ...grpc.CallOption) (LSVIDWorkloadAPI_FetchLSVIDBundlesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LSVIDWorkloadAPI_ServiceDesc.Streams[1], "/spire.api.workload.lsvid.v1.LSVIDWorkloadAPI/FetchLSVIDBundles", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &lSVIDWorkloadAPIFetchLSVIDBundlesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LSVIDWorkloadAPI_FetchLSVIDBundlesClient interface {
+	Recv() (*LSVIDBundlesResponse, error)
+	grpc.ClientStream
+}
+
+type lSVIDWorkloadAPIFetchLSVIDBundlesClient struct {
+	grpc.ClientStream
+}
+
+func (x *lSVIDWorkloadAPIFetchLSVIDBundlesClient) Recv() (*LSVIDBundlesResponse, error) {
+	m := new(LSVIDBundlesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *lSVIDWorkloadAPIClient) ValidateLSVID(ctx context.Context, in *ValidateLSVIDRequest, opts ...grpc.CallOption) (*ValidateLSVIDResponse, error) {
+	out := new(ValidateLSVIDResponse)
+	err := c.cc.Invoke(ctx, "/spire.api.workload.lsvid.v1.LSVIDWorkloadAPI/ValidateLSVID", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lSVIDWorkloadAPIClient) ExtendLSVID(ctx context.Context, in *ExtendLSVIDRequest, opts ...grpc.CallOption) (*ExtendLSVIDResponse, error) {
+	out := new(ExtendLSVIDResponse)
+	err := c.cc.Invoke(ctx, "/spire.api.workload.lsvid.v1.LSVIDWorkloadAPI/ExtendLSVID", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LSVIDWorkloadAPIServer is the server API for LSVIDWorkloadAPI service.
+// All implementations must embed UnimplementedLSVIDWorkloadAPIServer
+// for forward compatibility
+type LSVIDWorkloadAPIServer interface {
+	// Fetches the LSVIDs of the caller.
+	FetchLSVID(context.Context, *LSVIDRequest) (*LSVIDResponse, error)
+	// Fetches the LSVIDs of the caller, and new ones when the identities of
+	// the caller change or the LSVIDs reach half of their lifetime.
+	FetchLSVIDStream(*LSVIDRequest, LSVIDWorkloadAPI_FetchLSVIDStreamServer) error
+	// Fetches the bundles used to validate LSVIDs, and updates when they
+	// change.
+	FetchLSVIDBundles(*LSVIDBundlesRequest, LSVIDWorkloadAPI_FetchLSVIDBundlesServer) error
+	// Validates an LSVID.
+	ValidateLSVID(context.Context, *ValidateLSVIDRequest) (*ValidateLSVIDResponse, error)
+	// Extends an LSVID addressed to the caller to a new audience. The new
+	// layer is signed with the key of the X509-SVID of the caller.
+	ExtendLSVID(context.Context, *ExtendLSVIDRequest) (*ExtendLSVIDResponse, error)
+	mustEmbedUnimplementedLSVIDWorkloadAPIServer()
+}
+
+// UnimplementedLSVIDWorkloadAPIServer must be embedded to have forward compatible implementations.
+type UnimplementedLSVIDWorkloadAPIServer struct {
+}
+
+func (UnimplementedLSVIDWorkloadAPIServer) FetchLSVID(context.Context, *LSVIDRequest) (*LSVIDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FetchLSVID not implemented")
+}
+func (UnimplementedLSVIDWorkloadAPIServer) FetchLSVIDStream(*LSVIDRequest, LSVIDWorkloadAPI_FetchLSVIDStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method FetchLSVIDStream not implemented")
+}
+func (UnimplementedLSVIDWorkloadAPIServer) FetchLSVIDBundles(*LSVIDBundlesRequest, LSVIDWorkloadAPI_FetchLSVIDBundlesServer) error {
+	return status.Errorf(codes.Unimplemented, "method FetchLSVIDBundles not implemented")
+}
+func (UnimplementedLSVIDWorkloadAPIServer) ValidateLSVID(context.Context, *ValidateLSVIDRequest) (*ValidateLSVIDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateLSVID not implemented")
+}
+func (UnimplementedLSVIDWorkloadAPIServer) ExtendLSVID(context.Context, *ExtendLSVIDRequest) (*ExtendLSVIDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExtendLSVID not implemented")
+}
+func (UnimplementedLSVIDWorkloadAPIServer) mustEmbedUnimplementedLSVIDWorkloadAPIServer() {}
+
+// UnsafeLSVIDWorkloadAPIServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LSVIDWorkloadAPIServer will
+// result in compilation errors.
+type UnsafeLSVIDWorkloadAPIServer interface {
+	mustEmbedUnimplementedLSVIDWorkloadAPIServer()
+}
+
+func RegisterLSVIDWorkloadAPIServer(s grpc.ServiceRegistrar, srv LSVIDWorkloadAPIServer) {
+	s.RegisterService(&LSVIDWorkloadAPI_ServiceDesc, srv)
+}
+
+func _LSVIDWorkloadAPI_FetchLSVID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LSVIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LSVIDWorkloadAPIServer).FetchLSVID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/spire.api.workload.lsvid.v1.LSVIDWorkloadAPI/FetchLSVID",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LSVIDWorkloadAPIServer).FetchLSVID(ctx, req.(*LSVIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LSVIDWorkloadAPI_FetchLSVIDStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LSVIDRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LSVIDWorkloadAPIServer).FetchLSVIDStream(m, &lSVIDWorkloadAPIFetchLSVIDStreamServer{stream})
+}
+
+type LSVIDWorkloadAPI_FetchLSVIDStreamServer interface {
+	Send(*LSVIDResponse) error
+	grpc.ServerStream
+}
+
+type lSVIDWorkloadAPIFetchLSVIDStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *lSVIDWorkloadAPIFetchLSVIDStreamServer) Send(m *LSVIDResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LSVIDWorkloadAPI_FetchLSVIDBundles_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LSVIDBundlesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LSVIDWorkloadAPIServer).FetchLSVIDBundles(m, &lSVIDWorkloadAPIFetchLSVIDBundlesServer{stream})
+}
+
+type LSVIDWorkloadAPI_FetchLSVIDBundlesServer interface {
+	Send(*LSVIDBundlesResponse) error
+	grpc.ServerStream
+}
+
+type lSVIDWorkloadAPIFetchLSVIDBundlesServer struct {
+	grpc.ServerStream
+}
+
+func (x *lSVIDWorkloadAPIFetchLSVIDBundlesServer) Send(m *LSVIDBundlesResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LSVIDWorkloadAPI_ValidateLSVID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateLSVIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LSVIDWorkloadAPIServer).ValidateLSVID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/spire.api.workload.lsvid.v1.LSVIDWorkloadAPI/ValidateLSVID",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LSVIDWorkloadAPIServer).ValidateLSVID(ctx, req.(*ValidateLSVIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LSVIDWorkloadAPI_ExtendLSVID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExtendLSVIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LSVIDWorkloadAPIServer).ExtendLSVID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/spire.api.workload.lsvid.v1.LSVIDWorkloadAPI/ExtendLSVID",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LSVIDWorkloadAPIServer).ExtendLSVID(ctx, req.(*ExtendLSVIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// LSVIDWorkloadAPI_ServiceDesc is the grpc.ServiceDesc for LSVIDWorkloadAPI service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LSVIDWorkloadAPI_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "spire.api.workload.lsvid.v1.LSVIDWorkloadAPI",
+	HandlerType: (*LSVIDWorkloadAPIServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "FetchLSVID",
+			Handler:    _LSVIDWorkloadAPI_FetchLSVID_Handler,
+		},
+		{
+			MethodName: "ValidateLSVID",
+			Handler:    _LSVIDWorkloadAPI_ValidateLSVID_Handler,
+		},
+		{
+			MethodName: "ExtendLSVID",
+			Handler:    _LSVIDWorkloadAPI_ExtendLSVID_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "FetchLSVIDStream",
+			Handler:       _LSVIDWorkloadAPI_FetchLSVIDStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "FetchLSVIDBundles",
+			Handler:       _LSVIDWorkloadAPI_FetchLSVIDBundles_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "spire/api/workload/lsvid/v1/lsvid.proto",
+}
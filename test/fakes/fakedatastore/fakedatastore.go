@@ -309,6 +309,27 @@ func (s *DataStore) UpdateFederationRelationship(ctx context.Context, fr *datast
 	return s.ds.UpdateFederationRelationship(ctx, fr, mask)
 }
 
+func (s *DataStore) CreateLSVIDRevocation(ctx context.Context, revocation *datastore.LSVIDRevocation) (*datastore.LSVIDRevocation, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.CreateLSVIDRevocation(ctx, revocation)
+}
+
+func (s *DataStore) DeleteLSVIDRevocation(ctx context.Context, kind, value string) error {
+	if err := s.getNextError(); err != nil {
+		return err
+	}
+	return s.ds.DeleteLSVIDRevocation(ctx, kind, value)
+}
+
+func (s *DataStore) ListLSVIDRevocations(ctx context.Context, req *datastore.ListLSVIDRevocationsRequest) (*datastore.ListLSVIDRevocationsResponse, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.ListLSVIDRevocations(ctx, req)
+}
+
 func (s *DataStore) SetNextError(err error) {
 	s.errs = []error{err}
 }
@@ -32,6 +32,7 @@ type Options struct {
 	Clock       clock.Clock
 	X509SVIDTTL time.Duration
 	JWTSVIDTTL  time.Duration
+	LSVIDTTL    time.Duration
 }
 
 type CA struct {
@@ -53,6 +54,9 @@ func New(t *testing.T, trustDomain spiffeid.TrustDomain, options *Options) *CA {
 	if options.JWTSVIDTTL == 0 {
 		options.JWTSVIDTTL = time.Minute
 	}
+	if options.LSVIDTTL == 0 {
+		options.LSVIDTTL = time.Minute
+	}
 
 	log, _ := test.NewNullLogger()
 
@@ -73,6 +77,7 @@ func New(t *testing.T, trustDomain spiffeid.TrustDomain, options *Options) *CA {
 		TrustDomain:   trustDomain,
 		X509SVIDTTL:   options.X509SVIDTTL,
 		JWTSVIDTTL:    options.JWTSVIDTTL,
+		LSVIDTTL:      options.LSVIDTTL,
 		Clock:         options.Clock,
 		HealthChecker: healthChecker,
 	})
@@ -105,3 +110,7 @@ func (c *CA) X509SVIDTTL() time.Duration {
 func (c *CA) JWTSVIDTTL() time.Duration {
 	return c.options.JWTSVIDTTL
 }
+
+func (c *CA) LSVIDTTL() time.Duration {
+	return c.options.LSVIDTTL
+}
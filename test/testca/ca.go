@@ -20,6 +20,7 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/go-spiffe/v2/svid/jwtsvid"
 	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"github.com/spiffe/spire/pkg/common/lsvid"
 	"github.com/spiffe/spire/pkg/common/util"
 	"github.com/spiffe/spire/test/testkey"
 	"github.com/stretchr/testify/require"
@@ -119,6 +120,94 @@ func (ca *CA) CreateJWTSVID(id spiffeid.ID, audience []string) *jwtsvid.SVID {
 	return svid
 }
 
+// CreateLSVID creates the root layer of an LSVID for the given X509-SVID,
+// signed by the JWT authority of the CA.
+func (ca *CA) CreateLSVID(svid *x509svid.SVID, audience string) *lsvid.Token {
+	return ca.createLSVID(svid, audience, "")
+}
+
+// CreateCOSELSVID is like CreateLSVID, but encodes the LSVID as COSE. The
+// key of the JWT authority is referenced by key ID rather than embedded.
+func (ca *CA) CreateCOSELSVID(svid *x509svid.SVID, audience string) *lsvid.Token {
+	return ca.createLSVID(svid, audience, lsvid.TypCOSE)
+}
+
+// CreateJWSLSVID is like CreateLSVID, but encodes the LSVID as a JWS compact
+// serialization.
+func (ca *CA) CreateJWSLSVID(svid *x509svid.SVID, audience string) *lsvid.Token {
+	return ca.createLSVID(svid, audience, lsvid.TypJWS)
+}
+
+func (ca *CA) createLSVID(svid *x509svid.SVID, audience, typ string) *lsvid.Token {
+	issuerKey, err := x509.MarshalPKIXPublicKey(ca.jwtKey.Public())
+	require.NoError(ca.tb, err)
+	subjectKey, err := x509.MarshalPKIXPublicKey(svid.Certificates[0].PublicKey)
+	require.NoError(ca.tb, err)
+
+	now := time.Now()
+	token, err := lsvid.Sign(&lsvid.Payload{
+		Ver: lsvid.Version,
+		Typ: typ,
+		Iat: now.Unix(),
+		Exp: now.Add(time.Hour).Unix(),
+		Iss: &lsvid.IDClaim{
+			CN:  ca.td.IDString(),
+			PK:  issuerKey,
+			Kid: ca.jwtKid,
+		},
+		Sub: &lsvid.IDClaim{
+			CN:  svid.ID.String(),
+			PK:  subjectKey,
+			X5T: lsvid.X509SVIDThumbprint(svid.Certificates[0]),
+		},
+		Aud: &lsvid.IDClaim{
+			CN: audience,
+		},
+	}, ca.jwtKey)
+	require.NoError(ca.tb, err)
+	return token
+}
+
+// CreateLSVIDBundle creates an LSVID trust bundle document listing the JWT
+// authority of the CA, signed with the key of the X509 CA certificate.
+func (ca *CA) CreateLSVIDBundle() *lsvid.Token {
+	return ca.CreateLSVIDBundleFor(ca)
+}
+
+// CreateLSVIDBundleFor creates an LSVID trust bundle document listing the JWT
+// authority of the given CA, which may belong to a federated trust domain,
+// signed with the key of the X509 CA certificate.
+func (ca *CA) CreateLSVIDBundleFor(subject *CA) *lsvid.Token {
+	issuerKey, err := x509.MarshalPKIXPublicKey(ca.key.Public())
+	require.NoError(ca.tb, err)
+	jwtKey, err := x509.MarshalPKIXPublicKey(subject.jwtKey.Public())
+	require.NoError(ca.tb, err)
+
+	now := time.Now()
+	token, err := lsvid.Sign(&lsvid.Payload{
+		Ver: lsvid.Version,
+		Iat: now.Unix(),
+		Exp: now.Add(time.Hour).Unix(),
+		Iss: &lsvid.IDClaim{
+			CN: ca.td.IDString(),
+			PK: issuerKey,
+		},
+		Sub: &lsvid.IDClaim{
+			CN: subject.td.IDString(),
+		},
+		Keys: []*lsvid.Authority{
+			{Kid: subject.jwtKid, PK: jwtKey},
+		},
+	}, ca.key)
+	require.NoError(ca.tb, err)
+	return token
+}
+
+// X509CAKey returns the public key of the X509 CA certificate.
+func (ca *CA) X509CAKey() crypto.PublicKey {
+	return ca.key.Public()
+}
+
 func (ca *CA) X509Authorities() []*x509.Certificate {
 	root := ca
 	for root.parent != nil {